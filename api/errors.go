@@ -0,0 +1,130 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/backtests/pkg/export"
+	"github.com/cryptellation/backtests/pkg/montecarlo"
+	"github.com/cryptellation/backtests/pkg/quota"
+	"github.com/cryptellation/backtests/pkg/sweep"
+	"github.com/cryptellation/backtests/pkg/tenant"
+	"go.temporal.io/sdk/temporal"
+)
+
+// ErrorType is a domain error with the stable type name used to send it across
+// Temporal as an application error.
+type ErrorType struct {
+	Name string
+	Err  error
+}
+
+var (
+	// ErrNilID is returned by the database when the ID is nil.
+	ErrNilID = errors.New("ID is nil")
+	// ErrNotFound is returned by the database when the record is not found.
+	ErrNotFound = errors.New("not found")
+	// ErrNotImplemented is returned by the database when the method is not implemented.
+	ErrNotImplemented = errors.New("not implemented")
+	// ErrInvalidCursor is returned by the database when the pagination cursor is invalid.
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrVersionConflict is returned by the database when a record is updated
+	// from an outdated version, as it has been updated concurrently since it
+	// was read.
+	ErrVersionConflict = errors.New("version conflict")
+)
+
+// VersionConflictErrorType is the type of the application error of a version conflict.
+const VersionConflictErrorType = "db.VersionConflict"
+
+// DBErrorTypes are the database errors that can be returned by the activities,
+// then by the workflows. They are all non-retryable.
+var DBErrorTypes = []ErrorType{
+	{Name: "db.NilID", Err: ErrNilID},
+	{Name: "db.NotFound", Err: ErrNotFound},
+	{Name: "db.NotImplemented", Err: ErrNotImplemented},
+	{Name: "db.InvalidCursor", Err: ErrInvalidCursor},
+	{Name: VersionConflictErrorType, Err: ErrVersionConflict},
+}
+
+// ErrorTypes are the errors that can be returned by the workflows, i.e. the
+// domain errors then the database ones. As they come from invalid parameters
+// or states, they are all non-retryable. The first matching type is used, so
+// errors wrapping other domain errors (i.e. invalid purge filters on running
+// backtests) come first.
+var ErrorTypes = append([]ErrorType{
+	{Name: "backtest.InvalidCallbackFailureAction", Err: backtest.ErrInvalidCallbackFailureAction},
+	{Name: "backtest.InvalidCallbackRetryPolicy", Err: backtest.ErrInvalidCallbackRetryPolicy},
+	{Name: "backtest.InvalidExchange", Err: backtest.ErrInvalidExchange},
+	{Name: "backtest.InvalidFee", Err: backtest.ErrInvalidFee},
+	{Name: "backtest.InvalidGapPolicy", Err: backtest.ErrInvalidGapPolicy},
+	{Name: "backtest.InvalidMetadata", Err: backtest.ErrInvalidMetadata},
+	{Name: "backtest.InvalidMetric", Err: backtest.ErrInvalidMetric},
+	{Name: "backtest.InvalidMode", Err: backtest.ErrInvalidMode},
+	{Name: "backtest.InvalidPricePeriod", Err: backtest.ErrInvalidPricePeriod},
+	{Name: "backtest.InvalidPurgeFilters", Err: backtest.ErrInvalidPurgeFilters},
+	{Name: "backtest.InvalidQuoteAsset", Err: backtest.ErrInvalidQuoteAsset},
+	{Name: "backtest.InvalidSnapshot", Err: backtest.ErrInvalidSnapshot},
+	{Name: "backtest.InvalidSnapshotInterval", Err: backtest.ErrInvalidSnapshotInterval},
+	{Name: "backtest.InvalidSortField", Err: backtest.ErrInvalidSortField},
+	{Name: "backtest.InvalidStatus", Err: backtest.ErrInvalidStatus},
+	{Name: "backtest.InvalidSubscription", Err: backtest.ErrInvalidSubscription},
+	{Name: "backtest.InvalidWakeUp", Err: backtest.ErrInvalidWakeUp},
+	{Name: "backtest.InvalidWebhook", Err: backtest.ErrInvalidWebhook},
+	{Name: "backtest.NoDataForOrderValidation", Err: backtest.ErrNoDataForOrderValidation},
+	{Name: "backtest.StartAfterEnd", Err: backtest.ErrStartAfterEnd},
+	{Name: "backtest.TickSubscriptionAlreadyExists", Err: backtest.ErrTickSubscriptionAlreadyExists},
+	{Name: "backtest.BacktestRunning", Err: backtest.ErrBacktestRunning},
+	{Name: "backtest.DataGap", Err: backtest.ErrDataGap},
+	{Name: "export.InvalidDataset", Err: export.ErrInvalidDataset},
+	{Name: "montecarlo.InvalidMethod", Err: montecarlo.ErrInvalidMethod},
+	{Name: "montecarlo.InvalidParameters", Err: montecarlo.ErrInvalidParameters},
+	{Name: "montecarlo.NoTrade", Err: montecarlo.ErrNoTrade},
+	{Name: "quota.Exceeded", Err: quota.ErrExceeded},
+	{Name: "quota.InvalidLimits", Err: quota.ErrInvalidLimits},
+	{Name: "quota.WaitTimeout", Err: quota.ErrWaitTimeout},
+	{Name: "sweep.InvalidSearchSpace", Err: sweep.ErrInvalidSearchSpace},
+	{Name: "sweep.InvalidWindows", Err: sweep.ErrInvalidWindows},
+	{Name: "sweep.NoValidResult", Err: sweep.ErrNoValidResult},
+	{Name: "sweep.TooManyCombinations", Err: sweep.ErrTooManyCombinations},
+	{Name: "tenant.Invalid", Err: tenant.ErrInvalid},
+	{Name: "tenant.Mismatch", Err: tenant.ErrMismatch},
+}, DBErrorTypes...)
+
+// ErrorTypeNames returns the names of the error types.
+func ErrorTypeNames(types []ErrorType) []string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// NewApplicationError converts an error wrapping one of the typed errors, or
+// an application error of one of their types (i.e. from an activity or a child
+// workflow), into a non-retryable application error with the type name and
+// the message of the error. Other errors are returned unchanged.
+func NewApplicationError(err error, types []ErrorType) error {
+	if err == nil {
+		return nil
+	}
+
+	// Keep the type of an application error coming from another call
+	var appErr *temporal.ApplicationError
+	if errors.As(err, &appErr) {
+		for _, t := range types {
+			if appErr.Type() == t.Name {
+				return temporal.NewNonRetryableApplicationError(err.Error(), t.Name, nil)
+			}
+		}
+	}
+
+	// Set the type of a domain error
+	for _, t := range types {
+		if errors.Is(err, t.Err) {
+			return temporal.NewNonRetryableApplicationError(err.Error(), t.Name, nil)
+		}
+	}
+
+	return err
+}
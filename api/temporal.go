@@ -1,7 +1,15 @@
 package api
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/backtests/pkg/export"
+	"github.com/cryptellation/backtests/pkg/montecarlo"
+	"github.com/cryptellation/backtests/pkg/quota"
+	"github.com/cryptellation/backtests/pkg/report"
+	"github.com/cryptellation/backtests/pkg/sweep"
 	"github.com/cryptellation/runtime"
 	"github.com/cryptellation/runtime/account"
 	"github.com/cryptellation/runtime/order"
@@ -11,8 +19,16 @@ import (
 const (
 	// WorkerTaskQueueName is the name of the task queue for the cryptellation worker.
 	WorkerTaskQueueName = "CryptellationbacktestsTaskQueue"
+	// StrategyParametersMemoKey is the key of the strategy parameters in the memo
+	// of the init callback workflow.
+	StrategyParametersMemoKey = "strategy_parameters"
 )
 
+// The Tenant field of the workflow parameters is the owner of the backtests the
+// workflow works on. It is optional when the tenant is propagated with the
+// context (see the tenant package), but it has to match it otherwise. The
+// empty tenant is the default one.
+
 // CreateBacktestWorkflowName is the name of the workflow to create a backtest.
 const CreateBacktestWorkflowName = "CreateBacktestWorkflow"
 
@@ -21,6 +37,10 @@ type (
 	CreateBacktestWorkflowParams struct {
 		BacktestParameters backtest.Parameters
 		Callbacks          runtime.Callbacks
+		// Webhooks are notified when a run of the backtest finishes, fails
+		// or is aborted.
+		Webhooks []backtest.WebhookDefinition
+		Tenant   string
 	}
 
 	// CreateBacktestWorkflowResults is the results of the CreateBacktestWorkflow workflow.
@@ -29,19 +49,214 @@ type (
 	}
 )
 
+// ForkBacktestWorkflowName is the name of the workflow to fork a backtest from a snapshot.
+const ForkBacktestWorkflowName = "ForkBacktestWorkflow"
+
+type (
+	// ForkBacktestWorkflowParams is the parameters of the ForkBacktestWorkflow workflow.
+	ForkBacktestWorkflowParams struct {
+		BacktestID uuid.UUID
+		// Time is the time from which the backtest is forked. The latest snapshot
+		// at or before this time is used.
+		Time      time.Time
+		Callbacks runtime.Callbacks
+		Tenant    string
+	}
+
+	// ForkBacktestWorkflowResults is the results of the ForkBacktestWorkflow workflow.
+	ForkBacktestWorkflowResults struct {
+		ID           uuid.UUID
+		SnapshotTime time.Time
+	}
+)
+
 // RunBacktestWorkflowName is the name of the workflow to run a backtest.
 const RunBacktestWorkflowName = "RunBacktestWorkflow"
 
+// RunBacktestWorkflowID returns the ID of the workflow running a backtest.
+// Being deterministic, it prevents a backtest from running twice at the same
+// time and lets a client attach to a running backtest.
+func RunBacktestWorkflowID(backtestID uuid.UUID) string {
+	return fmt.Sprintf("backtest-%s-run", backtestID.String())
+}
+
 type (
 	// RunBacktestWorkflowParams is the parameters of the RunBacktestWorkflow workflow.
 	RunBacktestWorkflowParams struct {
 		BacktestID uuid.UUID
+		Tenant     string
 	}
 
 	// RunBacktestWorkflowResults is the results of the RunBacktestWorkflow workflow.
 	RunBacktestWorkflowResults struct{}
 )
 
+// BacktestEventsUpdateName is the name of the update of the RunBacktestWorkflow
+// workflow that waits for the events of the run (long polling).
+const BacktestEventsUpdateName = "BacktestEventsUpdate"
+
+// BacktestEventsQueryName is the name of the query of the RunBacktestWorkflow
+// workflow that returns the kept events of the run, even once it is over.
+// It has the same parameters and results as the BacktestEventsUpdate update.
+const BacktestEventsQueryName = "BacktestEventsQuery"
+
+// BacktestEventsPollTimeout is the maximum time that the BacktestEventsUpdate
+// update waits for new events before returning an empty list.
+const BacktestEventsPollTimeout = 30 * time.Second
+
+type (
+	// BacktestEventsUpdateParams is the parameters of the BacktestEventsUpdate update.
+	BacktestEventsUpdateParams struct {
+		// BacktestID is the backtest whose run is followed. It is only used
+		// by the clients to find the run.
+		BacktestID uuid.UUID
+		// AfterSequence is the sequence of the last event received by the
+		// client, or 0 to get all the kept events.
+		AfterSequence int64
+		// Tenant should be the owner of the backtest. The clients set it from
+		// the context when empty, as updates and queries have no propagated
+		// context.
+		Tenant string
+	}
+
+	// BacktestEventsUpdateResults is the results of the BacktestEventsUpdate update.
+	BacktestEventsUpdateResults struct {
+		Events []backtest.Event
+		// Finished is true when the run is over and no more event will come.
+		Finished bool
+	}
+)
+
+// BacktestEventSignalName is the name of the signal sent to the RunBacktestWorkflow
+// workflow to add an event to the run, with a backtest.Event as argument.
+const BacktestEventSignalName = "BacktestEventSignal"
+
+// RunParameterSweepWorkflowName is the name of the workflow to run backtests
+// over a search space of strategy parameters.
+const RunParameterSweepWorkflowName = "RunParameterSweepWorkflow"
+
+type (
+	// RunParameterSweepWorkflowParams is the parameters of the RunParameterSweepWorkflow workflow.
+	RunParameterSweepWorkflowParams struct {
+		// BacktestParameters are the parameters shared by every backtest. Their
+		// strategy parameters are overridden by the ones from the search space.
+		BacktestParameters backtest.Parameters
+		Callbacks          runtime.Callbacks
+		SearchSpace        sweep.SearchSpace
+		// MaxConcurrency is the maximum count of backtests running at the same
+		// time. Defaults to sweep.DefaultMaxConcurrency.
+		MaxConcurrency int
+		// Metric is the metric used to rank the backtests. Defaults to the return.
+		Metric backtest.Metric
+		// QuoteAsset is the asset in which the accounts are valued.
+		QuoteAsset string
+		Tenant     string
+	}
+
+	// RunParameterSweepWorkflowResults is the results of the RunParameterSweepWorkflow workflow.
+	RunParameterSweepWorkflowResults struct {
+		// Results are the backtests results, ranked from best to worst.
+		Results []sweep.Result
+	}
+)
+
+// WalkForwardWorkflowName is the name of the workflow to run a walk-forward
+// optimization of strategy parameters.
+const WalkForwardWorkflowName = "WalkForwardWorkflow"
+
+type (
+	// WalkForwardWorkflowParams is the parameters of the WalkForwardWorkflow workflow.
+	WalkForwardWorkflowParams struct {
+		// BacktestParameters are the parameters shared by every backtest. Their
+		// start and end times are the range split into windows.
+		BacktestParameters backtest.Parameters
+		Callbacks          runtime.Callbacks
+		SearchSpace        sweep.SearchSpace
+		// InSample is the duration of the periods where parameters are optimized.
+		InSample time.Duration
+		// OutOfSample is the duration of the periods where the best parameters
+		// are applied, and the step between two windows.
+		OutOfSample    time.Duration
+		MaxConcurrency int
+		Metric         backtest.Metric
+		QuoteAsset     string
+		Tenant         string
+	}
+
+	// WalkForwardWorkflowResults is the results of the WalkForwardWorkflow workflow.
+	WalkForwardWorkflowResults struct {
+		Windows []sweep.WindowResult
+		// EquityCurve is the combined equity curve of the out-of-sample periods.
+		EquityCurve []backtest.EquityPoint
+		// Stats are the statistics of the combined equity curve.
+		Stats backtest.Stats
+	}
+)
+
+// MonteCarloWorkflowName is the name of the workflow to run a Monte Carlo
+// robustness analysis of a backtest.
+const MonteCarloWorkflowName = "MonteCarloWorkflow"
+
+type (
+	// MonteCarloWorkflowParams is the parameters of the MonteCarloWorkflow workflow.
+	MonteCarloWorkflowParams struct {
+		BacktestID uuid.UUID
+		// QuoteAsset is the asset in which the accounts are valued.
+		QuoteAsset string
+		Parameters montecarlo.Parameters
+		Tenant     string
+	}
+
+	// MonteCarloWorkflowResults is the results of the MonteCarloWorkflow workflow.
+	MonteCarloWorkflowResults struct {
+		Report montecarlo.Report
+	}
+)
+
+// ExportBacktestWorkflowName is the name of the workflow to get the rows of
+// the datasets exported from a backtest.
+const ExportBacktestWorkflowName = "ExportBacktestWorkflow"
+
+type (
+	// ExportBacktestWorkflowParams is the parameters of the ExportBacktestWorkflow workflow.
+	ExportBacktestWorkflowParams struct {
+		BacktestID uuid.UUID
+		// Datasets are the exported datasets. Defaults to all of them.
+		Datasets []export.Dataset
+		// QuoteAsset is the asset in which the equity is valued. It is only
+		// required to export the equity.
+		QuoteAsset string
+		Tenant     string
+	}
+
+	// ExportBacktestWorkflowResults is the results of the ExportBacktestWorkflow workflow.
+	// Only the rows of the requested datasets are set.
+	ExportBacktestWorkflowResults struct {
+		Orders   []export.OrderRow
+		Balances []export.BalanceRow
+		Equity   []export.EquityRow
+	}
+)
+
+// GetBacktestReportWorkflowName is the name of the workflow to get the content
+// of the report of a backtest.
+const GetBacktestReportWorkflowName = "GetBacktestReportWorkflow"
+
+type (
+	// GetBacktestReportWorkflowParams is the parameters of the GetBacktestReportWorkflow workflow.
+	GetBacktestReportWorkflowParams struct {
+		BacktestID uuid.UUID
+		// QuoteAsset is the asset in which the accounts are valued.
+		QuoteAsset string
+		Tenant     string
+	}
+
+	// GetBacktestReportWorkflowResults is the results of the GetBacktestReportWorkflow workflow.
+	GetBacktestReportWorkflowResults struct {
+		Report report.Report
+	}
+)
+
 // GetBacktestWorkflowName is the name of the workflow to get a backtest.
 const GetBacktestWorkflowName = "GetBacktestWorkflow"
 
@@ -49,6 +264,7 @@ type (
 	// GetBacktestWorkflowParams is the parameters of the GetBacktestWorkflow workflow.
 	GetBacktestWorkflowParams struct {
 		BacktestID uuid.UUID
+		Tenant     string
 	}
 
 	// GetBacktestWorkflowResults is the results of the GetBacktestWorkflow workflow.
@@ -57,16 +273,91 @@ type (
 	}
 )
 
+// DeleteBacktestWorkflowName is the name of the workflow to delete a backtest.
+const DeleteBacktestWorkflowName = "DeleteBacktestWorkflow"
+
+type (
+	// DeleteBacktestWorkflowParams is the parameters of the DeleteBacktestWorkflow workflow.
+	DeleteBacktestWorkflowParams struct {
+		BacktestID uuid.UUID
+		Tenant     string
+	}
+
+	// DeleteBacktestWorkflowResults is the results of the DeleteBacktestWorkflow workflow.
+	DeleteBacktestWorkflowResults struct{}
+)
+
+// UpdateBacktestMetadataWorkflowName is the name of the workflow to update the
+// name, description and tags of a backtest.
+const UpdateBacktestMetadataWorkflowName = "UpdateBacktestMetadataWorkflow"
+
+type (
+	// UpdateBacktestMetadataWorkflowParams is the parameters of the UpdateBacktestMetadataWorkflow workflow.
+	UpdateBacktestMetadataWorkflowParams struct {
+		BacktestID uuid.UUID
+		Metadata   backtest.MetadataUpdate
+		Tenant     string
+	}
+
+	// UpdateBacktestMetadataWorkflowResults is the results of the UpdateBacktestMetadataWorkflow workflow.
+	UpdateBacktestMetadataWorkflowResults struct {
+		Backtest backtest.Backtest
+	}
+)
+
+// PurgeBacktestsWorkflowName is the name of the workflow to purge backtests.
+const PurgeBacktestsWorkflowName = "PurgeBacktestsWorkflow"
+
+// DefaultPurgeBatchSize is the default count of backtests deleted per batch
+// when purging backtests.
+const DefaultPurgeBatchSize = 100
+
+type (
+	// PurgeBacktestsWorkflowParams is the parameters of the PurgeBacktestsWorkflow workflow.
+	PurgeBacktestsWorkflowParams struct {
+		Filters backtest.PurgeFilters
+		// BatchSize is the count of backtests deleted per batch.
+		// Defaults to DefaultPurgeBatchSize if 0 or less.
+		BatchSize int
+		Tenant    string
+		// Deleted is the count of backtests deleted by the previous runs of the
+		// workflow, as it continues as new after a number of batches.
+		Deleted int
+		// CreatedBefore is the creation time limit set from the filters by the
+		// first run of the workflow, so it doesn't move in the following ones.
+		CreatedBefore *time.Time
+	}
+
+	// PurgeBacktestsWorkflowResults is the results of the PurgeBacktestsWorkflow workflow.
+	PurgeBacktestsWorkflowResults struct {
+		Deleted int
+	}
+)
+
 // ListBacktestsWorkflowName is the name of the workflow to list backtests.
 const ListBacktestsWorkflowName = "ListBacktestsWorkflow"
 
 type (
 	// ListBacktestsWorkflowParams is the parameters of the ListBacktestsWorkflow workflow.
-	ListBacktestsWorkflowParams struct{}
+	ListBacktestsWorkflowParams struct {
+		Filters backtest.Filters
+		Sort    backtest.Sort
+		// Cursor is the cursor returned with the previous page, empty for the first one.
+		Cursor string
+		// Limit is the maximum count of backtests per page. There is no limit if 0 or less.
+		Limit int
+		// SummaryOnly returns the summaries of the backtests, without their
+		// orders, instead of the backtests.
+		SummaryOnly bool
+		Tenant      string
+	}
 
 	// ListBacktestsWorkflowResults is the results of the ListBacktestsWorkflow workflow.
 	ListBacktestsWorkflowResults struct {
 		Backtests []backtest.Backtest
+		Summaries []backtest.Summary
+		// NextCursor is the cursor of the next page, empty if there is none.
+		NextCursor string
 	}
 )
 
@@ -77,6 +368,7 @@ type (
 	// GetBacktestAccountsWorkflowParams is the parameters of the GetBacktestAccountsWorkflow workflow.
 	GetBacktestAccountsWorkflowParams struct {
 		BacktestID uuid.UUID
+		Tenant     string
 	}
 
 	// GetBacktestAccountsWorkflowResults is the results of the GetBacktestAccountsWorkflow workflow.
@@ -85,6 +377,25 @@ type (
 	}
 )
 
+// GetBacktestStrategyParametersWorkflowName is the name of the workflow to get
+// the strategy parameters of a backtest.
+const GetBacktestStrategyParametersWorkflowName = "GetBacktestStrategyParametersWorkflow"
+
+type (
+	// GetBacktestStrategyParametersWorkflowParams is the parameters of the
+	// GetBacktestStrategyParametersWorkflow workflow.
+	GetBacktestStrategyParametersWorkflowParams struct {
+		BacktestID uuid.UUID
+		Tenant     string
+	}
+
+	// GetBacktestStrategyParametersWorkflowResults is the results of the
+	// GetBacktestStrategyParametersWorkflow workflow.
+	GetBacktestStrategyParametersWorkflowResults struct {
+		StrategyParameters map[string]any
+	}
+)
+
 // CreateBacktestOrderWorkflowName is the name of the workflow to create an order for a backtest.
 const CreateBacktestOrderWorkflowName = "CreateBacktestOrderWorkflow"
 
@@ -93,6 +404,7 @@ type (
 	CreateBacktestOrderWorkflowParams struct {
 		BacktestID uuid.UUID
 		Order      order.Order
+		Tenant     string
 	}
 
 	// CreateBacktestOrderWorkflowResults is the results of the CreateBacktestOrderWorkflow workflow.
@@ -106,6 +418,7 @@ type (
 	// GetBacktestOrdersWorkflowParams is the parameters of the GetBacktestOrdersWorkflow workflow.
 	GetBacktestOrdersWorkflowParams struct {
 		BacktestID uuid.UUID
+		Tenant     string
 	}
 
 	// GetBacktestOrdersWorkflowResults is the results of the GetBacktestOrdersWorkflow workflow.
@@ -123,12 +436,119 @@ type (
 		BacktestID uuid.UUID
 		Exchange   string
 		Pair       string
+		Tenant     string
 	}
 
 	// SubscribeToPriceWorkflowResults is the results of the SubscribeToPriceWorkflow workflow.
 	SubscribeToPriceWorkflowResults struct{}
 )
 
+// SetBacktestWakeUpWorkflowName is the name of the workflow to set the wake up
+// conditions of a backtest strategy.
+const SetBacktestWakeUpWorkflowName = "SetBacktestWakeUpWorkflow"
+
+type (
+	// SetBacktestWakeUpWorkflowParams is the parameters of the SetBacktestWakeUpWorkflow workflow.
+	SetBacktestWakeUpWorkflowParams struct {
+		BacktestID uuid.UUID
+		WakeUp     backtest.WakeUp
+		Tenant     string
+	}
+
+	// SetBacktestWakeUpWorkflowResults is the results of the SetBacktestWakeUpWorkflow workflow.
+	SetBacktestWakeUpWorkflowResults struct{}
+)
+
+// TenantQuotaWorkflowName is the name of the workflow managing the quota of
+// the running backtests of a tenant. It is started on the first request of a
+// slot, and ends once no run holds or waits for a slot for QuotaIdleTimeout.
+const TenantQuotaWorkflowName = "TenantQuotaWorkflow"
+
+// QuotaIdleTimeout is the time after which an unused tenant quota workflow ends.
+const QuotaIdleTimeout = 10 * time.Minute
+
+// QuotaCheckInterval is the interval at which the tenant quota workflow checks
+// that the runs holding or waiting for a slot are still running, in order to
+// reclaim the slots of the ones that ended without releasing them (i.e.
+// terminated, timed out or reset).
+const QuotaCheckInterval = time.Minute
+
+// TenantQuotaWorkflowID returns the ID of the workflow managing the quota of a
+// tenant. Being deterministic, there is only one per tenant.
+func TenantQuotaWorkflowID(tenant string) string {
+	return "backtests-quota-" + tenant
+}
+
+type (
+	// TenantQuotaWorkflowParams is the parameters of the TenantQuotaWorkflow workflow.
+	TenantQuotaWorkflowParams struct {
+		// State is the state kept when the workflow continues as new.
+		State  quota.State
+		Tenant string
+	}
+
+	// TenantQuotaWorkflowResults is the results of the TenantQuotaWorkflow workflow.
+	TenantQuotaWorkflowResults struct{}
+)
+
+// QuotaRequestSignalName is the name of the signal sent to the TenantQuotaWorkflow
+// workflow to request a slot for a run, with a QuotaRequestSignalParams as argument.
+// The run is answered with the QuotaResponseSignalName signal.
+const QuotaRequestSignalName = "QuotaRequestSignal"
+
+// QuotaRequestSignalParams is the argument of the QuotaRequestSignal signal.
+type QuotaRequestSignalParams struct {
+	Run quota.Run
+	// Limits are the current limits of the quota, set by the workers.
+	Limits quota.Limits
+}
+
+// QuotaReleaseSignalName is the name of the signal sent to the TenantQuotaWorkflow
+// workflow to free the slot of a run, with the workflow ID of the run as argument.
+const QuotaReleaseSignalName = "QuotaReleaseSignal"
+
+// QuotaResponseSignalName is the name of the signal sent to a run once it can
+// start, with a QuotaResponseSignalParams as argument.
+const QuotaResponseSignalName = "QuotaResponseSignal"
+
+// QuotaResponseSignalParams is the argument of the QuotaResponseSignal signal.
+type QuotaResponseSignalParams struct {
+	// Error is the reason why the run is refused, empty if it can start.
+	Error string
+}
+
+// QuotaUsageQueryName is the name of the query of the TenantQuotaWorkflow
+// workflow that returns the current usage of the quota.
+const QuotaUsageQueryName = "QuotaUsageQuery"
+
+type (
+	// QuotaUsageQueryParams is the parameters of the QuotaUsageQuery query.
+	QuotaUsageQueryParams struct {
+		Tenant string
+	}
+
+	// QuotaUsageQueryResults is the results of the QuotaUsageQuery query.
+	QuotaUsageQueryResults struct {
+		Usage quota.Usage
+	}
+)
+
+// GetQuotaUsageWorkflowName is the name of the workflow to get the current usage
+// of the quota of a tenant.
+const GetQuotaUsageWorkflowName = "GetQuotaUsageWorkflow"
+
+type (
+	// GetQuotaUsageWorkflowParams is the parameters of the GetQuotaUsageWorkflow workflow.
+	GetQuotaUsageWorkflowParams struct {
+		Tenant string
+	}
+
+	// GetQuotaUsageWorkflowResults is the results of the GetQuotaUsageWorkflow workflow.
+	GetQuotaUsageWorkflowResults struct {
+		Usage quota.Usage
+	}
+)
+
 const (
 	// ServiceInfoWorkflowName is the name of the workflow to get the service info.
 	ServiceInfoWorkflowName = "ServiceInfoWorkflow"
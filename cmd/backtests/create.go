@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/runtime"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// createFile is the content of the YAML file describing a backtest to create:
+// a backtest definition with the callbacks and webhooks of the run.
+type createFile struct {
+	backtest.Definition `yaml:",inline"`
+	Callbacks           struct {
+		TaskQueue   string `yaml:"task_queue"`
+		OnInit      string `yaml:"on_init"`
+		OnNewPrices string `yaml:"on_new_prices"`
+		OnExit      string `yaml:"on_exit"`
+	} `yaml:"callbacks"`
+	Webhooks []backtest.WebhookDefinition `yaml:"webhooks"`
+}
+
+var (
+	createFileFlag          string
+	createNameFlag          string
+	createDescriptionFlag   string
+	createCreatorFlag       string
+	createStartFlag         string
+	createEndFlag           string
+	createModeFlag          string
+	createPeriodFlag        string
+	createBalancesFlag      []string
+	createTagsFlag          []string
+	createParamsFlag        []string
+	createTaskQueueFlag     string
+	createOnInitFlag        string
+	createOnNewPricesFlag   string
+	createOnExitFlag        string
+	createWebhooksFlag      []string
+	createWebhookSecretFlag string
+)
+
+var createCmd = &cobra.Command{
+	Use:     "create",
+	Aliases: []string{"c"},
+	Short:   "Create a backtest from flags or a YAML file",
+	Long: "Create a backtest from flags or a YAML file.\n" +
+		"Flags override the values of the file.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		// Get the backtest definition
+		var def createFile
+		if createFileFlag != "" {
+			content, err := os.ReadFile(createFileFlag)
+			if err != nil {
+				return err
+			}
+			dec := yaml.NewDecoder(bytes.NewReader(content))
+			dec.KnownFields(true)
+			if err := dec.Decode(&def); err != nil {
+				return fmt.Errorf("decoding %q: %w", createFileFlag, err)
+			}
+		}
+		if err := applyCreateFlags(&def); err != nil {
+			return err
+		}
+		params, err := def.toParameters()
+		if err != nil {
+			return err
+		}
+
+		// Create the backtest
+		res, err := raw.CreateBacktest(cmd.Context(), params)
+		if err != nil {
+			return err
+		}
+
+		return render(cmd, map[string]any{"id": res.ID}, table{
+			Rows: [][]string{{res.ID.String()}},
+		})
+	},
+}
+
+func addCreateCommand(cmd *cobra.Command) {
+	flags := createCmd.Flags()
+	flags.StringVarP(&createFileFlag, "file", "f", "", "Set the YAML file describing the backtest")
+	flags.StringVar(&createNameFlag, "name", "", "Set the name")
+	flags.StringVar(&createDescriptionFlag, "description", "", "Set the description")
+	flags.StringVar(&createCreatorFlag, "creator", currentUsername(), "Set the creator")
+	flags.StringVar(&createStartFlag, "start", "", "Set the start time, in RFC 3339 format")
+	flags.StringVar(&createEndFlag, "end", "", "Set the end time, in RFC 3339 format")
+	flags.StringVar(&createModeFlag, "mode", "", "Set the mode (full_ohlc or close_ohlc)")
+	flags.StringVar(&createPeriodFlag, "period", "", "Set the price period (e.g. M1, H1)")
+	flags.StringSliceVar(&createBalancesFlag, "balance", nil,
+		"Add a balance, as exchange:ASSET=amount (e.g. binance:USDT=1000)")
+	flags.StringSliceVar(&createTagsFlag, "tag", nil, "Add a tag")
+	flags.StringSliceVar(&createParamsFlag, "param", nil, "Add a strategy parameter, as key=value")
+	flags.StringVar(&createTaskQueueFlag, "task-queue", "", "Set the task queue of the strategy callbacks")
+	flags.StringVar(&createOnInitFlag, "on-init", "", "Set the workflow called on backtest init")
+	flags.StringVar(&createOnNewPricesFlag, "on-new-prices", "", "Set the workflow called on new prices")
+	flags.StringVar(&createOnExitFlag, "on-exit", "", "Set the workflow called on backtest exit")
+	flags.StringSliceVar(&createWebhooksFlag, "webhook", nil, "Add a webhook URL notified at the end of the runs")
+	flags.StringVar(&createWebhookSecretFlag, "webhook-secret", "",
+		"Set the secret signing the payloads sent to the webhook flags")
+
+	cmd.AddCommand(createCmd)
+}
+
+// applyCreateFlags overrides the definition with the flags that are set.
+func applyCreateFlags(def *createFile) error {
+	if err := applyCreateTimeFlags(def); err != nil {
+		return err
+	}
+
+	setIfNotEmpty(&def.Name, createNameFlag)
+	setIfNotEmpty(&def.Description, createDescriptionFlag)
+	setIfNotEmpty(&def.Mode, createModeFlag)
+	setIfNotEmpty(&def.PricePeriod, createPeriodFlag)
+	setIfNotEmpty(&def.Callbacks.TaskQueue, createTaskQueueFlag)
+	setIfNotEmpty(&def.Callbacks.OnInit, createOnInitFlag)
+	setIfNotEmpty(&def.Callbacks.OnNewPrices, createOnNewPricesFlag)
+	setIfNotEmpty(&def.Callbacks.OnExit, createOnExitFlag)
+	def.Tags = append(def.Tags, createTagsFlag...)
+	for _, u := range createWebhooksFlag {
+		def.Webhooks = append(def.Webhooks, backtest.WebhookDefinition{URL: u, Secret: createWebhookSecretFlag})
+	}
+
+	for _, b := range createBalancesFlag {
+		exchange, asset, amount, err := parseBalance(b)
+		if err != nil {
+			return err
+		}
+		if def.Accounts == nil {
+			def.Accounts = make(map[string]map[string]float64)
+		}
+		if def.Accounts[exchange] == nil {
+			def.Accounts[exchange] = make(map[string]float64)
+		}
+		def.Accounts[exchange][asset] = amount
+	}
+
+	for _, p := range createParamsFlag {
+		key, value, ok := strings.Cut(p, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("invalid strategy parameter %q: should be key=value", p)
+		}
+
+		// Decode the value as YAML to get numbers and booleans
+		var v any
+		if err := yaml.Unmarshal([]byte(value), &v); err != nil {
+			v = value
+		}
+		if def.StrategyParameters == nil {
+			def.StrategyParameters = make(map[string]any)
+		}
+		def.StrategyParameters[key] = v
+	}
+
+	return nil
+}
+
+func applyCreateTimeFlags(def *createFile) error {
+	if createStartFlag != "" {
+		t, err := time.Parse(time.RFC3339, createStartFlag)
+		if err != nil {
+			return fmt.Errorf("invalid start time: %w", err)
+		}
+		def.StartTime = &t
+	}
+
+	if createEndFlag != "" {
+		t, err := time.Parse(time.RFC3339, createEndFlag)
+		if err != nil {
+			return fmt.Errorf("invalid end time: %w", err)
+		}
+		def.EndTime = &t
+	}
+
+	return nil
+}
+
+// currentUsername returns the name of the user running the command, or an
+// empty string if it is unknown.
+func currentUsername() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	return u.Username
+}
+
+func setIfNotEmpty(dst *string, value string) {
+	if value != "" {
+		*dst = value
+	}
+}
+
+// parseBalance parses a balance formatted as exchange:ASSET=amount.
+func parseBalance(s string) (exchange, asset string, amount float64, err error) {
+	exchange, rest, ok := strings.Cut(s, ":")
+	if ok {
+		var amountStr string
+		asset, amountStr, ok = strings.Cut(rest, "=")
+		if ok {
+			amount, err = strconv.ParseFloat(amountStr, 64)
+		}
+	}
+	if !ok || exchange == "" || asset == "" || err != nil {
+		return "", "", 0, fmt.Errorf("invalid balance %q: should be exchange:ASSET=amount", s)
+	}
+	return exchange, asset, amount, nil
+}
+
+// toParameters validates the definition and converts it to the backtest
+// creation parameters.
+func (def createFile) toParameters() (api.CreateBacktestWorkflowParams, error) {
+	// Files written before the definition format was versioned are version 1
+	if def.Version == 0 {
+		def.Version = backtest.DefinitionVersion
+	}
+	params, err := def.Parameters()
+	if err != nil {
+		return api.CreateBacktestWorkflowParams{}, err
+	}
+	params.Creator = createCreatorFlag
+
+	callback := func(name string) runtime.CallbackWorkflow {
+		return runtime.CallbackWorkflow{
+			Name:          name,
+			TaskQueueName: def.Callbacks.TaskQueue,
+		}
+	}
+	return api.CreateBacktestWorkflowParams{
+		BacktestParameters: params,
+		Callbacks: runtime.Callbacks{
+			OnInitCallback:      callback(def.Callbacks.OnInit),
+			OnNewPricesCallback: callback(def.Callbacks.OnNewPrices),
+			OnExitCallback:      callback(def.Callbacks.OnExit),
+		},
+		Webhooks: def.Webhooks,
+	}, nil
+}
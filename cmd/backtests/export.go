@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/export"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+// exportFormatJSON exports the whole backtest as a single JSON document.
+const exportFormatJSON = "json"
+
+var (
+	exportFileFlag     string
+	exportFormatFlag   string
+	exportDirFlag      string
+	exportDatasetsFlag []string
+	exportQuoteFlag    string
+	exportGzipFlag     bool
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export <id>",
+	Short: "Export a backtest as JSON, or its orders, balances and equity as CSV, JSON Lines or Parquet files",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseBacktestID(args[0])
+		if err != nil {
+			return err
+		}
+
+		if exportFormatFlag == exportFormatJSON {
+			return exportBacktestJSON(cmd, id)
+		}
+		return exportBacktestDatasets(cmd, id)
+	},
+}
+
+func addExportCommand(cmd *cobra.Command) {
+	flags := exportCmd.Flags()
+	flags.StringVarP(&exportFormatFlag, "format", "F", exportFormatJSON,
+		"Set the export format (json, csv, jsonl or parquet)")
+	flags.StringVarP(&exportFileFlag, "file", "f", "",
+		"Set the file to write with the json format, instead of the standard output")
+	flags.StringVarP(&exportDirFlag, "dir", "d", ".", "Set the directory where the dataset files are written")
+	flags.StringSliceVar(&exportDatasetsFlag, "dataset", nil,
+		"Add a dataset to export (orders, balances or equity), all by default")
+	flags.StringVar(&exportQuoteFlag, "quote", "", "Set the quote asset in which the equity is valued")
+	flags.BoolVar(&exportGzipFlag, "gzip", false, "Compress the dataset files with gzip")
+
+	cmd.AddCommand(exportCmd)
+}
+
+func exportBacktestJSON(cmd *cobra.Command, id uuid.UUID) error {
+	res, err := raw.GetBacktest(cmd.Context(), api.GetBacktestWorkflowParams{BacktestID: id})
+	if err != nil {
+		return err
+	}
+
+	if exportFileFlag == "" {
+		return printJSON(cmd, res.Backtest)
+	}
+
+	content, err := json.MarshalIndent(res.Backtest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(exportFileFlag, content, 0o600)
+}
+
+func exportBacktestDatasets(cmd *cobra.Command, id uuid.UUID) error {
+	opts := export.Options{
+		Format: export.Format(exportFormatFlag),
+		Gzip:   exportGzipFlag,
+	}
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	// Export the equity only when it can be valued
+	datasets := make([]export.Dataset, 0, len(export.Datasets))
+	for _, d := range exportDatasetsFlag {
+		datasets = append(datasets, export.Dataset(d))
+	}
+	if len(datasets) == 0 {
+		datasets = append(datasets, export.DatasetOrders, export.DatasetBalances)
+		if exportQuoteFlag != "" {
+			datasets = append(datasets, export.DatasetEquity)
+		}
+	}
+
+	res, err := raw.ExportBacktest(cmd.Context(), api.ExportBacktestWorkflowParams{
+		BacktestID: id,
+		Datasets:   datasets,
+		QuoteAsset: exportQuoteFlag,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, d := range datasets {
+		path := filepath.Join(exportDirFlag, opts.FileName(id, d))
+		switch d {
+		case export.DatasetOrders:
+			err = writeExportFile(path, res.Orders, opts)
+		case export.DatasetBalances:
+			err = writeExportFile(path, res.Balances, opts)
+		case export.DatasetEquity:
+			err = writeExportFile(path, res.Equity, opts)
+		}
+		if err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+
+		if _, err := fmt.Fprintln(cmd.OutOrStdout(), path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeExportFile[T export.Row](path string, rows []T, opts export.Options) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if err := export.Write(f, rows, opts); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
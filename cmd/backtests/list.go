@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listStatusesFlag   []string
+	listTagsFlag       []string
+	listAnyTagsFlag    []string
+	listNameFlag       string
+	listCreatorFlag    string
+	listExchangeFlag   string
+	listPairFlag       string
+	listSortFlag       string
+	listDescendingFlag bool
+	listCursorFlag     string
+	listLimitFlag      int
+)
+
+var listCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List backtests",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		params := api.ListBacktestsWorkflowParams{
+			Filters: backtest.Filters{
+				Exchange: listExchangeFlag,
+				Pair:     listPairFlag,
+				Tags:     listTagsFlag,
+				AnyTags:  listAnyTagsFlag,
+				Name:     listNameFlag,
+				Creator:  listCreatorFlag,
+			},
+			Sort: backtest.Sort{
+				Field:      backtest.SortField(listSortFlag),
+				Descending: listDescendingFlag,
+			},
+			Cursor:      listCursorFlag,
+			Limit:       listLimitFlag,
+			SummaryOnly: true,
+		}
+		for _, s := range listStatusesFlag {
+			params.Filters.Statuses = append(params.Filters.Statuses, backtest.Status(s))
+		}
+
+		res, err := raw.ListBacktests(cmd.Context(), params)
+		if err != nil {
+			return err
+		}
+
+		t := table{Headers: []string{"ID", "NAME", "STATUS", "START", "END", "CURRENT", "ORDERS", "TAGS"}}
+		for _, s := range res.Summaries {
+			t.Rows = append(t.Rows, []string{
+				s.ID.String(),
+				s.Name,
+				s.Status.String(),
+				formatTime(s.StartTime),
+				formatTime(s.EndTime),
+				formatTime(s.CurrentCandlestick.Time),
+				strconv.Itoa(s.OrdersCount),
+				strings.Join(s.Tags, ","),
+			})
+		}
+		if err := render(cmd, res, t); err != nil {
+			return err
+		}
+
+		if outputFlag == outputTable && res.NextCursor != "" {
+			_, err = fmt.Fprintf(cmd.ErrOrStderr(), "\nNext page: --cursor %s\n", res.NextCursor)
+		}
+		return err
+	},
+}
+
+func addListCommand(cmd *cobra.Command) {
+	flags := listCmd.Flags()
+	flags.StringSliceVar(&listStatusesFlag, "status", nil, "Filter on the statuses")
+	flags.StringSliceVar(&listTagsFlag, "tag", nil, "Filter on the tags that the backtests should all have")
+	flags.StringSliceVar(&listAnyTagsFlag, "any-tag", nil,
+		"Filter on the tags that the backtests should have at least one of")
+	flags.StringVar(&listNameFlag, "name", "", "Filter on a text contained in the name, regardless of the case")
+	flags.StringVar(&listCreatorFlag, "creator", "", "Filter on the creator")
+	flags.StringVar(&listExchangeFlag, "exchange", "", "Filter on the exchange of the prices subscriptions")
+	flags.StringVar(&listPairFlag, "pair", "", "Filter on the pair of the prices subscriptions")
+	flags.StringVar(&listSortFlag, "sort", "", "Sort on a field (start_time, end_time or current_time)")
+	flags.BoolVar(&listDescendingFlag, "desc", false, "Sort in descending order")
+	flags.StringVar(&listCursorFlag, "cursor", "", "Set the cursor returned with the previous page")
+	flags.IntVar(&listLimitFlag, "limit", 20, "Set the maximum count of backtests, no limit if 0")
+
+	cmd.AddCommand(listCmd)
+}
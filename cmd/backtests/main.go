@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cryptellation/backtests/configs"
+	"github.com/cryptellation/backtests/pkg/clients"
+	"github.com/cryptellation/backtests/pkg/tenant"
+	"github.com/cryptellation/version"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	temporalclient "go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/workflow"
+)
+
+var (
+	temporalAddressFlag string
+	tenantFlag          string
+	outputFlag          string
+)
+
+var (
+	temporal temporalclient.Client
+	client   clients.Client
+	raw      clients.RawClient
+)
+
+// rootCmd is the backtests root command.
+var rootCmd = &cobra.Command{
+	Use:          "backtests",
+	Version:      version.FullVersion(),
+	Short:        "backtests - a command-line client for the cryptellation backtests service",
+	SilenceUsage: true,
+	PersistentPreRunE: func(cmd *cobra.Command, _ []string) (err error) {
+		if outputFlag != outputTable && outputFlag != outputJSON {
+			return fmt.Errorf("invalid output %q: should be %q or %q", outputFlag, outputTable, outputJSON)
+		}
+
+		// Send the tenant with every call
+		if err := tenant.Validate(tenantFlag); err != nil {
+			return err
+		}
+		cmd.SetContext(tenant.WithID(cmd.Context(), tenantFlag))
+
+		// Connect lazily, on the first call to the service
+		temporal, err = temporalclient.NewLazyClient(temporalclient.Options{
+			HostPort:           temporalAddressFlag,
+			ContextPropagators: []workflow.ContextPropagator{tenant.NewContextPropagator()},
+		})
+		if err != nil {
+			return err
+		}
+
+		client = clients.New(temporal)
+		raw = clients.NewRaw(temporal)
+		return nil
+	},
+	PersistentPostRun: func(_ *cobra.Command, _ []string) {
+		temporal.Close()
+	},
+}
+
+func main() {
+	// Set flags
+	rootCmd.PersistentFlags().StringVar(&temporalAddressFlag, "temporal-address",
+		viper.GetString(configs.EnvTemporalAddress), "Set the temporal address")
+	rootCmd.PersistentFlags().StringVar(&tenantFlag, "tenant",
+		viper.GetString(configs.EnvTenant), "Set the tenant owning the backtests")
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", outputTable,
+		"Set the output format (table or json)")
+
+	// Set commands
+	addCreateCommand(rootCmd)
+	addListCommand(rootCmd)
+	rootCmd.AddCommand(showCmd)
+	addUpdateCommand(rootCmd)
+	rootCmd.AddCommand(ordersCmd)
+	rootCmd.AddCommand(accountsCmd)
+	addRunCommand(rootCmd)
+	rootCmd.AddCommand(deleteCmd)
+	addExportCommand(rootCmd)
+	addReportCommand(rootCmd)
+	rootCmd.AddCommand(quotaCmd)
+
+	// Execute command
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// table is a table to print, as an alternative to the JSON output.
+type table struct {
+	Headers []string
+	Rows    [][]string
+}
+
+// render prints the value as JSON or the table, depending on the output flag.
+func render(cmd *cobra.Command, v any, t table) error {
+	if outputFlag == outputJSON {
+		return printJSON(cmd, v)
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	if len(t.Headers) > 0 {
+		if _, err := fmt.Fprintln(w, strings.Join(t.Headers, "\t")); err != nil {
+			return err
+		}
+	}
+	for _, r := range t.Rows {
+		if _, err := fmt.Fprintln(w, strings.Join(r, "\t")); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// printJSON prints the value as indented JSON.
+func printJSON(cmd *cobra.Command, v any) error {
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// parseBacktestID parses the backtest ID given as argument.
+func parseBacktestID(arg string) (uuid.UUID, error) {
+	id, err := uuid.Parse(arg)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid backtest id %q: %w", arg, err)
+	}
+	return id, nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatFloat(f float64) string {
+	return fmt.Sprintf("%g", f)
+}
+
+func formatValue(v any) string {
+	return fmt.Sprintf("%v", v)
+}
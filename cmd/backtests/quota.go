@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var quotaCmd = &cobra.Command{
+	Use:   "quota",
+	Short: "Show the usage of the quota of the tenant",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		usage, err := client.QuotaUsage(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		t := table{Headers: []string{"STATE", "BACKTEST", "STEPS", "REQUESTED AT"}}
+		for _, r := range usage.Running {
+			t.Rows = append(t.Rows, []string{
+				"running",
+				r.BacktestID.String(),
+				strconv.FormatInt(r.Steps, 10),
+				formatTime(r.RequestedAt),
+			})
+		}
+		for _, r := range usage.Queue {
+			t.Rows = append(t.Rows, []string{
+				"queued",
+				r.BacktestID.String(),
+				strconv.FormatInt(r.Steps, 10),
+				formatTime(r.RequestedAt),
+			})
+		}
+
+		if outputFlag == outputTable {
+			cmd.Printf("%d running backtest(s) (limit: %s), %d running step(s) (limit: %s), "+
+				"%d queued backtest(s) (max wait: %s)\n",
+				usage.RunningBacktests, formatLimit(int64(usage.Limits.MaxRunningBacktests)),
+				usage.RunningSteps, formatLimit(usage.Limits.MaxRunningSteps),
+				usage.QueuedBacktests, formatWait(usage.Limits.MaxWaitTime))
+		}
+		return render(cmd, usage, t)
+	},
+}
+
+func formatLimit(limit int64) string {
+	if limit == 0 {
+		return "none"
+	}
+	return strconv.FormatInt(limit, 10)
+}
+
+func formatWait(wait time.Duration) string {
+	if wait == 0 {
+		return "none"
+	}
+	return wait.String()
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportFileFlag  string
+	reportQuoteFlag string
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report <id>",
+	Short: "Generate a self-contained HTML report of a backtest",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseBacktestID(args[0])
+		if err != nil {
+			return err
+		}
+
+		res, err := raw.GetBacktestReport(cmd.Context(), api.GetBacktestReportWorkflowParams{
+			BacktestID: id,
+			QuoteAsset: reportQuoteFlag,
+		})
+		if err != nil {
+			return err
+		}
+
+		var page bytes.Buffer
+		if err := report.Render(&page, res.Report); err != nil {
+			return err
+		}
+
+		file := reportFileFlag
+		if file == "" {
+			file = fmt.Sprintf("%s-report.html", id.String())
+		}
+		if err := os.WriteFile(file, page.Bytes(), 0o600); err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(cmd.OutOrStdout(), file)
+		return err
+	},
+}
+
+func addReportCommand(cmd *cobra.Command) {
+	reportCmd.Flags().StringVarP(&reportFileFlag, "file", "f", "", "Set the file to write (default <id>-report.html)")
+	reportCmd.Flags().StringVar(&reportQuoteFlag, "quote", "", "Set the quote asset in which the equity is valued")
+	_ = reportCmd.MarkFlagRequired("quote")
+
+	cmd.AddCommand(reportCmd)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/spf13/cobra"
+)
+
+var ordersCmd = &cobra.Command{
+	Use:   "orders <id>",
+	Short: "Show the orders of a backtest",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseBacktestID(args[0])
+		if err != nil {
+			return err
+		}
+
+		res, err := raw.GetBacktestOrders(cmd.Context(), api.GetBacktestOrdersWorkflowParams{BacktestID: id})
+		if err != nil {
+			return err
+		}
+
+		t := table{Headers: []string{"ID", "TIME", "EXCHANGE", "PAIR", "SIDE", "TYPE", "QUANTITY", "PRICE"}}
+		for _, o := range res.Orders {
+			executionTime := "-"
+			if o.ExecutionTime != nil {
+				executionTime = formatTime(*o.ExecutionTime)
+			}
+
+			t.Rows = append(t.Rows, []string{
+				o.ID.String(),
+				executionTime,
+				o.Exchange,
+				o.Pair,
+				string(o.Side),
+				string(o.Type),
+				formatFloat(o.Quantity),
+				formatFloat(o.Price),
+			})
+		}
+		return render(cmd, res.Orders, t)
+	},
+}
+
+var accountsCmd = &cobra.Command{
+	Use:   "accounts <id>",
+	Short: "Show the accounts of a backtest",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseBacktestID(args[0])
+		if err != nil {
+			return err
+		}
+
+		res, err := raw.GetBacktestAccounts(cmd.Context(), api.GetBacktestAccountsWorkflowParams{BacktestID: id})
+		if err != nil {
+			return err
+		}
+
+		t := table{Headers: []string{"EXCHANGE", "ASSET", "BALANCE"}}
+		for exchange, a := range res.Accounts {
+			for asset, balance := range a.Balances {
+				t.Rows = append(t.Rows, []string{exchange, asset, formatFloat(balance)})
+			}
+		}
+		slices.SortFunc(t.Rows, func(a, b []string) int {
+			return slices.Compare(a, b)
+		})
+		return render(cmd, res.Accounts, t)
+	},
+}
+
+var deleteCmd = &cobra.Command{
+	Use:     "delete <id>",
+	Aliases: []string{"rm"},
+	Short:   "Delete a backtest, unless it is running",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseBacktestID(args[0])
+		if err != nil {
+			return err
+		}
+
+		return client.DeleteBacktest(cmd.Context(), id)
+	},
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/clients"
+	"github.com/spf13/cobra"
+)
+
+var (
+	runFollowFlag   bool
+	runIntervalFlag time.Duration
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run <id>",
+	Short: "Run a backtest",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseBacktestID(args[0])
+		if err != nil {
+			return err
+		}
+
+		bt, err := client.GetBacktest(cmd.Context(), api.GetBacktestWorkflowParams{BacktestID: id})
+		if err != nil {
+			return err
+		}
+
+		run, err := bt.Start(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		if !runFollowFlag {
+			return render(cmd, map[string]string{
+				"backtest_id": id.String(),
+				"workflow_id": run.WorkflowID(),
+				"run_id":      run.RunID(),
+			}, table{Rows: [][]string{{run.WorkflowID(), run.RunID()}}})
+		}
+
+		return follow(cmd, run)
+	},
+}
+
+func addRunCommand(cmd *cobra.Command) {
+	runCmd.Flags().BoolVar(&runFollowFlag, "follow", false, "Follow the backtest progress until its end")
+	runCmd.Flags().DurationVar(&runIntervalFlag, "interval", 2*time.Second, "Set the interval between progress updates")
+
+	cmd.AddCommand(runCmd)
+}
+
+// follow prints the progress of the backtest until the end of the run.
+func follow(cmd *cobra.Command, run clients.BacktestRun) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- run.Wait(cmd.Context())
+	}()
+
+	ticker := time.NewTicker(runIntervalFlag)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				return err
+			}
+			return printProgress(cmd.Context(), cmd, run)
+		case <-ticker.C:
+			if err := printProgress(cmd.Context(), cmd, run); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func printProgress(ctx context.Context, cmd *cobra.Command, run clients.BacktestRun) error {
+	res, err := raw.GetBacktest(ctx, api.GetBacktestWorkflowParams{BacktestID: run.BacktestID})
+	if err != nil {
+		return err
+	}
+	bt := res.Backtest
+
+	if outputFlag == outputJSON {
+		return printJSON(cmd, bt.Summary())
+	}
+
+	_, err = fmt.Fprintf(cmd.OutOrStdout(), "%s  %-8s  %s  %s orders\n",
+		bt.ID, bt.Status, formatTime(bt.CurrentCandlestick.Time), strconv.Itoa(len(bt.Orders)))
+	return err
+}
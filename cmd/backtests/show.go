@@ -0,0 +1,57 @@
+package main
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/spf13/cobra"
+)
+
+var showCmd = &cobra.Command{
+	Use:     "show <id>",
+	Aliases: []string{"get"},
+	Short:   "Show a backtest",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseBacktestID(args[0])
+		if err != nil {
+			return err
+		}
+
+		res, err := raw.GetBacktest(cmd.Context(), api.GetBacktestWorkflowParams{BacktestID: id})
+		if err != nil {
+			return err
+		}
+		bt := res.Backtest
+
+		subscriptions := make([]string, 0, len(bt.PricesSubscriptions))
+		for _, s := range bt.PricesSubscriptions {
+			subscriptions = append(subscriptions, s.Exchange+":"+s.Pair)
+		}
+
+		parameters := make([]string, 0, len(bt.StrategyParameters))
+		for k, v := range bt.StrategyParameters {
+			parameters = append(parameters, k+"="+formatValue(v))
+		}
+		slices.Sort(parameters)
+
+		return render(cmd, bt, table{Rows: [][]string{
+			{"ID", bt.ID.String()},
+			{"Name", bt.Name},
+			{"Description", bt.Description},
+			{"Creator", bt.Creator},
+			{"Status", bt.Status.String()},
+			{"Start", formatTime(bt.StartTime)},
+			{"End", formatTime(bt.EndTime)},
+			{"Current", formatTime(bt.CurrentCandlestick.Time)},
+			{"Mode", bt.Mode.String()},
+			{"Period", bt.PricePeriod.String()},
+			{"Subscriptions", strings.Join(subscriptions, ", ")},
+			{"Orders", strconv.Itoa(len(bt.Orders))},
+			{"Tags", strings.Join(bt.Tags, ", ")},
+			{"Parameters", strings.Join(parameters, ", ")},
+		}})
+	},
+}
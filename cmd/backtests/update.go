@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/spf13/cobra"
+)
+
+var (
+	updateNameFlag        string
+	updateDescriptionFlag string
+	updateTagsFlag        []string
+	updateClearTagsFlag   bool
+)
+
+var updateCmd = &cobra.Command{
+	Use:   "update <id>",
+	Short: "Update the name, description or tags of a backtest",
+	Long: "Update the name, description or tags of a backtest.\n" +
+		"Only the set flags are updated, and the tag flags replace all the tags.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := parseBacktestID(args[0])
+		if err != nil {
+			return err
+		}
+
+		// Get the update from the set flags
+		var update backtest.MetadataUpdate
+		flags := cmd.Flags()
+		if flags.Changed("name") {
+			update.Name = &updateNameFlag
+		}
+		if flags.Changed("description") {
+			update.Description = &updateDescriptionFlag
+		}
+		switch {
+		case updateClearTagsFlag && flags.Changed("tag"):
+			return errors.New("--tag and --clear-tags cannot be used together")
+		case updateClearTagsFlag:
+			update.Tags = &[]string{}
+		case flags.Changed("tag"):
+			update.Tags = &updateTagsFlag
+		}
+
+		res, err := raw.UpdateBacktestMetadata(cmd.Context(), api.UpdateBacktestMetadataWorkflowParams{
+			BacktestID: id,
+			Metadata:   update,
+		})
+		if err != nil {
+			return err
+		}
+
+		bt := res.Backtest
+		return render(cmd, bt, table{Rows: [][]string{
+			{"ID", bt.ID.String()},
+			{"Name", bt.Name},
+			{"Description", bt.Description},
+			{"Tags", strings.Join(bt.Tags, ", ")},
+		}})
+	},
+}
+
+func addUpdateCommand(cmd *cobra.Command) {
+	flags := updateCmd.Flags()
+	flags.StringVar(&updateNameFlag, "name", "", "Set the name")
+	flags.StringVar(&updateDescriptionFlag, "description", "", "Set the description")
+	flags.StringSliceVar(&updateTagsFlag, "tag", nil, "Set the tags, replacing the existing ones")
+	flags.BoolVar(&updateClearTagsFlag, "clear-tags", false, "Remove all the tags")
+
+	cmd.AddCommand(updateCmd)
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"os"
+
+	"github.com/cryptellation/version"
+	"github.com/spf13/cobra"
+)
+
+// rootCmd is the gateway root command.
+var rootCmd = &cobra.Command{
+	Use:     "gateway",
+	Version: version.FullVersion(),
+	Short:   "gateway - an HTTP/JSON gateway to the cryptellation backtests temporal workflows",
+}
+
+func main() {
+	// Set commands
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(openapiCmd)
+
+	// Execute command
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
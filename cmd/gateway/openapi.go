@@ -0,0 +1,26 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cryptellation/backtests/pkg/clients"
+	"github.com/cryptellation/backtests/svc/gateway"
+	"github.com/spf13/cobra"
+)
+
+var openapiCmd = &cobra.Command{
+	Use:     "openapi",
+	Aliases: []string{"o"},
+	Short:   "Print the OpenAPI specification of the gateway",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		// The specification only depends on the types, so no client is needed
+		spec, err := json.MarshalIndent(gateway.New(clients.NewRaw(nil), nil).OpenAPI(), "", "  ")
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(cmd.OutOrStdout(), string(spec))
+		return err
+	},
+}
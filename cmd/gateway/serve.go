@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/cryptellation/backtests/configs"
+	"github.com/cryptellation/backtests/pkg/clients"
+	"github.com/cryptellation/backtests/pkg/tenant"
+	"github.com/cryptellation/backtests/svc/gateway"
+	"github.com/cryptellation/health"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/workflow"
+	"golang.org/x/sync/errgroup"
+)
+
+// shutdownTimeout is the maximum duration to wait for the requests to end.
+const shutdownTimeout = 10 * time.Second
+
+var serveCmd = &cobra.Command{
+	Use:     "serve",
+	Aliases: []string{"s"},
+	Short:   "Launch the gateway",
+	RunE:    serve,
+}
+
+func serve(cmd *cobra.Command, _ []string) error {
+	// Set up context that cancels on SIGTERM or SIGINT
+	sigCtx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	// Create errgroup and context
+	eg, ctx := errgroup.WithContext(sigCtx)
+
+	// Health server
+	h, err := health.New(viper.GetString(configs.EnvHealthAddress))
+	if err != nil {
+		return err
+	}
+	eg.Go(func() error {
+		return h.Serve(ctx)
+	})
+
+	// Temporal client
+	temporalClient, err := createTemporalClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer temporalClient.Close()
+
+	// HTTP server
+	auth, err := authenticator()
+	if err != nil {
+		return err
+	}
+	server := &http.Server{
+		Addr:              viper.GetString(configs.EnvHTTPAddress),
+		Handler:           gateway.New(clients.NewRaw(temporalClient), auth),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	eg.Go(func() error {
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	})
+	eg.Go(func() error {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	})
+
+	// Signal health server is ready
+	h.Ready(true)
+	defer h.Ready(false)
+
+	// Wait for everything to be finished
+	err = eg.Wait()
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return nil
+	}
+	return err
+}
+
+// authenticator returns the authenticator of the gateway requests from the
+// config. Without tokens nor trusted tenant header, the requests are not
+// authenticated and work on the default tenant.
+func authenticator() (gateway.Authenticator, error) {
+	tokens := viper.GetString(configs.EnvGatewayTokens)
+	trustHeader := viper.GetBool(configs.EnvGatewayTrustTenantHeader)
+	switch {
+	case tokens != "" && trustHeader:
+		return nil, fmt.Errorf("%s and %s cannot be set together",
+			configs.EnvGatewayTokens, configs.EnvGatewayTrustTenantHeader)
+	case tokens != "":
+		return gateway.ParseTokens(tokens)
+	case trustHeader:
+		return gateway.TrustedHeaderAuthenticator{}, nil
+	default:
+		return gateway.NoAuthenticator{}, nil
+	}
+}
+
+func createTemporalClient(ctx context.Context) (client.Client, error) {
+	// Set backoff callback
+	callback := func() (client.Client, error) {
+		return client.Dial(client.Options{
+			HostPort:           viper.GetString(configs.EnvTemporalAddress),
+			ContextPropagators: []workflow.ContextPropagator{tenant.NewContextPropagator()},
+		})
+	}
+
+	// Retry with backoff
+	return backoff.Retry(ctx, callback,
+		backoff.WithBackOff(backoff.NewExponentialBackOff()),
+		backoff.WithMaxTries(10))
+}
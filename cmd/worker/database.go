@@ -3,11 +3,16 @@ package main
 import (
 	"context"
 	"strconv"
+	"time"
 
 	"github.com/cenkalti/backoff/v5"
+	"github.com/cryptellation/backtests/api"
 	"github.com/cryptellation/backtests/configs"
 	"github.com/cryptellation/backtests/configs/sql/down"
 	"github.com/cryptellation/backtests/configs/sql/up"
+	"github.com/cryptellation/backtests/pkg/backtest"
+	dbpkg "github.com/cryptellation/backtests/svc/db"
+	"github.com/cryptellation/backtests/svc/db/sql"
 	"github.com/cryptellation/dbmigrator"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
@@ -94,9 +99,83 @@ var rollbackCmd = &cobra.Command{
 	},
 }
 
+var (
+	purgeOlderThanFlag  time.Duration
+	purgeStatusesFlag   []string
+	purgeTagsFlag       []string
+	purgeBatchSizeFlag  int
+	purgeTenantFlag     string
+	purgeAllTenantsFlag bool
+)
+
+var purgeCmd = &cobra.Command{
+	Use:     "purge",
+	Aliases: []string{"p"},
+	Short:   "Delete the backtests matching the filters, except the running ones",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		// Get the filters
+		filters := backtest.PurgeFilters{
+			OlderThan: purgeOlderThanFlag,
+			Tags:      purgeTagsFlag,
+		}
+		for _, s := range purgeStatusesFlag {
+			filters.Statuses = append(filters.Statuses, backtest.Status(s))
+		}
+		if err := filters.Validate(); err != nil {
+			return err
+		}
+
+		params := dbpkg.PurgeBacktestsActivityParams{
+			Statuses:   filters.Statuses,
+			Tags:       filters.Tags,
+			Limit:      purgeBatchSizeFlag,
+			Tenant:     purgeTenantFlag,
+			AllTenants: purgeAllTenantsFlag,
+		}
+		if filters.OlderThan > 0 {
+			createdBefore := time.Now().Add(-filters.OlderThan)
+			params.CreatedBefore = &createdBefore
+		}
+
+		// Delete batches until the last one is not full
+		activities := sql.NewFromDB(db)
+		deleted := 0
+		for {
+			res, err := activities.PurgeBacktestsActivity(cmd.Context(), params)
+			if err != nil {
+				return err
+			}
+
+			deleted += res.Deleted
+			if purgeBatchSizeFlag <= 0 || res.Deleted < purgeBatchSizeFlag {
+				break
+			}
+		}
+
+		cmd.Printf("%d backtest(s) deleted\n", deleted)
+		return nil
+	},
+}
+
 func addDatabaseCommands(cmd *cobra.Command) {
 	databaseCmd.AddCommand(migrateCmd)
 	databaseCmd.AddCommand(rollbackCmd)
+	databaseCmd.AddCommand(purgeCmd)
+
+	// Set purge flags
+	purgeCmd.Flags().DurationVar(&purgeOlderThanFlag, "older-than", 0,
+		"Delete the backtests created for longer than this duration")
+	purgeCmd.Flags().StringSliceVar(&purgeStatusesFlag, "status", nil,
+		"Delete the backtests having one of these statuses")
+	purgeCmd.Flags().StringSliceVar(&purgeTagsFlag, "tag", nil,
+		"Delete the backtests having all these tags")
+	purgeCmd.Flags().IntVar(&purgeBatchSizeFlag, "batch-size", api.DefaultPurgeBatchSize,
+		"Set the count of backtests deleted per batch")
+	purgeCmd.Flags().StringVar(&purgeTenantFlag, "tenant", "",
+		"Delete the backtests of this tenant (default tenant if empty)")
+	purgeCmd.Flags().BoolVar(&purgeAllTenantsFlag, "all-tenants", false,
+		"Delete the backtests of every tenant")
+	purgeCmd.MarkFlagsMutuallyExclusive("tenant", "all-tenants")
 
 	// Set flags
 	dsn := viper.GetString(configs.EnvSQLDSN)
@@ -9,13 +9,18 @@ import (
 	"github.com/cenkalti/backoff/v5"
 	"github.com/cryptellation/backtests/api"
 	"github.com/cryptellation/backtests/configs"
+	"github.com/cryptellation/backtests/pkg/quota"
+	"github.com/cryptellation/backtests/pkg/tenant"
 	"github.com/cryptellation/backtests/svc"
 	"github.com/cryptellation/backtests/svc/db/sql"
+	"github.com/cryptellation/backtests/svc/quotas"
+	"github.com/cryptellation/backtests/svc/webhook"
 	"github.com/cryptellation/health"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.temporal.io/sdk/client"
 	temporalwk "go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -41,14 +46,14 @@ func serve(cmd *cobra.Command, _ []string) error {
 	}
 
 	// Temporal worker
-	w, workerCleanup, err := setupWorker(ctx, eg)
+	w, temporalClient, workerCleanup, err := setupWorker(ctx, eg)
 	if err != nil {
 		return err
 	}
 	defer workerCleanup()
 
 	// Service
-	if err := setupService(ctx, w); err != nil {
+	if err := setupService(ctx, w, temporalClient); err != nil {
 		return err
 	}
 
@@ -83,12 +88,13 @@ func setupAndStartHealthServer(ctx context.Context, eg *errgroup.Group) (*health
 	return h, nil
 }
 
-// setupWorker creates the temporal client and worker, and returns the worker and a cleanup function.
-func setupWorker(ctx context.Context, eg *errgroup.Group) (temporalwk.Worker, func(), error) {
+// setupWorker creates the temporal client and worker, and returns the worker,
+// the client and a cleanup function.
+func setupWorker(ctx context.Context, eg *errgroup.Group) (temporalwk.Worker, client.Client, func(), error) {
 	// Create temporal client
 	temporalClient, err := createTemporalClient(ctx)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	// Create temporal worker and add to errgroup
@@ -112,11 +118,12 @@ func setupWorker(ctx context.Context, eg *errgroup.Group) (temporalwk.Worker, fu
 	// Cleanup function
 	cleanup := func() { temporalClient.Close() }
 
-	return w, cleanup, nil
+	return w, temporalClient, cleanup, nil
 }
 
-// setupService creates the db and service and registers them to the worker.
-func setupService(ctx context.Context, w temporalwk.Worker) error {
+// setupService creates the db, the webhooks, the quotas and the service and
+// registers them to the worker.
+func setupService(ctx context.Context, w temporalwk.Worker, temporalClient client.Client) error {
 	// Create db client
 	db, err := createDBClient(ctx)
 	if err != nil {
@@ -124,6 +131,20 @@ func setupService(ctx context.Context, w temporalwk.Worker) error {
 	}
 	db.Register(w)
 
+	// Create webhooks client
+	webhook.New(db, nil).Register(w)
+
+	// Create quotas client
+	quotaActivities, err := quotas.New(temporalClient, quota.Limits{
+		MaxRunningBacktests: viper.GetInt(configs.EnvQuotaMaxRunningBacktests),
+		MaxRunningSteps:     viper.GetInt64(configs.EnvQuotaMaxRunningSteps),
+		MaxWaitTime:         viper.GetDuration(configs.EnvQuotaMaxWaitTime),
+	})
+	if err != nil {
+		return err
+	}
+	quotaActivities.Register(w)
+
 	// Create service
 	service := svc.New(db)
 	service.Register(w)
@@ -135,7 +156,8 @@ func createTemporalClient(ctx context.Context) (client.Client, error) {
 	// Set backoff callback
 	callback := func() (client.Client, error) {
 		return client.Dial(client.Options{
-			HostPort: viper.GetString(configs.EnvTemporalAddress),
+			HostPort:           viper.GetString(configs.EnvTemporalAddress),
+			ContextPropagators: []workflow.ContextPropagator{tenant.NewContextPropagator()},
 		})
 	}
 
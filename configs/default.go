@@ -19,4 +19,31 @@ const (
 
 	// DefaultHealthAddress is the default health address.
 	DefaultHealthAddress = ":9000"
+
+	// DefaultHTTPAddress is the default HTTP gateway address.
+	DefaultHTTPAddress = ":8080"
+
+	// DefaultGatewayTokens is the default bearer tokens of the HTTP gateway
+	// (none, the requests are not authenticated).
+	DefaultGatewayTokens = ""
+
+	// DefaultGatewayTrustTenantHeader is the default trust of the tenant header
+	// by the HTTP gateway. It should only be enabled when the gateway can only be
+	// reached through an authenticating proxy setting it.
+	DefaultGatewayTrustTenantHeader = false
+
+	// DefaultTenant is the default tenant of the clients.
+	DefaultTenant = ""
+
+	// DefaultQuotaMaxRunningBacktests is the default maximum count of backtests
+	// running at the same time for a tenant.
+	DefaultQuotaMaxRunningBacktests = 10
+
+	// DefaultQuotaMaxRunningSteps is the default maximum total of steps left to
+	// simulate by the running backtests of a tenant (0 is no limit).
+	DefaultQuotaMaxRunningSteps = 0
+
+	// DefaultQuotaMaxWaitTime is the default maximum time a backtest run waits
+	// for a slot of the quota of its tenant (0 is no limit).
+	DefaultQuotaMaxWaitTime = 0
 )
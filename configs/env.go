@@ -17,6 +17,34 @@ const EnvTemporalAddress = "TEMPORAL_ADDRESS"
 // EnvHealthAddress is the environment variable name for the health address in the config.
 const EnvHealthAddress = "HEALTH_ADDRESS"
 
+// EnvHTTPAddress is the environment variable name for the HTTP gateway address in the config.
+const EnvHTTPAddress = "HTTP_ADDRESS"
+
+// EnvGatewayTokens is the environment variable name for the bearer tokens
+// authenticating the requests of the HTTP gateway, as comma-separated
+// "token=tenant" pairs, in the config.
+const EnvGatewayTokens = "GATEWAY_TOKENS"
+
+// EnvGatewayTrustTenantHeader is the environment variable name for trusting the
+// tenant header of the requests of the HTTP gateway, set by an authenticating
+// proxy, in the config.
+const EnvGatewayTrustTenantHeader = "GATEWAY_TRUST_TENANT_HEADER"
+
+// EnvTenant is the environment variable name for the tenant of the clients in the config.
+const EnvTenant = "TENANT"
+
+// EnvQuotaMaxRunningBacktests is the environment variable name for the maximum count
+// of backtests running at the same time for a tenant in the config.
+const EnvQuotaMaxRunningBacktests = "QUOTA_MAX_RUNNING_BACKTESTS"
+
+// EnvQuotaMaxRunningSteps is the environment variable name for the maximum total of
+// steps left to simulate by the running backtests of a tenant in the config.
+const EnvQuotaMaxRunningSteps = "QUOTA_MAX_RUNNING_STEPS"
+
+// EnvQuotaMaxWaitTime is the environment variable name for the maximum time a backtest
+// run waits for a slot of the quota of its tenant in the config.
+const EnvQuotaMaxWaitTime = "QUOTA_MAX_WAIT_TIME"
+
 func init() {
 	// Tell viper to read environment variables
 	viper.AutomaticEnv()
@@ -27,4 +55,11 @@ func init() {
 	viper.SetDefault(EnvBinanceSecretKey, DefaultBinanceSecretKey)
 	viper.SetDefault(EnvTemporalAddress, DefaultTemporalAddress)
 	viper.SetDefault(EnvHealthAddress, DefaultHealthAddress)
+	viper.SetDefault(EnvHTTPAddress, DefaultHTTPAddress)
+	viper.SetDefault(EnvGatewayTokens, DefaultGatewayTokens)
+	viper.SetDefault(EnvGatewayTrustTenantHeader, DefaultGatewayTrustTenantHeader)
+	viper.SetDefault(EnvTenant, DefaultTenant)
+	viper.SetDefault(EnvQuotaMaxRunningBacktests, DefaultQuotaMaxRunningBacktests)
+	viper.SetDefault(EnvQuotaMaxRunningSteps, DefaultQuotaMaxRunningSteps)
+	viper.SetDefault(EnvQuotaMaxWaitTime, DefaultQuotaMaxWaitTime)
 }
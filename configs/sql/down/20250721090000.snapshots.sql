@@ -0,0 +1 @@
+DROP TABLE backtest_snapshots;
\ No newline at end of file
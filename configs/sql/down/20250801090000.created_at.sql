@@ -0,0 +1 @@
+ALTER TABLE backtests DROP COLUMN created_at;
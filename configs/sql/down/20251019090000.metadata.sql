@@ -0,0 +1,13 @@
+UPDATE backtests
+SET data = data || jsonb_build_object('tags', to_jsonb(tags))
+WHERE cardinality(tags) > 0;
+
+DROP INDEX idx_backtests_tags;
+DROP INDEX idx_backtests_creator;
+DROP INDEX idx_backtests_name;
+
+ALTER TABLE backtests
+    DROP COLUMN tags,
+    DROP COLUMN creator,
+    DROP COLUMN description,
+    DROP COLUMN name;
@@ -0,0 +1,4 @@
+DROP INDEX idx_backtests_tenant;
+
+ALTER TABLE backtests
+    DROP COLUMN tenant;
@@ -0,0 +1,95 @@
+ALTER TABLE backtests
+    ADD COLUMN data JSONB NOT NULL DEFAULT '{}';
+
+UPDATE backtests b
+SET data = jsonb_build_object(
+    'status', b.status,
+    'start_time', to_char(b.start_time, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'),
+    'end_time', to_char(b.end_time, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'),
+    'mode', b.mode,
+    'price_period', b.price_period,
+    'current_time', to_char(b.current_candlestick_time, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'),
+    'current_price_type', b.current_price_type,
+    'balances', COALESCE((
+        SELECT jsonb_agg(jsonb_build_object(
+            'asset_name', bl.asset,
+            'exchange', bl.exchange,
+            'balance', bl.balance))
+        FROM backtest_balances bl
+        WHERE bl.backtest_id = b.id), '[]'),
+    'orders', COALESCE((
+        SELECT jsonb_agg(jsonb_build_object(
+            'id', o.id,
+            'execution_time', to_char(o.execution_time, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'),
+            'type', o.type,
+            'exchange', o.exchange,
+            'pair', o.pair,
+            'side', o.side,
+            'quantity', o.quantity,
+            'price', o.price) ORDER BY o.seq)
+        FROM backtest_orders o
+        WHERE o.backtest_id = b.id), '[]'),
+    'tick_subscriptions', COALESCE((
+        SELECT jsonb_agg(jsonb_build_object(
+            'exchange', s.exchange,
+            'pair', s.pair) ORDER BY s.seq)
+        FROM backtest_subscriptions s
+        WHERE s.backtest_id = b.id), '[]'),
+    'callbacks', (
+        SELECT jsonb_object_agg(c.event || '_callback', jsonb_build_object(
+            'name', c.workflow_name,
+            'task_queue_name', c.task_queue_name,
+            'execution_timeout', c.execution_timeout))
+        FROM backtest_callbacks c
+        WHERE c.backtest_id = b.id),
+    'callbacks_policies', (
+        SELECT jsonb_object_agg(c.event, jsonb_build_object(
+            'on_failure', c.on_failure,
+            'retry', CASE WHEN c.retry_initial_interval IS NOT NULL THEN jsonb_build_object(
+                'initial_interval', c.retry_initial_interval,
+                'backoff_coefficient', c.retry_backoff_coefficient,
+                'maximum_interval', c.retry_maximum_interval,
+                'maximum_attempts', c.retry_maximum_attempts) END))
+        FROM backtest_callbacks c
+        WHERE c.backtest_id = b.id),
+    'callbacks_failures', (
+        SELECT jsonb_object_agg(c.event, c.failures)
+        FROM backtest_callbacks c
+        WHERE c.backtest_id = b.id),
+    'gap_policy', b.gap_policy,
+    'gaps', b.gaps,
+    'wake_up', b.wake_up,
+    'snapshot_interval', b.snapshot_interval,
+    'forked_from', COALESCE(b.forked_from, ''),
+    'strategy_parameters', b.strategy_parameters,
+    'webhooks', b.webhooks,
+    'fees', b.fees);
+
+ALTER TABLE backtests
+    ALTER COLUMN data DROP DEFAULT;
+
+DROP TABLE backtest_callbacks;
+DROP TABLE backtest_subscriptions;
+DROP TABLE backtest_orders;
+DROP TABLE backtest_balances;
+
+DROP INDEX idx_backtests_strategy_parameters;
+DROP INDEX idx_backtests_start_time;
+DROP INDEX idx_backtests_status;
+
+ALTER TABLE backtests
+    DROP COLUMN fees,
+    DROP COLUMN webhooks,
+    DROP COLUMN strategy_parameters,
+    DROP COLUMN forked_from,
+    DROP COLUMN snapshot_interval,
+    DROP COLUMN wake_up,
+    DROP COLUMN gaps,
+    DROP COLUMN gap_policy,
+    DROP COLUMN current_price_type,
+    DROP COLUMN current_candlestick_time,
+    DROP COLUMN price_period,
+    DROP COLUMN mode,
+    DROP COLUMN end_time,
+    DROP COLUMN start_time,
+    DROP COLUMN status;
@@ -0,0 +1,2 @@
+ALTER TABLE backtests
+    DROP COLUMN version;
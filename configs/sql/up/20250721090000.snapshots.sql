@@ -0,0 +1,9 @@
+CREATE TABLE backtest_snapshots
+(
+    backtest_id VARCHAR(255) NOT NULL,
+    time TIMESTAMP NOT NULL,
+    data JSONB NOT NULL,
+    CONSTRAINT pk_backtest_snapshots PRIMARY KEY (backtest_id, time),
+    CONSTRAINT fk_backtest_snapshots_backtest FOREIGN KEY (backtest_id)
+        REFERENCES backtests (id) ON DELETE CASCADE
+);
@@ -0,0 +1,2 @@
+ALTER TABLE backtests
+    ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT NOW();
@@ -0,0 +1,14 @@
+ALTER TABLE backtests
+    ADD COLUMN name VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN description TEXT NOT NULL DEFAULT '',
+    ADD COLUMN creator VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+
+UPDATE backtests
+SET tags = ARRAY(SELECT jsonb_array_elements_text(data->'tags')),
+    data = data - 'tags'
+WHERE data ? 'tags';
+
+CREATE INDEX idx_backtests_name ON backtests (name);
+CREATE INDEX idx_backtests_creator ON backtests (creator);
+CREATE INDEX idx_backtests_tags ON backtests USING GIN (tags);
@@ -0,0 +1,4 @@
+ALTER TABLE backtests
+    ADD COLUMN tenant VARCHAR(255) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_backtests_tenant ON backtests (tenant);
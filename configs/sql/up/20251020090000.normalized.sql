@@ -0,0 +1,145 @@
+ALTER TABLE backtests
+    ADD COLUMN status VARCHAR(32) NOT NULL DEFAULT '',
+    ADD COLUMN start_time TIMESTAMP,
+    ADD COLUMN end_time TIMESTAMP,
+    ADD COLUMN mode VARCHAR(32) NOT NULL DEFAULT '',
+    ADD COLUMN price_period VARCHAR(32) NOT NULL DEFAULT '',
+    ADD COLUMN current_candlestick_time TIMESTAMP,
+    ADD COLUMN current_price_type VARCHAR(32) NOT NULL DEFAULT '',
+    ADD COLUMN gap_policy VARCHAR(32) NOT NULL DEFAULT '',
+    ADD COLUMN gaps JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN wake_up JSONB,
+    ADD COLUMN snapshot_interval BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN forked_from VARCHAR(255),
+    ADD COLUMN strategy_parameters JSONB,
+    ADD COLUMN webhooks JSONB,
+    ADD COLUMN fees JSONB;
+
+UPDATE backtests
+SET status = COALESCE(data->>'status', ''),
+    start_time = (data->>'start_time')::timestamptz AT TIME ZONE 'UTC',
+    end_time = (data->>'end_time')::timestamptz AT TIME ZONE 'UTC',
+    mode = COALESCE(data->>'mode', ''),
+    price_period = COALESCE(data->>'price_period', ''),
+    current_candlestick_time = (data->>'current_time')::timestamptz AT TIME ZONE 'UTC',
+    current_price_type = COALESCE(data->>'current_price_type', ''),
+    gap_policy = COALESCE(data->>'gap_policy', ''),
+    gaps = COALESCE(NULLIF(data->'gaps', 'null'), '[]'),
+    wake_up = NULLIF(data->'wake_up', 'null'),
+    snapshot_interval = COALESCE((data->>'snapshot_interval')::bigint, 0),
+    forked_from = NULLIF(data->>'forked_from', ''),
+    strategy_parameters = NULLIF(data->'strategy_parameters', 'null'),
+    webhooks = NULLIF(data->'webhooks', 'null'),
+    fees = NULLIF(data->'fees', 'null');
+
+ALTER TABLE backtests
+    ALTER COLUMN start_time SET NOT NULL,
+    ALTER COLUMN end_time SET NOT NULL,
+    ALTER COLUMN current_candlestick_time SET NOT NULL;
+
+CREATE INDEX idx_backtests_status ON backtests (status);
+CREATE INDEX idx_backtests_start_time ON backtests (start_time);
+CREATE INDEX idx_backtests_strategy_parameters ON backtests USING GIN (strategy_parameters);
+
+CREATE TABLE backtest_balances
+(
+    backtest_id VARCHAR(255) NOT NULL,
+    exchange VARCHAR(255) NOT NULL,
+    asset VARCHAR(255) NOT NULL,
+    balance DOUBLE PRECISION NOT NULL,
+    CONSTRAINT pk_backtest_balances PRIMARY KEY (backtest_id, exchange, asset),
+    CONSTRAINT fk_backtest_balances_backtest FOREIGN KEY (backtest_id)
+        REFERENCES backtests (id) ON DELETE CASCADE
+);
+
+INSERT INTO backtest_balances (backtest_id, exchange, asset, balance)
+SELECT b.id, e.value->>'exchange', e.value->>'asset_name', (e.value->>'balance')::double precision
+FROM backtests b
+CROSS JOIN LATERAL jsonb_array_elements(COALESCE(NULLIF(b.data->'balances', 'null'), '[]')) AS e(value)
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE backtest_orders
+(
+    backtest_id VARCHAR(255) NOT NULL,
+    seq INTEGER NOT NULL,
+    id VARCHAR(255) NOT NULL,
+    execution_time TIMESTAMP,
+    type VARCHAR(32) NOT NULL,
+    exchange VARCHAR(255) NOT NULL,
+    pair VARCHAR(255) NOT NULL,
+    side VARCHAR(32) NOT NULL,
+    quantity DOUBLE PRECISION NOT NULL,
+    price DOUBLE PRECISION NOT NULL,
+    CONSTRAINT pk_backtest_orders PRIMARY KEY (backtest_id, seq),
+    CONSTRAINT fk_backtest_orders_backtest FOREIGN KEY (backtest_id)
+        REFERENCES backtests (id) ON DELETE CASCADE
+);
+
+INSERT INTO backtest_orders (backtest_id, seq, id, execution_time, type, exchange, pair, side, quantity, price)
+SELECT b.id, e.seq - 1, e.value->>'id',
+    (e.value->>'execution_time')::timestamptz AT TIME ZONE 'UTC',
+    e.value->>'type', e.value->>'exchange', e.value->>'pair', e.value->>'side',
+    (e.value->>'quantity')::double precision, (e.value->>'price')::double precision
+FROM backtests b
+CROSS JOIN LATERAL jsonb_array_elements(COALESCE(NULLIF(b.data->'orders', 'null'), '[]'))
+    WITH ORDINALITY AS e(value, seq);
+
+CREATE TABLE backtest_subscriptions
+(
+    backtest_id VARCHAR(255) NOT NULL,
+    seq INTEGER NOT NULL,
+    exchange VARCHAR(255) NOT NULL,
+    pair VARCHAR(255) NOT NULL,
+    CONSTRAINT pk_backtest_subscriptions PRIMARY KEY (backtest_id, exchange, pair),
+    CONSTRAINT fk_backtest_subscriptions_backtest FOREIGN KEY (backtest_id)
+        REFERENCES backtests (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_backtest_subscriptions_exchange_pair ON backtest_subscriptions (exchange, pair);
+
+INSERT INTO backtest_subscriptions (backtest_id, seq, exchange, pair)
+SELECT b.id, e.seq - 1, e.value->>'exchange', e.value->>'pair'
+FROM backtests b
+CROSS JOIN LATERAL jsonb_array_elements(COALESCE(NULLIF(b.data->'tick_subscriptions', 'null'), '[]'))
+    WITH ORDINALITY AS e(value, seq)
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE backtest_callbacks
+(
+    backtest_id VARCHAR(255) NOT NULL,
+    event VARCHAR(32) NOT NULL,
+    workflow_name VARCHAR(255) NOT NULL,
+    task_queue_name VARCHAR(255) NOT NULL,
+    execution_timeout BIGINT NOT NULL,
+    on_failure VARCHAR(32) NOT NULL,
+    retry_initial_interval BIGINT,
+    retry_backoff_coefficient DOUBLE PRECISION,
+    retry_maximum_interval BIGINT,
+    retry_maximum_attempts INTEGER,
+    failures INTEGER NOT NULL DEFAULT 0,
+    CONSTRAINT pk_backtest_callbacks PRIMARY KEY (backtest_id, event),
+    CONSTRAINT fk_backtest_callbacks_backtest FOREIGN KEY (backtest_id)
+        REFERENCES backtests (id) ON DELETE CASCADE
+);
+
+INSERT INTO backtest_callbacks (
+    backtest_id, event, workflow_name, task_queue_name, execution_timeout, on_failure,
+    retry_initial_interval, retry_backoff_coefficient, retry_maximum_interval, retry_maximum_attempts,
+    failures)
+SELECT b.id, c.event,
+    COALESCE(w.value->>'name', ''),
+    COALESCE(w.value->>'task_queue_name', ''),
+    COALESCE((w.value->>'execution_timeout')::bigint, 0),
+    COALESCE(p.value->>'on_failure', ''),
+    (p.value->'retry'->>'initial_interval')::bigint,
+    (p.value->'retry'->>'backoff_coefficient')::double precision,
+    (p.value->'retry'->>'maximum_interval')::bigint,
+    (p.value->'retry'->>'maximum_attempts')::integer,
+    COALESCE((b.data->'callbacks_failures'->>c.event)::integer, 0)
+FROM backtests b
+CROSS JOIN (VALUES ('on_init'), ('on_new_prices'), ('on_exit')) AS c(event)
+CROSS JOIN LATERAL (SELECT b.data->'callbacks'->(c.event || '_callback')) AS w(value)
+CROSS JOIN LATERAL (SELECT b.data->'callbacks_policies'->c.event) AS p(value);
+
+ALTER TABLE backtests
+    DROP COLUMN data;
@@ -0,0 +1,2 @@
+ALTER TABLE backtests
+    ADD COLUMN version BIGINT NOT NULL DEFAULT 0;
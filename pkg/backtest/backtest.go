@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/cryptellation/candlesticks/pkg/candlestick"
+	"github.com/cryptellation/candlesticks/pkg/pair"
 	"github.com/cryptellation/candlesticks/pkg/period"
 	"github.com/cryptellation/runtime"
 	"github.com/cryptellation/runtime/account"
@@ -26,6 +28,8 @@ var (
 	ErrStartAfterEnd = errors.New("start after end")
 	// ErrInvalidPricePeriod is the error for an invalid price period.
 	ErrInvalidPricePeriod = errors.New("invalid price period")
+	// ErrInvalidSubscription is the error for an invalid price subscription.
+	ErrInvalidSubscription = errors.New("invalid price subscription")
 )
 
 // CurrentCandlestick represent the current price based on candlestick step.
@@ -36,7 +40,16 @@ type CurrentCandlestick struct {
 
 // Backtest is the struct for a backtest.
 type Backtest struct {
-	ID                  uuid.UUID                  `json:"id"`
+	ID uuid.UUID `json:"id"`
+	// Tenant is the owner of the backtest. Only the workflows of this tenant
+	// can read or update it.
+	Tenant string `json:"tenant,omitempty"`
+	// Name, Description, Tags and Creator describe the backtest for humans.
+	// All but the creator can be updated after the creation.
+	Name                string                     `json:"name,omitempty"`
+	Description         string                     `json:"description,omitempty"`
+	Creator             string                     `json:"creator,omitempty"`
+	Status              Status                     `json:"status"`
 	StartTime           time.Time                  `json:"start_time"`
 	EndTime             time.Time                  `json:"end_time"`
 	Mode                Mode                       `json:"mode"`
@@ -46,15 +59,43 @@ type Backtest struct {
 	PricesSubscriptions []tick.Subscription        `json:"tick_subscriptions"`
 	Orders              []order.Order              `json:"orders"`
 	Callbacks           runtime.Callbacks          `json:"callbacks"`
+	CallbacksPolicies   CallbacksPolicies          `json:"callbacks_policies"`
+	CallbacksFailures   CallbacksFailures          `json:"callbacks_failures"`
+	GapPolicy           GapPolicy                  `json:"gap_policy"`
+	Gaps                []Gap                      `json:"gaps"`
+	WakeUp              *WakeUp                    `json:"wake_up,omitempty"`
+	SnapshotInterval    time.Duration              `json:"snapshot_interval"`
+	ForkedFrom          *uuid.UUID                 `json:"forked_from,omitempty"`
+	StrategyParameters  map[string]any             `json:"strategy_parameters,omitempty"`
+	Tags                []string                   `json:"tags,omitempty"`
+	Webhooks            []Webhook                  `json:"webhooks,omitempty"`
+	// Fees are the fees charged on the orders, by exchange.
+	Fees map[string]Fee `json:"fees,omitempty"`
+	// Version is incremented on each update of the backtest in database. An
+	// update based on an outdated version is rejected.
+	Version int64 `json:"version"`
 }
 
 // Parameters is the struct for the backtest parameters.
 type Parameters struct {
-	Accounts    map[string]account.Account
-	StartTime   time.Time
-	EndTime     *time.Time
-	Mode        *Mode
-	PricePeriod *period.Symbol
+	Name               string
+	Description        string
+	Creator            string
+	Accounts           map[string]account.Account
+	StartTime          time.Time
+	EndTime            *time.Time
+	Mode               *Mode
+	PricePeriod        *period.Symbol
+	GapPolicy          *GapPolicy
+	CallbacksPolicies  CallbacksPolicies
+	SnapshotInterval   *time.Duration
+	StrategyParameters map[string]any
+	Tags               []string
+	// PricesSubscriptions are the prices the backtest is subscribed to from
+	// its creation.
+	PricesSubscriptions []tick.Subscription
+	// Fees are the fees charged on the orders, by exchange.
+	Fees map[string]Fee
 }
 
 // EmptyFieldsToDefault sets empty fields to default values.
@@ -72,6 +113,17 @@ func (params *Parameters) EmptyFieldsToDefault() *Parameters {
 		params.Mode = &m
 	}
 
+	if params.GapPolicy == nil {
+		params.GapPolicy = GapPolicySkip.Opt()
+	}
+
+	params.CallbacksPolicies.EmptyFieldsToDefault()
+
+	if params.SnapshotInterval == nil {
+		i := DefaultSnapshotInterval
+		params.SnapshotInterval = &i
+	}
+
 	return params
 }
 
@@ -89,6 +141,20 @@ func (params Parameters) Validate() error {
 		return ErrInvalidMode
 	}
 
+	if params.GapPolicy == nil {
+		return fmt.Errorf("%w: nil", ErrInvalidGapPolicy)
+	} else if err := params.GapPolicy.Validate(); err != nil {
+		return fmt.Errorf("%w: %q", err, *params.GapPolicy)
+	}
+
+	if err := params.CallbacksPolicies.Validate(); err != nil {
+		return err
+	}
+
+	if params.SnapshotInterval == nil || *params.SnapshotInterval < 0 {
+		return ErrInvalidSnapshotInterval
+	}
+
 	for exchange, a := range params.Accounts {
 		if exchange == "" {
 			return fmt.Errorf("error with exchange %q in new backtest params: %w", exchange, ErrInvalidExchange)
@@ -99,6 +165,50 @@ func (params Parameters) Validate() error {
 		}
 	}
 
+	for _, s := range params.PricesSubscriptions {
+		if err := validateSubscription(s); err != nil {
+			return err
+		}
+	}
+
+	if err := params.validateMetadata(); err != nil {
+		return err
+	}
+
+	for exchange, f := range params.Fees {
+		if exchange == "" {
+			return fmt.Errorf("error with fee exchange %q in new backtest params: %w", exchange, ErrInvalidExchange)
+		}
+
+		if err := f.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (params Parameters) validateMetadata() error {
+	if err := validateName("name", params.Name); err != nil {
+		return err
+	}
+
+	if err := validateName("creator", params.Creator); err != nil {
+		return err
+	}
+
+	return validateTags(params.Tags)
+}
+
+func validateSubscription(s tick.Subscription) error {
+	if s.Exchange == "" {
+		return fmt.Errorf("%w: empty exchange", ErrInvalidSubscription)
+	}
+
+	if _, _, err := pair.ParsePair(s.Pair); err != nil {
+		return fmt.Errorf("%w: pair %q: %w", ErrInvalidSubscription, s.Pair, err)
+	}
+
 	return nil
 }
 
@@ -125,8 +235,12 @@ func New(params Parameters, callbacks runtime.Callbacks) (Backtest, error) {
 		cc.Price = candlestick.PriceTypeIsOpen
 	}
 
-	return Backtest{
+	bt := Backtest{
 		ID:                  uuid.New(),
+		Name:                strings.TrimSpace(params.Name),
+		Description:         params.Description,
+		Creator:             strings.TrimSpace(params.Creator),
+		Status:              StatusCreated,
 		StartTime:           params.StartTime,
 		EndTime:             *params.EndTime,
 		Mode:                *params.Mode,
@@ -136,7 +250,22 @@ func New(params Parameters, callbacks runtime.Callbacks) (Backtest, error) {
 		PricesSubscriptions: make([]tick.Subscription, 0),
 		Orders:              make([]order.Order, 0),
 		Callbacks:           callbacks,
-	}, nil
+		CallbacksPolicies:   params.CallbacksPolicies,
+		GapPolicy:           *params.GapPolicy,
+		Gaps:                make([]Gap, 0),
+		SnapshotInterval:    *params.SnapshotInterval,
+		StrategyParameters:  params.StrategyParameters,
+		Tags:                NormalizeTags(params.Tags),
+		Fees:                params.Fees,
+	}
+
+	for _, s := range params.PricesSubscriptions {
+		if _, err := bt.CreateTickSubscription(s.Exchange, s.Pair); err != nil {
+			return Backtest{}, fmt.Errorf("%w: %s %s", err, s.Exchange, s.Pair)
+		}
+	}
+
+	return bt, nil
 }
 
 // CurrentTime returns the current time of the backtest.
@@ -192,6 +321,21 @@ func (bt Backtest) Done() bool {
 	return !bt.CurrentCandlestick.Time.Before(bt.EndTime)
 }
 
+// RemainingSteps returns the count of steps left to simulate until the end of
+// the backtest: one per candlestick, or one per price in full OHLC mode.
+func (bt Backtest) RemainingSteps() int64 {
+	interval := bt.PricePeriod.Duration()
+	if bt.Done() || interval <= 0 {
+		return 0
+	}
+
+	candlesticks := int64((bt.EndTime.Sub(bt.CurrentCandlestick.Time) + interval - 1) / interval)
+	if bt.Mode == ModeIsFullOHLC {
+		return candlesticks * 4
+	}
+	return candlesticks
+}
+
 // SetCurrentTime sets the current time of the backtest.
 func (bt *Backtest) SetCurrentTime(ts time.Time) {
 	// Set new time
@@ -228,9 +372,13 @@ func (bt *Backtest) AddOrder(ord order.Order, cs candlestick.Candlestick) error
 		return fmt.Errorf("error with orders exchange %q: %w", ord.Exchange, ErrInvalidExchange)
 	}
 
-	// Execute the order
+	// Execute the order, with its fee if the exchange charges one
 	price := cs.Price(bt.CurrentCandlestick.Price)
-	if err := exchangeAccount.ApplyOrder(price, ord); err != nil {
+	if fee, ok := exchangeFee(bt.Fees, ord.Exchange); ok {
+		if err := applyOrderWithFee(&exchangeAccount, fee, ord, price); err != nil {
+			return err
+		}
+	} else if err := exchangeAccount.ApplyOrder(price, ord); err != nil {
 		return err
 	}
 	bt.Accounts[ord.Exchange] = exchangeAccount
@@ -126,3 +126,22 @@ func (suite *BacktestSuite) TestBacktestSetNewTimeWithCloseOHLCMode() {
 	suite.Require().Equal(time.Unix(150, 0).UTC(), bt.CurrentCandlestick.Time)
 	suite.Require().Equal(candlestick.PriceTypeIsClose, bt.CurrentCandlestick.Price)
 }
+
+func (suite *BacktestSuite) TestBacktestRemainingSteps() {
+	bt := Backtest{
+		StartTime:   time.Unix(0, 0).UTC(),
+		EndTime:     time.Unix(600, 0).UTC(),
+		Mode:        ModeIsCloseOHLC,
+		PricePeriod: period.M1,
+		CurrentCandlestick: CurrentCandlestick{
+			Time: time.Unix(90, 0).UTC(),
+		},
+	}
+	suite.Require().Equal(int64(9), bt.RemainingSteps())
+
+	bt.Mode = ModeIsFullOHLC
+	suite.Require().Equal(int64(36), bt.RemainingSteps())
+
+	bt.CurrentCandlestick.Time = bt.EndTime
+	suite.Require().Zero(bt.RemainingSteps())
+}
@@ -0,0 +1,139 @@
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrInvalidCallbackFailureAction is returned when the callback failure action is invalid.
+	ErrInvalidCallbackFailureAction = errors.New("invalid callback failure action")
+	// ErrInvalidCallbackRetryPolicy is returned when the callback retry policy is invalid.
+	ErrInvalidCallbackRetryPolicy = errors.New("invalid callback retry policy")
+)
+
+// CallbackFailureAction is the action executed when a callback fails after
+// all its retries.
+type CallbackFailureAction string
+
+const (
+	// CallbackFailureActionFail fails the backtest.
+	CallbackFailureActionFail CallbackFailureAction = "fail"
+	// CallbackFailureActionSkipStep ignores the failure and continues with the
+	// next step of the backtest.
+	CallbackFailureActionSkipStep CallbackFailureAction = "skip_step"
+	// CallbackFailureActionAbort stops the backtest and calls the exit callback.
+	CallbackFailureActionAbort CallbackFailureAction = "abort"
+)
+
+// Validate will validate the callback failure action.
+func (a CallbackFailureAction) Validate() error {
+	switch a {
+	case CallbackFailureActionFail, CallbackFailureActionSkipStep, CallbackFailureActionAbort:
+		return nil
+	default:
+		return ErrInvalidCallbackFailureAction
+	}
+}
+
+// String will return the string representation of the callback failure action.
+func (a CallbackFailureAction) String() string {
+	return string(a)
+}
+
+// CallbackRetryPolicy is the retry policy of a callback.
+// Zero values use the temporal defaults, except for the maximum attempts that
+// is required: unlimited attempts would keep a backtest whose callback keeps
+// failing running forever.
+type CallbackRetryPolicy struct {
+	InitialInterval    time.Duration `json:"initial_interval"`
+	BackoffCoefficient float64       `json:"backoff_coefficient"`
+	MaximumInterval    time.Duration `json:"maximum_interval"`
+	MaximumAttempts    int32         `json:"maximum_attempts"`
+}
+
+// Validate will validate the callback retry policy.
+func (rp CallbackRetryPolicy) Validate() error {
+	switch {
+	case rp.InitialInterval < 0:
+		return fmt.Errorf("%w: negative initial interval", ErrInvalidCallbackRetryPolicy)
+	case rp.BackoffCoefficient != 0 && rp.BackoffCoefficient < 1:
+		return fmt.Errorf("%w: backoff coefficient lower than 1", ErrInvalidCallbackRetryPolicy)
+	case rp.MaximumInterval < 0:
+		return fmt.Errorf("%w: negative maximum interval", ErrInvalidCallbackRetryPolicy)
+	case rp.MaximumAttempts <= 0:
+		return fmt.Errorf("%w: maximum attempts should be positive", ErrInvalidCallbackRetryPolicy)
+	}
+	return nil
+}
+
+// CallbackPolicy is the error policy of a callback.
+type CallbackPolicy struct {
+	// Retry is the retry policy of the callback. If nil, the callback is not retried.
+	Retry *CallbackRetryPolicy `json:"retry,omitempty"`
+	// OnFailure is the action executed when the callback fails after all its retries.
+	OnFailure CallbackFailureAction `json:"on_failure"`
+}
+
+// Validate will validate the callback policy.
+func (p CallbackPolicy) Validate() error {
+	if p.Retry != nil {
+		if err := p.Retry.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := p.OnFailure.Validate(); err != nil {
+		return fmt.Errorf("%w: %q", err, p.OnFailure)
+	}
+
+	return nil
+}
+
+func (p *CallbackPolicy) emptyFieldsToDefault() {
+	if p.OnFailure == "" {
+		p.OnFailure = CallbackFailureActionFail
+	}
+}
+
+// CallbacksPolicies are the error policies of the backtest callbacks.
+type CallbacksPolicies struct {
+	OnInit      CallbackPolicy `json:"on_init"`
+	OnNewPrices CallbackPolicy `json:"on_new_prices"`
+	OnExit      CallbackPolicy `json:"on_exit"`
+}
+
+// EmptyFieldsToDefault sets empty fields to default values.
+func (p *CallbacksPolicies) EmptyFieldsToDefault() *CallbacksPolicies {
+	p.OnInit.emptyFieldsToDefault()
+	p.OnNewPrices.emptyFieldsToDefault()
+	p.OnExit.emptyFieldsToDefault()
+	return p
+}
+
+// Validate validates the callbacks policies.
+func (p CallbacksPolicies) Validate() error {
+	if err := p.OnInit.Validate(); err != nil {
+		return fmt.Errorf("onInit callback policy validation failed: %w", err)
+	}
+	if err := p.OnNewPrices.Validate(); err != nil {
+		return fmt.Errorf("onNewPrices callback policy validation failed: %w", err)
+	}
+	if err := p.OnExit.Validate(); err != nil {
+		return fmt.Errorf("onExit callback policy validation failed: %w", err)
+	}
+	return nil
+}
+
+// CallbacksFailures counts the failures of the backtest callbacks.
+type CallbacksFailures struct {
+	OnInit      int `json:"on_init"`
+	OnNewPrices int `json:"on_new_prices"`
+	OnExit      int `json:"on_exit"`
+}
+
+// Total returns the total count of callbacks failures.
+func (f CallbacksFailures) Total() int {
+	return f.OnInit + f.OnNewPrices + f.OnExit
+}
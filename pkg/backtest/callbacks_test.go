@@ -0,0 +1,47 @@
+//go:build unit
+// +build unit
+
+package backtest
+
+import "time"
+
+func (suite *BacktestSuite) TestCallbacksPoliciesEmptyFieldsToDefault() {
+	policies := CallbacksPolicies{
+		OnNewPrices: CallbackPolicy{
+			OnFailure: CallbackFailureActionSkipStep,
+		},
+	}
+
+	policies.EmptyFieldsToDefault()
+	suite.Require().Equal(CallbackFailureActionFail, policies.OnInit.OnFailure)
+	suite.Require().Equal(CallbackFailureActionSkipStep, policies.OnNewPrices.OnFailure)
+	suite.Require().Equal(CallbackFailureActionFail, policies.OnExit.OnFailure)
+	suite.Require().NoError(policies.Validate())
+}
+
+func (suite *BacktestSuite) TestCallbacksPoliciesValidate() {
+	policies := CallbacksPolicies{
+		OnInit: CallbackPolicy{
+			OnFailure: CallbackFailureActionAbort,
+			Retry: &CallbackRetryPolicy{
+				InitialInterval:    time.Second,
+				BackoffCoefficient: 2,
+				MaximumAttempts:    3,
+			},
+		},
+		OnNewPrices: CallbackPolicy{OnFailure: "unknown"},
+		OnExit:      CallbackPolicy{OnFailure: CallbackFailureActionFail},
+	}
+	suite.Require().ErrorIs(policies.Validate(), ErrInvalidCallbackFailureAction)
+
+	policies.OnNewPrices.OnFailure = CallbackFailureActionSkipStep
+	suite.Require().NoError(policies.Validate())
+
+	policies.OnInit.Retry.BackoffCoefficient = 0.5
+	suite.Require().ErrorIs(policies.Validate(), ErrInvalidCallbackRetryPolicy)
+
+	// Unlimited attempts are rejected
+	policies.OnInit.Retry.BackoffCoefficient = 2
+	policies.OnInit.Retry.MaximumAttempts = 0
+	suite.Require().ErrorIs(policies.Validate(), ErrInvalidCallbackRetryPolicy)
+}
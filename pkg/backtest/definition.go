@@ -0,0 +1,322 @@
+package backtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cryptellation/candlesticks/pkg/period"
+	"github.com/cryptellation/runtime/account"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"gopkg.in/yaml.v3"
+)
+
+// DefinitionVersion is the current version of the backtest definition format.
+const DefinitionVersion = 1
+
+var (
+	// ErrInvalidDefinition is returned when a backtest definition is invalid.
+	ErrInvalidDefinition = errors.New("invalid backtest definition")
+	// ErrInvalidDefinitionFormat is returned when the format of a backtest
+	// definition is not supported.
+	ErrInvalidDefinitionFormat = errors.New("invalid backtest definition format")
+)
+
+// DefinitionFormat is the encoding of a backtest definition.
+type DefinitionFormat string
+
+const (
+	// DefinitionFormatIsYAML is the YAML encoding of a definition.
+	DefinitionFormatIsYAML DefinitionFormat = "yaml"
+	// DefinitionFormatIsJSON is the JSON encoding of a definition.
+	DefinitionFormatIsJSON DefinitionFormat = "json"
+)
+
+// DefinitionFormatFromPath returns the format of a definition file from its
+// extension.
+func DefinitionFormatFromPath(path string) (DefinitionFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return DefinitionFormatIsYAML, nil
+	case ".json":
+		return DefinitionFormatIsJSON, nil
+	default:
+		return "", fmt.Errorf("%w: extension of %q", ErrInvalidDefinitionFormat, path)
+	}
+}
+
+// Definition is the declarative description of a backtest, that can be shared
+// as a YAML or JSON file.
+type Definition struct {
+	Version     int        `yaml:"version" json:"version"`
+	Name        string     `yaml:"name,omitempty" json:"name,omitempty"`
+	Description string     `yaml:"description,omitempty" json:"description,omitempty"`
+	StartTime   *time.Time `yaml:"start_time" json:"start_time"`
+	EndTime     *time.Time `yaml:"end_time,omitempty" json:"end_time,omitempty"`
+	Mode        string     `yaml:"mode,omitempty" json:"mode,omitempty"`
+	// PricePeriod is the symbol of the period between prices (e.g. M1).
+	PricePeriod string `yaml:"price_period,omitempty" json:"price_period,omitempty"`
+	GapPolicy   string `yaml:"gap_policy,omitempty" json:"gap_policy,omitempty"`
+	// SnapshotInterval is a duration as parsed by time.ParseDuration.
+	SnapshotInterval string `yaml:"snapshot_interval,omitempty" json:"snapshot_interval,omitempty"`
+	// Accounts are the initial balances, by exchange then asset.
+	Accounts      map[string]map[string]float64 `yaml:"accounts,omitempty" json:"accounts,omitempty"`
+	Subscriptions []DefinitionSubscription      `yaml:"subscriptions,omitempty" json:"subscriptions,omitempty"`
+	// Fees are the fees charged on the orders, by exchange.
+	Fees               map[string]Fee `yaml:"fees,omitempty" json:"fees,omitempty"`
+	StrategyParameters map[string]any `yaml:"strategy_parameters,omitempty" json:"strategy_parameters,omitempty"`
+	Tags               []string       `yaml:"tags,omitempty" json:"tags,omitempty"`
+}
+
+// DefinitionSubscription is a price subscription of a backtest definition.
+type DefinitionSubscription struct {
+	Exchange string `yaml:"exchange" json:"exchange"`
+	Pair     string `yaml:"pair" json:"pair"`
+}
+
+// DefinitionFieldError is a validation error on a field of a definition.
+type DefinitionFieldError struct {
+	// Field is the path of the field in the definition (e.g. fees.binance.rate).
+	Field string
+	Err   error
+}
+
+// Error returns the error message.
+func (e DefinitionFieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e DefinitionFieldError) Unwrap() error {
+	return e.Err
+}
+
+// DefinitionErrors are all the validation errors of a definition.
+type DefinitionErrors []DefinitionFieldError
+
+// Error returns the error message with every field error.
+func (errs DefinitionErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidDefinition, strings.Join(msgs, "; "))
+}
+
+// Unwrap returns ErrInvalidDefinition followed by the field errors.
+func (errs DefinitionErrors) Unwrap() []error {
+	unwrapped := make([]error, 0, len(errs)+1)
+	unwrapped = append(unwrapped, ErrInvalidDefinition)
+	for _, e := range errs {
+		unwrapped = append(unwrapped, e)
+	}
+	return unwrapped
+}
+
+func (errs *DefinitionErrors) add(field string, err error) {
+	*errs = append(*errs, DefinitionFieldError{Field: field, Err: err})
+}
+
+// LoadDefinitionFile reads a definition from a YAML or JSON file, depending on
+// its extension.
+func LoadDefinitionFile(path string) (Definition, error) {
+	format, err := DefinitionFormatFromPath(path)
+	if err != nil {
+		return Definition{}, err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Definition{}, fmt.Errorf("reading backtest definition: %w", err)
+	}
+
+	def, err := ParseDefinition(content, format)
+	if err != nil {
+		return Definition{}, fmt.Errorf("loading %q: %w", path, err)
+	}
+
+	return def, nil
+}
+
+// ParseDefinition decodes a definition, rejecting the fields that are not part
+// of the format. The returned definition is not validated yet.
+func ParseDefinition(content []byte, format DefinitionFormat) (Definition, error) {
+	var def Definition
+	var err error
+	switch format {
+	case DefinitionFormatIsYAML:
+		dec := yaml.NewDecoder(bytes.NewReader(content))
+		dec.KnownFields(true)
+		err = dec.Decode(&def)
+	case DefinitionFormatIsJSON:
+		dec := json.NewDecoder(bytes.NewReader(content))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&def)
+	default:
+		return Definition{}, fmt.Errorf("%w: %q", ErrInvalidDefinitionFormat, format)
+	}
+	if err != nil {
+		return Definition{}, fmt.Errorf("%w: decoding %s: %w", ErrInvalidDefinition, format, err)
+	}
+
+	return def, nil
+}
+
+// Parameters validates the definition and converts it to backtest parameters,
+// with default values for the empty fields. All the validation errors are
+// returned at once as DefinitionErrors.
+func (d Definition) Parameters() (Parameters, error) {
+	var errs DefinitionErrors
+	params := Parameters{
+		Name:               d.Name,
+		Description:        d.Description,
+		EndTime:            d.EndTime,
+		StrategyParameters: d.StrategyParameters,
+		Tags:               d.Tags,
+	}
+
+	switch {
+	case d.Version == 0:
+		errs.add("version", errors.New("missing"))
+	case d.Version != DefinitionVersion:
+		errs.add("version", fmt.Errorf("unsupported version %d (expected %d)", d.Version, DefinitionVersion))
+	}
+
+	d.timeParameters(&params, &errs)
+	d.stringParameters(&params, &errs)
+	d.accountsParameters(&params, &errs)
+	d.subscriptionsParameters(&params, &errs)
+	d.feesParameters(&params, &errs)
+	d.metadataParameters(&errs)
+
+	if len(errs) > 0 {
+		return Parameters{}, errs
+	}
+	return *params.EmptyFieldsToDefault(), nil
+}
+
+func (d Definition) timeParameters(params *Parameters, errs *DefinitionErrors) {
+	if d.StartTime == nil {
+		errs.add("start_time", errors.New("missing"))
+	} else {
+		params.StartTime = *d.StartTime
+		end := d.EndTime
+		if end == nil {
+			end = defaultEndTime()
+		}
+		if !d.StartTime.Before(*end) {
+			errs.add("end_time", ErrStartAfterEnd)
+		}
+	}
+
+	if d.SnapshotInterval != "" {
+		interval, err := time.ParseDuration(d.SnapshotInterval)
+		if err != nil || interval < 0 {
+			errs.add("snapshot_interval", fmt.Errorf("%w: %q", ErrInvalidSnapshotInterval, d.SnapshotInterval))
+		} else {
+			params.SnapshotInterval = &interval
+		}
+	}
+}
+
+func (d Definition) stringParameters(params *Parameters, errs *DefinitionErrors) {
+	if d.Mode != "" {
+		if err := Mode(d.Mode).Validate(); err != nil {
+			errs.add("mode", fmt.Errorf("%w: %q", err, d.Mode))
+		} else {
+			params.Mode = Mode(d.Mode).Opt()
+		}
+	}
+
+	if d.PricePeriod != "" {
+		if err := period.Symbol(d.PricePeriod).Validate(); err != nil {
+			errs.add("price_period", fmt.Errorf("%w: %w", ErrInvalidPricePeriod, err))
+		} else {
+			params.PricePeriod = period.Symbol(d.PricePeriod).Opt()
+		}
+	}
+
+	if d.GapPolicy != "" {
+		if err := GapPolicy(d.GapPolicy).Validate(); err != nil {
+			errs.add("gap_policy", fmt.Errorf("%w: %q", err, d.GapPolicy))
+		} else {
+			params.GapPolicy = GapPolicy(d.GapPolicy).Opt()
+		}
+	}
+}
+
+func (d Definition) accountsParameters(params *Parameters, errs *DefinitionErrors) {
+	params.Accounts = make(map[string]account.Account, len(d.Accounts))
+	for _, exchange := range slices.Sorted(maps.Keys(d.Accounts)) {
+		balances := d.Accounts[exchange]
+		if exchange == "" {
+			errs.add("accounts", ErrInvalidExchange)
+			continue
+		}
+
+		for _, asset := range slices.Sorted(maps.Keys(balances)) {
+			field := fmt.Sprintf("accounts.%s.%s", exchange, asset)
+			if err := (account.Account{Balances: map[string]float64{asset: balances[asset]}}).Validate(); err != nil {
+				errs.add(field, err)
+			}
+		}
+		params.Accounts[exchange] = account.Account{Balances: balances}
+	}
+}
+
+func (d Definition) subscriptionsParameters(params *Parameters, errs *DefinitionErrors) {
+	seen := make(map[tick.Subscription]bool, len(d.Subscriptions))
+	for i, s := range d.Subscriptions {
+		field := fmt.Sprintf("subscriptions[%d]", i)
+		sub := tick.Subscription{Exchange: s.Exchange, Pair: s.Pair}
+		if err := validateSubscription(sub); err != nil {
+			errs.add(field, err)
+			continue
+		} else if seen[sub] {
+			errs.add(field, fmt.Errorf("%w: %s %s", ErrTickSubscriptionAlreadyExists, s.Exchange, s.Pair))
+			continue
+		}
+
+		seen[sub] = true
+		params.PricesSubscriptions = append(params.PricesSubscriptions, sub)
+	}
+}
+
+func (d Definition) feesParameters(params *Parameters, errs *DefinitionErrors) {
+	for _, exchange := range slices.Sorted(maps.Keys(d.Fees)) {
+		fee := d.Fees[exchange]
+		if exchange == "" {
+			errs.add("fees", ErrInvalidExchange)
+			continue
+		}
+
+		if err := fee.Validate(); err != nil {
+			errs.add(fmt.Sprintf("fees.%s.rate", exchange), err)
+			continue
+		}
+
+		if params.Fees == nil {
+			params.Fees = make(map[string]Fee, len(d.Fees))
+		}
+		params.Fees[exchange] = fee
+	}
+}
+
+func (d Definition) metadataParameters(errs *DefinitionErrors) {
+	if err := validateName("name", d.Name); err != nil {
+		errs.add("name", err)
+	}
+
+	for i, t := range d.Tags {
+		if err := validateTags([]string{t}); err != nil {
+			errs.add(fmt.Sprintf("tags[%d]", i), err)
+		}
+	}
+}
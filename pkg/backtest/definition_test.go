@@ -0,0 +1,163 @@
+//go:build unit
+// +build unit
+
+package backtest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cryptellation/candlesticks/pkg/period"
+	"github.com/cryptellation/runtime"
+	"github.com/cryptellation/runtime/account"
+	"github.com/cryptellation/ticks/pkg/tick"
+)
+
+const testDefinitionYAML = `
+version: 1
+name: SMA crossover
+description: Buys when the fast SMA crosses the slow one
+start_time: 2024-01-01T00:00:00Z
+end_time: 2024-02-01T00:00:00Z
+mode: full_ohlc
+price_period: H1
+gap_policy: forward_fill
+snapshot_interval: 2h
+accounts:
+  binance:
+    USDT: 1000
+subscriptions:
+  - exchange: binance
+    pair: BTC-USDT
+fees:
+  binance:
+    rate: 0.001
+strategy_parameters:
+  window: 14
+tags: [sma]
+`
+
+func (suite *BacktestSuite) TestDefinitionParameters() {
+	def, err := ParseDefinition([]byte(testDefinitionYAML), DefinitionFormatIsYAML)
+	suite.Require().NoError(err)
+
+	params, err := def.Parameters()
+	suite.Require().NoError(err)
+	suite.Require().Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), params.StartTime)
+	suite.Require().Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), *params.EndTime)
+	suite.Require().Equal(ModeIsFullOHLC, *params.Mode)
+	suite.Require().Equal(period.H1, *params.PricePeriod)
+	suite.Require().Equal(GapPolicyForwardFill, *params.GapPolicy)
+	suite.Require().Equal(2*time.Hour, *params.SnapshotInterval)
+	suite.Require().Equal(map[string]account.Account{
+		"binance": {Balances: map[string]float64{"USDT": 1000}},
+	}, params.Accounts)
+	suite.Require().Equal([]tick.Subscription{{Exchange: "binance", Pair: "BTC-USDT"}}, params.PricesSubscriptions)
+	suite.Require().Equal(map[string]Fee{"binance": {Rate: 0.001}}, params.Fees)
+	suite.Require().Equal(14, params.StrategyParameters["window"])
+	suite.Require().Equal([]string{"sma"}, params.Tags)
+	suite.Require().Equal("SMA crossover", params.Name)
+	suite.Require().Equal("Buys when the fast SMA crosses the slow one", params.Description)
+	suite.Require().NoError(params.Validate())
+
+	// The parameters should create a subscribed backtest
+	bt, err := New(params, runtime.Callbacks{})
+	suite.Require().NoError(err)
+	suite.Require().Len(bt.PricesSubscriptions, 1)
+	suite.Require().Equal(params.Fees, bt.Fees)
+}
+
+func (suite *BacktestSuite) TestDefinitionParametersDefaults() {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	params, err := Definition{Version: DefinitionVersion, StartTime: &start}.Parameters()
+	suite.Require().NoError(err)
+	suite.Require().Equal(period.M1, *params.PricePeriod)
+	suite.Require().Equal(ModeIsCloseOHLC, *params.Mode)
+	suite.Require().Equal(GapPolicySkip, *params.GapPolicy)
+	suite.Require().NoError(params.Validate())
+}
+
+func (suite *BacktestSuite) TestDefinitionParametersErrors() {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	def := Definition{
+		Version:          2,
+		StartTime:        &start,
+		EndTime:          &start,
+		Mode:             "unknown",
+		PricePeriod:      "M7",
+		GapPolicy:        "unknown",
+		SnapshotInterval: "often",
+		Accounts:         map[string]map[string]float64{"binance": {"USDT": -1}},
+		Subscriptions: []DefinitionSubscription{
+			{Exchange: "binance", Pair: "BTC-USDT"},
+			{Exchange: "binance", Pair: "BTC-USDT"},
+			{Exchange: "", Pair: "BTC"},
+		},
+		Fees: map[string]Fee{"binance": {Rate: 2}},
+		Tags: []string{"sma", ""},
+	}
+
+	_, err := def.Parameters()
+	suite.Require().ErrorIs(err, ErrInvalidDefinition)
+
+	var errs DefinitionErrors
+	suite.Require().True(errors.As(err, &errs))
+	fields := make([]string, len(errs))
+	for i, e := range errs {
+		fields[i] = e.Field
+	}
+	suite.Require().Equal([]string{
+		"version", "end_time", "snapshot_interval", "mode", "price_period", "gap_policy",
+		"accounts.binance.USDT", "subscriptions[1]", "subscriptions[2]", "fees.binance.rate",
+		"tags[1]",
+	}, fields)
+
+	// Every underlying error should be reachable
+	suite.Require().ErrorIs(err, ErrStartAfterEnd)
+	suite.Require().ErrorIs(err, ErrInvalidSnapshotInterval)
+	suite.Require().ErrorIs(err, ErrInvalidMode)
+	suite.Require().ErrorIs(err, ErrInvalidPricePeriod)
+	suite.Require().ErrorIs(err, ErrInvalidGapPolicy)
+	suite.Require().ErrorIs(err, account.ErrInvalidBalanceAmount)
+	suite.Require().ErrorIs(err, ErrTickSubscriptionAlreadyExists)
+	suite.Require().ErrorIs(err, ErrInvalidSubscription)
+	suite.Require().ErrorIs(err, ErrInvalidFee)
+	suite.Require().ErrorIs(err, ErrInvalidMetadata)
+
+	// Missing fields
+	_, err = Definition{}.Parameters()
+	suite.Require().ErrorContains(err, "version: missing; start_time: missing")
+}
+
+func (suite *BacktestSuite) TestParseDefinitionUnknownField() {
+	_, err := ParseDefinition([]byte("version: 1\nstart: 2024-01-01T00:00:00Z\n"), DefinitionFormatIsYAML)
+	suite.Require().ErrorIs(err, ErrInvalidDefinition)
+
+	_, err = ParseDefinition([]byte(`{"version": 1, "start": "2024-01-01T00:00:00Z"}`), DefinitionFormatIsJSON)
+	suite.Require().ErrorIs(err, ErrInvalidDefinition)
+
+	_, err = ParseDefinition([]byte(`version = 1`), DefinitionFormat("toml"))
+	suite.Require().ErrorIs(err, ErrInvalidDefinitionFormat)
+}
+
+func (suite *BacktestSuite) TestLoadDefinitionFile() {
+	dir := suite.T().TempDir()
+	path := filepath.Join(dir, "backtest.json")
+	content := `{
+		"version": 1,
+		"start_time": "2024-01-01T00:00:00Z",
+		"accounts": {"binance": {"USDT": 1000}},
+		"fees": {"binance": {"rate": 0.001}}
+	}`
+	suite.Require().NoError(os.WriteFile(path, []byte(content), 0o600))
+
+	def, err := LoadDefinitionFile(path)
+	suite.Require().NoError(err)
+	suite.Require().Equal(DefinitionVersion, def.Version)
+	suite.Require().Equal(Fee{Rate: 0.001}, def.Fees["binance"])
+
+	_, err = LoadDefinitionFile(filepath.Join(dir, "backtest.txt"))
+	suite.Require().ErrorIs(err, ErrInvalidDefinitionFormat)
+}
@@ -0,0 +1,100 @@
+package backtest
+
+import (
+	"time"
+
+	"github.com/cryptellation/runtime/order"
+	"github.com/google/uuid"
+)
+
+// DefaultEventLogCapacity is the default count of events kept by an event log.
+const DefaultEventLogCapacity = 1000
+
+// EventType is the type of an event happening during a backtest run.
+type EventType string
+
+const (
+	// EventTypeStepAdvanced is the type of the event sent when the backtest
+	// advanced to its next step.
+	EventTypeStepAdvanced EventType = "step_advanced"
+	// EventTypeOrderFilled is the type of the event sent when an order has been
+	// executed on the backtest.
+	EventTypeOrderFilled EventType = "order_filled"
+	// EventTypeOrderRejected is the type of the event sent when an order has been
+	// refused by the backtest.
+	EventTypeOrderRejected EventType = "order_rejected"
+	// EventTypeStatusChanged is the type of the event sent when the status of
+	// the backtest changed.
+	EventTypeStatusChanged EventType = "status_changed"
+)
+
+// String will return the string representation of the event type.
+func (t EventType) String() string {
+	return string(t)
+}
+
+// Event is an event happening during a backtest run.
+type Event struct {
+	// Sequence is the position of the event in the run, starting at 1.
+	Sequence   int64     `json:"sequence"`
+	Type       EventType `json:"type"`
+	BacktestID uuid.UUID `json:"backtest_id"`
+	// Time is the time of the backtest when the event happened.
+	Time time.Time `json:"time"`
+	// Status is set on status changes.
+	Status Status `json:"status,omitempty"`
+	// Order is set on filled and rejected orders.
+	Order *order.Order `json:"order,omitempty"`
+	// Error is the reason of a rejected order or of a failed run.
+	Error string `json:"error,omitempty"`
+}
+
+// EventLog is the log of the events of a backtest run. Only the last events
+// are kept, up to its capacity.
+type EventLog struct {
+	Capacity int
+	Events   []Event
+	// LastSequence is the sequence of the last appended event.
+	LastSequence int64
+	// Closed is true when no more event will be appended.
+	Closed bool
+}
+
+// NewEventLog creates a new event log keeping up to capacity events.
+func NewEventLog(capacity int) *EventLog {
+	if capacity <= 0 {
+		capacity = DefaultEventLogCapacity
+	}
+	return &EventLog{Capacity: capacity}
+}
+
+// Append sets the sequence of the event and adds it to the log, dropping the
+// oldest event if the log is full.
+func (l *EventLog) Append(evt Event) Event {
+	l.LastSequence++
+	evt.Sequence = l.LastSequence
+
+	l.Events = append(l.Events, evt)
+	if len(l.Events) > l.Capacity {
+		l.Events = l.Events[len(l.Events)-l.Capacity:]
+	}
+
+	return evt
+}
+
+// HasAfter returns true if events with a sequence greater than the given one
+// are available.
+func (l *EventLog) HasAfter(sequence int64) bool {
+	return l.LastSequence > sequence
+}
+
+// After returns the kept events with a sequence greater than the given one.
+func (l *EventLog) After(sequence int64) []Event {
+	events := make([]Event, 0)
+	for _, evt := range l.Events {
+		if evt.Sequence > sequence {
+			events = append(events, evt)
+		}
+	}
+	return events
+}
@@ -0,0 +1,43 @@
+//go:build unit
+// +build unit
+
+package backtest
+
+func (suite *BacktestSuite) TestEventLogAppendAndAfter() {
+	l := NewEventLog(10)
+	suite.Require().False(l.HasAfter(0))
+
+	for range 3 {
+		l.Append(Event{Type: EventTypeStepAdvanced})
+	}
+	evt := l.Append(Event{Type: EventTypeStatusChanged, Status: StatusFinished})
+	suite.Require().Equal(int64(4), evt.Sequence)
+
+	suite.Require().True(l.HasAfter(2))
+	suite.Require().False(l.HasAfter(4))
+
+	events := l.After(2)
+	suite.Require().Len(events, 2)
+	suite.Require().Equal(int64(3), events[0].Sequence)
+	suite.Require().Equal(StatusFinished, events[1].Status)
+	suite.Require().Empty(l.After(4))
+}
+
+func (suite *BacktestSuite) TestEventLogCapacity() {
+	l := NewEventLog(2)
+	for range 5 {
+		l.Append(Event{Type: EventTypeStepAdvanced})
+	}
+
+	suite.Require().Len(l.Events, 2)
+	suite.Require().Equal(int64(5), l.LastSequence)
+
+	events := l.After(0)
+	suite.Require().Len(events, 2)
+	suite.Require().Equal(int64(4), events[0].Sequence)
+	suite.Require().Equal(int64(5), events[1].Sequence)
+}
+
+func (suite *BacktestSuite) TestEventLogDefaultCapacity() {
+	suite.Require().Equal(DefaultEventLogCapacity, NewEventLog(0).Capacity)
+}
@@ -0,0 +1,65 @@
+package backtest
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cryptellation/candlesticks/pkg/pair"
+	"github.com/cryptellation/runtime/account"
+	"github.com/cryptellation/runtime/order"
+)
+
+// ErrInvalidFee is returned when a fee is invalid.
+var ErrInvalidFee = errors.New("invalid fee")
+
+// Fee is the fee charged by an exchange on the executed orders.
+type Fee struct {
+	// Rate is the fraction of the order value charged, in the quote asset of
+	// the order pair.
+	Rate float64 `json:"rate"`
+}
+
+// Validate validates the fee.
+func (f Fee) Validate() error {
+	if f.Rate < 0 || f.Rate >= 1 {
+		return fmt.Errorf("%w: rate %v should be in [0, 1)", ErrInvalidFee, f.Rate)
+	}
+	return nil
+}
+
+// Amount returns the fee charged on the order, in the quote asset of its pair.
+func (f Fee) Amount(o order.Order, price float64) float64 {
+	return o.Quantity * price * f.Rate
+}
+
+// exchangeFee returns the fee charged on the orders of the exchange, and false
+// if there is none. The orders of an exchange without fee are applied exactly
+// as if the fees did not exist.
+func exchangeFee(fees map[string]Fee, exchange string) (Fee, bool) {
+	fee, ok := fees[exchange]
+	return fee, ok && fee.Rate > 0
+}
+
+// applyOrderWithFee applies the order at the price on the account and charges
+// its fee, in the quote asset of its pair. Sells pay the fee with their
+// proceeds, buys need it on top of the order.
+func applyOrderWithFee(a *account.Account, fee Fee, o order.Order, price float64) error {
+	_, quote, err := pair.ParsePair(o.Pair)
+	if err != nil {
+		return fmt.Errorf("error when parsing order pair symbol: %w", err)
+	}
+
+	amount := fee.Amount(o, price)
+	if o.Side == order.SideIsBuy {
+		if required := o.Quantity*price + amount; a.Balances[quote] < required {
+			return fmt.Errorf("%w: not enough %s on %s with fees (min=%f, got=%f)",
+				account.ErrNotEnoughAsset, quote, o.Pair, required, a.Balances[quote])
+		}
+	}
+
+	if err := a.ApplyOrder(price, o); err != nil {
+		return err
+	}
+	a.Balances[quote] -= amount
+	return nil
+}
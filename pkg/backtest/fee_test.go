@@ -0,0 +1,68 @@
+//go:build unit
+// +build unit
+
+package backtest
+
+import (
+	"github.com/cryptellation/candlesticks/pkg/candlestick"
+	"github.com/cryptellation/runtime/account"
+	"github.com/cryptellation/runtime/order"
+)
+
+func (suite *BacktestSuite) TestFeeValidate() {
+	suite.Require().NoError(Fee{}.Validate())
+	suite.Require().NoError(Fee{Rate: 0.001}.Validate())
+	suite.Require().ErrorIs(Fee{Rate: -0.1}.Validate(), ErrInvalidFee)
+	suite.Require().ErrorIs(Fee{Rate: 1}.Validate(), ErrInvalidFee)
+}
+
+func (suite *BacktestSuite) TestApplyOrderWithFee() {
+	a := account.Account{Balances: map[string]float64{"USDT": 1000, "BTC": 1}}
+	buy := order.Order{Pair: "BTC-USDT", Side: order.SideIsBuy, Quantity: 10}
+
+	// The buy order and its fee should not exceed the quote balance
+	err := applyOrderWithFee(&a, Fee{Rate: 0.01}, buy, 100)
+	suite.Require().ErrorIs(err, account.ErrNotEnoughAsset)
+	suite.Require().Equal(map[string]float64{"USDT": 1000, "BTC": 1}, a.Balances)
+
+	// Sells pay the fee from their proceeds
+	sell := order.Order{Pair: "BTC-USDT", Side: order.SideIsSell, Quantity: 1}
+	suite.Require().NoError(applyOrderWithFee(&a, Fee{Rate: 0.01}, sell, 100))
+	suite.Require().Equal(float64(1099), a.Balances["USDT"])
+}
+
+func (suite *BacktestSuite) TestAddOrderWithoutFees() {
+	ord := order.Order{Exchange: "exchange", Pair: "BTC-USDT", Side: order.SideIsBuy, Quantity: 10}
+	cs := candlestick.Candlestick{Open: 100, High: 100, Low: 100, Close: 100}
+
+	// Without fee on the exchange, the order is applied as on the account only
+	expected := account.Account{Balances: map[string]float64{"USDT": 1000}}
+	suite.Require().NoError(expected.ApplyOrder(100, ord))
+
+	for _, fees := range []map[string]Fee{nil, {"exchange": {}}, {"other": {Rate: 0.01}}} {
+		bt := Backtest{
+			Accounts: map[string]account.Account{
+				"exchange": {Balances: map[string]float64{"USDT": 1000}},
+			},
+			Fees: fees,
+		}
+		suite.Require().NoError(bt.AddOrder(ord, cs))
+		suite.Require().Equal(expected.Balances, bt.Accounts["exchange"].Balances)
+
+		// The statistics get back the same initial accounts
+		accounts, err := bt.InitialAccounts()
+		suite.Require().NoError(err)
+		suite.Require().Equal(float64(1000), accounts["exchange"].Balances["USDT"])
+	}
+}
+
+func (suite *BacktestSuite) TestInitialAccountsWithFees() {
+	bt := suite.newStatsBacktest()
+	bt.Fees = map[string]Fee{"exchange": {Rate: 0.01}}
+	bt.Accounts["exchange"].Balances["USDT"] -= 2.5
+
+	accounts, err := bt.InitialAccounts()
+	suite.Require().NoError(err)
+	suite.Require().InDelta(1000, accounts["exchange"].Balances["USDT"], 1e-9)
+	suite.Require().Equal(float64(0), accounts["exchange"].Balances["BTC"])
+}
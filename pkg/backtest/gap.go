@@ -0,0 +1,205 @@
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cryptellation/ticks/pkg/tick"
+)
+
+var (
+	// ErrInvalidGapPolicy is returned when the gap policy is invalid.
+	ErrInvalidGapPolicy = errors.New("invalid gap policy")
+	// ErrDataGap is returned when a gap is detected with the fail gap policy.
+	ErrDataGap = errors.New("data gap")
+)
+
+// GapPolicy is the policy applied when prices are missing for a subscription.
+type GapPolicy string
+
+const (
+	// GapPolicySkip skips to the next available candlestick. Subscriptions without
+	// price at this time are removed from the step.
+	GapPolicySkip GapPolicy = "skip"
+	// GapPolicyForwardFill uses the last known price of a subscription when its
+	// price is missing.
+	GapPolicyForwardFill GapPolicy = "forward_fill"
+	// GapPolicyStop stops the backtest at the first gap.
+	GapPolicyStop GapPolicy = "stop"
+	// GapPolicyFail fails the backtest at the first gap with the detected gap ranges.
+	GapPolicyFail GapPolicy = "fail"
+)
+
+// Validate will validate the gap policy.
+func (p GapPolicy) Validate() error {
+	switch p {
+	case GapPolicySkip, GapPolicyForwardFill, GapPolicyStop, GapPolicyFail:
+		return nil
+	default:
+		return ErrInvalidGapPolicy
+	}
+}
+
+// String will return the string representation of the gap policy.
+func (p GapPolicy) String() string {
+	return string(p)
+}
+
+// Opt will return a pointer to the gap policy.
+func (p GapPolicy) Opt() *GapPolicy {
+	return &p
+}
+
+// Gap is a time range where prices were missing for a subscription.
+// The start is included and the end is excluded.
+type Gap struct {
+	Exchange string    `json:"exchange"`
+	Pair     string    `json:"pair"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+}
+
+// String returns a string representation of the gap.
+func (g Gap) String() string {
+	return fmt.Sprintf("%s %s [%s, %s)",
+		g.Exchange, g.Pair,
+		g.Start.Format(time.RFC3339), g.End.Format(time.RFC3339))
+}
+
+// Step is the result of the resolution of the prices for a backtest step.
+type Step struct {
+	// Ticks are the prices to send to the strategy for this step.
+	Ticks []tick.Tick
+	// Gaps are the gaps detected on this step.
+	Gaps []Gap
+	// Stop is true if the backtest should not execute any more steps.
+	Stop bool
+}
+
+// ResolveStep applies the gap policy on the first available prices of each
+// subscription from the current time (at most one per subscription) and the
+// last known prices. It moves the backtest to the time of the step and records
+// the detected gaps on the backtest.
+func (bt *Backtest) ResolveStep(
+	firsts []tick.Tick,
+	lastKnown map[tick.Subscription]tick.Tick,
+) (Step, error) {
+	current := bt.CurrentCandlestick.Time
+	earliest, _ := tick.OnlyKeepEarliestSameTime(firsts, bt.EndTime)
+
+	// Get the next available price of each subscription
+	next := make(map[tick.Subscription]tick.Tick, len(firsts))
+	for _, t := range firsts {
+		next[tick.Subscription{Exchange: t.Exchange, Pair: t.Pair}] = t
+	}
+
+	// Detect the gaps from the current time
+	var step Step
+	for _, sub := range bt.PricesSubscriptions {
+		end := bt.EndTime
+		if t, ok := next[sub]; ok {
+			end = t.Time
+		}
+
+		if end.After(current) {
+			step.Gaps = append(step.Gaps, Gap{
+				Exchange: sub.Exchange,
+				Pair:     sub.Pair,
+				Start:    current,
+				End:      end,
+			})
+		}
+	}
+	bt.recordGaps(step.Gaps)
+
+	// Apply the policy on gaps
+	stepTime := earliest
+	if len(step.Gaps) > 0 {
+		switch bt.GapPolicy {
+		case GapPolicyStop:
+			bt.SetCurrentTime(bt.EndTime)
+			step.Stop = true
+			return step, nil
+		case GapPolicyFail:
+			bt.SetCurrentTime(bt.EndTime)
+			step.Stop = true
+			return step, fmt.Errorf("%w: %s", ErrDataGap, gapsToString(step.Gaps))
+		case GapPolicyForwardFill:
+			if hasLastKnown(step.Gaps, lastKnown) {
+				stepTime = current
+			}
+		case GapPolicySkip:
+		default:
+			return step, fmt.Errorf("error with gap policy %q: %w", bt.GapPolicy, ErrInvalidGapPolicy)
+		}
+	}
+
+	// Stop if there is no more price
+	if !stepTime.Before(bt.EndTime) {
+		bt.SetCurrentTime(bt.EndTime)
+		step.Stop = true
+		return step, nil
+	}
+
+	// Get the ticks for the step
+	for _, sub := range bt.PricesSubscriptions {
+		if t, ok := next[sub]; ok && t.Time.Equal(stepTime) {
+			step.Ticks = append(step.Ticks, t)
+		} else if t, ok := lastKnown[sub]; ok && bt.GapPolicy == GapPolicyForwardFill {
+			t.Time = stepTime
+			step.Ticks = append(step.Ticks, t)
+		}
+	}
+
+	// Move to the step time if needed
+	if stepTime.After(current) {
+		bt.SetCurrentTime(stepTime)
+	}
+
+	return step, nil
+}
+
+// recordGaps adds the gaps to the backtest, merging them with the previous
+// gap of the same subscription when they overlap.
+func (bt *Backtest) recordGaps(gaps []Gap) {
+	for _, g := range gaps {
+		merged := false
+		for i := len(bt.Gaps) - 1; i >= 0; i-- {
+			prev := &bt.Gaps[i]
+			if prev.Exchange != g.Exchange || prev.Pair != g.Pair {
+				continue
+			}
+
+			if !prev.End.Before(g.Start) {
+				if g.End.After(prev.End) {
+					prev.End = g.End
+				}
+				merged = true
+			}
+			break
+		}
+
+		if !merged {
+			bt.Gaps = append(bt.Gaps, g)
+		}
+	}
+}
+
+func hasLastKnown(gaps []Gap, lastKnown map[tick.Subscription]tick.Tick) bool {
+	for _, g := range gaps {
+		if _, ok := lastKnown[tick.Subscription{Exchange: g.Exchange, Pair: g.Pair}]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func gapsToString(gaps []Gap) string {
+	str := make([]string, len(gaps))
+	for i, g := range gaps {
+		str[i] = g.String()
+	}
+	return strings.Join(str, ", ")
+}
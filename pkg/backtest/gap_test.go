@@ -0,0 +1,130 @@
+//go:build unit
+// +build unit
+
+package backtest
+
+import (
+	"time"
+
+	"github.com/cryptellation/candlesticks/pkg/candlestick"
+	"github.com/cryptellation/candlesticks/pkg/period"
+	"github.com/cryptellation/ticks/pkg/tick"
+)
+
+func (suite *BacktestSuite) newGapBacktest(policy GapPolicy) Backtest {
+	return Backtest{
+		StartTime:   time.Unix(0, 0).UTC(),
+		EndTime:     time.Unix(600, 0).UTC(),
+		Mode:        ModeIsCloseOHLC,
+		PricePeriod: period.M1,
+		CurrentCandlestick: CurrentCandlestick{
+			Time:  time.Unix(60, 0).UTC(),
+			Price: candlestick.PriceTypeIsClose,
+		},
+		PricesSubscriptions: []tick.Subscription{
+			{Exchange: "exchange", Pair: "BTC-USDT"},
+			{Exchange: "exchange", Pair: "ETH-USDT"},
+		},
+		GapPolicy: policy,
+	}
+}
+
+func (suite *BacktestSuite) TestResolveStepWithoutGap() {
+	bt := suite.newGapBacktest(GapPolicyFail)
+	prices := []tick.Tick{
+		{Exchange: "exchange", Pair: "BTC-USDT", Time: time.Unix(60, 0).UTC(), Price: 1},
+		{Exchange: "exchange", Pair: "ETH-USDT", Time: time.Unix(60, 0).UTC(), Price: 2},
+	}
+
+	step, err := bt.ResolveStep(prices, nil)
+	suite.Require().NoError(err)
+	suite.Require().False(step.Stop)
+	suite.Require().Len(step.Ticks, 2)
+	suite.Require().Empty(step.Gaps)
+	suite.Require().Empty(bt.Gaps)
+}
+
+func (suite *BacktestSuite) TestResolveStepSkip() {
+	bt := suite.newGapBacktest(GapPolicySkip)
+	prices := []tick.Tick{
+		{Exchange: "exchange", Pair: "BTC-USDT", Time: time.Unix(120, 0).UTC(), Price: 1},
+		{Exchange: "exchange", Pair: "ETH-USDT", Time: time.Unix(240, 0).UTC(), Price: 2},
+	}
+
+	step, err := bt.ResolveStep(prices, nil)
+	suite.Require().NoError(err)
+	suite.Require().False(step.Stop)
+	suite.Require().Equal(time.Unix(120, 0).UTC(), bt.CurrentCandlestick.Time)
+	suite.Require().Len(step.Ticks, 1)
+	suite.Require().Equal("BTC-USDT", step.Ticks[0].Pair)
+	suite.Require().Equal([]Gap{
+		{Exchange: "exchange", Pair: "BTC-USDT", Start: time.Unix(60, 0).UTC(), End: time.Unix(120, 0).UTC()},
+		{Exchange: "exchange", Pair: "ETH-USDT", Start: time.Unix(60, 0).UTC(), End: time.Unix(240, 0).UTC()},
+	}, bt.Gaps)
+
+	// The same gap on the next step should be merged
+	bt.CurrentCandlestick.Time = time.Unix(180, 0).UTC()
+	prices[0].Time = time.Unix(180, 0).UTC()
+	_, err = bt.ResolveStep(prices, nil)
+	suite.Require().NoError(err)
+	suite.Require().Len(bt.Gaps, 2)
+	suite.Require().Equal(time.Unix(240, 0).UTC(), bt.Gaps[1].End)
+}
+
+func (suite *BacktestSuite) TestResolveStepForwardFill() {
+	bt := suite.newGapBacktest(GapPolicyForwardFill)
+	prices := []tick.Tick{
+		{Exchange: "exchange", Pair: "BTC-USDT", Time: time.Unix(60, 0).UTC(), Price: 1},
+	}
+	lastKnown := map[tick.Subscription]tick.Tick{
+		{Exchange: "exchange", Pair: "ETH-USDT"}: {
+			Exchange: "exchange", Pair: "ETH-USDT", Time: time.Unix(0, 0).UTC(), Price: 2,
+		},
+	}
+
+	step, err := bt.ResolveStep(prices, lastKnown)
+	suite.Require().NoError(err)
+	suite.Require().False(step.Stop)
+	suite.Require().Len(step.Ticks, 2)
+	suite.Require().Equal(time.Unix(60, 0).UTC(), step.Ticks[1].Time)
+	suite.Require().Equal(float64(2), step.Ticks[1].Price)
+	suite.Require().Len(bt.Gaps, 1)
+	suite.Require().Equal(bt.EndTime, bt.Gaps[0].End)
+}
+
+func (suite *BacktestSuite) TestResolveStepStop() {
+	bt := suite.newGapBacktest(GapPolicyStop)
+	prices := []tick.Tick{
+		{Exchange: "exchange", Pair: "BTC-USDT", Time: time.Unix(60, 0).UTC(), Price: 1},
+	}
+
+	step, err := bt.ResolveStep(prices, nil)
+	suite.Require().NoError(err)
+	suite.Require().True(step.Stop)
+	suite.Require().Empty(step.Ticks)
+	suite.Require().True(bt.Done())
+	suite.Require().Len(bt.Gaps, 1)
+}
+
+func (suite *BacktestSuite) TestResolveStepFail() {
+	bt := suite.newGapBacktest(GapPolicyFail)
+	prices := []tick.Tick{
+		{Exchange: "exchange", Pair: "ETH-USDT", Time: time.Unix(120, 0).UTC(), Price: 1},
+	}
+
+	step, err := bt.ResolveStep(prices, nil)
+	suite.Require().ErrorIs(err, ErrDataGap)
+	suite.Require().Contains(err.Error(), "BTC-USDT")
+	suite.Require().Contains(err.Error(), "ETH-USDT")
+	suite.Require().True(step.Stop)
+	suite.Require().Len(bt.Gaps, 2)
+}
+
+func (suite *BacktestSuite) TestResolveStepNoMorePrice() {
+	bt := suite.newGapBacktest(GapPolicySkip)
+
+	step, err := bt.ResolveStep(nil, nil)
+	suite.Require().NoError(err)
+	suite.Require().True(step.Stop)
+	suite.Require().True(bt.Done())
+}
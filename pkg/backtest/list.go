@@ -0,0 +1,167 @@
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cryptellation/candlesticks/pkg/period"
+	"github.com/cryptellation/runtime/account"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidSortField is returned when the sort field is invalid.
+	ErrInvalidSortField = errors.New("invalid sort field")
+	// ErrInvalidPurgeFilters is returned when the purge filters are invalid.
+	ErrInvalidPurgeFilters = errors.New("invalid purge filters")
+)
+
+// Filters are the filters applied when listing backtests. Empty fields are
+// not applied.
+type Filters struct {
+	// Statuses filters the backtests having one of these statuses.
+	Statuses []Status
+	// Exchange filters the backtests subscribed to prices on this exchange.
+	Exchange string
+	// Pair filters the backtests subscribed to prices of this pair.
+	Pair string
+	// From filters the backtests starting at or after this time.
+	From *time.Time
+	// To filters the backtests ending at or before this time.
+	To *time.Time
+	// Tags filters the backtests having all these tags.
+	Tags []string
+	// AnyTags filters the backtests having at least one of these tags.
+	AnyTags []string
+	// Name filters the backtests whose name contains this text, regardless
+	// of the case.
+	Name string
+	// Creator filters the backtests created by this creator.
+	Creator string
+	// StrategyParameters filters the backtests whose strategy parameters
+	// contain all these ones.
+	StrategyParameters map[string]any
+}
+
+// Validate validates the filters.
+func (f Filters) Validate() error {
+	for _, s := range f.Statuses {
+		if err := s.Validate(); err != nil {
+			return fmt.Errorf("%w: %q", err, s)
+		}
+	}
+
+	if f.From != nil && f.To != nil && f.From.After(*f.To) {
+		return ErrStartAfterEnd
+	}
+
+	return nil
+}
+
+// PurgeFilters are the filters selecting the backtests to purge. At least one
+// filter is required, and running backtests are never purged.
+type PurgeFilters struct {
+	// OlderThan purges the backtests created for longer than this duration.
+	OlderThan time.Duration
+	// Statuses purges the backtests having one of these statuses.
+	Statuses []Status
+	// Tags purges the backtests having all these tags.
+	Tags []string
+}
+
+// Validate validates the purge filters.
+func (f PurgeFilters) Validate() error {
+	switch {
+	case f.OlderThan < 0:
+		return fmt.Errorf("%w: negative duration", ErrInvalidPurgeFilters)
+	case f.OlderThan == 0 && len(f.Statuses) == 0 && len(f.Tags) == 0:
+		return fmt.Errorf("%w: no filter", ErrInvalidPurgeFilters)
+	}
+
+	for _, s := range f.Statuses {
+		if err := s.Validate(); err != nil {
+			return fmt.Errorf("%w: %q", err, s)
+		} else if s == StatusRunning {
+			return fmt.Errorf("%w: %w", ErrInvalidPurgeFilters, ErrBacktestRunning)
+		}
+	}
+
+	return nil
+}
+
+// SortField is the field used to sort backtests.
+type SortField string
+
+const (
+	// SortFieldStartTime sorts the backtests on their start time.
+	SortFieldStartTime SortField = "start_time"
+	// SortFieldEndTime sorts the backtests on their end time.
+	SortFieldEndTime SortField = "end_time"
+	// SortFieldCurrentTime sorts the backtests on their current time.
+	SortFieldCurrentTime SortField = "current_time"
+)
+
+// Validate will validate the sort field.
+func (f SortField) Validate() error {
+	switch f {
+	case SortFieldStartTime, SortFieldEndTime, SortFieldCurrentTime:
+		return nil
+	default:
+		return ErrInvalidSortField
+	}
+}
+
+// String will return the string representation of the sort field.
+func (f SortField) String() string {
+	return string(f)
+}
+
+// Sort is the order of listed backtests. Backtests with the same value on the
+// sort field are ordered by ID.
+type Sort struct {
+	// Field is the field used to sort backtests. Defaults to the start time.
+	Field      SortField
+	Descending bool
+}
+
+// Summary is a lightweight representation of a backtest, without its orders.
+type Summary struct {
+	ID                  uuid.UUID                  `json:"id"`
+	Name                string                     `json:"name,omitempty"`
+	Description         string                     `json:"description,omitempty"`
+	Creator             string                     `json:"creator,omitempty"`
+	Status              Status                     `json:"status"`
+	StartTime           time.Time                  `json:"start_time"`
+	EndTime             time.Time                  `json:"end_time"`
+	Mode                Mode                       `json:"mode"`
+	PricePeriod         period.Symbol              `json:"price_period"`
+	CurrentCandlestick  CurrentCandlestick         `json:"current_candlestick"`
+	Accounts            map[string]account.Account `json:"accounts"`
+	PricesSubscriptions []tick.Subscription        `json:"tick_subscriptions"`
+	OrdersCount         int                        `json:"orders_count"`
+	Tags                []string                   `json:"tags,omitempty"`
+	StrategyParameters  map[string]any             `json:"strategy_parameters,omitempty"`
+}
+
+// Summary returns the summary of the backtest.
+func (bt Backtest) Summary() Summary {
+	return Summary{
+		ID:                  bt.ID,
+		Name:                bt.Name,
+		Description:         bt.Description,
+		Creator:             bt.Creator,
+		Status:              bt.Status,
+		StartTime:           bt.StartTime,
+		EndTime:             bt.EndTime,
+		Mode:                bt.Mode,
+		PricePeriod:         bt.PricePeriod,
+		CurrentCandlestick:  bt.CurrentCandlestick,
+		Accounts:            bt.Accounts,
+		PricesSubscriptions: bt.PricesSubscriptions,
+		OrdersCount:         len(bt.Orders),
+		Tags:                bt.Tags,
+		StrategyParameters:  bt.StrategyParameters,
+	}
+}
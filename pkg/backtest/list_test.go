@@ -0,0 +1,81 @@
+//go:build unit
+// +build unit
+
+package backtest
+
+import (
+	"time"
+
+	"github.com/cryptellation/runtime/order"
+	"github.com/google/uuid"
+)
+
+func (suite *BacktestSuite) TestFiltersValidate() {
+	from, to := time.Unix(60, 0), time.Unix(0, 0)
+
+	cases := []struct {
+		Filters Filters
+		Err     error
+	}{
+		{Filters: Filters{}},
+		{Filters: Filters{Statuses: []Status{StatusRunning, StatusFinished}}},
+		{Filters: Filters{Statuses: []Status{"unknown"}}, Err: ErrInvalidStatus},
+		{Filters: Filters{From: &to, To: &from}},
+		{Filters: Filters{From: &from, To: &to}, Err: ErrStartAfterEnd},
+	}
+
+	for i, c := range cases {
+		err := c.Filters.Validate()
+		if c.Err == nil {
+			suite.Require().NoError(err, i)
+		} else {
+			suite.Require().ErrorIs(err, c.Err, i)
+		}
+	}
+}
+
+func (suite *BacktestSuite) TestPurgeFiltersValidate() {
+	cases := []struct {
+		Filters PurgeFilters
+		Err     error
+	}{
+		{Filters: PurgeFilters{}, Err: ErrInvalidPurgeFilters},
+		{Filters: PurgeFilters{OlderThan: -time.Hour}, Err: ErrInvalidPurgeFilters},
+		{Filters: PurgeFilters{OlderThan: time.Hour}},
+		{Filters: PurgeFilters{Tags: []string{"tag"}}},
+		{Filters: PurgeFilters{Statuses: []Status{StatusFailed}}},
+		{Filters: PurgeFilters{Statuses: []Status{"unknown"}}, Err: ErrInvalidStatus},
+		{Filters: PurgeFilters{Statuses: []Status{StatusRunning}}, Err: ErrBacktestRunning},
+	}
+
+	for i, c := range cases {
+		err := c.Filters.Validate()
+		if c.Err == nil {
+			suite.Require().NoError(err, i)
+		} else {
+			suite.Require().ErrorIs(err, c.Err, i)
+		}
+	}
+}
+
+func (suite *BacktestSuite) TestSortFieldValidate() {
+	suite.Require().NoError(SortFieldStartTime.Validate())
+	suite.Require().NoError(SortFieldEndTime.Validate())
+	suite.Require().NoError(SortFieldCurrentTime.Validate())
+	suite.Require().ErrorIs(SortField("unknown").Validate(), ErrInvalidSortField)
+}
+
+func (suite *BacktestSuite) TestSummary() {
+	bt := Backtest{
+		ID:     uuid.New(),
+		Status: StatusRunning,
+		Orders: []order.Order{{ID: uuid.New()}, {ID: uuid.New()}},
+		Tags:   []string{"tag"},
+	}
+
+	s := bt.Summary()
+	suite.Require().Equal(bt.ID, s.ID)
+	suite.Require().Equal(StatusRunning, s.Status)
+	suite.Require().Equal(2, s.OrdersCount)
+	suite.Require().Equal([]string{"tag"}, s.Tags)
+}
@@ -0,0 +1,103 @@
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxNameLength is the maximum length of the name and the creator of a
+	// backtest, in characters.
+	MaxNameLength = 255
+	// MaxTagLength is the maximum length of a backtest tag, in characters.
+	MaxTagLength = 64
+)
+
+// ErrInvalidMetadata is returned when the name, description, tags or creator
+// of a backtest are invalid.
+var ErrInvalidMetadata = errors.New("invalid backtest metadata")
+
+// MetadataUpdate is the update of the descriptive fields of a backtest. Nil
+// fields are left unchanged.
+type MetadataUpdate struct {
+	Name        *string   `json:"name,omitempty"`
+	Description *string   `json:"description,omitempty"`
+	Tags        *[]string `json:"tags,omitempty"`
+}
+
+// Validate validates the metadata update.
+func (u MetadataUpdate) Validate() error {
+	switch {
+	case u.Name == nil && u.Description == nil && u.Tags == nil:
+		return fmt.Errorf("%w: nothing to update", ErrInvalidMetadata)
+	case u.Name != nil:
+		if err := validateName("name", *u.Name); err != nil {
+			return err
+		}
+	}
+
+	if u.Tags != nil {
+		return validateTags(*u.Tags)
+	}
+
+	return nil
+}
+
+// UpdateMetadata applies the update to the backtest.
+func (bt *Backtest) UpdateMetadata(u MetadataUpdate) error {
+	if err := u.Validate(); err != nil {
+		return err
+	}
+
+	if u.Name != nil {
+		bt.Name = strings.TrimSpace(*u.Name)
+	}
+	if u.Description != nil {
+		bt.Description = *u.Description
+	}
+	if u.Tags != nil {
+		bt.Tags = NormalizeTags(*u.Tags)
+	}
+
+	return nil
+}
+
+// NormalizeTags returns the tags without surrounding spaces nor duplicates,
+// in their original order.
+func NormalizeTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	normalized := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		if !slices.Contains(normalized, t) {
+			normalized = append(normalized, t)
+		}
+	}
+	return normalized
+}
+
+func validateName(field, name string) error {
+	if utf8.RuneCountInString(strings.TrimSpace(name)) > MaxNameLength {
+		return fmt.Errorf("%w: %s longer than %d characters", ErrInvalidMetadata, field, MaxNameLength)
+	}
+	return nil
+}
+
+func validateTags(tags []string) error {
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		switch {
+		case t == "":
+			return fmt.Errorf("%w: empty tag", ErrInvalidMetadata)
+		case utf8.RuneCountInString(t) > MaxTagLength:
+			return fmt.Errorf("%w: tag %q longer than %d characters", ErrInvalidMetadata, t, MaxTagLength)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+//go:build unit
+// +build unit
+
+package backtest
+
+import "strings"
+
+func (suite *BacktestSuite) TestMetadataUpdateValidate() {
+	name, long := "SMA crossover", strings.Repeat("a", MaxNameLength+1)
+	empty, invalid := []string{}, []string{"ok", " "}
+
+	suite.Require().NoError(MetadataUpdate{Name: &name}.Validate())
+	suite.Require().NoError(MetadataUpdate{Tags: &empty}.Validate())
+	suite.Require().ErrorIs(MetadataUpdate{}.Validate(), ErrInvalidMetadata)
+	suite.Require().ErrorIs(MetadataUpdate{Name: &long}.Validate(), ErrInvalidMetadata)
+	suite.Require().ErrorIs(MetadataUpdate{Tags: &invalid}.Validate(), ErrInvalidMetadata)
+}
+
+func (suite *BacktestSuite) TestUpdateMetadata() {
+	bt := Backtest{Name: "old", Description: "kept", Creator: "alice", Tags: []string{"old"}}
+
+	name, tags := " new ", []string{"sma", " btc", "sma"}
+	suite.Require().NoError(bt.UpdateMetadata(MetadataUpdate{Name: &name, Tags: &tags}))
+	suite.Require().Equal("new", bt.Name)
+	suite.Require().Equal("kept", bt.Description)
+	suite.Require().Equal("alice", bt.Creator)
+	suite.Require().Equal([]string{"sma", "btc"}, bt.Tags)
+
+	// Tags can be removed
+	tags = nil
+	suite.Require().NoError(bt.UpdateMetadata(MetadataUpdate{Tags: &tags}))
+	suite.Require().Nil(bt.Tags)
+}
+
+func (suite *BacktestSuite) TestParametersValidateMetadata() {
+	params := Parameters{Creator: strings.Repeat("a", MaxNameLength+1)}
+	suite.Require().ErrorIs(params.validateMetadata(), ErrInvalidMetadata)
+
+	params = Parameters{Name: "name", Tags: []string{"a", ""}}
+	suite.Require().ErrorIs(params.validateMetadata(), ErrInvalidMetadata)
+}
@@ -0,0 +1,115 @@
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/cryptellation/runtime"
+	"github.com/cryptellation/runtime/account"
+	"github.com/cryptellation/runtime/order"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidSnapshotInterval is returned when the snapshot interval is invalid.
+	ErrInvalidSnapshotInterval = errors.New("invalid snapshot interval")
+	// ErrInvalidSnapshot is returned when the snapshot does not match the backtest.
+	ErrInvalidSnapshot = errors.New("invalid snapshot")
+)
+
+// DefaultSnapshotInterval is the default interval of simulated time between
+// two snapshots of a backtest.
+const DefaultSnapshotInterval = 24 * time.Hour
+
+// Snapshot is the state of a backtest at a given time.
+// Orders being only appended to a backtest, the snapshot only keeps their count.
+type Snapshot struct {
+	BacktestID          uuid.UUID                  `json:"backtest_id"`
+	CurrentCandlestick  CurrentCandlestick         `json:"current_candlestick"`
+	Accounts            map[string]account.Account `json:"accounts"`
+	OrdersCount         int                        `json:"orders_count"`
+	PricesSubscriptions []tick.Subscription        `json:"tick_subscriptions"`
+}
+
+// Snapshot takes a snapshot of the backtest current state.
+func (bt Backtest) Snapshot() Snapshot {
+	return Snapshot{
+		BacktestID:          bt.ID,
+		CurrentCandlestick:  bt.CurrentCandlestick,
+		Accounts:            copyAccounts(bt.Accounts),
+		OrdersCount:         len(bt.Orders),
+		PricesSubscriptions: append(make([]tick.Subscription, 0, len(bt.PricesSubscriptions)), bt.PricesSubscriptions...),
+	}
+}
+
+// IsSnapshotDue returns true if a snapshot interval boundary has been crossed
+// since the previous time of the backtest.
+func (bt Backtest) IsSnapshotDue(previous time.Time) bool {
+	if bt.SnapshotInterval <= 0 || !bt.CurrentCandlestick.Time.After(previous) {
+		return false
+	}
+
+	previousInterval := previous.Sub(bt.StartTime) / bt.SnapshotInterval
+	currentInterval := bt.CurrentCandlestick.Time.Sub(bt.StartTime) / bt.SnapshotInterval
+	return previousInterval != currentInterval
+}
+
+// Fork creates a new backtest from a snapshot of this backtest, with new
+// callbacks. Only the period after the snapshot remains to be run.
+func (bt Backtest) Fork(s Snapshot, callbacks runtime.Callbacks) (Backtest, error) {
+	if s.BacktestID != bt.ID {
+		return Backtest{}, fmt.Errorf("%w: snapshot from backtest %q", ErrInvalidSnapshot, s.BacktestID)
+	} else if s.OrdersCount > len(bt.Orders) {
+		return Backtest{}, fmt.Errorf("%w: %d orders in snapshot but %d in backtest",
+			ErrInvalidSnapshot, s.OrdersCount, len(bt.Orders))
+	}
+
+	// Only keep the gaps detected before the snapshot
+	gaps := make([]Gap, 0, len(bt.Gaps))
+	for _, g := range bt.Gaps {
+		if g.Start.Before(s.CurrentCandlestick.Time) {
+			gaps = append(gaps, g)
+		}
+	}
+
+	parentID := bt.ID
+	return Backtest{
+		ID:                  uuid.New(),
+		Tenant:              bt.Tenant,
+		Name:                bt.Name,
+		Description:         bt.Description,
+		Creator:             bt.Creator,
+		Status:              StatusCreated,
+		StartTime:           bt.StartTime,
+		EndTime:             bt.EndTime,
+		Mode:                bt.Mode,
+		PricePeriod:         bt.PricePeriod,
+		CurrentCandlestick:  s.CurrentCandlestick,
+		Accounts:            copyAccounts(s.Accounts),
+		PricesSubscriptions: append(make([]tick.Subscription, 0, len(s.PricesSubscriptions)), s.PricesSubscriptions...),
+		Orders:              append(make([]order.Order, 0, s.OrdersCount), bt.Orders[:s.OrdersCount]...),
+		Callbacks:           callbacks,
+		CallbacksPolicies:   bt.CallbacksPolicies,
+		GapPolicy:           bt.GapPolicy,
+		Gaps:                gaps,
+		SnapshotInterval:    bt.SnapshotInterval,
+		ForkedFrom:          &parentID,
+		StrategyParameters:  maps.Clone(bt.StrategyParameters),
+		Tags:                slices.Clone(bt.Tags),
+		Fees:                maps.Clone(bt.Fees),
+	}, nil
+}
+
+func copyAccounts(accounts map[string]account.Account) map[string]account.Account {
+	c := make(map[string]account.Account, len(accounts))
+	for exchange, a := range accounts {
+		c[exchange] = account.Account{
+			Balances: maps.Clone(a.Balances),
+		}
+	}
+	return c
+}
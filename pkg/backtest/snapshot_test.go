@@ -0,0 +1,80 @@
+//go:build unit
+// +build unit
+
+package backtest
+
+import (
+	"time"
+
+	"github.com/cryptellation/candlesticks/pkg/candlestick"
+	"github.com/cryptellation/candlesticks/pkg/period"
+	"github.com/cryptellation/runtime"
+	"github.com/cryptellation/runtime/account"
+	"github.com/cryptellation/runtime/order"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/google/uuid"
+)
+
+func (suite *BacktestSuite) TestIsSnapshotDue() {
+	bt := Backtest{
+		StartTime:        time.Unix(0, 0).UTC(),
+		EndTime:          time.Unix(600, 0).UTC(),
+		SnapshotInterval: 2 * time.Minute,
+		CurrentCandlestick: CurrentCandlestick{
+			Time: time.Unix(60, 0).UTC(),
+		},
+	}
+	suite.Require().False(bt.IsSnapshotDue(time.Unix(0, 0).UTC()))
+	suite.Require().False(bt.IsSnapshotDue(time.Unix(60, 0).UTC()))
+
+	bt.CurrentCandlestick.Time = time.Unix(120, 0).UTC()
+	suite.Require().True(bt.IsSnapshotDue(time.Unix(60, 0).UTC()))
+
+	bt.SnapshotInterval = 0
+	suite.Require().False(bt.IsSnapshotDue(time.Unix(60, 0).UTC()))
+}
+
+func (suite *BacktestSuite) TestForkFromSnapshot() {
+	bt := Backtest{
+		ID:          uuid.New(),
+		Tenant:      "tenant",
+		StartTime:   time.Unix(0, 0).UTC(),
+		EndTime:     time.Unix(600, 0).UTC(),
+		Mode:        ModeIsCloseOHLC,
+		PricePeriod: period.M1,
+		CurrentCandlestick: CurrentCandlestick{
+			Time:  time.Unix(120, 0).UTC(),
+			Price: candlestick.PriceTypeIsClose,
+		},
+		Accounts: map[string]account.Account{
+			"exchange": {Balances: map[string]float64{"USDT": 1000}},
+		},
+		PricesSubscriptions: []tick.Subscription{{Exchange: "exchange", Pair: "BTC-USDT"}},
+		Orders:              []order.Order{{ID: uuid.New()}},
+		GapPolicy:           GapPolicySkip,
+		SnapshotInterval:    time.Minute,
+	}
+	snapshot := bt.Snapshot()
+
+	// Modify the backtest after the snapshot
+	bt.Accounts["exchange"].Balances["USDT"] = 500
+	bt.Orders = append(bt.Orders, order.Order{ID: uuid.New()})
+	bt.CurrentCandlestick.Time = time.Unix(300, 0).UTC()
+
+	forked, err := bt.Fork(snapshot, runtime.Callbacks{})
+	suite.Require().NoError(err)
+	suite.Require().NotEqual(bt.ID, forked.ID)
+	suite.Require().Equal(bt.ID, *forked.ForkedFrom)
+	suite.Require().Equal("tenant", forked.Tenant)
+	suite.Require().Equal(time.Unix(120, 0).UTC(), forked.CurrentCandlestick.Time)
+	suite.Require().Equal(float64(1000), forked.Accounts["exchange"].Balances["USDT"])
+	suite.Require().Len(forked.Orders, 1)
+	suite.Require().Equal(bt.Orders[0].ID, forked.Orders[0].ID)
+	suite.Require().Equal(bt.PricesSubscriptions, forked.PricesSubscriptions)
+	suite.Require().Equal(bt.EndTime, forked.EndTime)
+
+	// A snapshot from another backtest is refused
+	snapshot.BacktestID = uuid.New()
+	_, err = bt.Fork(snapshot, runtime.Callbacks{})
+	suite.Require().ErrorIs(err, ErrInvalidSnapshot)
+}
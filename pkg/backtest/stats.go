@@ -0,0 +1,346 @@
+package backtest
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cryptellation/candlesticks/pkg/pair"
+	"github.com/cryptellation/runtime/account"
+	"github.com/cryptellation/runtime/order"
+)
+
+var (
+	// ErrInvalidMetric is returned when the metric is invalid.
+	ErrInvalidMetric = errors.New("invalid metric")
+	// ErrInvalidQuoteAsset is returned when the quote asset is invalid.
+	ErrInvalidQuoteAsset = errors.New("invalid quote asset")
+)
+
+// Metric is a performance metric used to compare backtests.
+type Metric string
+
+const (
+	// MetricReturn is the return of the backtest, relative to its initial equity.
+	MetricReturn Metric = "return"
+	// MetricFinalEquity is the final equity of the backtest.
+	MetricFinalEquity Metric = "final_equity"
+	// MetricMaxDrawdown is the maximum drawdown of the backtest, relative to the
+	// equity peak.
+	MetricMaxDrawdown Metric = "max_drawdown"
+)
+
+// Validate will validate the metric.
+func (m Metric) Validate() error {
+	switch m {
+	case MetricReturn, MetricFinalEquity, MetricMaxDrawdown:
+		return nil
+	default:
+		return ErrInvalidMetric
+	}
+}
+
+// String will return the string representation of the metric.
+func (m Metric) String() string {
+	return string(m)
+}
+
+// Value returns the value of the metric from the stats.
+func (m Metric) Value(s Stats) float64 {
+	switch m {
+	case MetricFinalEquity:
+		return s.FinalEquity
+	case MetricMaxDrawdown:
+		return s.MaxDrawdown
+	default:
+		return s.Return
+	}
+}
+
+// HigherIsBetter returns true if a higher value of the metric is better.
+func (m Metric) HigherIsBetter() bool {
+	return m != MetricMaxDrawdown
+}
+
+// EquityPoint is the equity of a backtest at a given time.
+type EquityPoint struct {
+	Time   time.Time `json:"time"`
+	Equity float64   `json:"equity"`
+}
+
+// Stats are the performance statistics of a backtest, valued in a quote asset.
+type Stats struct {
+	QuoteAsset    string  `json:"quote_asset"`
+	InitialEquity float64 `json:"initial_equity"`
+	FinalEquity   float64 `json:"final_equity"`
+	Return        float64 `json:"return"`
+	MaxDrawdown   float64 `json:"max_drawdown"`
+	OrdersCount   int     `json:"orders_count"`
+}
+
+// InitialAccounts returns the accounts of the backtest before any order, by
+// reverting the orders on the current accounts.
+func (bt Backtest) InitialAccounts() (map[string]account.Account, error) {
+	accounts := copyAccounts(bt.Accounts)
+	for i := len(bt.Orders) - 1; i >= 0; i-- {
+		if err := applyOrder(accounts, bt.Fees, bt.Orders[i], true); err != nil {
+			return nil, err
+		}
+	}
+
+	return accounts, nil
+}
+
+// EquityCurve returns the equity of the backtest accounts at the start, after
+// each order and at the current time, valued in the quote asset.
+//
+// Assets are valued with the price of their last order against the quote asset,
+// or the first one if there is none yet. Assets without any order against the
+// quote asset are not valued. At the current time, the assets are valued with
+// their price in endPrices, if any, so that the open positions are priced.
+func (bt Backtest) EquityCurve(quoteAsset string, endPrices map[string]float64) ([]EquityPoint, error) {
+	if quoteAsset == "" {
+		return nil, fmt.Errorf("%w: empty", ErrInvalidQuoteAsset)
+	}
+
+	accounts, err := bt.InitialAccounts()
+	if err != nil {
+		return nil, err
+	}
+
+	// Set prices to the first price of each asset
+	prices := make(map[string]float64)
+	for i := len(bt.Orders) - 1; i >= 0; i-- {
+		if base, ok := baseAgainstQuote(bt.Orders[i].Pair, quoteAsset); ok {
+			prices[base] = bt.Orders[i].Price
+		}
+	}
+
+	// Compute the equity after each order
+	curve := make([]EquityPoint, 0, len(bt.Orders)+2)
+	curve = append(curve, EquityPoint{Time: bt.StartTime, Equity: equity(accounts, prices, quoteAsset)})
+	for _, o := range bt.Orders {
+		if err := applyOrder(accounts, bt.Fees, o, false); err != nil {
+			return nil, err
+		}
+
+		if base, ok := baseAgainstQuote(o.Pair, quoteAsset); ok {
+			prices[base] = o.Price
+		}
+
+		t := bt.CurrentCandlestick.Time
+		if o.ExecutionTime != nil {
+			t = *o.ExecutionTime
+		}
+		curve = append(curve, EquityPoint{Time: t, Equity: equity(accounts, prices, quoteAsset)})
+	}
+
+	// Value the open positions at their end price
+	for asset, price := range endPrices {
+		if _, ok := prices[asset]; ok {
+			prices[asset] = price
+		}
+	}
+	curve = append(curve, EquityPoint{
+		Time:   bt.CurrentCandlestick.Time,
+		Equity: equity(accounts, prices, quoteAsset),
+	})
+
+	return curve, nil
+}
+
+// Position is an asset held in an account of the backtest, that can be
+// valued with its pair against the quote asset.
+type Position struct {
+	Exchange string `json:"exchange"`
+	Pair     string `json:"pair"`
+	Asset    string `json:"asset"`
+}
+
+// OpenPositions returns the assets held in the current accounts that have been
+// traded against the quote asset, sorted by asset and exchange. These are the
+// assets whose end price is needed to value the last point of the equity curve.
+func (bt Backtest) OpenPositions(quoteAsset string) []Position {
+	positions := make([]Position, 0)
+	for _, o := range bt.Orders {
+		base, ok := baseAgainstQuote(o.Pair, quoteAsset)
+		if !ok || bt.Accounts[o.Exchange].Balances[base] == 0 {
+			continue
+		}
+
+		p := Position{Exchange: o.Exchange, Pair: o.Pair, Asset: base}
+		if !slices.Contains(positions, p) {
+			positions = append(positions, p)
+		}
+	}
+
+	slices.SortFunc(positions, func(a, b Position) int {
+		return cmp.Or(strings.Compare(a.Asset, b.Asset), strings.Compare(a.Exchange, b.Exchange))
+	})
+	return positions
+}
+
+// AccountsPoint is the state of the accounts of a backtest at a given time.
+type AccountsPoint struct {
+	Time     time.Time                  `json:"time"`
+	Accounts map[string]account.Account `json:"accounts"`
+}
+
+// AccountsHistory returns the accounts of the backtest at the start and after
+// each order.
+func (bt Backtest) AccountsHistory() ([]AccountsPoint, error) {
+	accounts, err := bt.InitialAccounts()
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]AccountsPoint, 0, len(bt.Orders)+1)
+	history = append(history, AccountsPoint{Time: bt.StartTime, Accounts: copyAccounts(accounts)})
+	for _, o := range bt.Orders {
+		if err := applyOrder(accounts, bt.Fees, o, false); err != nil {
+			return nil, err
+		}
+
+		t := bt.CurrentCandlestick.Time
+		if o.ExecutionTime != nil {
+			t = *o.ExecutionTime
+		}
+		history = append(history, AccountsPoint{Time: t, Accounts: copyAccounts(accounts)})
+	}
+
+	return history, nil
+}
+
+// Stats returns the performance statistics of the backtest, valued in the
+// quote asset, with the open positions valued at their end price.
+func (bt Backtest) Stats(quoteAsset string, endPrices map[string]float64) (Stats, error) {
+	curve, err := bt.EquityCurve(quoteAsset, endPrices)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	return NewStats(quoteAsset, curve, len(bt.Orders)), nil
+}
+
+// NewStats computes the performance statistics from an equity curve.
+func NewStats(quoteAsset string, curve []EquityPoint, ordersCount int) Stats {
+	s := Stats{
+		QuoteAsset:  quoteAsset,
+		MaxDrawdown: MaxDrawdown(curve),
+		OrdersCount: ordersCount,
+	}
+	if len(curve) == 0 {
+		return s
+	}
+
+	s.InitialEquity = curve[0].Equity
+	s.FinalEquity = curve[len(curve)-1].Equity
+	if s.InitialEquity != 0 {
+		s.Return = (s.FinalEquity - s.InitialEquity) / s.InitialEquity
+	}
+
+	return s
+}
+
+// MaxDrawdown returns the maximum drawdown of an equity curve, relative to the
+// equity peak.
+func MaxDrawdown(curve []EquityPoint) float64 {
+	var peak, maxDrawdown float64
+	for _, p := range curve {
+		if p.Equity > peak {
+			peak = p.Equity
+		}
+
+		if peak > 0 {
+			if dd := (peak - p.Equity) / peak; dd > maxDrawdown {
+				maxDrawdown = dd
+			}
+		}
+	}
+	return maxDrawdown
+}
+
+// DrawdownPoint is the drawdown of a backtest at a given time, relative to the
+// equity peak.
+type DrawdownPoint struct {
+	Time     time.Time `json:"time"`
+	Drawdown float64   `json:"drawdown"`
+}
+
+// DrawdownCurve returns the drawdown at each point of an equity curve,
+// relative to the equity peak.
+func DrawdownCurve(curve []EquityPoint) []DrawdownPoint {
+	var peak float64
+	drawdowns := make([]DrawdownPoint, len(curve))
+	for i, p := range curve {
+		if p.Equity > peak {
+			peak = p.Equity
+		}
+
+		drawdowns[i] = DrawdownPoint{Time: p.Time}
+		if peak > 0 {
+			drawdowns[i].Drawdown = (peak - p.Equity) / peak
+		}
+	}
+	return drawdowns
+}
+
+func baseAgainstQuote(symbol, quoteAsset string) (string, bool) {
+	base, quote, err := pair.ParsePair(symbol)
+	if err != nil || quote != quoteAsset {
+		return "", false
+	}
+	return base, true
+}
+
+// applyOrder applies the order and its fee on the balances of the accounts,
+// without any check as it has already been executed. If revert is true, it is
+// undone.
+func applyOrder(accounts map[string]account.Account, fees map[string]Fee, o order.Order, revert bool) error {
+	base, quote, err := pair.ParsePair(o.Pair)
+	if err != nil {
+		return fmt.Errorf("error when parsing order pair symbol: %w", err)
+	}
+
+	a, ok := accounts[o.Exchange]
+	if !ok {
+		return fmt.Errorf("error with orders exchange %q: %w", o.Exchange, ErrInvalidExchange)
+	} else if a.Balances == nil {
+		a.Balances = make(map[string]float64)
+		accounts[o.Exchange] = a
+	}
+
+	quantity := o.Quantity
+	if (o.Side == order.SideIsSell) != revert {
+		quantity = -quantity
+	}
+	a.Balances[base] += quantity
+	a.Balances[quote] -= o.Price * quantity
+
+	if fee, ok := exchangeFee(fees, o.Exchange); ok {
+		amount := fee.Amount(o, o.Price)
+		if revert {
+			amount = -amount
+		}
+		a.Balances[quote] -= amount
+	}
+
+	return nil
+}
+
+func equity(accounts map[string]account.Account, prices map[string]float64, quoteAsset string) float64 {
+	var total float64
+	for _, a := range accounts {
+		for asset, balance := range a.Balances {
+			if asset == quoteAsset {
+				total += balance
+			} else {
+				total += balance * prices[asset]
+			}
+		}
+	}
+	return total
+}
@@ -0,0 +1,156 @@
+//go:build unit
+// +build unit
+
+package backtest
+
+import (
+	"time"
+
+	"github.com/cryptellation/runtime/account"
+	"github.com/cryptellation/runtime/order"
+)
+
+func (suite *BacktestSuite) newStatsBacktest() Backtest {
+	buyTime, sellTime := time.Unix(60, 0).UTC(), time.Unix(120, 0).UTC()
+	return Backtest{
+		StartTime: time.Unix(0, 0).UTC(),
+		EndTime:   time.Unix(600, 0).UTC(),
+		CurrentCandlestick: CurrentCandlestick{
+			Time: time.Unix(600, 0).UTC(),
+		},
+		Accounts: map[string]account.Account{
+			"exchange": {Balances: map[string]float64{"USDT": 850, "BTC": 1}},
+		},
+		Orders: []order.Order{
+			{
+				ExecutionTime: &buyTime, Exchange: "exchange", Pair: "BTC-USDT",
+				Side: order.SideIsBuy, Quantity: 2, Price: 100,
+			},
+			{
+				ExecutionTime: &sellTime, Exchange: "exchange", Pair: "BTC-USDT",
+				Side: order.SideIsSell, Quantity: 1, Price: 50,
+			},
+		},
+	}
+}
+
+func (suite *BacktestSuite) TestInitialAccounts() {
+	bt := suite.newStatsBacktest()
+
+	accounts, err := bt.InitialAccounts()
+	suite.Require().NoError(err)
+	suite.Require().Equal(float64(1000), accounts["exchange"].Balances["USDT"])
+	suite.Require().Equal(float64(0), accounts["exchange"].Balances["BTC"])
+
+	// Backtest accounts should not be modified
+	suite.Require().Equal(float64(850), bt.Accounts["exchange"].Balances["USDT"])
+}
+
+func (suite *BacktestSuite) TestEquityCurve() {
+	bt := suite.newStatsBacktest()
+
+	curve, err := bt.EquityCurve("USDT", nil)
+	suite.Require().NoError(err)
+	suite.Require().Equal([]EquityPoint{
+		{Time: time.Unix(0, 0).UTC(), Equity: 1000},
+		{Time: time.Unix(60, 0).UTC(), Equity: 1000},
+		{Time: time.Unix(120, 0).UTC(), Equity: 900},
+		{Time: time.Unix(600, 0).UTC(), Equity: 900},
+	}, curve)
+
+	// The open position is valued at its end price, but not the unknown assets
+	curve, err = bt.EquityCurve("USDT", map[string]float64{"BTC": 200, "ETH": 10})
+	suite.Require().NoError(err)
+	suite.Require().Equal(EquityPoint{Time: time.Unix(600, 0).UTC(), Equity: 1050}, curve[3])
+	suite.Require().Equal(float64(900), curve[2].Equity)
+
+	_, err = bt.EquityCurve("", nil)
+	suite.Require().ErrorIs(err, ErrInvalidQuoteAsset)
+}
+
+func (suite *BacktestSuite) TestAccountsHistory() {
+	bt := suite.newStatsBacktest()
+
+	history, err := bt.AccountsHistory()
+	suite.Require().NoError(err)
+	suite.Require().Equal([]AccountsPoint{
+		{Time: time.Unix(0, 0).UTC(), Accounts: map[string]account.Account{
+			"exchange": {Balances: map[string]float64{"USDT": 1000, "BTC": 0}},
+		}},
+		{Time: time.Unix(60, 0).UTC(), Accounts: map[string]account.Account{
+			"exchange": {Balances: map[string]float64{"USDT": 800, "BTC": 2}},
+		}},
+		{Time: time.Unix(120, 0).UTC(), Accounts: map[string]account.Account{
+			"exchange": {Balances: map[string]float64{"USDT": 850, "BTC": 1}},
+		}},
+	}, history)
+}
+
+func (suite *BacktestSuite) TestDrawdownCurve() {
+	curve := []EquityPoint{
+		{Time: time.Unix(0, 0), Equity: 100},
+		{Time: time.Unix(60, 0), Equity: 80},
+		{Time: time.Unix(120, 0), Equity: 120},
+		{Time: time.Unix(180, 0), Equity: 90},
+	}
+
+	drawdowns := DrawdownCurve(curve)
+	suite.Require().Len(drawdowns, 4)
+	suite.Require().Equal(time.Unix(60, 0), drawdowns[1].Time)
+	suite.Require().InDelta(0, drawdowns[0].Drawdown, 1e-9)
+	suite.Require().InDelta(0.2, drawdowns[1].Drawdown, 1e-9)
+	suite.Require().InDelta(0, drawdowns[2].Drawdown, 1e-9)
+	suite.Require().InDelta(0.25, drawdowns[3].Drawdown, 1e-9)
+	suite.Require().InDelta(MaxDrawdown(curve), drawdowns[3].Drawdown, 1e-9)
+}
+
+func (suite *BacktestSuite) TestStats() {
+	bt := suite.newStatsBacktest()
+
+	s, err := bt.Stats("USDT", nil)
+	suite.Require().NoError(err)
+	suite.Require().Equal(Stats{
+		QuoteAsset:    "USDT",
+		InitialEquity: 1000,
+		FinalEquity:   900,
+		Return:        -0.1,
+		MaxDrawdown:   0.1,
+		OrdersCount:   2,
+	}, s)
+
+	suite.Require().Equal(-0.1, MetricReturn.Value(s))
+	suite.Require().Equal(float64(900), MetricFinalEquity.Value(s))
+	suite.Require().Equal(0.1, MetricMaxDrawdown.Value(s))
+	suite.Require().False(MetricMaxDrawdown.HigherIsBetter())
+	suite.Require().ErrorIs(Metric("unknown").Validate(), ErrInvalidMetric)
+}
+
+func (suite *BacktestSuite) TestStatsWithEndPrices() {
+	bt := suite.newStatsBacktest()
+
+	s, err := bt.Stats("USDT", map[string]float64{"BTC": 25})
+	suite.Require().NoError(err)
+	suite.Require().Equal(float64(875), s.FinalEquity)
+	suite.Require().InDelta(-0.125, s.Return, 1e-9)
+	suite.Require().InDelta(0.125, s.MaxDrawdown, 1e-9)
+}
+
+func (suite *BacktestSuite) TestOpenPositions() {
+	bt := suite.newStatsBacktest()
+	bt.Accounts["other"] = account.Account{Balances: map[string]float64{"ETH": 3, "USDT": 10}}
+	bt.Orders = append(bt.Orders,
+		order.Order{Exchange: "other", Pair: "ETH-USDT", Side: order.SideIsBuy, Quantity: 3, Price: 10},
+		order.Order{Exchange: "exchange", Pair: "ETH-BTC", Side: order.SideIsBuy, Quantity: 1, Price: 1})
+
+	suite.Require().Equal([]Position{
+		{Exchange: "exchange", Pair: "BTC-USDT", Asset: "BTC"},
+		{Exchange: "other", Pair: "ETH-USDT", Asset: "ETH"},
+	}, bt.OpenPositions("USDT"))
+
+	// Nothing is held once the position is closed
+	bt.Accounts["exchange"].Balances["BTC"] = 0
+	suite.Require().Equal([]Position{
+		{Exchange: "other", Pair: "ETH-USDT", Asset: "ETH"},
+	}, bt.OpenPositions("USDT"))
+	suite.Require().Empty(bt.OpenPositions(""))
+}
@@ -0,0 +1,43 @@
+package backtest
+
+import "errors"
+
+var (
+	// ErrInvalidStatus is returned when the status is invalid.
+	ErrInvalidStatus = errors.New("invalid status")
+	// ErrBacktestRunning is returned when an operation is not allowed on a
+	// running backtest.
+	ErrBacktestRunning = errors.New("backtest is running")
+)
+
+// Status is the status of the backtest.
+type Status string
+
+const (
+	// StatusCreated is the status of a backtest that has not been run yet.
+	StatusCreated Status = "created"
+	// StatusRunning is the status of a backtest being run.
+	StatusRunning Status = "running"
+	// StatusFinished is the status of a backtest that has been run until its end.
+	StatusFinished Status = "finished"
+	// StatusAborted is the status of a backtest whose run has been aborted by a
+	// callback failure.
+	StatusAborted Status = "aborted"
+	// StatusFailed is the status of a backtest whose run failed.
+	StatusFailed Status = "failed"
+)
+
+// Validate will validate the status.
+func (s Status) Validate() error {
+	switch s {
+	case StatusCreated, StatusRunning, StatusFinished, StatusAborted, StatusFailed:
+		return nil
+	default:
+		return ErrInvalidStatus
+	}
+}
+
+// String will return the string representation of the status.
+func (s Status) String() string {
+	return string(s)
+}
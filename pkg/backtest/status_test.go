@@ -0,0 +1,11 @@
+//go:build unit
+// +build unit
+
+package backtest
+
+func (suite *BacktestSuite) TestStatusValidate() {
+	for _, s := range []Status{StatusCreated, StatusRunning, StatusFinished, StatusAborted, StatusFailed} {
+		suite.Require().NoError(s.Validate(), s)
+	}
+	suite.Require().ErrorIs(Status("unknown").Validate(), ErrInvalidStatus)
+}
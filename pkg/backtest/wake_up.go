@@ -0,0 +1,80 @@
+package backtest
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cryptellation/ticks/pkg/tick"
+)
+
+var (
+	// ErrInvalidWakeUp is returned when the wake up has no condition.
+	ErrInvalidWakeUp = errors.New("invalid wake up")
+)
+
+// PriceCross is a price level that wakes the strategy up when crossed by the
+// price of a pair.
+type PriceCross struct {
+	Exchange string  `json:"exchange"`
+	Pair     string  `json:"pair"`
+	Price    float64 `json:"price"`
+}
+
+// WakeUp are the conditions requested by a strategy to be woken up.
+// The strategy is woken up as soon as one of the conditions is reached.
+type WakeUp struct {
+	// Time wakes the strategy up when the backtest reaches it.
+	Time *time.Time `json:"time,omitempty"`
+	// PriceCrosses wake the strategy up when one of the price levels is crossed.
+	PriceCrosses []PriceCross `json:"price_crosses,omitempty"`
+}
+
+// Validate validates the wake up.
+func (w WakeUp) Validate() error {
+	if w.Time == nil && len(w.PriceCrosses) == 0 {
+		return ErrInvalidWakeUp
+	}
+	return nil
+}
+
+// IsReached returns true if one of the conditions is reached at the given time
+// with the given prices, compared to the previous known prices.
+func (w WakeUp) IsReached(
+	now time.Time,
+	prices []tick.Tick,
+	previous map[tick.Subscription]tick.Tick,
+) bool {
+	if w.Time != nil && !now.Before(*w.Time) {
+		return true
+	}
+
+	for _, c := range w.PriceCrosses {
+		for _, p := range prices {
+			if p.Exchange != c.Exchange || p.Pair != c.Pair {
+				continue
+			}
+
+			prev, ok := previous[tick.Subscription{Exchange: p.Exchange, Pair: p.Pair}]
+			if !ok {
+				continue
+			}
+
+			if (prev.Price < c.Price && p.Price >= c.Price) || (prev.Price > c.Price && p.Price <= c.Price) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// SetWakeUp sets the wake up conditions of the backtest strategy. Until one of
+// them is reached, the backtest runs without calling the strategy.
+func (bt *Backtest) SetWakeUp(w WakeUp) error {
+	if err := w.Validate(); err != nil {
+		return err
+	}
+
+	bt.WakeUp = &w
+	return nil
+}
@@ -0,0 +1,56 @@
+//go:build unit
+// +build unit
+
+package backtest
+
+import (
+	"time"
+
+	"github.com/cryptellation/ticks/pkg/tick"
+)
+
+func (suite *BacktestSuite) TestSetWakeUpWithoutCondition() {
+	bt := Backtest{}
+	suite.Require().ErrorIs(bt.SetWakeUp(WakeUp{}), ErrInvalidWakeUp)
+	suite.Require().Nil(bt.WakeUp)
+}
+
+func (suite *BacktestSuite) TestWakeUpIsReachedOnTime() {
+	t := time.Unix(120, 0).UTC()
+	w := WakeUp{Time: &t}
+
+	suite.Require().False(w.IsReached(time.Unix(60, 0).UTC(), nil, nil))
+	suite.Require().True(w.IsReached(time.Unix(120, 0).UTC(), nil, nil))
+	suite.Require().True(w.IsReached(time.Unix(180, 0).UTC(), nil, nil))
+}
+
+func (suite *BacktestSuite) TestWakeUpIsReachedOnPriceCross() {
+	w := WakeUp{
+		PriceCrosses: []PriceCross{
+			{Exchange: "exchange", Pair: "BTC-USDT", Price: 100},
+		},
+	}
+	sub := tick.Subscription{Exchange: "exchange", Pair: "BTC-USDT"}
+	now := time.Unix(60, 0).UTC()
+
+	// No previous price
+	prices := []tick.Tick{{Exchange: "exchange", Pair: "BTC-USDT", Price: 110}}
+	suite.Require().False(w.IsReached(now, prices, nil))
+
+	// Not crossed
+	previous := map[tick.Subscription]tick.Tick{sub: {Exchange: "exchange", Pair: "BTC-USDT", Price: 105}}
+	suite.Require().False(w.IsReached(now, prices, previous))
+
+	// Crossed upward
+	previous[sub] = tick.Tick{Exchange: "exchange", Pair: "BTC-USDT", Price: 90}
+	suite.Require().True(w.IsReached(now, prices, previous))
+
+	// Crossed downward
+	previous[sub] = tick.Tick{Exchange: "exchange", Pair: "BTC-USDT", Price: 120}
+	prices[0].Price = 100
+	suite.Require().True(w.IsReached(now, prices, previous))
+
+	// Other pair
+	prices[0].Pair = "ETH-USDT"
+	suite.Require().False(w.IsReached(now, prices, previous))
+}
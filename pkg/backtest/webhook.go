@@ -0,0 +1,149 @@
+package backtest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// WebhookSignatureHeader is the HTTP header containing the signature of
+	// the webhook payload, as "sha256=<hex encoded HMAC>".
+	WebhookSignatureHeader = "X-Cryptellation-Signature"
+	// WebhookTimestampHeader is the HTTP header containing the Unix time at
+	// which the webhook payload has been signed.
+	WebhookTimestampHeader = "X-Cryptellation-Timestamp"
+)
+
+var (
+	// ErrInvalidWebhook is returned when a webhook is invalid.
+	ErrInvalidWebhook = errors.New("invalid webhook")
+	// ErrInvalidWebhookSignature is returned when the signature of a webhook
+	// payload does not match.
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+)
+
+// Webhook is an HTTP endpoint notified at the end of the backtest runs.
+type Webhook struct {
+	URL string `json:"url"`
+	// Secret is the key used to sign the payloads with HMAC-SHA256. The
+	// payloads are not signed if it is empty. It is never serialized with the
+	// backtest, so that it is not sent back to the clients: it is only set on
+	// creation, with a WebhookDefinition, and read from the database.
+	Secret string `json:"-"`
+}
+
+// WebhookDefinition is a webhook as defined on the backtest creation, with
+// its secret.
+type WebhookDefinition struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret,omitempty"`
+}
+
+// Webhook returns the webhook of the definition.
+func (d WebhookDefinition) Webhook() Webhook {
+	return Webhook{URL: d.URL, Secret: d.Secret}
+}
+
+// NewWebhooks returns the webhooks of the definitions.
+func NewWebhooks(definitions []WebhookDefinition) []Webhook {
+	if len(definitions) == 0 {
+		return nil
+	}
+
+	webhooks := make([]Webhook, len(definitions))
+	for i, d := range definitions {
+		webhooks[i] = d.Webhook()
+	}
+	return webhooks
+}
+
+// Validate validates the webhook.
+func (w Webhook) Validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidWebhook, err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: %q is not an absolute HTTP URL", ErrInvalidWebhook, w.URL)
+	}
+
+	return nil
+}
+
+// WebhookEvent is the event notified to the webhooks.
+type WebhookEvent string
+
+const (
+	// WebhookEventFinished is sent when a backtest run reached its end.
+	WebhookEventFinished WebhookEvent = "backtest.finished"
+	// WebhookEventFailed is sent when a backtest run failed.
+	WebhookEventFailed WebhookEvent = "backtest.failed"
+	// WebhookEventAborted is sent when a backtest run has been aborted by a
+	// callback failure.
+	WebhookEventAborted WebhookEvent = "backtest.aborted"
+)
+
+// String will return the string representation of the webhook event.
+func (e WebhookEvent) String() string {
+	return string(e)
+}
+
+// WebhookPayload is the JSON payload sent to the webhooks.
+type WebhookPayload struct {
+	Event WebhookEvent `json:"event"`
+	// Time is the time at which the event happened.
+	Time     time.Time `json:"time"`
+	Backtest Summary   `json:"backtest"`
+	// Error is the reason of a failed run.
+	Error string `json:"error,omitempty"`
+}
+
+// SignWebhookPayload returns the signature of a webhook payload, sent in the
+// WebhookSignatureHeader header. The timestamp is part of the signed content
+// to let the receivers reject replayed payloads.
+func SignWebhookPayload(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature checks the signature of a webhook payload, from the
+// values of the WebhookSignatureHeader and WebhookTimestampHeader headers.
+func VerifyWebhookSignature(secret, signature, timestamp string, body []byte) error {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid timestamp %q", ErrInvalidWebhookSignature, timestamp)
+	}
+
+	expected := SignWebhookPayload(secret, time.Unix(unix, 0), body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidWebhookSignature
+	}
+
+	return nil
+}
+
+// WithoutSecrets returns the backtest with the secrets of its webhooks removed,
+// in order to send it to clients.
+func (bt Backtest) WithoutSecrets() Backtest {
+	if len(bt.Webhooks) == 0 {
+		return bt
+	}
+
+	webhooks := make([]Webhook, len(bt.Webhooks))
+	for i, w := range bt.Webhooks {
+		webhooks[i] = Webhook{URL: w.URL}
+	}
+	bt.Webhooks = webhooks
+
+	return bt
+}
@@ -0,0 +1,74 @@
+//go:build unit
+// +build unit
+
+package backtest
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+func (suite *BacktestSuite) TestWebhookValidate() {
+	cases := []struct {
+		URL   string
+		Valid bool
+	}{
+		{URL: "https://example.com/hook", Valid: true},
+		{URL: "http://localhost:8080", Valid: true},
+		{URL: "ftp://example.com"},
+		{URL: "/hook"},
+		{URL: "://"},
+		{URL: ""},
+	}
+
+	for _, c := range cases {
+		err := Webhook{URL: c.URL}.Validate()
+		if c.Valid {
+			suite.Require().NoError(err, c.URL)
+		} else {
+			suite.Require().ErrorIs(err, ErrInvalidWebhook, c.URL)
+		}
+	}
+}
+
+func (suite *BacktestSuite) TestWebhookSignature() {
+	now, body := time.Unix(1700000000, 0), []byte(`{"event":"backtest.finished"}`)
+	signature := SignWebhookPayload("secret", now, body)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	suite.Require().NoError(VerifyWebhookSignature("secret", signature, timestamp, body))
+	suite.Require().ErrorIs(VerifyWebhookSignature("other", signature, timestamp, body),
+		ErrInvalidWebhookSignature)
+	suite.Require().ErrorIs(VerifyWebhookSignature("secret", signature, "1700000001", body),
+		ErrInvalidWebhookSignature)
+	suite.Require().ErrorIs(VerifyWebhookSignature("secret", signature, "now", body),
+		ErrInvalidWebhookSignature)
+	suite.Require().ErrorIs(VerifyWebhookSignature("secret", signature, timestamp, []byte("{}")),
+		ErrInvalidWebhookSignature)
+}
+
+func (suite *BacktestSuite) TestWithoutSecrets() {
+	bt := Backtest{Webhooks: []Webhook{{URL: "https://example.com", Secret: "secret"}}}
+
+	suite.Require().Equal([]Webhook{{URL: "https://example.com"}}, bt.WithoutSecrets().Webhooks)
+	suite.Require().Equal("secret", bt.Webhooks[0].Secret)
+}
+
+func (suite *BacktestSuite) TestWebhookSecretNotSerialized() {
+	bt := Backtest{Webhooks: NewWebhooks([]WebhookDefinition{{URL: "https://example.com", Secret: "secret"}})}
+	suite.Require().Equal("secret", bt.Webhooks[0].Secret)
+
+	b, err := json.Marshal(bt)
+	suite.Require().NoError(err)
+	suite.Require().NotContains(string(b), "secret")
+
+	var decoded Backtest
+	suite.Require().NoError(json.Unmarshal(b, &decoded))
+	suite.Require().Equal([]Webhook{{URL: "https://example.com"}}, decoded.Webhooks)
+
+	// The definitions keep it, to create the backtests
+	var def WebhookDefinition
+	suite.Require().NoError(json.Unmarshal([]byte(`{"url":"https://example.com","secret":"secret"}`), &def))
+	suite.Require().Equal(Webhook{URL: "https://example.com", Secret: "secret"}, def.Webhook())
+}
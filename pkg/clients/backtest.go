@@ -2,21 +2,252 @@ package clients
 
 import (
 	"context"
+	"time"
 
 	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/backtests/pkg/export"
+	"github.com/cryptellation/backtests/pkg/montecarlo"
+	"github.com/cryptellation/backtests/pkg/report"
+	"github.com/cryptellation/runtime"
+	"github.com/cryptellation/runtime/account"
+	"github.com/cryptellation/runtime/order"
 	"github.com/google/uuid"
 )
 
+// BacktestRun is a run of a backtest on the Cryptellation API.
+type BacktestRun struct {
+	BacktestID uuid.UUID
+	run        WorkflowRun
+	raw        RawClient
+}
+
+// WorkflowID returns the ID of the workflow running the backtest.
+func (r BacktestRun) WorkflowID() string {
+	return r.run.GetID()
+}
+
+// RunID returns the ID of the workflow run.
+func (r BacktestRun) RunID() string {
+	return r.run.GetRunID()
+}
+
+// Wait waits for the end of the backtest run.
+func (r BacktestRun) Wait(ctx context.Context) error {
+	var res api.RunBacktestWorkflowResults
+	return DecodeError(r.run.Get(ctx, &res))
+}
+
+// Events streams the events of the backtest run, starting with the ones kept
+// by the run. The events channel is closed when the run is over, the context
+// is done or an error happens; the error is then sent on the errors channel.
+// As only the last events are kept by the run, a client too slow to read them
+// can miss some: this is visible with gaps in the sequences.
+func (r BacktestRun) Events(ctx context.Context) (<-chan backtest.Event, <-chan error) {
+	events, errs := make(chan backtest.Event), make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(events)
+
+		for sequence := int64(0); ; {
+			res, err := r.raw.WaitBacktestEvents(ctx, api.BacktestEventsUpdateParams{
+				BacktestID:    r.BacktestID,
+				AfterSequence: sequence,
+			})
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			for _, evt := range res.Events {
+				select {
+				case events <- evt:
+					sequence = evt.Sequence
+				case <-ctx.Done():
+					errs <- ctx.Err()
+					return
+				}
+			}
+
+			if res.Finished {
+				return
+			}
+		}
+	}()
+
+	return events, errs
+}
+
 // Backtest is a local representation of a backtest running on the Cryptellation API.
 type Backtest struct {
-	ID     uuid.UUID
-	client client
+	ID  uuid.UUID
+	raw RawClient
 }
 
-// Run starts the backtest on Cryptellation API.
+// NewBacktestHandle creates the handle of an existing backtest, sending its
+// requests through the raw client (e.g. a MockRawClient in tests).
+func NewBacktestHandle(id uuid.UUID, raw RawClient) Backtest {
+	return Backtest{
+		ID:  id,
+		raw: raw,
+	}
+}
+
+// Run runs the backtest on Cryptellation API and waits for its end.
 func (bt *Backtest) Run(ctx context.Context) error {
-	_, err := bt.client.raw.RunBacktest(ctx, api.RunBacktestWorkflowParams{
+	run, err := bt.Start(ctx)
+	if err != nil {
+		return err
+	}
+	return run.Wait(ctx)
+}
+
+// Start starts the backtest on Cryptellation API without waiting for its end.
+// It returns ErrBacktestAlreadyRunning if the backtest is already running.
+func (bt *Backtest) Start(ctx context.Context) (BacktestRun, error) {
+	run, err := bt.raw.StartBacktest(ctx, api.RunBacktestWorkflowParams{
 		BacktestID: bt.ID,
 	})
+	if err != nil {
+		return BacktestRun{}, err
+	}
+
+	return BacktestRun{
+		BacktestID: bt.ID,
+		run:        run,
+		raw:        bt.raw,
+	}, nil
+}
+
+// Attach gets the last run of the backtest, in order to wait for it.
+// It returns ErrBacktestRunNotFound if the backtest has never been started.
+func (bt *Backtest) Attach(ctx context.Context) (BacktestRun, error) {
+	run, err := bt.raw.AttachBacktest(ctx, api.RunBacktestWorkflowParams{
+		BacktestID: bt.ID,
+	})
+	if err != nil {
+		return BacktestRun{}, err
+	}
+
+	return BacktestRun{
+		BacktestID: bt.ID,
+		run:        run,
+		raw:        bt.raw,
+	}, nil
+}
+
+// Get gets the backtest.
+func (bt *Backtest) Get(ctx context.Context) (backtest.Backtest, error) {
+	res, err := bt.raw.GetBacktest(ctx, api.GetBacktestWorkflowParams{
+		BacktestID: bt.ID,
+	})
+	return res.Backtest, err
+}
+
+// Accounts gets the accounts of the backtest.
+func (bt *Backtest) Accounts(ctx context.Context) (map[string]account.Account, error) {
+	res, err := bt.raw.GetBacktestAccounts(ctx, api.GetBacktestAccountsWorkflowParams{
+		BacktestID: bt.ID,
+	})
+	return res.Accounts, err
+}
+
+// Orders gets the orders of the backtest.
+func (bt *Backtest) Orders(ctx context.Context) ([]order.Order, error) {
+	res, err := bt.raw.GetBacktestOrders(ctx, api.GetBacktestOrdersWorkflowParams{
+		BacktestID: bt.ID,
+	})
+	return res.Orders, err
+}
+
+// CreateOrder creates an order on the backtest.
+func (bt *Backtest) CreateOrder(ctx context.Context, o order.Order) error {
+	_, err := bt.raw.CreateBacktestOrder(ctx, api.CreateBacktestOrderWorkflowParams{
+		BacktestID: bt.ID,
+		Order:      o,
+	})
+	return err
+}
+
+// SubscribeToPrice subscribes the backtest to the prices of a pair on an exchange.
+func (bt *Backtest) SubscribeToPrice(ctx context.Context, exchange, pair string) error {
+	_, err := bt.raw.SubscribeToPrice(ctx, api.SubscribeToPriceWorkflowParams{
+		BacktestID: bt.ID,
+		Exchange:   exchange,
+		Pair:       pair,
+	})
 	return err
 }
+
+// UpdateMetadata updates the name, description and tags of the backtest. Nil
+// fields of the update are left unchanged.
+func (bt *Backtest) UpdateMetadata(ctx context.Context, update backtest.MetadataUpdate) error {
+	_, err := bt.raw.UpdateBacktestMetadata(ctx, api.UpdateBacktestMetadataWorkflowParams{
+		BacktestID: bt.ID,
+		Metadata:   update,
+	})
+	return err
+}
+
+// Delete deletes the backtest. It fails if the backtest is running.
+func (bt *Backtest) Delete(ctx context.Context) error {
+	_, err := bt.raw.DeleteBacktest(ctx, api.DeleteBacktestWorkflowParams{
+		BacktestID: bt.ID,
+	})
+	return err
+}
+
+// Fork creates a new backtest from the latest snapshot of this backtest at or
+// before the given time, with new callbacks.
+func (bt *Backtest) Fork(ctx context.Context, t time.Time, callbacks runtime.Callbacks) (Backtest, error) {
+	res, err := bt.raw.ForkBacktest(ctx, api.ForkBacktestWorkflowParams{
+		BacktestID: bt.ID,
+		Time:       t,
+		Callbacks:  callbacks,
+	})
+	if err != nil {
+		return Backtest{}, err
+	}
+
+	return NewBacktestHandle(res.ID, bt.raw), nil
+}
+
+// MonteCarlo resamples the trades of the backtest to analyze the robustness
+// of its results, valued in the quote asset.
+func (bt *Backtest) MonteCarlo(
+	ctx context.Context,
+	quoteAsset string,
+	params montecarlo.Parameters,
+) (montecarlo.Report, error) {
+	res, err := bt.raw.MonteCarlo(ctx, api.MonteCarloWorkflowParams{
+		BacktestID: bt.ID,
+		QuoteAsset: quoteAsset,
+		Parameters: params,
+	})
+	return res.Report, err
+}
+
+// Export gets the rows of the datasets exported from the backtest, or of all
+// of them if none is given. The equity is valued in the quote asset.
+func (bt *Backtest) Export(
+	ctx context.Context,
+	quoteAsset string,
+	datasets ...export.Dataset,
+) (api.ExportBacktestWorkflowResults, error) {
+	return bt.raw.ExportBacktest(ctx, api.ExportBacktestWorkflowParams{
+		BacktestID: bt.ID,
+		Datasets:   datasets,
+		QuoteAsset: quoteAsset,
+	})
+}
+
+// Report gets the content of the report of the backtest, valued in the quote
+// asset. It can be rendered as HTML with report.Render.
+func (bt *Backtest) Report(ctx context.Context, quoteAsset string) (report.Report, error) {
+	res, err := bt.raw.GetBacktestReport(ctx, api.GetBacktestReportWorkflowParams{
+		BacktestID: bt.ID,
+		QuoteAsset: quoteAsset,
+	})
+	return res.Report, err
+}
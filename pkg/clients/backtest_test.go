@@ -0,0 +1,170 @@
+//go:build unit
+// +build unit
+
+package clients
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/runtime"
+	"github.com/cryptellation/runtime/account"
+	"github.com/cryptellation/runtime/order"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/mock/gomock"
+)
+
+func TestBacktestSuite(t *testing.T) {
+	suite.Run(t, new(BacktestSuite))
+}
+
+type BacktestSuite struct {
+	suite.Suite
+	raw *MockRawClient
+	bt  Backtest
+}
+
+func (suite *BacktestSuite) SetupTest() {
+	suite.raw = NewMockRawClient(gomock.NewController(suite.T()))
+	suite.bt = NewBacktestHandle(uuid.New(), suite.raw)
+}
+
+func (suite *BacktestSuite) TestAccounts() {
+	accounts := map[string]account.Account{
+		"exchange": {Balances: map[string]float64{"USDT": 1000}},
+	}
+	suite.raw.EXPECT().
+		GetBacktestAccounts(gomock.Any(), api.GetBacktestAccountsWorkflowParams{BacktestID: suite.bt.ID}).
+		Return(api.GetBacktestAccountsWorkflowResults{Accounts: accounts}, nil)
+
+	res, err := suite.bt.Accounts(context.Background())
+	suite.Require().NoError(err)
+	suite.Require().Equal(accounts, res)
+}
+
+func (suite *BacktestSuite) TestCreateOrder() {
+	o := order.Order{Exchange: "exchange", Pair: "BTC-USDT", Side: order.SideIsBuy, Quantity: 1}
+	suite.raw.EXPECT().
+		CreateBacktestOrder(gomock.Any(), api.CreateBacktestOrderWorkflowParams{BacktestID: suite.bt.ID, Order: o}).
+		Return(api.CreateBacktestOrderWorkflowResults{}, nil)
+
+	suite.Require().NoError(suite.bt.CreateOrder(context.Background(), o))
+}
+
+func (suite *BacktestSuite) TestDelete() {
+	suite.raw.EXPECT().
+		DeleteBacktest(gomock.Any(), api.DeleteBacktestWorkflowParams{BacktestID: suite.bt.ID}).
+		Return(api.DeleteBacktestWorkflowResults{}, nil)
+
+	suite.Require().NoError(suite.bt.Delete(context.Background()))
+}
+
+func (suite *BacktestSuite) TestUpdateMetadata() {
+	tags := []string{"sma", "btc"}
+	update := backtest.MetadataUpdate{Tags: &tags}
+	suite.raw.EXPECT().
+		UpdateBacktestMetadata(gomock.Any(), api.UpdateBacktestMetadataWorkflowParams{
+			BacktestID: suite.bt.ID,
+			Metadata:   update,
+		}).
+		Return(api.UpdateBacktestMetadataWorkflowResults{}, nil)
+
+	suite.Require().NoError(suite.bt.UpdateMetadata(context.Background(), update))
+}
+
+func (suite *BacktestSuite) TestStartAndWait() {
+	params := api.RunBacktestWorkflowParams{BacktestID: suite.bt.ID}
+	run := NewMockWorkflowRun(gomock.NewController(suite.T()))
+	run.EXPECT().GetID().Return(api.RunBacktestWorkflowID(suite.bt.ID))
+	run.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil)
+	suite.raw.EXPECT().StartBacktest(gomock.Any(), params).Return(run, nil)
+
+	r, err := suite.bt.Start(context.Background())
+	suite.Require().NoError(err)
+	suite.Require().Equal("backtest-"+suite.bt.ID.String()+"-run", r.WorkflowID())
+	suite.Require().NoError(r.Wait(context.Background()))
+}
+
+func (suite *BacktestSuite) TestStartAlreadyRunning() {
+	params := api.RunBacktestWorkflowParams{BacktestID: suite.bt.ID}
+	suite.raw.EXPECT().StartBacktest(gomock.Any(), params).Return(nil, ErrBacktestAlreadyRunning)
+
+	_, err := suite.bt.Start(context.Background())
+	suite.Require().ErrorIs(err, ErrBacktestAlreadyRunning)
+}
+
+func (suite *BacktestSuite) TestRunEvents() {
+	run := BacktestRun{BacktestID: suite.bt.ID, raw: suite.raw}
+	gomock.InOrder(
+		suite.raw.EXPECT().
+			WaitBacktestEvents(gomock.Any(), api.BacktestEventsUpdateParams{BacktestID: suite.bt.ID}).
+			Return(api.BacktestEventsUpdateResults{Events: []backtest.Event{
+				{Sequence: 1, Type: backtest.EventTypeStatusChanged, Status: backtest.StatusRunning},
+				{Sequence: 2, Type: backtest.EventTypeStepAdvanced},
+			}}, nil),
+		suite.raw.EXPECT().
+			WaitBacktestEvents(gomock.Any(), api.BacktestEventsUpdateParams{BacktestID: suite.bt.ID, AfterSequence: 2}).
+			Return(api.BacktestEventsUpdateResults{}, nil),
+		suite.raw.EXPECT().
+			WaitBacktestEvents(gomock.Any(), api.BacktestEventsUpdateParams{BacktestID: suite.bt.ID, AfterSequence: 2}).
+			Return(api.BacktestEventsUpdateResults{Events: []backtest.Event{
+				{Sequence: 3, Type: backtest.EventTypeStatusChanged, Status: backtest.StatusFinished},
+			}, Finished: true}, nil),
+	)
+
+	events, errs := run.Events(context.Background())
+	sequences := make([]int64, 0)
+	for evt := range events {
+		sequences = append(sequences, evt.Sequence)
+	}
+	suite.Require().Equal([]int64{1, 2, 3}, sequences)
+	suite.Require().NoError(<-errs)
+}
+
+func (suite *BacktestSuite) TestRunEventsNotRunning() {
+	run := BacktestRun{BacktestID: suite.bt.ID, raw: suite.raw}
+	suite.raw.EXPECT().
+		WaitBacktestEvents(gomock.Any(), api.BacktestEventsUpdateParams{BacktestID: suite.bt.ID}).
+		Return(api.BacktestEventsUpdateResults{}, ErrBacktestNotRunning)
+
+	events, errs := run.Events(context.Background())
+	_, open := <-events
+	suite.Require().False(open)
+	suite.Require().ErrorIs(<-errs, ErrBacktestNotRunning)
+}
+
+func (suite *BacktestSuite) TestHandleFromMockClient() {
+	ctrl := gomock.NewController(suite.T())
+	c := NewMockClient(ctrl)
+	params := api.GetBacktestWorkflowParams{BacktestID: suite.bt.ID}
+	c.EXPECT().GetBacktest(gomock.Any(), params).Return(NewBacktestHandle(suite.bt.ID, suite.raw), nil)
+
+	bt, err := c.GetBacktest(context.Background(), params)
+	suite.Require().NoError(err)
+
+	// The handle sends its requests through the mocked raw client
+	suite.raw.EXPECT().
+		DeleteBacktest(gomock.Any(), api.DeleteBacktestWorkflowParams{BacktestID: suite.bt.ID}).
+		Return(api.DeleteBacktestWorkflowResults{}, nil)
+	suite.Require().NoError(bt.Delete(context.Background()))
+}
+
+func (suite *BacktestSuite) TestForkKeepsRawClient() {
+	forkID := uuid.New()
+	suite.raw.EXPECT().
+		ForkBacktest(gomock.Any(), gomock.Any()).
+		Return(api.ForkBacktestWorkflowResults{ID: forkID}, nil)
+
+	fork, err := suite.bt.Fork(context.Background(), time.Unix(0, 0), runtime.Callbacks{})
+	suite.Require().NoError(err)
+	suite.Require().Equal(forkID, fork.ID)
+
+	suite.raw.EXPECT().
+		DeleteBacktest(gomock.Any(), api.DeleteBacktestWorkflowParams{BacktestID: forkID}).
+		Return(api.DeleteBacktestWorkflowResults{}, nil)
+	suite.Require().NoError(fork.Delete(context.Background()))
+}
@@ -1,3 +1,6 @@
+// Generate code for mock
+//go:generate go run go.uber.org/mock/mockgen@v0.2.0 -source=client.go -destination=mock_client.gen.go -package clients
+
 package clients
 
 import (
@@ -5,7 +8,9 @@ import (
 
 	"github.com/cryptellation/backtests/api"
 	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/backtests/pkg/quota"
 	"github.com/cryptellation/runtime"
+	"github.com/google/uuid"
 	temporalclient "go.temporal.io/sdk/client"
 )
 
@@ -27,6 +32,39 @@ type Client interface {
 		ctx context.Context,
 		params api.ListBacktestsWorkflowParams,
 	) ([]Backtest, error)
+	// Attach gets the last run of a backtest, in order to wait for it.
+	Attach(
+		ctx context.Context,
+		backtestID uuid.UUID,
+	) (BacktestRun, error)
+	// DeleteBacktest deletes a backtest, unless it is running.
+	DeleteBacktest(
+		ctx context.Context,
+		backtestID uuid.UUID,
+	) error
+	// PurgeBacktests deletes the backtests matching the filters, except the
+	// running ones, and returns the count of deleted backtests.
+	PurgeBacktests(
+		ctx context.Context,
+		params api.PurgeBacktestsWorkflowParams,
+	) (int, error)
+	// RunParameterSweep runs a backtest for each combination of strategy
+	// parameters and returns them ranked on a metric.
+	RunParameterSweep(
+		ctx context.Context,
+		params api.RunParameterSweepWorkflowParams,
+	) (api.RunParameterSweepWorkflowResults, error)
+	// WalkForward optimizes the strategy parameters on rolling in-sample
+	// windows and applies the best ones on the out-of-sample windows.
+	WalkForward(
+		ctx context.Context,
+		params api.WalkForwardWorkflowParams,
+	) (api.WalkForwardWorkflowResults, error)
+	// QuotaUsage gets the current usage of the quota of the tenant of the
+	// context. The runs over the quota are queued until a slot is released, and
+	// fail with quota.ErrWaitTimeout only if they wait longer than the
+	// Limits.MaxWaitTime of the quota, when it is set.
+	QuotaUsage(ctx context.Context) (quota.Usage, error)
 	// Info calls the service info.
 	Info(ctx context.Context) (api.ServiceInfoResults, error)
 }
@@ -57,10 +95,7 @@ func (c client) NewBacktest(
 	})
 
 	// Return backtest
-	return Backtest{
-		ID:     res.ID,
-		client: c,
-	}, err
+	return NewBacktestHandle(res.ID, c.raw), err
 }
 
 func (c client) GetBacktest(
@@ -72,10 +107,7 @@ func (c client) GetBacktest(
 		return Backtest{}, err
 	}
 
-	return Backtest{
-		ID:     res.Backtest.ID,
-		client: c,
-	}, nil
+	return NewBacktestHandle(res.Backtest.ID, c.raw), nil
 }
 
 func (c client) ListBacktests(
@@ -87,30 +119,73 @@ func (c client) ListBacktests(
 		return nil, err
 	}
 
-	backtests := make([]Backtest, len(res.Backtests))
-	for i, bt := range res.Backtests {
-		backtests[i] = Backtest{
-			ID:     bt.ID,
-			client: c,
-		}
+	backtests := make([]Backtest, 0, len(res.Backtests)+len(res.Summaries))
+	for _, bt := range res.Backtests {
+		backtests = append(backtests, NewBacktestHandle(bt.ID, c.raw))
+	}
+	for _, s := range res.Summaries {
+		backtests = append(backtests, NewBacktestHandle(s.ID, c.raw))
 	}
 
 	return backtests, nil
 }
 
-// Info calls the service info.
-func (c client) Info(ctx context.Context) (res api.ServiceInfoResults, err error) {
-	workflowOptions := temporalclient.StartWorkflowOptions{
-		TaskQueue: api.WorkerTaskQueueName,
-	}
+// Attach gets the last run of a backtest, in order to wait for it.
+func (c client) Attach(
+	ctx context.Context,
+	backtestID uuid.UUID,
+) (BacktestRun, error) {
+	bt := NewBacktestHandle(backtestID, c.raw)
+	return bt.Attach(ctx)
+}
 
-	// Execute workflow
-	exec, err := c.temporal.ExecuteWorkflow(ctx, workflowOptions, api.ServiceInfoWorkflowName)
-	if err != nil {
-		return api.ServiceInfoResults{}, err
-	}
+// DeleteBacktest deletes a backtest, unless it is running.
+func (c client) DeleteBacktest(
+	ctx context.Context,
+	backtestID uuid.UUID,
+) error {
+	bt := NewBacktestHandle(backtestID, c.raw)
+	return bt.Delete(ctx)
+}
+
+// PurgeBacktests deletes the backtests matching the filters, except the
+// running ones, and returns the count of deleted backtests.
+func (c client) PurgeBacktests(
+	ctx context.Context,
+	params api.PurgeBacktestsWorkflowParams,
+) (int, error) {
+	res, err := c.raw.PurgeBacktests(ctx, params)
+	return res.Deleted, err
+}
+
+// RunParameterSweep runs a backtest for each combination of strategy
+// parameters and returns them ranked on a metric.
+func (c client) RunParameterSweep(
+	ctx context.Context,
+	params api.RunParameterSweepWorkflowParams,
+) (api.RunParameterSweepWorkflowResults, error) {
+	return c.raw.RunParameterSweep(ctx, params)
+}
 
-	// Get result and return
-	err = exec.Get(ctx, &res)
-	return res, err
+// WalkForward optimizes the strategy parameters on rolling in-sample
+// windows and applies the best ones on the out-of-sample windows.
+func (c client) WalkForward(
+	ctx context.Context,
+	params api.WalkForwardWorkflowParams,
+) (api.WalkForwardWorkflowResults, error) {
+	return c.raw.WalkForward(ctx, params)
+}
+
+// QuotaUsage gets the current usage of the quota of the tenant of the
+// context. The runs over the quota are queued until a slot is released, and
+// fail with quota.ErrWaitTimeout only if they wait longer than the
+// Limits.MaxWaitTime of the quota, when it is set.
+func (c client) QuotaUsage(ctx context.Context) (quota.Usage, error) {
+	res, err := c.raw.GetQuotaUsage(ctx, api.GetQuotaUsageWorkflowParams{})
+	return res.Usage, err
+}
+
+// Info calls the service info.
+func (c client) Info(ctx context.Context) (res api.ServiceInfoResults, err error) {
+	return c.raw.ServiceInfo(ctx)
 }
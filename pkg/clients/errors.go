@@ -0,0 +1,42 @@
+package clients
+
+import (
+	"errors"
+
+	"github.com/cryptellation/backtests/api"
+	"go.temporal.io/sdk/temporal"
+)
+
+// typedError is an error returned by a workflow, decoded from an application
+// error of one of the error types.
+type typedError struct {
+	err    error
+	domain error
+}
+
+func (e typedError) Error() string {
+	return e.err.Error()
+}
+
+func (e typedError) Unwrap() []error {
+	return []error{e.domain, e.err}
+}
+
+// DecodeError maps the application errors returned by the workflows back to
+// their domain errors (i.e. from pkg/backtest), so they can be checked with
+// errors.Is. The message and the original error are kept. Other errors are
+// returned unchanged.
+func DecodeError(err error) error {
+	var appErr *temporal.ApplicationError
+	if !errors.As(err, &appErr) {
+		return err
+	}
+
+	for _, t := range api.ErrorTypes {
+		if appErr.Type() == t.Name {
+			return typedError{err: err, domain: t.Err}
+		}
+	}
+
+	return err
+}
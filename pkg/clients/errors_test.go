@@ -0,0 +1,70 @@
+//go:build unit
+// +build unit
+
+package clients
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/temporal"
+)
+
+func TestErrorsSuite(t *testing.T) {
+	suite.Run(t, new(ErrorsSuite))
+}
+
+type ErrorsSuite struct {
+	suite.Suite
+}
+
+func (suite *ErrorsSuite) TestDomainErrorRoundTrip() {
+	err := fmt.Errorf("error with orders exchange %q: %w", "unknown", backtest.ErrInvalidExchange)
+
+	appErr := api.NewApplicationError(err, api.ErrorTypes)
+	suite.Require().False(errors.Is(appErr, backtest.ErrInvalidExchange))
+
+	var typed *temporal.ApplicationError
+	suite.Require().ErrorAs(appErr, &typed)
+	suite.Require().Equal("backtest.InvalidExchange", typed.Type())
+	suite.Require().True(typed.NonRetryable())
+	suite.Require().Equal(err.Error(), typed.Message())
+
+	decoded := DecodeError(appErr)
+	suite.Require().ErrorIs(decoded, backtest.ErrInvalidExchange)
+	suite.Require().ErrorAs(decoded, &typed)
+	suite.Require().Equal(appErr.Error(), decoded.Error())
+}
+
+func (suite *ErrorsSuite) TestNestedApplicationErrorKeepsType() {
+	activityErr := api.NewApplicationError(api.ErrNotFound, api.DBErrorTypes)
+	err := fmt.Errorf("read backtest from db: %w", activityErr)
+
+	decoded := DecodeError(api.NewApplicationError(err, api.ErrorTypes))
+	suite.Require().ErrorIs(decoded, api.ErrNotFound)
+	suite.Require().Contains(decoded.Error(), "read backtest from db")
+}
+
+func (suite *ErrorsSuite) TestUntypedErrors() {
+	suite.Require().NoError(api.NewApplicationError(nil, api.ErrorTypes))
+
+	err := errors.New("unexpected")
+	suite.Require().Equal(err, api.NewApplicationError(err, api.ErrorTypes))
+	suite.Require().Equal(err, DecodeError(err))
+
+	appErr := temporal.NewApplicationError("unexpected", "other.Type")
+	suite.Require().Equal(appErr, DecodeError(appErr))
+}
+
+func (suite *ErrorsSuite) TestErrorTypesAreUnique() {
+	names := make(map[string]bool)
+	for _, t := range api.ErrorTypes {
+		suite.Require().False(names[t.Name], t.Name)
+		names[t.Name] = true
+	}
+	suite.Require().Subset(api.ErrorTypeNames(api.ErrorTypes), api.ErrorTypeNames(api.DBErrorTypes))
+}
@@ -0,0 +1,189 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: client.go
+
+// Package clients is a generated GoMock package.
+package clients
+
+import (
+	context "context"
+	reflect "reflect"
+
+	api "github.com/cryptellation/backtests/api"
+	backtest "github.com/cryptellation/backtests/pkg/backtest"
+	quota "github.com/cryptellation/backtests/pkg/quota"
+	runtime "github.com/cryptellation/runtime"
+	uuid "github.com/google/uuid"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockClient is a mock of Client interface.
+type MockClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockClientMockRecorder
+}
+
+// MockClientMockRecorder is the mock recorder for MockClient.
+type MockClientMockRecorder struct {
+	mock *MockClient
+}
+
+// NewMockClient creates a new mock instance.
+func NewMockClient(ctrl *gomock.Controller) *MockClient {
+	mock := &MockClient{ctrl: ctrl}
+	mock.recorder = &MockClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClient) EXPECT() *MockClientMockRecorder {
+	return m.recorder
+}
+
+// Attach mocks base method.
+func (m *MockClient) Attach(ctx context.Context, backtestID uuid.UUID) (BacktestRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Attach", ctx, backtestID)
+	ret0, _ := ret[0].(BacktestRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Attach indicates an expected call of Attach.
+func (mr *MockClientMockRecorder) Attach(ctx, backtestID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Attach", reflect.TypeOf((*MockClient)(nil).Attach), ctx, backtestID)
+}
+
+// DeleteBacktest mocks base method.
+func (m *MockClient) DeleteBacktest(ctx context.Context, backtestID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBacktest", ctx, backtestID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBacktest indicates an expected call of DeleteBacktest.
+func (mr *MockClientMockRecorder) DeleteBacktest(ctx, backtestID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBacktest", reflect.TypeOf((*MockClient)(nil).DeleteBacktest), ctx, backtestID)
+}
+
+// GetBacktest mocks base method.
+func (m *MockClient) GetBacktest(ctx context.Context, params api.GetBacktestWorkflowParams) (Backtest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBacktest", ctx, params)
+	ret0, _ := ret[0].(Backtest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBacktest indicates an expected call of GetBacktest.
+func (mr *MockClientMockRecorder) GetBacktest(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBacktest", reflect.TypeOf((*MockClient)(nil).GetBacktest), ctx, params)
+}
+
+// Info mocks base method.
+func (m *MockClient) Info(ctx context.Context) (api.ServiceInfoResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Info", ctx)
+	ret0, _ := ret[0].(api.ServiceInfoResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Info indicates an expected call of Info.
+func (mr *MockClientMockRecorder) Info(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Info", reflect.TypeOf((*MockClient)(nil).Info), ctx)
+}
+
+// ListBacktests mocks base method.
+func (m *MockClient) ListBacktests(ctx context.Context, params api.ListBacktestsWorkflowParams) ([]Backtest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBacktests", ctx, params)
+	ret0, _ := ret[0].([]Backtest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBacktests indicates an expected call of ListBacktests.
+func (mr *MockClientMockRecorder) ListBacktests(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBacktests", reflect.TypeOf((*MockClient)(nil).ListBacktests), ctx, params)
+}
+
+// NewBacktest mocks base method.
+func (m *MockClient) NewBacktest(ctx context.Context, params backtest.Parameters, callbacks runtime.Callbacks) (Backtest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewBacktest", ctx, params, callbacks)
+	ret0, _ := ret[0].(Backtest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewBacktest indicates an expected call of NewBacktest.
+func (mr *MockClientMockRecorder) NewBacktest(ctx, params, callbacks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewBacktest", reflect.TypeOf((*MockClient)(nil).NewBacktest), ctx, params, callbacks)
+}
+
+// PurgeBacktests mocks base method.
+func (m *MockClient) PurgeBacktests(ctx context.Context, params api.PurgeBacktestsWorkflowParams) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeBacktests", ctx, params)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeBacktests indicates an expected call of PurgeBacktests.
+func (mr *MockClientMockRecorder) PurgeBacktests(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeBacktests", reflect.TypeOf((*MockClient)(nil).PurgeBacktests), ctx, params)
+}
+
+// QuotaUsage mocks base method.
+func (m *MockClient) QuotaUsage(ctx context.Context) (quota.Usage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QuotaUsage", ctx)
+	ret0, _ := ret[0].(quota.Usage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QuotaUsage indicates an expected call of QuotaUsage.
+func (mr *MockClientMockRecorder) QuotaUsage(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuotaUsage", reflect.TypeOf((*MockClient)(nil).QuotaUsage), ctx)
+}
+
+// RunParameterSweep mocks base method.
+func (m *MockClient) RunParameterSweep(ctx context.Context, params api.RunParameterSweepWorkflowParams) (api.RunParameterSweepWorkflowResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunParameterSweep", ctx, params)
+	ret0, _ := ret[0].(api.RunParameterSweepWorkflowResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RunParameterSweep indicates an expected call of RunParameterSweep.
+func (mr *MockClientMockRecorder) RunParameterSweep(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunParameterSweep", reflect.TypeOf((*MockClient)(nil).RunParameterSweep), ctx, params)
+}
+
+// WalkForward mocks base method.
+func (m *MockClient) WalkForward(ctx context.Context, params api.WalkForwardWorkflowParams) (api.WalkForwardWorkflowResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WalkForward", ctx, params)
+	ret0, _ := ret[0].(api.WalkForwardWorkflowResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WalkForward indicates an expected call of WalkForward.
+func (mr *MockClientMockRecorder) WalkForward(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WalkForward", reflect.TypeOf((*MockClient)(nil).WalkForward), ctx, params)
+}
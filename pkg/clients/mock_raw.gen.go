@@ -0,0 +1,461 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: raw.go
+
+// Package clients is a generated GoMock package.
+package clients
+
+import (
+	context "context"
+	reflect "reflect"
+
+	api "github.com/cryptellation/backtests/api"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRawClient is a mock of RawClient interface.
+type MockRawClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockRawClientMockRecorder
+}
+
+// MockRawClientMockRecorder is the mock recorder for MockRawClient.
+type MockRawClientMockRecorder struct {
+	mock *MockRawClient
+}
+
+// NewMockRawClient creates a new mock instance.
+func NewMockRawClient(ctrl *gomock.Controller) *MockRawClient {
+	mock := &MockRawClient{ctrl: ctrl}
+	mock.recorder = &MockRawClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRawClient) EXPECT() *MockRawClientMockRecorder {
+	return m.recorder
+}
+
+// AttachBacktest mocks base method.
+func (m *MockRawClient) AttachBacktest(ctx context.Context, params api.RunBacktestWorkflowParams) (WorkflowRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AttachBacktest", ctx, params)
+	ret0, _ := ret[0].(WorkflowRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AttachBacktest indicates an expected call of AttachBacktest.
+func (mr *MockRawClientMockRecorder) AttachBacktest(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttachBacktest", reflect.TypeOf((*MockRawClient)(nil).AttachBacktest), ctx, params)
+}
+
+// CreateBacktest mocks base method.
+func (m *MockRawClient) CreateBacktest(ctx context.Context, params api.CreateBacktestWorkflowParams) (api.CreateBacktestWorkflowResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBacktest", ctx, params)
+	ret0, _ := ret[0].(api.CreateBacktestWorkflowResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBacktest indicates an expected call of CreateBacktest.
+func (mr *MockRawClientMockRecorder) CreateBacktest(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBacktest", reflect.TypeOf((*MockRawClient)(nil).CreateBacktest), ctx, params)
+}
+
+// CreateBacktestOrder mocks base method.
+func (m *MockRawClient) CreateBacktestOrder(ctx context.Context, params api.CreateBacktestOrderWorkflowParams) (api.CreateBacktestOrderWorkflowResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBacktestOrder", ctx, params)
+	ret0, _ := ret[0].(api.CreateBacktestOrderWorkflowResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBacktestOrder indicates an expected call of CreateBacktestOrder.
+func (mr *MockRawClientMockRecorder) CreateBacktestOrder(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBacktestOrder", reflect.TypeOf((*MockRawClient)(nil).CreateBacktestOrder), ctx, params)
+}
+
+// DeleteBacktest mocks base method.
+func (m *MockRawClient) DeleteBacktest(ctx context.Context, params api.DeleteBacktestWorkflowParams) (api.DeleteBacktestWorkflowResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBacktest", ctx, params)
+	ret0, _ := ret[0].(api.DeleteBacktestWorkflowResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteBacktest indicates an expected call of DeleteBacktest.
+func (mr *MockRawClientMockRecorder) DeleteBacktest(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBacktest", reflect.TypeOf((*MockRawClient)(nil).DeleteBacktest), ctx, params)
+}
+
+// ExportBacktest mocks base method.
+func (m *MockRawClient) ExportBacktest(ctx context.Context, params api.ExportBacktestWorkflowParams) (api.ExportBacktestWorkflowResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportBacktest", ctx, params)
+	ret0, _ := ret[0].(api.ExportBacktestWorkflowResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportBacktest indicates an expected call of ExportBacktest.
+func (mr *MockRawClientMockRecorder) ExportBacktest(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportBacktest", reflect.TypeOf((*MockRawClient)(nil).ExportBacktest), ctx, params)
+}
+
+// ForkBacktest mocks base method.
+func (m *MockRawClient) ForkBacktest(ctx context.Context, params api.ForkBacktestWorkflowParams) (api.ForkBacktestWorkflowResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForkBacktest", ctx, params)
+	ret0, _ := ret[0].(api.ForkBacktestWorkflowResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ForkBacktest indicates an expected call of ForkBacktest.
+func (mr *MockRawClientMockRecorder) ForkBacktest(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForkBacktest", reflect.TypeOf((*MockRawClient)(nil).ForkBacktest), ctx, params)
+}
+
+// GetBacktest mocks base method.
+func (m *MockRawClient) GetBacktest(ctx context.Context, params api.GetBacktestWorkflowParams) (api.GetBacktestWorkflowResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBacktest", ctx, params)
+	ret0, _ := ret[0].(api.GetBacktestWorkflowResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBacktest indicates an expected call of GetBacktest.
+func (mr *MockRawClientMockRecorder) GetBacktest(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBacktest", reflect.TypeOf((*MockRawClient)(nil).GetBacktest), ctx, params)
+}
+
+// GetBacktestAccounts mocks base method.
+func (m *MockRawClient) GetBacktestAccounts(ctx context.Context, params api.GetBacktestAccountsWorkflowParams) (api.GetBacktestAccountsWorkflowResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBacktestAccounts", ctx, params)
+	ret0, _ := ret[0].(api.GetBacktestAccountsWorkflowResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBacktestAccounts indicates an expected call of GetBacktestAccounts.
+func (mr *MockRawClientMockRecorder) GetBacktestAccounts(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBacktestAccounts", reflect.TypeOf((*MockRawClient)(nil).GetBacktestAccounts), ctx, params)
+}
+
+// GetBacktestOrders mocks base method.
+func (m *MockRawClient) GetBacktestOrders(ctx context.Context, params api.GetBacktestOrdersWorkflowParams) (api.GetBacktestOrdersWorkflowResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBacktestOrders", ctx, params)
+	ret0, _ := ret[0].(api.GetBacktestOrdersWorkflowResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBacktestOrders indicates an expected call of GetBacktestOrders.
+func (mr *MockRawClientMockRecorder) GetBacktestOrders(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBacktestOrders", reflect.TypeOf((*MockRawClient)(nil).GetBacktestOrders), ctx, params)
+}
+
+// GetBacktestReport mocks base method.
+func (m *MockRawClient) GetBacktestReport(ctx context.Context, params api.GetBacktestReportWorkflowParams) (api.GetBacktestReportWorkflowResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBacktestReport", ctx, params)
+	ret0, _ := ret[0].(api.GetBacktestReportWorkflowResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBacktestReport indicates an expected call of GetBacktestReport.
+func (mr *MockRawClientMockRecorder) GetBacktestReport(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBacktestReport", reflect.TypeOf((*MockRawClient)(nil).GetBacktestReport), ctx, params)
+}
+
+// GetBacktestStrategyParameters mocks base method.
+func (m *MockRawClient) GetBacktestStrategyParameters(ctx context.Context, params api.GetBacktestStrategyParametersWorkflowParams) (api.GetBacktestStrategyParametersWorkflowResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBacktestStrategyParameters", ctx, params)
+	ret0, _ := ret[0].(api.GetBacktestStrategyParametersWorkflowResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBacktestStrategyParameters indicates an expected call of GetBacktestStrategyParameters.
+func (mr *MockRawClientMockRecorder) GetBacktestStrategyParameters(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBacktestStrategyParameters", reflect.TypeOf((*MockRawClient)(nil).GetBacktestStrategyParameters), ctx, params)
+}
+
+// GetQuotaUsage mocks base method.
+func (m *MockRawClient) GetQuotaUsage(ctx context.Context, params api.GetQuotaUsageWorkflowParams) (api.GetQuotaUsageWorkflowResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQuotaUsage", ctx, params)
+	ret0, _ := ret[0].(api.GetQuotaUsageWorkflowResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQuotaUsage indicates an expected call of GetQuotaUsage.
+func (mr *MockRawClientMockRecorder) GetQuotaUsage(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQuotaUsage", reflect.TypeOf((*MockRawClient)(nil).GetQuotaUsage), ctx, params)
+}
+
+// ListBacktests mocks base method.
+func (m *MockRawClient) ListBacktests(ctx context.Context, params api.ListBacktestsWorkflowParams) (api.ListBacktestsWorkflowResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBacktests", ctx, params)
+	ret0, _ := ret[0].(api.ListBacktestsWorkflowResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBacktests indicates an expected call of ListBacktests.
+func (mr *MockRawClientMockRecorder) ListBacktests(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBacktests", reflect.TypeOf((*MockRawClient)(nil).ListBacktests), ctx, params)
+}
+
+// MonteCarlo mocks base method.
+func (m *MockRawClient) MonteCarlo(ctx context.Context, params api.MonteCarloWorkflowParams) (api.MonteCarloWorkflowResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MonteCarlo", ctx, params)
+	ret0, _ := ret[0].(api.MonteCarloWorkflowResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MonteCarlo indicates an expected call of MonteCarlo.
+func (mr *MockRawClientMockRecorder) MonteCarlo(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MonteCarlo", reflect.TypeOf((*MockRawClient)(nil).MonteCarlo), ctx, params)
+}
+
+// PurgeBacktests mocks base method.
+func (m *MockRawClient) PurgeBacktests(ctx context.Context, params api.PurgeBacktestsWorkflowParams) (api.PurgeBacktestsWorkflowResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeBacktests", ctx, params)
+	ret0, _ := ret[0].(api.PurgeBacktestsWorkflowResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeBacktests indicates an expected call of PurgeBacktests.
+func (mr *MockRawClientMockRecorder) PurgeBacktests(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeBacktests", reflect.TypeOf((*MockRawClient)(nil).PurgeBacktests), ctx, params)
+}
+
+// RunBacktest mocks base method.
+func (m *MockRawClient) RunBacktest(ctx context.Context, params api.RunBacktestWorkflowParams) (api.RunBacktestWorkflowResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunBacktest", ctx, params)
+	ret0, _ := ret[0].(api.RunBacktestWorkflowResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RunBacktest indicates an expected call of RunBacktest.
+func (mr *MockRawClientMockRecorder) RunBacktest(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunBacktest", reflect.TypeOf((*MockRawClient)(nil).RunBacktest), ctx, params)
+}
+
+// RunParameterSweep mocks base method.
+func (m *MockRawClient) RunParameterSweep(ctx context.Context, params api.RunParameterSweepWorkflowParams) (api.RunParameterSweepWorkflowResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunParameterSweep", ctx, params)
+	ret0, _ := ret[0].(api.RunParameterSweepWorkflowResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RunParameterSweep indicates an expected call of RunParameterSweep.
+func (mr *MockRawClientMockRecorder) RunParameterSweep(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunParameterSweep", reflect.TypeOf((*MockRawClient)(nil).RunParameterSweep), ctx, params)
+}
+
+// ServiceInfo mocks base method.
+func (m *MockRawClient) ServiceInfo(ctx context.Context) (api.ServiceInfoResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ServiceInfo", ctx)
+	ret0, _ := ret[0].(api.ServiceInfoResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ServiceInfo indicates an expected call of ServiceInfo.
+func (mr *MockRawClientMockRecorder) ServiceInfo(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ServiceInfo", reflect.TypeOf((*MockRawClient)(nil).ServiceInfo), ctx)
+}
+
+// SetBacktestWakeUp mocks base method.
+func (m *MockRawClient) SetBacktestWakeUp(ctx context.Context, params api.SetBacktestWakeUpWorkflowParams) (api.SetBacktestWakeUpWorkflowResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBacktestWakeUp", ctx, params)
+	ret0, _ := ret[0].(api.SetBacktestWakeUpWorkflowResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetBacktestWakeUp indicates an expected call of SetBacktestWakeUp.
+func (mr *MockRawClientMockRecorder) SetBacktestWakeUp(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBacktestWakeUp", reflect.TypeOf((*MockRawClient)(nil).SetBacktestWakeUp), ctx, params)
+}
+
+// StartBacktest mocks base method.
+func (m *MockRawClient) StartBacktest(ctx context.Context, params api.RunBacktestWorkflowParams) (WorkflowRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartBacktest", ctx, params)
+	ret0, _ := ret[0].(WorkflowRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartBacktest indicates an expected call of StartBacktest.
+func (mr *MockRawClientMockRecorder) StartBacktest(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartBacktest", reflect.TypeOf((*MockRawClient)(nil).StartBacktest), ctx, params)
+}
+
+// SubscribeToPrice mocks base method.
+func (m *MockRawClient) SubscribeToPrice(ctx context.Context, params api.SubscribeToPriceWorkflowParams) (api.SubscribeToPriceWorkflowResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeToPrice", ctx, params)
+	ret0, _ := ret[0].(api.SubscribeToPriceWorkflowResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubscribeToPrice indicates an expected call of SubscribeToPrice.
+func (mr *MockRawClientMockRecorder) SubscribeToPrice(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeToPrice", reflect.TypeOf((*MockRawClient)(nil).SubscribeToPrice), ctx, params)
+}
+
+// UpdateBacktestMetadata mocks base method.
+func (m *MockRawClient) UpdateBacktestMetadata(ctx context.Context, params api.UpdateBacktestMetadataWorkflowParams) (api.UpdateBacktestMetadataWorkflowResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBacktestMetadata", ctx, params)
+	ret0, _ := ret[0].(api.UpdateBacktestMetadataWorkflowResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateBacktestMetadata indicates an expected call of UpdateBacktestMetadata.
+func (mr *MockRawClientMockRecorder) UpdateBacktestMetadata(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBacktestMetadata", reflect.TypeOf((*MockRawClient)(nil).UpdateBacktestMetadata), ctx, params)
+}
+
+// WaitBacktestEvents mocks base method.
+func (m *MockRawClient) WaitBacktestEvents(ctx context.Context, params api.BacktestEventsUpdateParams) (api.BacktestEventsUpdateResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitBacktestEvents", ctx, params)
+	ret0, _ := ret[0].(api.BacktestEventsUpdateResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WaitBacktestEvents indicates an expected call of WaitBacktestEvents.
+func (mr *MockRawClientMockRecorder) WaitBacktestEvents(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitBacktestEvents", reflect.TypeOf((*MockRawClient)(nil).WaitBacktestEvents), ctx, params)
+}
+
+// WalkForward mocks base method.
+func (m *MockRawClient) WalkForward(ctx context.Context, params api.WalkForwardWorkflowParams) (api.WalkForwardWorkflowResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WalkForward", ctx, params)
+	ret0, _ := ret[0].(api.WalkForwardWorkflowResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WalkForward indicates an expected call of WalkForward.
+func (mr *MockRawClientMockRecorder) WalkForward(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WalkForward", reflect.TypeOf((*MockRawClient)(nil).WalkForward), ctx, params)
+}
+
+// MockWorkflowRun is a mock of WorkflowRun interface.
+type MockWorkflowRun struct {
+	ctrl     *gomock.Controller
+	recorder *MockWorkflowRunMockRecorder
+}
+
+// MockWorkflowRunMockRecorder is the mock recorder for MockWorkflowRun.
+type MockWorkflowRunMockRecorder struct {
+	mock *MockWorkflowRun
+}
+
+// NewMockWorkflowRun creates a new mock instance.
+func NewMockWorkflowRun(ctrl *gomock.Controller) *MockWorkflowRun {
+	mock := &MockWorkflowRun{ctrl: ctrl}
+	mock.recorder = &MockWorkflowRunMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWorkflowRun) EXPECT() *MockWorkflowRunMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockWorkflowRun) Get(ctx context.Context, valuePtr any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, valuePtr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockWorkflowRunMockRecorder) Get(ctx, valuePtr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockWorkflowRun)(nil).Get), ctx, valuePtr)
+}
+
+// GetID mocks base method.
+func (m *MockWorkflowRun) GetID() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetID")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetID indicates an expected call of GetID.
+func (mr *MockWorkflowRunMockRecorder) GetID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetID", reflect.TypeOf((*MockWorkflowRun)(nil).GetID))
+}
+
+// GetRunID mocks base method.
+func (m *MockWorkflowRun) GetRunID() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRunID")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetRunID indicates an expected call of GetRunID.
+func (mr *MockWorkflowRunMockRecorder) GetRunID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRunID", reflect.TypeOf((*MockWorkflowRun)(nil).GetRunID))
+}
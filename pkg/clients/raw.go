@@ -1,12 +1,32 @@
+// Generate code for mock
+//go:generate go run go.uber.org/mock/mockgen@v0.2.0 -source=raw.go -destination=mock_raw.gen.go -package clients
+
 package clients
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/tenant"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
 	temporalclient "go.temporal.io/sdk/client"
 )
 
+var (
+	// ErrBacktestAlreadyRunning is returned when starting a backtest that is
+	// already running or that has already completed.
+	ErrBacktestAlreadyRunning = errors.New("backtest already running")
+	// ErrBacktestRunNotFound is returned when attaching to a backtest that has
+	// never been started.
+	ErrBacktestRunNotFound = errors.New("backtest run not found")
+	// ErrBacktestNotRunning is returned when waiting for the events of a
+	// backtest that has never been started.
+	ErrBacktestNotRunning = errors.New("backtest not running")
+)
+
 // RawClient is a client for the cryptellation backtests service with just the
 // calls to the temporal workflows.
 type RawClient interface {
@@ -18,6 +38,42 @@ type RawClient interface {
 		ctx context.Context,
 		params api.RunBacktestWorkflowParams,
 	) (api.RunBacktestWorkflowResults, error)
+	StartBacktest(
+		ctx context.Context,
+		params api.RunBacktestWorkflowParams,
+	) (WorkflowRun, error)
+	AttachBacktest(
+		ctx context.Context,
+		params api.RunBacktestWorkflowParams,
+	) (WorkflowRun, error)
+	WaitBacktestEvents(
+		ctx context.Context,
+		params api.BacktestEventsUpdateParams,
+	) (api.BacktestEventsUpdateResults, error)
+	ForkBacktest(
+		ctx context.Context,
+		params api.ForkBacktestWorkflowParams,
+	) (api.ForkBacktestWorkflowResults, error)
+	RunParameterSweep(
+		ctx context.Context,
+		params api.RunParameterSweepWorkflowParams,
+	) (api.RunParameterSweepWorkflowResults, error)
+	WalkForward(
+		ctx context.Context,
+		params api.WalkForwardWorkflowParams,
+	) (api.WalkForwardWorkflowResults, error)
+	MonteCarlo(
+		ctx context.Context,
+		params api.MonteCarloWorkflowParams,
+	) (api.MonteCarloWorkflowResults, error)
+	ExportBacktest(
+		ctx context.Context,
+		params api.ExportBacktestWorkflowParams,
+	) (api.ExportBacktestWorkflowResults, error)
+	GetBacktestReport(
+		ctx context.Context,
+		params api.GetBacktestReportWorkflowParams,
+	) (api.GetBacktestReportWorkflowResults, error)
 	GetBacktest(
 		ctx context.Context,
 		params api.GetBacktestWorkflowParams,
@@ -30,6 +86,52 @@ type RawClient interface {
 		ctx context.Context,
 		params api.SubscribeToPriceWorkflowParams,
 	) (api.SubscribeToPriceWorkflowResults, error)
+	GetBacktestAccounts(
+		ctx context.Context,
+		params api.GetBacktestAccountsWorkflowParams,
+	) (api.GetBacktestAccountsWorkflowResults, error)
+	GetBacktestOrders(
+		ctx context.Context,
+		params api.GetBacktestOrdersWorkflowParams,
+	) (api.GetBacktestOrdersWorkflowResults, error)
+	CreateBacktestOrder(
+		ctx context.Context,
+		params api.CreateBacktestOrderWorkflowParams,
+	) (api.CreateBacktestOrderWorkflowResults, error)
+	UpdateBacktestMetadata(
+		ctx context.Context,
+		params api.UpdateBacktestMetadataWorkflowParams,
+	) (api.UpdateBacktestMetadataWorkflowResults, error)
+	DeleteBacktest(
+		ctx context.Context,
+		params api.DeleteBacktestWorkflowParams,
+	) (api.DeleteBacktestWorkflowResults, error)
+	PurgeBacktests(
+		ctx context.Context,
+		params api.PurgeBacktestsWorkflowParams,
+	) (api.PurgeBacktestsWorkflowResults, error)
+	GetBacktestStrategyParameters(
+		ctx context.Context,
+		params api.GetBacktestStrategyParametersWorkflowParams,
+	) (api.GetBacktestStrategyParametersWorkflowResults, error)
+	SetBacktestWakeUp(
+		ctx context.Context,
+		params api.SetBacktestWakeUpWorkflowParams,
+	) (api.SetBacktestWakeUpWorkflowResults, error)
+	GetQuotaUsage(
+		ctx context.Context,
+		params api.GetQuotaUsageWorkflowParams,
+	) (api.GetQuotaUsageWorkflowResults, error)
+	ServiceInfo(
+		ctx context.Context,
+	) (api.ServiceInfoResults, error)
+}
+
+// WorkflowRun is a run of a temporal workflow.
+type WorkflowRun interface {
+	GetID() string
+	GetRunID() string
+	Get(ctx context.Context, valuePtr any) error
 }
 
 var _ RawClient = raw{}
@@ -62,29 +164,259 @@ func (c raw) CreateBacktest(
 	var res api.CreateBacktestWorkflowResults
 	err = exec.Get(ctx, &res)
 
-	return res, err
+	return res, DecodeError(err)
 }
 
-// RunBacktest runs a backtest workflow.
+// RunBacktest runs a backtest workflow and waits for its end.
 func (c raw) RunBacktest(
 	ctx context.Context,
 	params api.RunBacktestWorkflowParams,
 ) (api.RunBacktestWorkflowResults, error) {
+	exec, err := c.StartBacktest(ctx, params)
+	if err != nil {
+		return api.RunBacktestWorkflowResults{}, err
+	}
+
+	// Get result and return
+	var res api.RunBacktestWorkflowResults
+	err = exec.Get(ctx, &res)
+
+	return res, DecodeError(err)
+}
+
+// StartBacktest starts a backtest workflow without waiting for its end.
+// It returns ErrBacktestAlreadyRunning if the backtest is already running.
+func (c raw) StartBacktest(
+	ctx context.Context,
+	params api.RunBacktestWorkflowParams,
+) (WorkflowRun, error) {
 	workflowOptions := temporalclient.StartWorkflowOptions{
+		ID:        api.RunBacktestWorkflowID(params.BacktestID),
 		TaskQueue: api.WorkerTaskQueueName,
+		// Only allow to run again a backtest that failed
+		WorkflowIDReusePolicy:                    enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE_FAILED_ONLY,
+		WorkflowExecutionErrorWhenAlreadyStarted: true,
 	}
 
 	// Execute workflow
 	exec, err := c.temporal.ExecuteWorkflow(ctx, workflowOptions, api.RunBacktestWorkflowName, params)
+	var alreadyStarted *serviceerror.WorkflowExecutionAlreadyStarted
+	if errors.As(err, &alreadyStarted) {
+		return nil, fmt.Errorf("%w: %s", ErrBacktestAlreadyRunning, params.BacktestID)
+	}
+
+	return exec, err
+}
+
+// AttachBacktest gets the last workflow run of a backtest.
+// It returns ErrBacktestRunNotFound if the backtest has never been started.
+func (c raw) AttachBacktest(
+	ctx context.Context,
+	params api.RunBacktestWorkflowParams,
+) (WorkflowRun, error) {
+	// Check that the workflow exists
+	workflowID := api.RunBacktestWorkflowID(params.BacktestID)
+	desc, err := c.temporal.DescribeWorkflowExecution(ctx, workflowID, "")
+	var notFound *serviceerror.NotFound
+	if errors.As(err, &notFound) {
+		return nil, fmt.Errorf("%w: %s", ErrBacktestRunNotFound, params.BacktestID)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return c.temporal.GetWorkflow(ctx, workflowID, desc.GetWorkflowExecutionInfo().GetExecution().GetRunId()), nil
+}
+
+// WaitBacktestEvents waits for the events of a backtest run following the given
+// sequence, for at most api.BacktestEventsPollTimeout. If the run is over, it
+// returns its last events at once.
+// It returns ErrBacktestNotRunning if the backtest has never been started.
+// The tenant of the context is used if the parameters have none.
+func (c raw) WaitBacktestEvents(
+	ctx context.Context,
+	params api.BacktestEventsUpdateParams,
+) (api.BacktestEventsUpdateResults, error) {
+	if params.Tenant == "" {
+		params.Tenant = tenant.FromContext(ctx)
+	}
+
+	// Send the update
+	handle, err := c.temporal.UpdateWorkflow(ctx, temporalclient.UpdateWorkflowOptions{
+		WorkflowID:   api.RunBacktestWorkflowID(params.BacktestID),
+		UpdateName:   api.BacktestEventsUpdateName,
+		Args:         []any{params},
+		WaitForStage: temporalclient.WorkflowUpdateStageCompleted,
+	})
+	var notFound *serviceerror.NotFound
+	if errors.As(err, &notFound) {
+		// The run is over (or has never been started): get its last events
+		return c.getEndedBacktestEvents(ctx, params)
+	} else if err != nil {
+		return api.BacktestEventsUpdateResults{}, err
+	}
+
+	// Get result and return
+	var res api.BacktestEventsUpdateResults
+	err = handle.Get(ctx, &res)
+
+	return res, DecodeError(err)
+}
+
+func (c raw) getEndedBacktestEvents(
+	ctx context.Context,
+	params api.BacktestEventsUpdateParams,
+) (api.BacktestEventsUpdateResults, error) {
+	// Query the run
+	value, err := c.temporal.QueryWorkflow(ctx,
+		api.RunBacktestWorkflowID(params.BacktestID), "",
+		api.BacktestEventsQueryName, params)
+	var notFound *serviceerror.NotFound
+	if errors.As(err, &notFound) {
+		return api.BacktestEventsUpdateResults{}, fmt.Errorf("%w: %s", ErrBacktestNotRunning, params.BacktestID)
+	} else if err != nil {
+		return api.BacktestEventsUpdateResults{}, err
+	}
+
+	// Get result and return, as finished since the run is over
+	var res api.BacktestEventsUpdateResults
+	if err := value.Get(&res); err != nil {
+		return api.BacktestEventsUpdateResults{}, err
+	}
+	res.Finished = true
+
+	return res, nil
+}
+
+// ForkBacktest forks a backtest workflow from one of its snapshots.
+func (c raw) ForkBacktest(
+	ctx context.Context,
+	params api.ForkBacktestWorkflowParams,
+) (api.ForkBacktestWorkflowResults, error) {
+	workflowOptions := temporalclient.StartWorkflowOptions{
+		TaskQueue: api.WorkerTaskQueueName,
+	}
+
+	// Execute workflow
+	exec, err := c.temporal.ExecuteWorkflow(ctx, workflowOptions, api.ForkBacktestWorkflowName, params)
 	if err != nil {
-		return api.RunBacktestWorkflowResults{}, err
+		return api.ForkBacktestWorkflowResults{}, err
 	}
 
 	// Get result and return
-	var res api.RunBacktestWorkflowResults
+	var res api.ForkBacktestWorkflowResults
+	err = exec.Get(ctx, &res)
+
+	return res, DecodeError(err)
+}
+
+// RunParameterSweep runs a parameter sweep workflow.
+func (c raw) RunParameterSweep(
+	ctx context.Context,
+	params api.RunParameterSweepWorkflowParams,
+) (api.RunParameterSweepWorkflowResults, error) {
+	workflowOptions := temporalclient.StartWorkflowOptions{
+		TaskQueue: api.WorkerTaskQueueName,
+	}
+
+	// Execute workflow
+	exec, err := c.temporal.ExecuteWorkflow(ctx, workflowOptions, api.RunParameterSweepWorkflowName, params)
+	if err != nil {
+		return api.RunParameterSweepWorkflowResults{}, err
+	}
+
+	// Get result and return
+	var res api.RunParameterSweepWorkflowResults
+	err = exec.Get(ctx, &res)
+
+	return res, DecodeError(err)
+}
+
+// WalkForward runs a walk-forward optimization workflow.
+func (c raw) WalkForward(
+	ctx context.Context,
+	params api.WalkForwardWorkflowParams,
+) (api.WalkForwardWorkflowResults, error) {
+	workflowOptions := temporalclient.StartWorkflowOptions{
+		TaskQueue: api.WorkerTaskQueueName,
+	}
+
+	// Execute workflow
+	exec, err := c.temporal.ExecuteWorkflow(ctx, workflowOptions, api.WalkForwardWorkflowName, params)
+	if err != nil {
+		return api.WalkForwardWorkflowResults{}, err
+	}
+
+	// Get result and return
+	var res api.WalkForwardWorkflowResults
+	err = exec.Get(ctx, &res)
+
+	return res, DecodeError(err)
+}
+
+// MonteCarlo runs a Monte Carlo analysis workflow.
+func (c raw) MonteCarlo(
+	ctx context.Context,
+	params api.MonteCarloWorkflowParams,
+) (api.MonteCarloWorkflowResults, error) {
+	workflowOptions := temporalclient.StartWorkflowOptions{
+		TaskQueue: api.WorkerTaskQueueName,
+	}
+
+	// Execute workflow
+	exec, err := c.temporal.ExecuteWorkflow(ctx, workflowOptions, api.MonteCarloWorkflowName, params)
+	if err != nil {
+		return api.MonteCarloWorkflowResults{}, err
+	}
+
+	// Get result and return
+	var res api.MonteCarloWorkflowResults
 	err = exec.Get(ctx, &res)
 
-	return res, err
+	return res, DecodeError(err)
+}
+
+// ExportBacktest gets the rows of the datasets exported from a backtest.
+func (c raw) ExportBacktest(
+	ctx context.Context,
+	params api.ExportBacktestWorkflowParams,
+) (api.ExportBacktestWorkflowResults, error) {
+	workflowOptions := temporalclient.StartWorkflowOptions{
+		TaskQueue: api.WorkerTaskQueueName,
+	}
+
+	// Execute workflow
+	exec, err := c.temporal.ExecuteWorkflow(ctx, workflowOptions, api.ExportBacktestWorkflowName, params)
+	if err != nil {
+		return api.ExportBacktestWorkflowResults{}, err
+	}
+
+	// Get result and return
+	var res api.ExportBacktestWorkflowResults
+	err = exec.Get(ctx, &res)
+
+	return res, DecodeError(err)
+}
+
+// GetBacktestReport gets the content of the report of a backtest.
+func (c raw) GetBacktestReport(
+	ctx context.Context,
+	params api.GetBacktestReportWorkflowParams,
+) (api.GetBacktestReportWorkflowResults, error) {
+	workflowOptions := temporalclient.StartWorkflowOptions{
+		TaskQueue: api.WorkerTaskQueueName,
+	}
+
+	// Execute workflow
+	exec, err := c.temporal.ExecuteWorkflow(ctx, workflowOptions, api.GetBacktestReportWorkflowName, params)
+	if err != nil {
+		return api.GetBacktestReportWorkflowResults{}, err
+	}
+
+	// Get result and return
+	var res api.GetBacktestReportWorkflowResults
+	err = exec.Get(ctx, &res)
+
+	return res, DecodeError(err)
 }
 
 // SubscribeToPrice subscribes to the backtest price workflow.
@@ -106,7 +438,7 @@ func (c raw) SubscribeToPrice(
 	var res api.SubscribeToPriceWorkflowResults
 	err = exec.Get(ctx, &res)
 
-	return res, err
+	return res, DecodeError(err)
 }
 
 // ListBacktests lists backtest workflows.
@@ -128,7 +460,7 @@ func (c raw) ListBacktests(
 	var res api.ListBacktestsWorkflowResults
 	err = exec.Get(ctx, &res)
 
-	return res, err
+	return res, DecodeError(err)
 }
 
 // GetBacktest retrieves a backtest workflow.
@@ -150,5 +482,222 @@ func (c raw) GetBacktest(
 	var res api.GetBacktestWorkflowResults
 	err = exec.Get(ctx, &res)
 
-	return res, err
+	return res, DecodeError(err)
+}
+
+// GetBacktestAccounts gets the accounts of a backtest.
+func (c raw) GetBacktestAccounts(
+	ctx context.Context,
+	params api.GetBacktestAccountsWorkflowParams,
+) (api.GetBacktestAccountsWorkflowResults, error) {
+	workflowOptions := temporalclient.StartWorkflowOptions{
+		TaskQueue: api.WorkerTaskQueueName,
+	}
+
+	// Execute workflow
+	exec, err := c.temporal.ExecuteWorkflow(ctx, workflowOptions, api.GetBacktestAccountsWorkflowName, params)
+	if err != nil {
+		return api.GetBacktestAccountsWorkflowResults{}, err
+	}
+
+	// Get result and return
+	var res api.GetBacktestAccountsWorkflowResults
+	err = exec.Get(ctx, &res)
+
+	return res, DecodeError(err)
+}
+
+// GetBacktestOrders gets the orders of a backtest.
+func (c raw) GetBacktestOrders(
+	ctx context.Context,
+	params api.GetBacktestOrdersWorkflowParams,
+) (api.GetBacktestOrdersWorkflowResults, error) {
+	workflowOptions := temporalclient.StartWorkflowOptions{
+		TaskQueue: api.WorkerTaskQueueName,
+	}
+
+	// Execute workflow
+	exec, err := c.temporal.ExecuteWorkflow(ctx, workflowOptions, api.GetBacktestOrdersWorkflowName, params)
+	if err != nil {
+		return api.GetBacktestOrdersWorkflowResults{}, err
+	}
+
+	// Get result and return
+	var res api.GetBacktestOrdersWorkflowResults
+	err = exec.Get(ctx, &res)
+
+	return res, DecodeError(err)
+}
+
+// CreateBacktestOrder creates an order on a backtest.
+func (c raw) CreateBacktestOrder(
+	ctx context.Context,
+	params api.CreateBacktestOrderWorkflowParams,
+) (api.CreateBacktestOrderWorkflowResults, error) {
+	workflowOptions := temporalclient.StartWorkflowOptions{
+		TaskQueue: api.WorkerTaskQueueName,
+	}
+
+	// Execute workflow
+	exec, err := c.temporal.ExecuteWorkflow(ctx, workflowOptions, api.CreateBacktestOrderWorkflowName, params)
+	if err != nil {
+		return api.CreateBacktestOrderWorkflowResults{}, err
+	}
+
+	// Get result and return
+	var res api.CreateBacktestOrderWorkflowResults
+	err = exec.Get(ctx, &res)
+
+	return res, DecodeError(err)
+}
+
+// UpdateBacktestMetadata updates the name, description and tags of a backtest.
+func (c raw) UpdateBacktestMetadata(
+	ctx context.Context,
+	params api.UpdateBacktestMetadataWorkflowParams,
+) (api.UpdateBacktestMetadataWorkflowResults, error) {
+	workflowOptions := temporalclient.StartWorkflowOptions{
+		TaskQueue: api.WorkerTaskQueueName,
+	}
+
+	// Execute workflow
+	exec, err := c.temporal.ExecuteWorkflow(ctx, workflowOptions, api.UpdateBacktestMetadataWorkflowName, params)
+	if err != nil {
+		return api.UpdateBacktestMetadataWorkflowResults{}, err
+	}
+
+	// Get result and return
+	var res api.UpdateBacktestMetadataWorkflowResults
+	err = exec.Get(ctx, &res)
+
+	return res, DecodeError(err)
+}
+
+// DeleteBacktest deletes a backtest.
+func (c raw) DeleteBacktest(
+	ctx context.Context,
+	params api.DeleteBacktestWorkflowParams,
+) (api.DeleteBacktestWorkflowResults, error) {
+	workflowOptions := temporalclient.StartWorkflowOptions{
+		TaskQueue: api.WorkerTaskQueueName,
+	}
+
+	// Execute workflow
+	exec, err := c.temporal.ExecuteWorkflow(ctx, workflowOptions, api.DeleteBacktestWorkflowName, params)
+	if err != nil {
+		return api.DeleteBacktestWorkflowResults{}, err
+	}
+
+	// Get result and return
+	var res api.DeleteBacktestWorkflowResults
+	err = exec.Get(ctx, &res)
+
+	return res, DecodeError(err)
+}
+
+// PurgeBacktests deletes the backtests matching the filters.
+func (c raw) PurgeBacktests(
+	ctx context.Context,
+	params api.PurgeBacktestsWorkflowParams,
+) (api.PurgeBacktestsWorkflowResults, error) {
+	workflowOptions := temporalclient.StartWorkflowOptions{
+		TaskQueue: api.WorkerTaskQueueName,
+	}
+
+	// Execute workflow
+	exec, err := c.temporal.ExecuteWorkflow(ctx, workflowOptions, api.PurgeBacktestsWorkflowName, params)
+	if err != nil {
+		return api.PurgeBacktestsWorkflowResults{}, err
+	}
+
+	// Get result and return
+	var res api.PurgeBacktestsWorkflowResults
+	err = exec.Get(ctx, &res)
+
+	return res, DecodeError(err)
+}
+
+// GetBacktestStrategyParameters gets the strategy parameters of a backtest.
+func (c raw) GetBacktestStrategyParameters(
+	ctx context.Context,
+	params api.GetBacktestStrategyParametersWorkflowParams,
+) (api.GetBacktestStrategyParametersWorkflowResults, error) {
+	workflowOptions := temporalclient.StartWorkflowOptions{
+		TaskQueue: api.WorkerTaskQueueName,
+	}
+
+	// Execute workflow
+	exec, err := c.temporal.ExecuteWorkflow(ctx, workflowOptions, api.GetBacktestStrategyParametersWorkflowName, params)
+	if err != nil {
+		return api.GetBacktestStrategyParametersWorkflowResults{}, err
+	}
+
+	// Get result and return
+	var res api.GetBacktestStrategyParametersWorkflowResults
+	err = exec.Get(ctx, &res)
+
+	return res, DecodeError(err)
+}
+
+// SetBacktestWakeUp sets the wake up of a backtest.
+func (c raw) SetBacktestWakeUp(
+	ctx context.Context,
+	params api.SetBacktestWakeUpWorkflowParams,
+) (api.SetBacktestWakeUpWorkflowResults, error) {
+	workflowOptions := temporalclient.StartWorkflowOptions{
+		TaskQueue: api.WorkerTaskQueueName,
+	}
+
+	// Execute workflow
+	exec, err := c.temporal.ExecuteWorkflow(ctx, workflowOptions, api.SetBacktestWakeUpWorkflowName, params)
+	if err != nil {
+		return api.SetBacktestWakeUpWorkflowResults{}, err
+	}
+
+	// Get result and return
+	var res api.SetBacktestWakeUpWorkflowResults
+	err = exec.Get(ctx, &res)
+
+	return res, DecodeError(err)
+}
+
+// GetQuotaUsage gets the current usage of the quota of a tenant.
+func (c raw) GetQuotaUsage(
+	ctx context.Context,
+	params api.GetQuotaUsageWorkflowParams,
+) (api.GetQuotaUsageWorkflowResults, error) {
+	workflowOptions := temporalclient.StartWorkflowOptions{
+		TaskQueue: api.WorkerTaskQueueName,
+	}
+
+	// Execute workflow
+	exec, err := c.temporal.ExecuteWorkflow(ctx, workflowOptions, api.GetQuotaUsageWorkflowName, params)
+	if err != nil {
+		return api.GetQuotaUsageWorkflowResults{}, err
+	}
+
+	// Get result and return
+	var res api.GetQuotaUsageWorkflowResults
+	err = exec.Get(ctx, &res)
+
+	return res, DecodeError(err)
+}
+
+// ServiceInfo calls the service info.
+func (c raw) ServiceInfo(ctx context.Context) (api.ServiceInfoResults, error) {
+	workflowOptions := temporalclient.StartWorkflowOptions{
+		TaskQueue: api.WorkerTaskQueueName,
+	}
+
+	// Execute workflow
+	exec, err := c.temporal.ExecuteWorkflow(ctx, workflowOptions, api.ServiceInfoWorkflowName)
+	if err != nil {
+		return api.ServiceInfoResults{}, err
+	}
+
+	// Get result and return
+	var res api.ServiceInfoResults
+	err = exec.Get(ctx, &res)
+
+	return res, DecodeError(err)
 }
@@ -0,0 +1,24 @@
+package clients
+
+import (
+	"github.com/cryptellation/backtests/api"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/workflow"
+)
+
+// StrategyParameters decodes the strategy parameters of the backtest into v.
+// It should be called from the init callback workflow of the strategy and
+// leaves v untouched if the backtest has no strategy parameters.
+func StrategyParameters(ctx workflow.Context, v any) error {
+	memo := workflow.GetInfo(ctx).Memo
+	if memo == nil {
+		return nil
+	}
+
+	payload, ok := memo.Fields[api.StrategyParametersMemoKey]
+	if !ok {
+		return nil
+	}
+
+	return converter.GetDefaultDataConverter().FromPayload(payload, v)
+}
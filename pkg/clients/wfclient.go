@@ -12,6 +12,17 @@ type WfClient interface {
 		ctx workflow.Context,
 		params api.SubscribeToPriceWorkflowParams,
 	) (api.SubscribeToPriceWorkflowResults, error)
+	// SetBacktestWakeUp sets the conditions to wake the strategy up. Until one of
+	// them is reached, the backtest runs without calling the strategy.
+	SetBacktestWakeUp(
+		ctx workflow.Context,
+		params api.SetBacktestWakeUpWorkflowParams,
+	) (api.SetBacktestWakeUpWorkflowResults, error)
+	// GetBacktestStrategyParameters gets the strategy parameters of the backtest.
+	GetBacktestStrategyParameters(
+		ctx workflow.Context,
+		params api.GetBacktestStrategyParametersWorkflowParams,
+	) (api.GetBacktestStrategyParametersWorkflowResults, error)
 }
 
 type wfClient struct{}
@@ -38,7 +49,49 @@ func (c wfClient) SubscribeToPrice(
 	var res api.SubscribeToPriceWorkflowResults
 	err := workflow.ExecuteChildWorkflow(ctx, api.SubscribeToPriceWorkflowName, params).Get(ctx, &res)
 	if err != nil {
-		return api.SubscribeToPriceWorkflowResults{}, err
+		return api.SubscribeToPriceWorkflowResults{}, DecodeError(err)
+	}
+
+	return res, nil
+}
+
+// SetBacktestWakeUp sets the wake up conditions of the backtest strategy.
+func (c wfClient) SetBacktestWakeUp(
+	ctx workflow.Context,
+	params api.SetBacktestWakeUpWorkflowParams,
+) (api.SetBacktestWakeUpWorkflowResults, error) {
+	// Set options
+	childWorkflowOptions := workflow.ChildWorkflowOptions{
+		TaskQueue: api.WorkerTaskQueueName,
+	}
+	ctx = workflow.WithChildOptions(ctx, childWorkflowOptions)
+
+	// Execute child workflow
+	var res api.SetBacktestWakeUpWorkflowResults
+	err := workflow.ExecuteChildWorkflow(ctx, api.SetBacktestWakeUpWorkflowName, params).Get(ctx, &res)
+	if err != nil {
+		return api.SetBacktestWakeUpWorkflowResults{}, DecodeError(err)
+	}
+
+	return res, nil
+}
+
+// GetBacktestStrategyParameters gets the strategy parameters of the backtest.
+func (c wfClient) GetBacktestStrategyParameters(
+	ctx workflow.Context,
+	params api.GetBacktestStrategyParametersWorkflowParams,
+) (api.GetBacktestStrategyParametersWorkflowResults, error) {
+	// Set options
+	childWorkflowOptions := workflow.ChildWorkflowOptions{
+		TaskQueue: api.WorkerTaskQueueName,
+	}
+	ctx = workflow.WithChildOptions(ctx, childWorkflowOptions)
+
+	// Execute child workflow
+	var res api.GetBacktestStrategyParametersWorkflowResults
+	err := workflow.ExecuteChildWorkflow(ctx, api.GetBacktestStrategyParametersWorkflowName, params).Get(ctx, &res)
+	if err != nil {
+		return api.GetBacktestStrategyParametersWorkflowResults{}, DecodeError(err)
 	}
 
 	return res, nil
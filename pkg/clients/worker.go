@@ -0,0 +1,17 @@
+package clients
+
+import (
+	"github.com/cryptellation/backtests/pkg/tenant"
+	temporalclient "go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/worker"
+)
+
+// NewWorker creates a worker running the callback workflows of backtests on the
+// task queue. The callbacks keep the tenant of their backtest and send it with
+// their calls to the service, even if the client has been created without the
+// tenant context propagator.
+func NewWorker(cl temporalclient.Client, taskQueue string, options worker.Options) worker.Worker {
+	options.Interceptors = append([]interceptor.WorkerInterceptor{tenant.NewWorkerInterceptor()}, options.Interceptors...)
+	return worker.New(cl, taskQueue, options)
+}
@@ -0,0 +1,100 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidFormat is returned when the export format is invalid.
+	ErrInvalidFormat = errors.New("invalid export format")
+	// ErrInvalidDataset is returned when the exported dataset is invalid.
+	ErrInvalidDataset = errors.New("invalid export dataset")
+)
+
+// Format is the file format of an export.
+type Format string
+
+const (
+	// FormatCSV is the comma-separated values format, with a header line.
+	FormatCSV Format = "csv"
+	// FormatJSONL is the JSON Lines format, with one JSON object per row.
+	FormatJSONL Format = "jsonl"
+	// FormatParquet is the Apache Parquet columnar format.
+	FormatParquet Format = "parquet"
+)
+
+// Formats are the available export formats.
+var Formats = []Format{FormatCSV, FormatJSONL, FormatParquet}
+
+// Validate will validate the export format.
+func (f Format) Validate() error {
+	switch f {
+	case FormatCSV, FormatJSONL, FormatParquet:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidFormat, f)
+	}
+}
+
+// String will return the string representation of the export format.
+func (f Format) String() string {
+	return string(f)
+}
+
+// Dataset is a set of rows exported from a backtest.
+type Dataset string
+
+const (
+	// DatasetOrders are the orders of the backtest.
+	DatasetOrders Dataset = "orders"
+	// DatasetBalances are the balances of the backtest accounts at the start
+	// and after each order.
+	DatasetBalances Dataset = "balances"
+	// DatasetEquity is the equity of the backtest accounts at the start,
+	// after each order and at the current time, valued in a quote asset.
+	DatasetEquity Dataset = "equity"
+)
+
+// Datasets are the available datasets.
+var Datasets = []Dataset{DatasetOrders, DatasetBalances, DatasetEquity}
+
+// Validate will validate the dataset.
+func (d Dataset) Validate() error {
+	switch d {
+	case DatasetOrders, DatasetBalances, DatasetEquity:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidDataset, d)
+	}
+}
+
+// String will return the string representation of the dataset.
+func (d Dataset) String() string {
+	return string(d)
+}
+
+// Options are the options of an export.
+type Options struct {
+	Format Format
+	// Gzip compresses the files. CSV and JSON Lines files are compressed as a
+	// whole while Parquet files have their columns compressed, in order to stay
+	// readable by Parquet readers.
+	Gzip bool
+}
+
+// Validate will validate the export options.
+func (o Options) Validate() error {
+	return o.Format.Validate()
+}
+
+// FileName returns the name of the file containing a dataset of a backtest.
+func (o Options) FileName(backtestID uuid.UUID, d Dataset) string {
+	name := fmt.Sprintf("%s-%s.%s", backtestID.String(), d, o.Format)
+	if o.Gzip && o.Format != FormatParquet {
+		name += ".gz"
+	}
+	return name
+}
@@ -0,0 +1,154 @@
+//go:build unit
+// +build unit
+
+package export
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/runtime/account"
+	"github.com/cryptellation/runtime/order"
+	"github.com/google/uuid"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestExportSuite(t *testing.T) {
+	suite.Run(t, new(ExportSuite))
+}
+
+type ExportSuite struct {
+	suite.Suite
+}
+
+func (suite *ExportSuite) newBacktest() backtest.Backtest {
+	buyTime, sellTime := time.Unix(60, 0).UTC(), time.Unix(120, 0).UTC()
+	return backtest.Backtest{
+		ID:        uuid.MustParse("3c5d4a6e-7e8f-4a3b-9c1d-2e4f6a8b0c1d"),
+		StartTime: time.Unix(0, 0).UTC(),
+		EndTime:   time.Unix(600, 0).UTC(),
+		CurrentCandlestick: backtest.CurrentCandlestick{
+			Time: time.Unix(600, 0).UTC(),
+		},
+		Accounts: map[string]account.Account{
+			"exchange": {Balances: map[string]float64{"USDT": 850, "BTC": 1}},
+		},
+		Orders: []order.Order{
+			{
+				ID:            uuid.MustParse("11111111-1111-1111-1111-111111111111"),
+				ExecutionTime: &buyTime, Type: order.TypeIsMarket, Exchange: "exchange",
+				Pair: "BTC-USDT", Side: order.SideIsBuy, Quantity: 2, Price: 100,
+			},
+			{
+				ID:            uuid.MustParse("22222222-2222-2222-2222-222222222222"),
+				ExecutionTime: &sellTime, Type: order.TypeIsMarket, Exchange: "exchange",
+				Pair: "BTC-USDT", Side: order.SideIsSell, Quantity: 1, Price: 50,
+			},
+		},
+	}
+}
+
+func (suite *ExportSuite) TestValidate() {
+	for _, f := range Formats {
+		suite.Require().NoError(f.Validate())
+	}
+	suite.Require().ErrorIs(Format("xlsx").Validate(), ErrInvalidFormat)
+
+	for _, d := range Datasets {
+		suite.Require().NoError(d.Validate())
+	}
+	suite.Require().ErrorIs(Dataset("trades").Validate(), ErrInvalidDataset)
+}
+
+func (suite *ExportSuite) TestFileName() {
+	id := suite.newBacktest().ID
+
+	suite.Require().Equal(id.String()+"-orders.csv",
+		Options{Format: FormatCSV}.FileName(id, DatasetOrders))
+	suite.Require().Equal(id.String()+"-equity.jsonl.gz",
+		Options{Format: FormatJSONL, Gzip: true}.FileName(id, DatasetEquity))
+	suite.Require().Equal(id.String()+"-balances.parquet",
+		Options{Format: FormatParquet, Gzip: true}.FileName(id, DatasetBalances))
+}
+
+func (suite *ExportSuite) TestBalanceRows() {
+	rows, err := BalanceRows(suite.newBacktest())
+	suite.Require().NoError(err)
+	suite.Require().Equal([]BalanceRow{
+		{Time: time.Unix(0, 0).UTC(), Exchange: "exchange", Asset: "BTC", Balance: 0},
+		{Time: time.Unix(0, 0).UTC(), Exchange: "exchange", Asset: "USDT", Balance: 1000},
+		{Time: time.Unix(60, 0).UTC(), Exchange: "exchange", Asset: "BTC", Balance: 2},
+		{Time: time.Unix(60, 0).UTC(), Exchange: "exchange", Asset: "USDT", Balance: 800},
+		{Time: time.Unix(120, 0).UTC(), Exchange: "exchange", Asset: "BTC", Balance: 1},
+		{Time: time.Unix(120, 0).UTC(), Exchange: "exchange", Asset: "USDT", Balance: 850},
+	}, rows)
+}
+
+func (suite *ExportSuite) TestEquityRows() {
+	rows, err := EquityRows(suite.newBacktest(), "USDT", nil)
+	suite.Require().NoError(err)
+	suite.Require().Len(rows, 4)
+	suite.Require().Equal(EquityRow{Time: time.Unix(0, 0).UTC(), QuoteAsset: "USDT", Equity: 1000}, rows[0])
+
+	_, err = EquityRows(suite.newBacktest(), "", nil)
+	suite.Require().ErrorIs(err, backtest.ErrInvalidQuoteAsset)
+}
+
+func (suite *ExportSuite) TestWriteCSV() {
+	var buf bytes.Buffer
+	err := Write(&buf, OrderRows(suite.newBacktest()), Options{Format: FormatCSV})
+	suite.Require().NoError(err)
+	suite.Require().Equal(
+		"id,execution_time,type,exchange,pair,side,quantity,price\n"+
+			"11111111-1111-1111-1111-111111111111,1970-01-01T00:01:00Z,market,exchange,BTC-USDT,buy,2,100\n"+
+			"22222222-2222-2222-2222-222222222222,1970-01-01T00:02:00Z,market,exchange,BTC-USDT,sell,1,50\n",
+		buf.String())
+}
+
+func (suite *ExportSuite) TestWriteCSVEmpty() {
+	var buf bytes.Buffer
+	suite.Require().NoError(Write(&buf, []EquityRow{}, Options{Format: FormatCSV}))
+	suite.Require().Equal("time,quote_asset,equity\n", buf.String())
+}
+
+func (suite *ExportSuite) TestWriteJSONLGzip() {
+	rows, err := EquityRows(suite.newBacktest(), "USDT", nil)
+	suite.Require().NoError(err)
+
+	var buf bytes.Buffer
+	suite.Require().NoError(Write(&buf, rows[:2], Options{Format: FormatJSONL, Gzip: true}))
+
+	gz, err := gzip.NewReader(&buf)
+	suite.Require().NoError(err)
+	content, err := io.ReadAll(gz)
+	suite.Require().NoError(err)
+	suite.Require().Equal(
+		`{"time":"1970-01-01T00:00:00Z","quote_asset":"USDT","equity":1000}`+"\n"+
+			`{"time":"1970-01-01T00:01:00Z","quote_asset":"USDT","equity":1000}`+"\n",
+		string(content))
+}
+
+func (suite *ExportSuite) TestWriteParquet() {
+	rows := OrderRows(suite.newBacktest())
+	rows[1].ExecutionTime = nil
+
+	for _, compressed := range []bool{false, true} {
+		var buf bytes.Buffer
+		suite.Require().NoError(Write(&buf, rows, Options{Format: FormatParquet, Gzip: compressed}))
+
+		read, err := parquet.Read[OrderRow](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		suite.Require().NoError(err)
+		suite.Require().Equal(rows, read)
+	}
+}
+
+func (suite *ExportSuite) TestWriteInvalidFormat() {
+	var buf bytes.Buffer
+	err := Write(&buf, []EquityRow{}, Options{Format: "xlsx"})
+	suite.Require().ErrorIs(err, ErrInvalidFormat)
+}
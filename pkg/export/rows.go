@@ -0,0 +1,173 @@
+package export
+
+import (
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/cryptellation/backtests/pkg/backtest"
+)
+
+// Row is a row of an exported dataset. The columns of the rows are fixed, in
+// order to keep the same schema between exports.
+type Row interface {
+	// Header returns the names of the columns.
+	Header() []string
+	// Record returns the values of the columns, formatted as strings.
+	Record() []string
+}
+
+// OrderRow is a row of the orders dataset.
+type OrderRow struct {
+	ID            string     `json:"id" parquet:"id"`
+	ExecutionTime *time.Time `json:"execution_time" parquet:"execution_time,optional"`
+	Type          string     `json:"type" parquet:"type"`
+	Exchange      string     `json:"exchange" parquet:"exchange"`
+	Pair          string     `json:"pair" parquet:"pair"`
+	Side          string     `json:"side" parquet:"side"`
+	Quantity      float64    `json:"quantity" parquet:"quantity"`
+	Price         float64    `json:"price" parquet:"price"`
+}
+
+// Header returns the names of the columns.
+func (OrderRow) Header() []string {
+	return []string{"id", "execution_time", "type", "exchange", "pair", "side", "quantity", "price"}
+}
+
+// Record returns the values of the columns, formatted as strings.
+func (r OrderRow) Record() []string {
+	executionTime := ""
+	if r.ExecutionTime != nil {
+		executionTime = formatTime(*r.ExecutionTime)
+	}
+
+	return []string{
+		r.ID, executionTime, r.Type, r.Exchange, r.Pair, r.Side,
+		formatFloat(r.Quantity), formatFloat(r.Price),
+	}
+}
+
+// OrderRows returns the rows of the orders dataset of the backtest.
+func OrderRows(bt backtest.Backtest) []OrderRow {
+	rows := make([]OrderRow, len(bt.Orders))
+	for i, o := range bt.Orders {
+		var executionTime *time.Time
+		if o.ExecutionTime != nil {
+			t := o.ExecutionTime.UTC()
+			executionTime = &t
+		}
+
+		rows[i] = OrderRow{
+			ID:            o.ID.String(),
+			ExecutionTime: executionTime,
+			Type:          o.Type.String(),
+			Exchange:      o.Exchange,
+			Pair:          o.Pair,
+			Side:          o.Side.String(),
+			Quantity:      o.Quantity,
+			Price:         o.Price,
+		}
+	}
+	return rows
+}
+
+// BalanceRow is a row of the balances dataset.
+type BalanceRow struct {
+	Time     time.Time `json:"time" parquet:"time"`
+	Exchange string    `json:"exchange" parquet:"exchange"`
+	Asset    string    `json:"asset" parquet:"asset"`
+	Balance  float64   `json:"balance" parquet:"balance"`
+}
+
+// Header returns the names of the columns.
+func (BalanceRow) Header() []string {
+	return []string{"time", "exchange", "asset", "balance"}
+}
+
+// Record returns the values of the columns, formatted as strings.
+func (r BalanceRow) Record() []string {
+	return []string{formatTime(r.Time), r.Exchange, r.Asset, formatFloat(r.Balance)}
+}
+
+// BalanceRows returns the rows of the balances dataset of the backtest, with
+// one row per exchange and asset at the start and after each order. Rows with
+// the same time are sorted by exchange and asset.
+func BalanceRows(bt backtest.Backtest) ([]BalanceRow, error) {
+	history, err := bt.AccountsHistory()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]BalanceRow, 0)
+	for _, p := range history {
+		exchanges := make([]string, 0, len(p.Accounts))
+		for exchange := range p.Accounts {
+			exchanges = append(exchanges, exchange)
+		}
+		slices.Sort(exchanges)
+
+		for _, exchange := range exchanges {
+			balances := p.Accounts[exchange].Balances
+			assets := make([]string, 0, len(balances))
+			for asset := range balances {
+				assets = append(assets, asset)
+			}
+			slices.Sort(assets)
+
+			for _, asset := range assets {
+				rows = append(rows, BalanceRow{
+					Time:     p.Time.UTC(),
+					Exchange: exchange,
+					Asset:    asset,
+					Balance:  balances[asset],
+				})
+			}
+		}
+	}
+
+	return rows, nil
+}
+
+// EquityRow is a row of the equity dataset.
+type EquityRow struct {
+	Time       time.Time `json:"time" parquet:"time"`
+	QuoteAsset string    `json:"quote_asset" parquet:"quote_asset"`
+	Equity     float64   `json:"equity" parquet:"equity"`
+}
+
+// Header returns the names of the columns.
+func (EquityRow) Header() []string {
+	return []string{"time", "quote_asset", "equity"}
+}
+
+// Record returns the values of the columns, formatted as strings.
+func (r EquityRow) Record() []string {
+	return []string{formatTime(r.Time), r.QuoteAsset, formatFloat(r.Equity)}
+}
+
+// EquityRows returns the rows of the equity dataset of the backtest, valued
+// in the quote asset with the open positions at their end price.
+func EquityRows(bt backtest.Backtest, quoteAsset string, endPrices map[string]float64) ([]EquityRow, error) {
+	curve, err := bt.EquityCurve(quoteAsset, endPrices)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]EquityRow, len(curve))
+	for i, p := range curve {
+		rows[i] = EquityRow{
+			Time:       p.Time.UTC(),
+			QuoteAsset: quoteAsset,
+			Equity:     p.Equity,
+		}
+	}
+	return rows, nil
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,84 @@
+package export
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Write writes the rows to w in the format of the options.
+func Write[T Row](w io.Writer, rows []T, opts Options) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	if opts.Format == FormatParquet {
+		return writeParquet(w, rows, opts.Gzip)
+	}
+
+	// Compress the whole file
+	if opts.Gzip {
+		gz := gzip.NewWriter(w)
+		if err := write(gz, rows, opts.Format); err != nil {
+			return err
+		}
+		return gz.Close()
+	}
+
+	return write(w, rows, opts.Format)
+}
+
+func write[T Row](w io.Writer, rows []T, format Format) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, rows)
+	case FormatJSONL:
+		return writeJSONL(w, rows)
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidFormat, format)
+	}
+}
+
+func writeCSV[T Row](w io.Writer, rows []T) error {
+	var header T
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header.Header()); err != nil {
+		return fmt.Errorf("writing csv header: %w", err)
+	}
+
+	for _, r := range rows {
+		if err := cw.Write(r.Record()); err != nil {
+			return fmt.Errorf("writing csv record: %w", err)
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeJSONL[T Row](w io.Writer, rows []T) error {
+	enc := json.NewEncoder(w)
+	for _, r := range rows {
+		if err := enc.Encode(r); err != nil {
+			return fmt.Errorf("writing json line: %w", err)
+		}
+	}
+	return nil
+}
+
+func writeParquet[T Row](w io.Writer, rows []T, compressed bool) error {
+	options := make([]parquet.WriterOption, 0, 1)
+	if compressed {
+		options = append(options, parquet.Compression(&parquet.Gzip))
+	}
+
+	pw := parquet.NewGenericWriter[T](w, options...)
+	if _, err := pw.Write(rows); err != nil {
+		return fmt.Errorf("writing parquet rows: %w", err)
+	}
+	return pw.Close()
+}
@@ -0,0 +1,243 @@
+package montecarlo
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+
+	"github.com/cryptellation/backtests/pkg/backtest"
+)
+
+var (
+	// ErrInvalidMethod is returned when the resampling method is invalid.
+	ErrInvalidMethod = errors.New("invalid resampling method")
+	// ErrInvalidParameters is returned when the simulation parameters are invalid.
+	ErrInvalidParameters = errors.New("invalid monte carlo parameters")
+	// ErrNoTrade is returned when there is no trade to resample.
+	ErrNoTrade = errors.New("no trade")
+)
+
+const (
+	// DefaultSimulations is the default count of simulated equity paths.
+	DefaultSimulations = 1000
+	// MaxSimulations is the maximum count of simulated equity paths.
+	MaxSimulations = 100000
+	// DefaultSkipProbability is the default probability to skip a trade with
+	// the random skip method.
+	DefaultSkipProbability = 0.1
+	// DefaultRuinThreshold is the default loss, relative to the initial equity,
+	// from which a path is considered ruined.
+	DefaultRuinThreshold = 0.5
+)
+
+// Method is the method used to resample the trades.
+type Method string
+
+const (
+	// MethodShuffle changes the order of the trades.
+	MethodShuffle Method = "shuffle"
+	// MethodBootstrap draws the trades with replacement.
+	MethodBootstrap Method = "bootstrap"
+	// MethodRandomSkip skips each trade with a probability.
+	MethodRandomSkip Method = "random_skip"
+)
+
+// Validate will validate the resampling method.
+func (m Method) Validate() error {
+	switch m {
+	case MethodShuffle, MethodBootstrap, MethodRandomSkip:
+		return nil
+	default:
+		return ErrInvalidMethod
+	}
+}
+
+// String will return the string representation of the resampling method.
+func (m Method) String() string {
+	return string(m)
+}
+
+// Parameters are the parameters of a Monte Carlo simulation.
+type Parameters struct {
+	Method Method `json:"method"`
+	// Simulations is the count of simulated equity paths.
+	Simulations int `json:"simulations"`
+	// Seed is the seed of the random generator, in order to get the same
+	// results for the same parameters.
+	Seed int64 `json:"seed"`
+	// SkipProbability is the probability to skip a trade with the random skip
+	// method. It is DefaultSkipProbability if nil, so that 0 can be set.
+	SkipProbability *float64 `json:"skip_probability,omitempty"`
+	// RuinThreshold is the loss, relative to the initial equity, from which a
+	// path is considered ruined.
+	RuinThreshold float64 `json:"ruin_threshold"`
+}
+
+// EmptyFieldsToDefault sets empty fields to default values.
+func (p *Parameters) EmptyFieldsToDefault() *Parameters {
+	if p.Method == "" {
+		p.Method = MethodShuffle
+	}
+
+	if p.Simulations == 0 {
+		p.Simulations = DefaultSimulations
+	}
+
+	if p.SkipProbability == nil {
+		skip := DefaultSkipProbability
+		p.SkipProbability = &skip
+	}
+
+	if p.RuinThreshold == 0 {
+		p.RuinThreshold = DefaultRuinThreshold
+	}
+
+	return p
+}
+
+// Validate validates the simulation parameters.
+func (p Parameters) Validate() error {
+	if err := p.Method.Validate(); err != nil {
+		return fmt.Errorf("%w: %q", err, p.Method)
+	}
+
+	switch {
+	case p.Simulations <= 0 || p.Simulations > MaxSimulations:
+		return fmt.Errorf("%w: simulations should be between 1 and %d", ErrInvalidParameters, MaxSimulations)
+	case p.SkipProbability != nil && (*p.SkipProbability < 0 || *p.SkipProbability >= 1):
+		return fmt.Errorf("%w: skip probability should be in [0, 1)", ErrInvalidParameters)
+	case p.RuinThreshold <= 0 || p.RuinThreshold > 1:
+		return fmt.Errorf("%w: ruin threshold should be in (0, 1]", ErrInvalidParameters)
+	}
+
+	return nil
+}
+
+// Bands are percentiles of a simulated value.
+type Bands struct {
+	P5  float64 `json:"p5"`
+	P25 float64 `json:"p25"`
+	P50 float64 `json:"p50"`
+	P75 float64 `json:"p75"`
+	P95 float64 `json:"p95"`
+}
+
+// NewBands computes the percentiles of the values.
+func NewBands(values []float64) Bands {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	return Bands{
+		P5:  percentile(sorted, 5),
+		P25: percentile(sorted, 25),
+		P50: percentile(sorted, 50),
+		P75: percentile(sorted, 75),
+		P95: percentile(sorted, 95),
+	}
+}
+
+// percentile returns the percentile of sorted values, with linear interpolation.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := p / 100 * float64(len(sorted)-1)
+	low := int(rank)
+	if low+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[low] + (rank-float64(low))*(sorted[low+1]-sorted[low])
+}
+
+// Report is the result of a Monte Carlo simulation.
+type Report struct {
+	Parameters    Parameters `json:"parameters"`
+	TradesCount   int        `json:"trades_count"`
+	InitialEquity float64    `json:"initial_equity"`
+	FinalEquity   Bands      `json:"final_equity"`
+	MaxDrawdown   Bands      `json:"max_drawdown"`
+	// RiskOfRuin is the ratio of paths where the equity reached the ruin threshold.
+	RiskOfRuin float64 `json:"risk_of_ruin"`
+}
+
+// TradeReturns returns the relative equity changes between the consecutive
+// points of an equity curve. Points that do not change the equity, like the
+// opening of a position, are not considered as trades.
+func TradeReturns(curve []backtest.EquityPoint) []float64 {
+	returns := make([]float64, 0, len(curve))
+	for i := 1; i < len(curve); i++ {
+		previous, current := curve[i-1].Equity, curve[i].Equity
+		if previous == 0 || current == previous {
+			continue
+		}
+		returns = append(returns, current/previous-1)
+	}
+	return returns
+}
+
+// Simulate resamples the trade returns to simulate equity paths from the
+// initial equity, then reports the distribution of their outcomes.
+func Simulate(initialEquity float64, returns []float64, params Parameters) (Report, error) {
+	if err := params.EmptyFieldsToDefault().Validate(); err != nil {
+		return Report{}, err
+	} else if len(returns) == 0 {
+		return Report{}, ErrNoTrade
+	}
+
+	rnd := rand.New(rand.NewSource(params.Seed))
+	finals := make([]float64, params.Simulations)
+	drawdowns := make([]float64, params.Simulations)
+	ruinEquity := initialEquity * (1 - params.RuinThreshold)
+	ruined := 0
+	path := make([]float64, 0, len(returns))
+	for i := range params.Simulations {
+		path = resample(rnd, returns, params, path[:0])
+
+		// Compute the equity path
+		equity, peak, minEquity := initialEquity, initialEquity, initialEquity
+		for _, r := range path {
+			equity *= 1 + r
+			peak = max(peak, equity)
+			minEquity = min(minEquity, equity)
+			if peak > 0 {
+				drawdowns[i] = max(drawdowns[i], (peak-equity)/peak)
+			}
+		}
+		finals[i] = equity
+
+		if minEquity <= ruinEquity {
+			ruined++
+		}
+	}
+
+	return Report{
+		Parameters:    params,
+		TradesCount:   len(returns),
+		InitialEquity: initialEquity,
+		FinalEquity:   NewBands(finals),
+		MaxDrawdown:   NewBands(drawdowns),
+		RiskOfRuin:    float64(ruined) / float64(params.Simulations),
+	}, nil
+}
+
+func resample(rnd *rand.Rand, returns []float64, params Parameters, path []float64) []float64 {
+	switch params.Method {
+	case MethodBootstrap:
+		for range returns {
+			path = append(path, returns[rnd.Intn(len(returns))])
+		}
+	case MethodRandomSkip:
+		for _, r := range returns {
+			if rnd.Float64() >= *params.SkipProbability {
+				path = append(path, r)
+			}
+		}
+	default:
+		path = append(path, returns...)
+		rnd.Shuffle(len(path), func(i, j int) {
+			path[i], path[j] = path[j], path[i]
+		})
+	}
+	return path
+}
@@ -0,0 +1,109 @@
+//go:build unit
+// +build unit
+
+package montecarlo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestMonteCarloSuite(t *testing.T) {
+	suite.Run(t, new(MonteCarloSuite))
+}
+
+type MonteCarloSuite struct {
+	suite.Suite
+}
+
+func (suite *MonteCarloSuite) TestTradeReturns() {
+	curve := []backtest.EquityPoint{
+		{Time: time.Unix(0, 0), Equity: 100},
+		{Time: time.Unix(60, 0), Equity: 100},
+		{Time: time.Unix(120, 0), Equity: 110},
+		{Time: time.Unix(180, 0), Equity: 99},
+	}
+
+	returns := TradeReturns(curve)
+	suite.Require().Len(returns, 2)
+	suite.Require().InDelta(0.1, returns[0], 1e-9)
+	suite.Require().InDelta(-0.1, returns[1], 1e-9)
+}
+
+func (suite *MonteCarloSuite) TestNewBands() {
+	values := make([]float64, 101)
+	for i := range values {
+		values[i] = float64(100 - i)
+	}
+
+	suite.Require().Equal(Bands{P5: 5, P25: 25, P50: 50, P75: 75, P95: 95}, NewBands(values))
+}
+
+func (suite *MonteCarloSuite) TestSimulateShuffle() {
+	returns := []float64{0.1, -0.5, 0.2, -0.1}
+
+	report, err := Simulate(100, returns, Parameters{Seed: 1})
+	suite.Require().NoError(err)
+	suite.Require().Equal(MethodShuffle, report.Parameters.Method)
+	suite.Require().Equal(DefaultSimulations, report.Parameters.Simulations)
+	suite.Require().Equal(4, report.TradesCount)
+
+	// Shuffling does not change the final equity, only the path
+	suite.Require().InDelta(59.4, report.FinalEquity.P5, 1e-9)
+	suite.Require().InDelta(59.4, report.FinalEquity.P95, 1e-9)
+	suite.Require().GreaterOrEqual(report.MaxDrawdown.P95, report.MaxDrawdown.P5)
+	suite.Require().GreaterOrEqual(report.MaxDrawdown.P5, 0.5)
+	suite.Require().Greater(report.RiskOfRuin, 0.0)
+
+	// Same seed should give the same report
+	again, err := Simulate(100, returns, Parameters{Seed: 1})
+	suite.Require().NoError(err)
+	suite.Require().Equal(report, again)
+}
+
+func (suite *MonteCarloSuite) TestSimulateBootstrapAndSkip() {
+	returns := []float64{0.1, -0.05, 0.2, -0.1}
+
+	for _, m := range []Method{MethodBootstrap, MethodRandomSkip} {
+		report, err := Simulate(100, returns, Parameters{Method: m, Simulations: 500, Seed: 2})
+		suite.Require().NoError(err, m)
+		suite.Require().LessOrEqual(report.FinalEquity.P5, report.FinalEquity.P50, m)
+		suite.Require().LessOrEqual(report.FinalEquity.P50, report.FinalEquity.P95, m)
+		suite.Require().Less(report.FinalEquity.P5, report.FinalEquity.P95, m)
+	}
+}
+
+func (suite *MonteCarloSuite) TestSimulateSkipProbability() {
+	returns := []float64{0.1, -0.05, 0.2, -0.1}
+
+	// No trade is skipped with an explicit zero probability
+	zero := 0.0
+	report, err := Simulate(100, returns, Parameters{Method: MethodRandomSkip, SkipProbability: &zero})
+	suite.Require().NoError(err)
+	suite.Require().Equal(zero, *report.Parameters.SkipProbability)
+	suite.Require().InDelta(report.FinalEquity.P5, report.FinalEquity.P95, 1e-9)
+
+	// The default probability is used when it is not set
+	report, err = Simulate(100, returns, Parameters{Method: MethodRandomSkip})
+	suite.Require().NoError(err)
+	suite.Require().Equal(DefaultSkipProbability, *report.Parameters.SkipProbability)
+	suite.Require().Less(report.FinalEquity.P5, report.FinalEquity.P95)
+
+	one := 1.0
+	_, err = Simulate(100, returns, Parameters{Method: MethodRandomSkip, SkipProbability: &one})
+	suite.Require().ErrorIs(err, ErrInvalidParameters)
+}
+
+func (suite *MonteCarloSuite) TestSimulateInvalid() {
+	_, err := Simulate(100, nil, Parameters{})
+	suite.Require().ErrorIs(err, ErrNoTrade)
+
+	_, err = Simulate(100, []float64{0.1}, Parameters{Method: "unknown"})
+	suite.Require().ErrorIs(err, ErrInvalidMethod)
+
+	_, err = Simulate(100, []float64{0.1}, Parameters{Simulations: MaxSimulations + 1})
+	suite.Require().ErrorIs(err, ErrInvalidParameters)
+}
@@ -0,0 +1,171 @@
+// Package quota limits the backtests running at the same time for each tenant.
+// The runs exceeding the limits are queued and started in FIFO order.
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidLimits is returned when the limits of a quota are invalid.
+	ErrInvalidLimits = errors.New("invalid quota limits")
+	// ErrExceeded is returned when a run alone exceeds the limits of the quota,
+	// so it could never be started.
+	ErrExceeded = errors.New("quota exceeded")
+	// ErrWaitTimeout is returned when a run waited for a slot longer than the
+	// maximum wait time of the quota.
+	ErrWaitTimeout = errors.New("quota wait timed out")
+)
+
+// Limits are the limits of the backtests running at the same time for a
+// tenant. A zero limit is no limit.
+type Limits struct {
+	// MaxRunningBacktests is the maximum count of running backtests.
+	MaxRunningBacktests int `json:"max_running_backtests"`
+	// MaxRunningSteps is the maximum total of steps left to simulate by the
+	// running backtests.
+	MaxRunningSteps int64 `json:"max_running_steps"`
+	// MaxWaitTime is the maximum time a run waits for a slot before failing
+	// with ErrWaitTimeout.
+	MaxWaitTime time.Duration `json:"max_wait_time"`
+}
+
+// Validate validates the limits.
+func (l Limits) Validate() error {
+	if l.MaxRunningBacktests < 0 || l.MaxRunningSteps < 0 || l.MaxWaitTime < 0 {
+		return fmt.Errorf("%w: negative limit", ErrInvalidLimits)
+	}
+	return nil
+}
+
+// Allows returns an error if a run of this count of steps would exceed the
+// limits, even without any other running backtest.
+func (l Limits) Allows(steps int64) error {
+	if l.MaxRunningSteps > 0 && steps > l.MaxRunningSteps {
+		return fmt.Errorf("%w: %d steps to simulate for a maximum of %d", ErrExceeded, steps, l.MaxRunningSteps)
+	}
+	return nil
+}
+
+// Run is a backtest run holding or waiting for a slot of the quota.
+type Run struct {
+	// WorkflowID is the ID of the workflow running the backtest, that is
+	// notified when the run can start.
+	WorkflowID  string    `json:"workflow_id"`
+	BacktestID  uuid.UUID `json:"backtest_id"`
+	Steps       int64     `json:"steps"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// State is the state of the quota of a tenant.
+type State struct {
+	Limits Limits `json:"limits"`
+	// Running are the runs holding a slot of the quota.
+	Running []Run `json:"running"`
+	// Queue are the runs waiting for a slot, in order of request.
+	Queue []Run `json:"queue"`
+}
+
+// Request queues the run until it fits in the quota. Requesting again for a
+// run holding or waiting for a slot has no effect.
+func (s *State) Request(r Run) error {
+	if err := s.Limits.Allows(r.Steps); err != nil {
+		return err
+	}
+
+	if !slices.ContainsFunc(s.Running, r.is) && !slices.ContainsFunc(s.Queue, r.is) {
+		s.Queue = append(s.Queue, r)
+	}
+	return nil
+}
+
+// Release frees the slot held by the run, or removes it from the queue. It
+// returns false if the run is unknown.
+func (s *State) Release(workflowID string) bool {
+	is := Run{WorkflowID: workflowID}.is
+	runningCount, queuedCount := len(s.Running), len(s.Queue)
+	s.Running = slices.DeleteFunc(s.Running, is)
+	s.Queue = slices.DeleteFunc(s.Queue, is)
+	return len(s.Running) < runningCount || len(s.Queue) < queuedCount
+}
+
+// Grant gives a slot to the queued runs fitting in the quota and returns them.
+// The queue is served in order: a run never starts before an older one, so
+// the big runs are not starved by the small ones.
+func (s *State) Grant() []Run {
+	var granted []Run
+	for len(s.Queue) > 0 && s.fits(s.Queue[0]) {
+		granted = append(granted, s.Queue[0])
+		s.Running = append(s.Running, s.Queue[0])
+		s.Queue = s.Queue[1:]
+	}
+	return granted
+}
+
+// WorkflowIDs returns the IDs of the workflows of the runs holding or waiting
+// for a slot.
+func (s State) WorkflowIDs() []string {
+	ids := make([]string, 0, len(s.Running)+len(s.Queue))
+	for _, r := range slices.Concat(s.Running, s.Queue) {
+		ids = append(ids, r.WorkflowID)
+	}
+	return ids
+}
+
+// Idle returns true if no run holds or waits for a slot.
+func (s State) Idle() bool {
+	return len(s.Running) == 0 && len(s.Queue) == 0
+}
+
+// Usage returns the usage of the quota.
+func (s State) Usage(tenant string) Usage {
+	return Usage{
+		Tenant:           tenant,
+		Limits:           s.Limits,
+		RunningBacktests: len(s.Running),
+		RunningSteps:     s.runningSteps(),
+		QueuedBacktests:  len(s.Queue),
+		Running:          slices.Clone(s.Running),
+		Queue:            slices.Clone(s.Queue),
+	}
+}
+
+func (s State) fits(r Run) bool {
+	if s.Limits.MaxRunningBacktests > 0 && len(s.Running) >= s.Limits.MaxRunningBacktests {
+		return false
+	}
+	if s.Limits.MaxRunningSteps > 0 && s.runningSteps()+r.Steps > s.Limits.MaxRunningSteps {
+		return false
+	}
+	return true
+}
+
+func (s State) runningSteps() int64 {
+	var steps int64
+	for _, r := range s.Running {
+		steps += r.Steps
+	}
+	return steps
+}
+
+// is returns true if the other run is from the same workflow.
+func (r Run) is(other Run) bool {
+	return r.WorkflowID == other.WorkflowID
+}
+
+// Usage is the current usage of the quota of a tenant.
+type Usage struct {
+	Tenant           string `json:"tenant"`
+	Limits           Limits `json:"limits"`
+	RunningBacktests int    `json:"running_backtests"`
+	RunningSteps     int64  `json:"running_steps"`
+	QueuedBacktests  int    `json:"queued_backtests"`
+	Running          []Run  `json:"running"`
+	// Queue are the queued runs, in the order they will start.
+	Queue []Run `json:"queue"`
+}
@@ -0,0 +1,123 @@
+//go:build unit
+// +build unit
+
+package quota
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestQuotaSuite(t *testing.T) {
+	suite.Run(t, new(QuotaSuite))
+}
+
+type QuotaSuite struct {
+	suite.Suite
+}
+
+func run(id string, steps int64) Run {
+	return Run{WorkflowID: id, BacktestID: uuid.New(), Steps: steps}
+}
+
+func workflowIDs(runs []Run) []string {
+	ids := make([]string, len(runs))
+	for i, r := range runs {
+		ids[i] = r.WorkflowID
+	}
+	return ids
+}
+
+func (suite *QuotaSuite) TestValidateLimits() {
+	suite.Require().NoError(Limits{}.Validate())
+	suite.Require().NoError(Limits{MaxRunningBacktests: 2, MaxRunningSteps: 100}.Validate())
+	suite.Require().ErrorIs(Limits{MaxRunningBacktests: -1}.Validate(), ErrInvalidLimits)
+	suite.Require().ErrorIs(Limits{MaxRunningSteps: -1}.Validate(), ErrInvalidLimits)
+	suite.Require().ErrorIs(Limits{MaxWaitTime: -time.Second}.Validate(), ErrInvalidLimits)
+}
+
+func (suite *QuotaSuite) TestMaxRunningBacktests() {
+	s := State{Limits: Limits{MaxRunningBacktests: 2}}
+	for _, id := range []string{"a", "b", "c"} {
+		suite.Require().NoError(s.Request(run(id, 10)))
+	}
+
+	// Only the first ones start, the other waits
+	suite.Require().Equal([]string{"a", "b"}, workflowIDs(s.Grant()))
+	suite.Require().Empty(s.Grant())
+	suite.Require().Equal([]string{"c"}, workflowIDs(s.Queue))
+
+	// It starts once a slot is released
+	suite.Require().True(s.Release("a"))
+	suite.Require().Equal([]string{"c"}, workflowIDs(s.Grant()))
+	suite.Require().Equal([]string{"b", "c"}, workflowIDs(s.Running))
+}
+
+func (suite *QuotaSuite) TestMaxRunningSteps() {
+	s := State{Limits: Limits{MaxRunningSteps: 100}}
+	suite.Require().NoError(s.Request(run("a", 60)))
+	suite.Require().NoError(s.Request(run("b", 60)))
+	suite.Require().NoError(s.Request(run("c", 10)))
+
+	// The queue is served in order, even if a later run would fit
+	suite.Require().Equal([]string{"a"}, workflowIDs(s.Grant()))
+	suite.Require().Equal([]string{"b", "c"}, workflowIDs(s.Queue))
+
+	suite.Require().True(s.Release("a"))
+	suite.Require().Equal([]string{"b", "c"}, workflowIDs(s.Grant()))
+
+	// A run exceeding the limit alone is refused
+	suite.Require().ErrorIs(s.Request(run("d", 101)), ErrExceeded)
+}
+
+func (suite *QuotaSuite) TestRequestTwice() {
+	s := State{Limits: Limits{MaxRunningBacktests: 1}}
+	suite.Require().NoError(s.Request(run("a", 10)))
+	suite.Require().Len(s.Grant(), 1)
+
+	suite.Require().NoError(s.Request(run("a", 10)))
+	suite.Require().Empty(s.Queue)
+	suite.Require().Len(s.Running, 1)
+}
+
+func (suite *QuotaSuite) TestReleaseQueued() {
+	s := State{Limits: Limits{MaxRunningBacktests: 1}}
+	suite.Require().NoError(s.Request(run("a", 10)))
+	suite.Require().NoError(s.Request(run("b", 10)))
+	s.Grant()
+
+	suite.Require().True(s.Release("b"))
+	suite.Require().Empty(s.Queue)
+	suite.Require().False(s.Release("b"))
+
+	suite.Require().True(s.Release("a"))
+	suite.Require().True(s.Idle())
+}
+
+func (suite *QuotaSuite) TestUsage() {
+	s := State{Limits: Limits{MaxRunningBacktests: 1}}
+	suite.Require().NoError(s.Request(run("a", 10)))
+	suite.Require().NoError(s.Request(run("b", 20)))
+	s.Grant()
+
+	u := s.Usage("tenant")
+	suite.Require().Equal("tenant", u.Tenant)
+	suite.Require().Equal(s.Limits, u.Limits)
+	suite.Require().Equal(1, u.RunningBacktests)
+	suite.Require().Equal(int64(10), u.RunningSteps)
+	suite.Require().Equal(1, u.QueuedBacktests)
+	suite.Require().Equal([]string{"b"}, workflowIDs(u.Queue))
+}
+
+func (suite *QuotaSuite) TestWorkflowIDs() {
+	s := State{Limits: Limits{MaxRunningBacktests: 1}}
+	suite.Require().Empty(s.WorkflowIDs())
+
+	suite.Require().NoError(s.Request(run("a", 10)))
+	suite.Require().NoError(s.Request(run("b", 10)))
+	s.Grant()
+	suite.Require().Equal([]string{"a", "b"}, s.WorkflowIDs())
+}
@@ -0,0 +1,202 @@
+(function () {
+  "use strict";
+
+  const SVG_NS = "http://www.w3.org/2000/svg";
+  const MARGIN = { top: 10, right: 60, bottom: 24, left: 10 };
+  const TICKS = 5;
+  // Colors of the styles, as SVG attributes do not support CSS variables
+  const COLORS = { positive: "#1a9850", negative: "#d73027", line: "#2c6fbb" };
+
+  function element(tag, attributes, parent) {
+    const e = document.createElementNS(SVG_NS, tag);
+    for (const [key, value] of Object.entries(attributes)) {
+      e.setAttribute(key, value);
+    }
+    if (parent) {
+      parent.appendChild(e);
+    }
+    return e;
+  }
+
+  function scale(domainMin, domainMax, rangeMin, rangeMax) {
+    const span = domainMax - domainMin || 1;
+    return (v) => rangeMin + ((v - domainMin) / span) * (rangeMax - rangeMin);
+  }
+
+  function formatNumber(v) {
+    return Math.abs(v) >= 1000 ? v.toFixed(0) : Number(v.toPrecision(6)).toString();
+  }
+
+  function formatPercent(v) {
+    return (v * 100).toFixed(2) + " %";
+  }
+
+  function formatDate(t) {
+    return new Date(t).toISOString().replace("T", " ").replace(":00.000Z", "");
+  }
+
+  // frame creates the SVG of a chart with its axes and returns the scales
+  // converting times and values to coordinates.
+  function frame(container, tMin, tMax, vMin, vMax, formatValue) {
+    const width = container.clientWidth || 800;
+    const height = container.clientHeight || 300;
+    const svg = element("svg", { viewBox: `0 0 ${width} ${height}` }, container);
+
+    const x = scale(tMin, tMax, MARGIN.left, width - MARGIN.right);
+    const y = scale(vMin, vMax, height - MARGIN.bottom, MARGIN.top);
+
+    for (let i = 0; i <= TICKS; i++) {
+      const v = vMin + ((vMax - vMin) * i) / TICKS;
+      element("line", { class: "grid", x1: MARGIN.left, x2: width - MARGIN.right, y1: y(v), y2: y(v) }, svg);
+      element("text", { class: "axis", x: width - MARGIN.right + 4, y: y(v) + 4 }, svg).textContent = formatValue(v);
+
+      const t = tMin + ((tMax - tMin) * i) / TICKS;
+      const label = element("text", { class: "axis", x: x(t), y: height - 6, "text-anchor": "middle" }, svg);
+      label.textContent = new Date(t).toISOString().slice(0, 10);
+    }
+
+    const tooltip = document.createElement("div");
+    tooltip.className = "tooltip";
+    container.appendChild(tooltip);
+
+    return { svg, x, y, tooltip };
+  }
+
+  // hover shows the tooltip of the nearest point to the mouse.
+  function hover(chart, times, describe) {
+    chart.svg.addEventListener("mousemove", (event) => {
+      const box = chart.svg.getBoundingClientRect();
+      const viewBox = chart.svg.viewBox.baseVal;
+      const mouseX = ((event.clientX - box.left) / box.width) * viewBox.width;
+
+      let nearest = 0;
+      for (let i = 1; i < times.length; i++) {
+        if (Math.abs(chart.x(times[i]) - mouseX) < Math.abs(chart.x(times[nearest]) - mouseX)) {
+          nearest = i;
+        }
+      }
+
+      chart.tooltip.textContent = describe(nearest);
+      chart.tooltip.style.display = "block";
+      chart.tooltip.style.left = event.clientX - box.left + 12 + "px";
+      chart.tooltip.style.top = event.clientY - box.top + 12 + "px";
+    });
+    chart.svg.addEventListener("mouseleave", () => {
+      chart.tooltip.style.display = "none";
+    });
+  }
+
+  function lineChart(container, points, options) {
+    if (!container || points.length === 0) {
+      return;
+    }
+
+    const times = points.map((p) => Date.parse(p.time));
+    const values = points.map(options.value);
+    const bounds = options.base === undefined ? values : [...values, options.base];
+    const vMin = Math.min(...bounds);
+    const vMax = Math.max(...bounds);
+    const chart = frame(container, times[0], times[times.length - 1], vMin, vMax, options.format);
+
+    // Draw steps, as values only change at the given times
+    let path = `M ${chart.x(times[0])} ${chart.y(values[0])}`;
+    for (let i = 1; i < points.length; i++) {
+      path += ` H ${chart.x(times[i])} V ${chart.y(values[i])}`;
+    }
+    if (options.base !== undefined) {
+      const base = chart.y(options.base);
+      element("path", {
+        d: `${path} V ${base} H ${chart.x(times[0])} Z`,
+        fill: options.color,
+        "fill-opacity": 0.2,
+        stroke: "none",
+      }, chart.svg);
+    }
+    element("path", { d: path, fill: "none", stroke: options.color, "stroke-width": 1.5 }, chart.svg);
+
+    hover(chart, times, (i) => `${formatDate(times[i])}\n${options.format(values[i])}`);
+  }
+
+  function candlestickChart(container, data) {
+    const candlesticks = data.candlesticks || [];
+    if (!container || candlesticks.length === 0) {
+      if (container) {
+        container.textContent = "No candlestick.";
+        container.className = "empty";
+      }
+      return;
+    }
+
+    const times = candlesticks.map((c) => Date.parse(c.time));
+    const lows = candlesticks.map((c) => c.low || 0);
+    const highs = candlesticks.map((c) => c.high || 0);
+    const orders = data.orders || [];
+    const prices = orders.map((o) => o.price);
+    const tMin = times[0];
+    const tMax = times[times.length - 1] + (times.length > 1 ? times[1] - times[0] : 0);
+    const chart = frame(container, tMin, tMax, Math.min(...lows, ...prices), Math.max(...highs, ...prices),
+      formatNumber);
+
+    // Draw candlesticks
+    const step = (chart.x(tMax) - chart.x(tMin)) / candlesticks.length;
+    const bodyWidth = Math.max(1, step * 0.7);
+    candlesticks.forEach((c, i) => {
+      const color = (c.close || 0) >= (c.open || 0) ? COLORS.positive : COLORS.negative;
+      const center = chart.x(times[i]) + step / 2;
+      element("line", { x1: center, x2: center, y1: chart.y(highs[i]), y2: chart.y(lows[i]), stroke: color },
+        chart.svg);
+
+      const top = chart.y(Math.max(c.open || 0, c.close || 0));
+      const bottom = chart.y(Math.min(c.open || 0, c.close || 0));
+      element("rect", {
+        x: center - bodyWidth / 2,
+        y: top,
+        width: bodyWidth,
+        height: Math.max(1, bottom - top),
+        fill: color,
+      }, chart.svg);
+    });
+
+    // Draw buy and sell markers
+    orders.forEach((o) => {
+      if (!o.execution_time) {
+        return;
+      }
+
+      const cx = chart.x(Date.parse(o.execution_time)) + step / 2;
+      const cy = chart.y(o.price);
+      const buy = o.side === "buy";
+      const points = buy
+        ? `${cx},${cy} ${cx - 6},${cy + 10} ${cx + 6},${cy + 10}`
+        : `${cx},${cy} ${cx - 6},${cy - 10} ${cx + 6},${cy - 10}`;
+      const marker = element("polygon", {
+        points,
+        fill: buy ? COLORS.positive : COLORS.negative,
+        stroke: "#fff",
+      }, chart.svg);
+      element("title", {}, marker).textContent =
+        `${o.side} ${formatNumber(o.quantity)} @ ${formatNumber(o.price)}\n${formatDate(o.execution_time)}`;
+    });
+
+    hover(chart, times, (i) => {
+      const c = candlesticks[i];
+      return `${formatDate(times[i])}\nO ${formatNumber(c.open || 0)}  H ${formatNumber(c.high || 0)}\n` +
+        `L ${formatNumber(c.low || 0)}  C ${formatNumber(c.close || 0)}`;
+    });
+  }
+
+  lineChart(document.getElementById("equity-chart"), report.equity_curve || [], {
+    value: (p) => p.equity,
+    format: formatNumber,
+    color: COLORS.line,
+  });
+  lineChart(document.getElementById("drawdown-chart"), report.drawdowns || [], {
+    value: (p) => -p.drawdown,
+    format: (v) => formatPercent(-v),
+    color: COLORS.negative,
+    base: 0,
+  });
+  (report.charts || []).forEach((c, i) => {
+    candlestickChart(document.getElementById(`price-chart-${i}`), c);
+  });
+})();
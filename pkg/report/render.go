@@ -0,0 +1,86 @@
+package report
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/cryptellation/backtests/pkg/backtest"
+)
+
+//go:embed templates/report.html.tmpl assets/report.css assets/report.js
+var files embed.FS
+
+var reportTemplate = template.Must(template.New("report.html.tmpl").Funcs(template.FuncMap{
+	"time":    formatTime,
+	"float":   formatFloat,
+	"percent": formatPercent,
+	"keys":    sortedKeys,
+}).ParseFS(files, "templates/report.html.tmpl"))
+
+// templateData is the data given to the report template.
+type templateData struct {
+	Report
+	CSS template.CSS
+	JS  template.JS
+	// Chart is the data drawn by the script, encoded as JSON by the template.
+	Chart chartData
+}
+
+type chartData struct {
+	EquityCurve []backtest.EquityPoint   `json:"equity_curve"`
+	Drawdowns   []backtest.DrawdownPoint `json:"drawdowns"`
+	Charts      []PriceChart             `json:"charts"`
+}
+
+// Render writes the report as a self-contained HTML page, with its styles,
+// scripts and data embedded, so it can be opened without any server.
+func Render(w io.Writer, r Report) error {
+	css, err := files.ReadFile("assets/report.css")
+	if err != nil {
+		return fmt.Errorf("reading report styles: %w", err)
+	}
+	js, err := files.ReadFile("assets/report.js")
+	if err != nil {
+		return fmt.Errorf("reading report script: %w", err)
+	}
+
+	return reportTemplate.Execute(w, templateData{
+		Report: r,
+		CSS:    template.CSS(css),
+		JS:     template.JS(js),
+		Chart: chartData{
+			EquityCurve: r.EquityCurve,
+			Drawdowns:   r.Drawdowns,
+			Charts:      r.Charts,
+		},
+	})
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func formatPercent(f float64) string {
+	return strconv.FormatFloat(f*100, 'f', 2, 64) + " %"
+}
+
+func sortedKeys(m any) []string {
+	switch v := m.(type) {
+	case map[string]any:
+		return slices.Sorted(maps.Keys(v))
+	case map[string]float64:
+		return slices.Sorted(maps.Keys(v))
+	default:
+		return nil
+	}
+}
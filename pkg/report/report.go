@@ -0,0 +1,122 @@
+package report
+
+import (
+	"slices"
+	"time"
+
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/candlesticks/pkg/candlestick"
+	"github.com/cryptellation/candlesticks/pkg/period"
+	"github.com/cryptellation/runtime/order"
+	"github.com/cryptellation/ticks/pkg/tick"
+)
+
+// MaxCandlesticks is the maximum count of candlesticks displayed on a price
+// chart, in order to keep the reports light.
+const MaxCandlesticks = 500
+
+// Trade is an order of the backtest, as displayed in the trade table.
+type Trade struct {
+	Order order.Order `json:"order"`
+	// Value is the value of the order, in the quote asset of its pair.
+	Value float64 `json:"value"`
+	// Equity is the equity of the backtest after the order, in the quote asset
+	// of the report.
+	Equity float64 `json:"equity"`
+}
+
+// PriceChart is the candlestick chart of a pair subscribed by the backtest,
+// with the orders made on it.
+type PriceChart struct {
+	Exchange     string                    `json:"exchange"`
+	Pair         string                    `json:"pair"`
+	Period       period.Symbol             `json:"period"`
+	Candlesticks []candlestick.Candlestick `json:"candlesticks"`
+	Orders       []order.Order             `json:"orders"`
+}
+
+// Report is the content of the report of a backtest.
+type Report struct {
+	Backtest    backtest.Backtest        `json:"backtest"`
+	Stats       backtest.Stats           `json:"stats"`
+	EquityCurve []backtest.EquityPoint   `json:"equity_curve"`
+	Drawdowns   []backtest.DrawdownPoint `json:"drawdowns"`
+	Trades      []Trade                  `json:"trades"`
+	Charts      []PriceChart             `json:"charts"`
+	// GeneratedAt is the time at which the report has been generated.
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// New creates the report of a backtest, valued in the quote asset with the
+// open positions at their end price, without its price charts.
+func New(
+	bt backtest.Backtest,
+	quoteAsset string,
+	endPrices map[string]float64,
+	generatedAt time.Time,
+) (Report, error) {
+	curve, err := bt.EquityCurve(quoteAsset, endPrices)
+	if err != nil {
+		return Report{}, err
+	}
+
+	// The first point of the curve is the start, then one per order
+	trades := make([]Trade, len(bt.Orders))
+	for i, o := range bt.Orders {
+		trades[i] = Trade{
+			Order:  o,
+			Value:  o.Quantity * o.Price,
+			Equity: curve[i+1].Equity,
+		}
+	}
+
+	return Report{
+		Backtest:    bt.WithoutSecrets(),
+		Stats:       backtest.NewStats(quoteAsset, curve, len(bt.Orders)),
+		EquityCurve: curve,
+		Drawdowns:   backtest.DrawdownCurve(curve),
+		Trades:      trades,
+		Charts:      make([]PriceChart, 0, len(bt.PricesSubscriptions)),
+		GeneratedAt: generatedAt,
+	}, nil
+}
+
+// AddChart adds the price chart of a subscription of the backtest, with the
+// orders made on its pair.
+func (r *Report) AddChart(sub tick.Subscription, p period.Symbol, candlesticks []candlestick.Candlestick) {
+	orders := make([]order.Order, 0)
+	for _, o := range r.Backtest.Orders {
+		if o.Exchange == sub.Exchange && o.Pair == sub.Pair {
+			orders = append(orders, o)
+		}
+	}
+
+	r.Charts = append(r.Charts, PriceChart{
+		Exchange:     sub.Exchange,
+		Pair:         sub.Pair,
+		Period:       p,
+		Candlesticks: candlesticks,
+		Orders:       orders,
+	})
+}
+
+// ChartPeriod returns the smallest period, not smaller than the price period
+// of the backtest, displaying the backtest with at most MaxCandlesticks.
+func ChartPeriod(bt backtest.Backtest) period.Symbol {
+	symbols := period.Symbols()
+	slices.SortFunc(symbols, func(a, b period.Symbol) int {
+		return int(a.Duration() - b.Duration())
+	})
+
+	for _, s := range symbols {
+		if s.Duration() < bt.PricePeriod.Duration() {
+			continue
+		}
+
+		if s.CountBetweenTimes(bt.StartTime, bt.CurrentCandlestick.Time) < MaxCandlesticks {
+			return s
+		}
+	}
+
+	return symbols[len(symbols)-1]
+}
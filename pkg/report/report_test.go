@@ -0,0 +1,141 @@
+//go:build unit
+// +build unit
+
+package report
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/candlesticks/pkg/candlestick"
+	"github.com/cryptellation/candlesticks/pkg/period"
+	"github.com/cryptellation/runtime/account"
+	"github.com/cryptellation/runtime/order"
+	"github.com/cryptellation/ticks/pkg/tick"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestReportSuite(t *testing.T) {
+	suite.Run(t, new(ReportSuite))
+}
+
+type ReportSuite struct {
+	suite.Suite
+}
+
+func (suite *ReportSuite) newBacktest() backtest.Backtest {
+	buyTime, sellTime := time.Unix(60, 0).UTC(), time.Unix(120, 0).UTC()
+	return backtest.Backtest{
+		ID:          uuid.New(),
+		Status:      backtest.StatusFinished,
+		StartTime:   time.Unix(0, 0).UTC(),
+		EndTime:     time.Unix(600, 0).UTC(),
+		PricePeriod: period.M1,
+		CurrentCandlestick: backtest.CurrentCandlestick{
+			Time: time.Unix(600, 0).UTC(),
+		},
+		Accounts: map[string]account.Account{
+			"exchange": {Balances: map[string]float64{"USDT": 850, "BTC": 1}},
+		},
+		PricesSubscriptions: []tick.Subscription{
+			{Exchange: "exchange", Pair: "BTC-USDT"},
+			{Exchange: "exchange", Pair: "ETH-USDT"},
+		},
+		Orders: []order.Order{
+			{
+				ExecutionTime: &buyTime, Type: order.TypeIsMarket, Exchange: "exchange",
+				Pair: "BTC-USDT", Side: order.SideIsBuy, Quantity: 2, Price: 100,
+			},
+			{
+				ExecutionTime: &sellTime, Type: order.TypeIsMarket, Exchange: "exchange",
+				Pair: "BTC-USDT", Side: order.SideIsSell, Quantity: 1, Price: 50,
+			},
+		},
+		StrategyParameters: map[string]any{"window": 14},
+		Tags:               []string{"<script>alert(1)</script>"},
+		Webhooks:           []backtest.Webhook{{URL: "https://example.com", Secret: "secret"}},
+	}
+}
+
+func (suite *ReportSuite) TestNew() {
+	r, err := New(suite.newBacktest(), "USDT", nil, time.Unix(1000, 0))
+	suite.Require().NoError(err)
+
+	suite.Require().Equal(float64(1000), r.Stats.InitialEquity)
+	suite.Require().Equal(float64(900), r.Stats.FinalEquity)
+	suite.Require().Len(r.EquityCurve, 4)
+	suite.Require().Len(r.Drawdowns, 4)
+	suite.Require().InDelta(0.1, r.Drawdowns[3].Drawdown, 1e-9)
+
+	suite.Require().Len(r.Trades, 2)
+	suite.Require().Equal(float64(200), r.Trades[0].Value)
+	suite.Require().Equal(float64(1000), r.Trades[0].Equity)
+	suite.Require().Equal(float64(50), r.Trades[1].Value)
+	suite.Require().Equal(float64(900), r.Trades[1].Equity)
+
+	suite.Require().Empty(r.Backtest.Webhooks[0].Secret)
+
+	_, err = New(suite.newBacktest(), "", nil, time.Unix(1000, 0))
+	suite.Require().ErrorIs(err, backtest.ErrInvalidQuoteAsset)
+}
+
+func (suite *ReportSuite) TestAddChart() {
+	bt := suite.newBacktest()
+	r, err := New(bt, "USDT", nil, time.Unix(1000, 0))
+	suite.Require().NoError(err)
+
+	r.AddChart(bt.PricesSubscriptions[0], period.M1, []candlestick.Candlestick{{Time: time.Unix(0, 0)}})
+	r.AddChart(bt.PricesSubscriptions[1], period.M1, nil)
+	suite.Require().Len(r.Charts, 2)
+	suite.Require().Len(r.Charts[0].Orders, 2)
+	suite.Require().Len(r.Charts[0].Candlesticks, 1)
+	suite.Require().Empty(r.Charts[1].Orders)
+}
+
+func (suite *ReportSuite) TestChartPeriod() {
+	bt := suite.newBacktest()
+	suite.Require().Equal(period.M1, ChartPeriod(bt))
+
+	// A year would have too many candlesticks per minute
+	bt.CurrentCandlestick.Time = bt.StartTime.Add(365 * 24 * time.Hour)
+	suite.Require().Equal(period.D1, ChartPeriod(bt))
+
+	// The price period is the smallest one
+	bt.CurrentCandlestick.Time = bt.StartTime.Add(time.Hour)
+	bt.PricePeriod = period.M15
+	suite.Require().Equal(period.M15, ChartPeriod(bt))
+}
+
+func (suite *ReportSuite) TestRender() {
+	bt := suite.newBacktest()
+	r, err := New(bt, "USDT", nil, time.Unix(1000, 0))
+	suite.Require().NoError(err)
+	r.AddChart(bt.PricesSubscriptions[0], period.M1, []candlestick.Candlestick{
+		{Time: time.Unix(0, 0).UTC(), Open: 100, High: 110, Low: 90, Close: 105},
+	})
+
+	var buf bytes.Buffer
+	suite.Require().NoError(Render(&buf, r))
+	page := buf.String()
+
+	// Parameters, stats and trades
+	suite.Require().Contains(page, bt.ID.String())
+	suite.Require().Contains(page, "<tr><th>window</th><td>14</td></tr>")
+	suite.Require().Contains(page, "-10.00 %")
+	suite.Require().Contains(page, `<td class="sell">sell</td>`)
+
+	// Embedded styles, script and chart data
+	suite.Require().Contains(page, "--positive")
+	suite.Require().Contains(page, "function candlestickChart")
+	suite.Require().Contains(page, `"equity_curve":[{"time":"1970-01-01T00:00:00Z","equity":1000}`)
+	suite.Require().Contains(page, `id="price-chart-0"`)
+	suite.Require().NotContains(page, "<script src=")
+	suite.Require().NotContains(page, "<link")
+
+	// Content from users is escaped and secrets are not displayed
+	suite.Require().NotContains(page, "<script>alert(1)</script>")
+	suite.Require().NotContains(page, "secret")
+}
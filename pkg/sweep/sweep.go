@@ -0,0 +1,212 @@
+package sweep
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidSearchSpace is returned when the search space is invalid.
+	ErrInvalidSearchSpace = errors.New("invalid search space")
+	// ErrTooManyCombinations is returned when the search space has more
+	// combinations than MaxCombinations.
+	ErrTooManyCombinations = errors.New("too many combinations")
+)
+
+const (
+	// MaxCombinations is the maximum count of combinations of a search space.
+	MaxCombinations = 1000
+	// DefaultMaxConcurrency is the default maximum count of backtests running at
+	// the same time during a sweep.
+	DefaultMaxConcurrency = 4
+)
+
+// Range is a range of numeric values, with both bounds included.
+type Range struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+	// Integer is true if only integer values should be sampled.
+	Integer bool `json:"integer"`
+}
+
+// Validate will validate the range.
+func (r Range) Validate() error {
+	switch {
+	case r.Min > r.Max:
+		return fmt.Errorf("%w: range min greater than max", ErrInvalidSearchSpace)
+	case r.Integer && math.Floor(r.Max) < math.Ceil(r.Min):
+		return fmt.Errorf("%w: range without integer", ErrInvalidSearchSpace)
+	}
+	return nil
+}
+
+func (r Range) sample(rnd *rand.Rand) any {
+	if r.Integer {
+		low, high := int64(math.Ceil(r.Min)), int64(math.Floor(r.Max))
+		return low + rnd.Int63n(high-low+1)
+	}
+	return r.Min + rnd.Float64()*(r.Max-r.Min)
+}
+
+// RandomSearch is a search space where parameters are randomly sampled.
+type RandomSearch struct {
+	// Samples is the count of combinations to sample.
+	Samples int `json:"samples"`
+	// Seed is the seed of the random generator, in order to get the same
+	// combinations on each sweep.
+	Seed int64 `json:"seed"`
+	// Ranges are the numeric parameters sampled in a range.
+	Ranges map[string]Range `json:"ranges,omitempty"`
+	// Choices are the parameters sampled in a list of values.
+	Choices map[string][]any `json:"choices,omitempty"`
+}
+
+// Validate will validate the random search.
+func (rs RandomSearch) Validate() error {
+	if rs.Samples <= 0 {
+		return fmt.Errorf("%w: no sample", ErrInvalidSearchSpace)
+	} else if rs.Samples > MaxCombinations {
+		return fmt.Errorf("%w: %d samples", ErrTooManyCombinations, rs.Samples)
+	}
+
+	for name, r := range rs.Ranges {
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("parameter %q: %w", name, err)
+		}
+	}
+
+	for name, values := range rs.Choices {
+		if len(values) == 0 {
+			return fmt.Errorf("%w: no choice for parameter %q", ErrInvalidSearchSpace, name)
+		} else if _, ok := rs.Ranges[name]; ok {
+			return fmt.Errorf("%w: parameter %q both in ranges and choices", ErrInvalidSearchSpace, name)
+		}
+	}
+
+	return nil
+}
+
+// SearchSpace is the space of strategy parameters to explore during a sweep.
+// Exactly one of the grid or the random search should be set.
+type SearchSpace struct {
+	// Grid are the values of each parameter, every combination being tested.
+	Grid map[string][]any `json:"grid,omitempty"`
+	// Random is a search where parameters are randomly sampled.
+	Random *RandomSearch `json:"random,omitempty"`
+}
+
+// Validate will validate the search space.
+func (s SearchSpace) Validate() error {
+	switch {
+	case len(s.Grid) > 0 && s.Random != nil:
+		return fmt.Errorf("%w: both grid and random search", ErrInvalidSearchSpace)
+	case s.Random != nil:
+		return s.Random.Validate()
+	case len(s.Grid) == 0:
+		return fmt.Errorf("%w: no grid nor random search", ErrInvalidSearchSpace)
+	}
+
+	count := 1
+	for name, values := range s.Grid {
+		if len(values) == 0 {
+			return fmt.Errorf("%w: no value for parameter %q", ErrInvalidSearchSpace, name)
+		}
+
+		count *= len(values)
+		if count > MaxCombinations {
+			return fmt.Errorf("%w: more than %d", ErrTooManyCombinations, MaxCombinations)
+		}
+	}
+
+	return nil
+}
+
+// Combinations returns the combinations of parameters of the search space.
+// The order is deterministic, so it can be used inside workflows.
+func (s SearchSpace) Combinations() ([]map[string]any, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	if s.Random != nil {
+		return s.randomCombinations(), nil
+	}
+	return s.gridCombinations(), nil
+}
+
+func (s SearchSpace) gridCombinations() []map[string]any {
+	combinations := []map[string]any{{}}
+	for _, name := range slices.Sorted(maps.Keys(s.Grid)) {
+		next := make([]map[string]any, 0, len(combinations)*len(s.Grid[name]))
+		for _, c := range combinations {
+			for _, v := range s.Grid[name] {
+				nc := maps.Clone(c)
+				nc[name] = v
+				next = append(next, nc)
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+func (s SearchSpace) randomCombinations() []map[string]any {
+	rs := s.Random
+	rangesNames := slices.Sorted(maps.Keys(rs.Ranges))
+	choicesNames := slices.Sorted(maps.Keys(rs.Choices))
+
+	rnd := rand.New(rand.NewSource(rs.Seed))
+	combinations := make([]map[string]any, rs.Samples)
+	for i := range combinations {
+		c := make(map[string]any, len(rangesNames)+len(choicesNames))
+		for _, name := range rangesNames {
+			c[name] = rs.Ranges[name].sample(rnd)
+		}
+		for _, name := range choicesNames {
+			c[name] = rs.Choices[name][rnd.Intn(len(rs.Choices[name]))]
+		}
+		combinations[i] = c
+	}
+	return combinations
+}
+
+// Result is the result of a backtest from a sweep.
+type Result struct {
+	// Rank is the rank of the result, starting from 1 for the best one.
+	Rank       int            `json:"rank"`
+	BacktestID uuid.UUID      `json:"backtest_id"`
+	Parameters map[string]any `json:"parameters"`
+	Stats      backtest.Stats `json:"stats"`
+	Score      float64        `json:"score"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// RankResults sorts the results from the best to the worst score on the
+// metric and sets their rank. Failed backtests are ranked last.
+func RankResults(results []Result, metric backtest.Metric) {
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		switch {
+		case (a.Error == "") != (b.Error == ""):
+			return a.Error == ""
+		case a.Error != "":
+			return false
+		case metric.HigherIsBetter():
+			return a.Score > b.Score
+		default:
+			return a.Score < b.Score
+		}
+	})
+
+	for i := range results {
+		results[i].Rank = i + 1
+	}
+}
@@ -0,0 +1,111 @@
+//go:build unit
+// +build unit
+
+package sweep
+
+import (
+	"testing"
+
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSweepSuite(t *testing.T) {
+	suite.Run(t, new(SweepSuite))
+}
+
+type SweepSuite struct {
+	suite.Suite
+}
+
+func (suite *SweepSuite) TestGridCombinations() {
+	s := SearchSpace{
+		Grid: map[string][]any{
+			"period":    {10, 20},
+			"threshold": {0.1, 0.2, 0.3},
+		},
+	}
+
+	combinations, err := s.Combinations()
+	suite.Require().NoError(err)
+	suite.Require().Len(combinations, 6)
+	suite.Require().Equal(map[string]any{"period": 10, "threshold": 0.1}, combinations[0])
+	suite.Require().Equal(map[string]any{"period": 20, "threshold": 0.3}, combinations[5])
+}
+
+func (suite *SweepSuite) TestRandomCombinations() {
+	s := SearchSpace{
+		Random: &RandomSearch{
+			Samples: 10,
+			Seed:    42,
+			Ranges: map[string]Range{
+				"period":    {Min: 5, Max: 50, Integer: true},
+				"threshold": {Min: 0.1, Max: 0.5},
+			},
+			Choices: map[string][]any{
+				"side": {"long", "short"},
+			},
+		},
+	}
+
+	combinations, err := s.Combinations()
+	suite.Require().NoError(err)
+	suite.Require().Len(combinations, 10)
+	for _, c := range combinations {
+		suite.Require().GreaterOrEqual(c["period"], int64(5))
+		suite.Require().LessOrEqual(c["period"], int64(50))
+		suite.Require().GreaterOrEqual(c["threshold"], 0.1)
+		suite.Require().LessOrEqual(c["threshold"], 0.5)
+		suite.Require().Contains([]any{"long", "short"}, c["side"])
+	}
+
+	// Same seed should give the same combinations
+	again, err := s.Combinations()
+	suite.Require().NoError(err)
+	suite.Require().Equal(combinations, again)
+}
+
+func (suite *SweepSuite) TestInvalidSearchSpace() {
+	_, err := SearchSpace{}.Combinations()
+	suite.Require().ErrorIs(err, ErrInvalidSearchSpace)
+
+	_, err = SearchSpace{
+		Grid:   map[string][]any{"a": {1}},
+		Random: &RandomSearch{Samples: 1},
+	}.Combinations()
+	suite.Require().ErrorIs(err, ErrInvalidSearchSpace)
+
+	_, err = SearchSpace{Grid: map[string][]any{"a": {}}}.Combinations()
+	suite.Require().ErrorIs(err, ErrInvalidSearchSpace)
+
+	values := make([]any, 100)
+	_, err = SearchSpace{Grid: map[string][]any{"a": values, "b": values}}.Combinations()
+	suite.Require().ErrorIs(err, ErrTooManyCombinations)
+
+	_, err = SearchSpace{Random: &RandomSearch{
+		Samples: 1,
+		Ranges:  map[string]Range{"a": {Min: 1.2, Max: 1.8, Integer: true}},
+	}}.Combinations()
+	suite.Require().ErrorIs(err, ErrInvalidSearchSpace)
+}
+
+func (suite *SweepSuite) TestRankResults() {
+	results := []Result{
+		{Score: 0.1},
+		{Score: 0.3, Error: "failed"},
+		{Score: 0.5},
+		{Score: 0.2},
+	}
+
+	RankResults(results, backtest.MetricReturn)
+	suite.Require().Equal(0.5, results[0].Score)
+	suite.Require().Equal(0.2, results[1].Score)
+	suite.Require().Equal(0.1, results[2].Score)
+	suite.Require().Equal("failed", results[3].Error)
+	suite.Require().Equal(4, results[3].Rank)
+
+	RankResults(results, backtest.MetricMaxDrawdown)
+	suite.Require().Equal(0.1, results[0].Score)
+	suite.Require().Equal(1, results[0].Rank)
+	suite.Require().Equal("failed", results[3].Error)
+}
@@ -0,0 +1,105 @@
+package sweep
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidWindows is returned when the walk-forward windows are invalid.
+	ErrInvalidWindows = errors.New("invalid walk-forward windows")
+	// ErrNoValidResult is returned when no backtest of a parameter search succeeded.
+	ErrNoValidResult = errors.New("no valid result")
+)
+
+// MaxWindows is the maximum count of windows of a walk-forward optimization.
+const MaxWindows = 100
+
+// Window is a walk-forward window: the parameters are optimized on the
+// in-sample period, then applied on the out-of-sample period that follows.
+type Window struct {
+	InSampleStart    time.Time `json:"in_sample_start"`
+	InSampleEnd      time.Time `json:"in_sample_end"`
+	OutOfSampleStart time.Time `json:"out_of_sample_start"`
+	OutOfSampleEnd   time.Time `json:"out_of_sample_end"`
+}
+
+// Windows splits a period into rolling windows, each one moving forward by the
+// out-of-sample duration. The last out-of-sample period is truncated to the end.
+func Windows(start, end time.Time, inSample, outOfSample time.Duration) ([]Window, error) {
+	switch {
+	case inSample <= 0 || outOfSample <= 0:
+		return nil, fmt.Errorf("%w: durations should be positive", ErrInvalidWindows)
+	case !start.Add(inSample).Before(end):
+		return nil, fmt.Errorf("%w: in-sample period longer than the range", ErrInvalidWindows)
+	}
+
+	windows := make([]Window, 0)
+	for isStart := start; isStart.Add(inSample).Before(end); isStart = isStart.Add(outOfSample) {
+		if len(windows) == MaxWindows {
+			return nil, fmt.Errorf("%w: more than %d windows", ErrInvalidWindows, MaxWindows)
+		}
+
+		oosStart := isStart.Add(inSample)
+		oosEnd := oosStart.Add(outOfSample)
+		if oosEnd.After(end) {
+			oosEnd = end
+		}
+
+		windows = append(windows, Window{
+			InSampleStart:    isStart,
+			InSampleEnd:      oosStart,
+			OutOfSampleStart: oosStart,
+			OutOfSampleEnd:   oosEnd,
+		})
+	}
+
+	return windows, nil
+}
+
+// WindowResult is the result of a walk-forward window.
+type WindowResult struct {
+	Window Window `json:"window"`
+	// InSample is the best result of the parameter search on the in-sample period.
+	InSample Result `json:"in_sample"`
+	// OutOfSampleBacktestID is the backtest run with the best parameters on the
+	// out-of-sample period.
+	OutOfSampleBacktestID uuid.UUID              `json:"out_of_sample_backtest_id"`
+	OutOfSampleStats      backtest.Stats         `json:"out_of_sample_stats"`
+	OutOfSampleCurve      []backtest.EquityPoint `json:"out_of_sample_curve"`
+}
+
+// StitchEquityCurves combines the equity curves of consecutive periods into
+// one curve. Each curve is scaled to start at the final equity of the previous
+// one, so the returns of each period are compounded.
+func StitchEquityCurves(curves ...[]backtest.EquityPoint) []backtest.EquityPoint {
+	stitched := make([]backtest.EquityPoint, 0)
+	for _, curve := range curves {
+		if len(curve) == 0 {
+			continue
+		}
+
+		// Get the scale to apply on this curve
+		scale := 1.0
+		if len(stitched) > 0 && curve[0].Equity != 0 {
+			scale = stitched[len(stitched)-1].Equity / curve[0].Equity
+		}
+
+		// Skip the first point of the curve if it is the end of the previous one
+		if len(stitched) > 0 && !curve[0].Time.After(stitched[len(stitched)-1].Time) {
+			curve = curve[1:]
+		}
+
+		for _, p := range curve {
+			stitched = append(stitched, backtest.EquityPoint{
+				Time:   p.Time,
+				Equity: p.Equity * scale,
+			})
+		}
+	}
+	return stitched
+}
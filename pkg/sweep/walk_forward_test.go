@@ -0,0 +1,57 @@
+//go:build unit
+// +build unit
+
+package sweep
+
+import (
+	"time"
+
+	"github.com/cryptellation/backtests/pkg/backtest"
+)
+
+func (suite *SweepSuite) TestWindows() {
+	start := time.Unix(0, 0).UTC()
+	windows, err := Windows(start, start.Add(10*time.Hour), 4*time.Hour, 4*time.Hour)
+	suite.Require().NoError(err)
+	suite.Require().Equal([]Window{
+		{
+			InSampleStart:    start,
+			InSampleEnd:      start.Add(4 * time.Hour),
+			OutOfSampleStart: start.Add(4 * time.Hour),
+			OutOfSampleEnd:   start.Add(8 * time.Hour),
+		},
+		{
+			InSampleStart:    start.Add(4 * time.Hour),
+			InSampleEnd:      start.Add(8 * time.Hour),
+			OutOfSampleStart: start.Add(8 * time.Hour),
+			OutOfSampleEnd:   start.Add(10 * time.Hour),
+		},
+	}, windows)
+}
+
+func (suite *SweepSuite) TestInvalidWindows() {
+	start := time.Unix(0, 0).UTC()
+
+	_, err := Windows(start, start.Add(time.Hour), 2*time.Hour, time.Hour)
+	suite.Require().ErrorIs(err, ErrInvalidWindows)
+
+	_, err = Windows(start, start.Add(time.Hour), 0, time.Hour)
+	suite.Require().ErrorIs(err, ErrInvalidWindows)
+
+	_, err = Windows(start, start.Add(time.Hour), time.Second, time.Second)
+	suite.Require().ErrorIs(err, ErrInvalidWindows)
+}
+
+func (suite *SweepSuite) TestStitchEquityCurves() {
+	t := func(s int64) time.Time { return time.Unix(s, 0).UTC() }
+
+	curve := StitchEquityCurves(
+		[]backtest.EquityPoint{{Time: t(0), Equity: 100}, {Time: t(10), Equity: 110}},
+		nil,
+		[]backtest.EquityPoint{{Time: t(10), Equity: 100}, {Time: t(20), Equity: 90}},
+	)
+	suite.Require().Len(curve, 3)
+	suite.Require().Equal(backtest.EquityPoint{Time: t(10), Equity: 110}, curve[1])
+	suite.Require().InDelta(99, curve[2].Equity, 1e-9)
+	suite.Require().Equal(t(20), curve[2].Time)
+}
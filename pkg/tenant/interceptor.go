@@ -0,0 +1,148 @@
+package tenant
+
+import (
+	"context"
+
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/workflow"
+)
+
+// NewWorkerInterceptor creates a worker interceptor propagating the tenant like
+// the context propagator, from the headers of the workflows and activities to
+// their context, then to the activities and child workflows of the workflows.
+// It keeps the tenant in the workflows of a worker whose client has been
+// created without the propagator.
+func NewWorkerInterceptor() interceptor.WorkerInterceptor {
+	return &workerInterceptor{}
+}
+
+type workerInterceptor struct {
+	interceptor.WorkerInterceptorBase
+}
+
+func (i *workerInterceptor) InterceptActivity(
+	_ context.Context,
+	next interceptor.ActivityInboundInterceptor,
+) interceptor.ActivityInboundInterceptor {
+	return &activityInboundInterceptor{
+		ActivityInboundInterceptorBase: interceptor.ActivityInboundInterceptorBase{Next: next},
+	}
+}
+
+func (i *workerInterceptor) InterceptWorkflow(
+	_ workflow.Context,
+	next interceptor.WorkflowInboundInterceptor,
+) interceptor.WorkflowInboundInterceptor {
+	return &workflowInboundInterceptor{
+		WorkflowInboundInterceptorBase: interceptor.WorkflowInboundInterceptorBase{Next: next},
+	}
+}
+
+type activityInboundInterceptor struct {
+	interceptor.ActivityInboundInterceptorBase
+}
+
+func (i *activityInboundInterceptor) ExecuteActivity(
+	ctx context.Context,
+	in *interceptor.ExecuteActivityInput,
+) (any, error) {
+	// Keep the tenant already extracted by a context propagator, if any
+	if FromContext(ctx) == "" {
+		id, ok, err := extract(header(interceptor.Header(ctx)))
+		if err != nil {
+			return nil, err
+		} else if ok {
+			ctx = WithID(ctx, id)
+		}
+	}
+
+	return i.Next.ExecuteActivity(ctx, in)
+}
+
+type workflowInboundInterceptor struct {
+	interceptor.WorkflowInboundInterceptorBase
+}
+
+func (i *workflowInboundInterceptor) Init(outbound interceptor.WorkflowOutboundInterceptor) error {
+	return i.Next.Init(&workflowOutboundInterceptor{
+		WorkflowOutboundInterceptorBase: interceptor.WorkflowOutboundInterceptorBase{Next: outbound},
+	})
+}
+
+func (i *workflowInboundInterceptor) ExecuteWorkflow(
+	ctx workflow.Context,
+	in *interceptor.ExecuteWorkflowInput,
+) (any, error) {
+	// Keep the tenant already extracted by a context propagator, if any
+	if FromWorkflow(ctx) == "" {
+		id, ok, err := extract(header(interceptor.WorkflowHeader(ctx)))
+		if err != nil {
+			return nil, err
+		} else if ok {
+			ctx = WithWorkflowID(ctx, id)
+		}
+	}
+
+	return i.Next.ExecuteWorkflow(ctx, in)
+}
+
+type workflowOutboundInterceptor struct {
+	interceptor.WorkflowOutboundInterceptorBase
+}
+
+func (o *workflowOutboundInterceptor) ExecuteActivity(
+	ctx workflow.Context,
+	activityType string,
+	args ...any,
+) workflow.Future {
+	injectFromWorkflow(ctx)
+	return o.Next.ExecuteActivity(ctx, activityType, args...)
+}
+
+func (o *workflowOutboundInterceptor) ExecuteLocalActivity(
+	ctx workflow.Context,
+	activityType string,
+	args ...any,
+) workflow.Future {
+	injectFromWorkflow(ctx)
+	return o.Next.ExecuteLocalActivity(ctx, activityType, args...)
+}
+
+func (o *workflowOutboundInterceptor) ExecuteChildWorkflow(
+	ctx workflow.Context,
+	childWorkflowType string,
+	args ...any,
+) workflow.ChildWorkflowFuture {
+	injectFromWorkflow(ctx)
+	return o.Next.ExecuteChildWorkflow(ctx, childWorkflowType, args...)
+}
+
+// injectFromWorkflow injects the tenant of the workflow into the headers of
+// its outbound call.
+func injectFromWorkflow(ctx workflow.Context) {
+	if err := inject(FromWorkflow(ctx), header(interceptor.WorkflowHeader(ctx))); err != nil {
+		workflow.GetLogger(ctx).Error("Cannot send the tenant", "error", err)
+	}
+}
+
+// header reads and writes the Temporal headers of the interceptors.
+type header map[string]*commonpb.Payload
+
+func (h header) Get(key string) (*commonpb.Payload, bool) {
+	payload, ok := h[key]
+	return payload, ok
+}
+
+func (h header) Set(key string, value *commonpb.Payload) {
+	h[key] = value
+}
+
+func (h header) ForEachKey(handler func(string, *commonpb.Payload) error) error {
+	for k, v := range h {
+		if err := handler(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+// Package tenant identifies the tenant owning the backtests, and propagates it
+// from the clients to the workflows and activities.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/workflow"
+)
+
+// HeaderKey is the key of the Temporal header containing the tenant.
+const HeaderKey = "cryptellation-tenant"
+
+// MaxLength is the maximum length of a tenant identifier, in characters.
+const MaxLength = 255
+
+var (
+	// ErrInvalid is returned when a tenant identifier is invalid.
+	ErrInvalid = errors.New("invalid tenant")
+	// ErrMismatch is returned when the tenant of the workflow parameters is
+	// different from the one propagated with the context.
+	ErrMismatch = errors.New("tenant mismatch")
+)
+
+// contextKey is the key of the tenant in the contexts.
+type contextKey struct{}
+
+// WithID returns a copy of the context with the tenant. The empty tenant is
+// the default one, shared by the clients that do not set any.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the tenant of the context.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// WithWorkflowID returns a copy of the workflow context with the tenant.
+func WithWorkflowID(ctx workflow.Context, id string) workflow.Context {
+	return workflow.WithValue(ctx, contextKey{}, id)
+}
+
+// FromWorkflow returns the tenant of the workflow context.
+func FromWorkflow(ctx workflow.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Validate validates the tenant identifier.
+func Validate(id string) error {
+	if utf8.RuneCountInString(id) > MaxLength {
+		return fmt.Errorf("%w: longer than %d characters", ErrInvalid, MaxLength)
+	}
+	return nil
+}
+
+// Resolve returns the workflow context with the tenant of the workflow
+// parameters, if any. It fails if a different tenant has been propagated.
+func Resolve(ctx workflow.Context, id string) (workflow.Context, error) {
+	if err := Validate(id); err != nil {
+		return nil, err
+	}
+
+	current := FromWorkflow(ctx)
+	switch {
+	case id == "" || id == current:
+		return ctx, nil
+	case current == "":
+		return WithWorkflowID(ctx, id), nil
+	default:
+		return nil, fmt.Errorf("%w: %q in parameters and %q in context", ErrMismatch, id, current)
+	}
+}
+
+// propagator propagates the tenant in the Temporal headers.
+type propagator struct{}
+
+// NewContextPropagator creates a context propagator sending the tenant from the
+// clients to the workflows, then to their activities and child workflows. It
+// should be set on the clients of both the callers and the workers.
+func NewContextPropagator() workflow.ContextPropagator {
+	return propagator{}
+}
+
+// Inject injects the tenant from the context into the headers.
+func (p propagator) Inject(ctx context.Context, w workflow.HeaderWriter) error {
+	return inject(FromContext(ctx), w)
+}
+
+// InjectFromWorkflow injects the tenant from the workflow context into the headers.
+func (p propagator) InjectFromWorkflow(ctx workflow.Context, w workflow.HeaderWriter) error {
+	return inject(FromWorkflow(ctx), w)
+}
+
+// Extract extracts the tenant from the headers into the context.
+func (p propagator) Extract(ctx context.Context, r workflow.HeaderReader) (context.Context, error) {
+	id, ok, err := extract(r)
+	if err != nil || !ok {
+		return ctx, err
+	}
+	return WithID(ctx, id), nil
+}
+
+// ExtractToWorkflow extracts the tenant from the headers into the workflow context.
+func (p propagator) ExtractToWorkflow(ctx workflow.Context, r workflow.HeaderReader) (workflow.Context, error) {
+	id, ok, err := extract(r)
+	if err != nil || !ok {
+		return ctx, err
+	}
+	return WithWorkflowID(ctx, id), nil
+}
+
+func inject(id string, w workflow.HeaderWriter) error {
+	if id == "" {
+		return nil
+	}
+
+	payload, err := converter.GetDefaultDataConverter().ToPayload(id)
+	if err != nil {
+		return fmt.Errorf("encoding tenant: %w", err)
+	}
+	w.Set(HeaderKey, payload)
+	return nil
+}
+
+func extract(r workflow.HeaderReader) (string, bool, error) {
+	payload, ok := r.Get(HeaderKey)
+	if !ok {
+		return "", false, nil
+	}
+
+	var id string
+	if err := converter.GetDefaultDataConverter().FromPayload(payload, &id); err != nil {
+		return "", false, fmt.Errorf("decoding tenant: %w", err)
+	}
+	return id, true, nil
+}
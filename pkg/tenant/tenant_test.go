@@ -0,0 +1,137 @@
+//go:build unit
+// +build unit
+
+package tenant
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+)
+
+func TestTenantSuite(t *testing.T) {
+	suite.Run(t, new(TenantSuite))
+}
+
+type TenantSuite struct {
+	suite.Suite
+}
+
+func (suite *TenantSuite) TestValidate() {
+	suite.Require().NoError(Validate(""))
+	suite.Require().NoError(Validate("tenant"))
+	suite.Require().ErrorIs(Validate(strings.Repeat("a", MaxLength+1)), ErrInvalid)
+}
+
+func (suite *TenantSuite) TestPropagator() {
+	p := NewContextPropagator()
+
+	// The tenant goes through the headers
+	h := header{}
+	suite.Require().NoError(p.Inject(WithID(context.Background(), "tenant"), h))
+	suite.Require().Contains(h, HeaderKey)
+
+	ctx, err := p.Extract(context.Background(), h)
+	suite.Require().NoError(err)
+	suite.Require().Equal("tenant", FromContext(ctx))
+
+	// The default tenant is not sent
+	h = header{}
+	suite.Require().NoError(p.Inject(context.Background(), h))
+	suite.Require().Empty(h)
+
+	ctx, err = p.Extract(context.Background(), h)
+	suite.Require().NoError(err)
+	suite.Require().Empty(FromContext(ctx))
+}
+
+func (suite *TenantSuite) TestResolve() {
+	cases := []struct {
+		Name       string
+		Propagated string
+		Params     string
+		Expected   string
+		Err        error
+	}{
+		{Name: "default", Expected: ""},
+		{Name: "from parameters", Params: "a", Expected: "a"},
+		{Name: "from context", Propagated: "a", Expected: "a"},
+		{Name: "same", Propagated: "a", Params: "a", Expected: "a"},
+		{Name: "mismatch", Propagated: "a", Params: "b", Err: ErrMismatch},
+	}
+
+	for _, c := range cases {
+		suite.Run(c.Name, func() {
+			env := new(testsuite.WorkflowTestSuite).NewTestWorkflowEnvironment()
+			env.SetContextPropagators([]workflow.ContextPropagator{NewContextPropagator()})
+			if c.Propagated != "" {
+				h := header{}
+				suite.Require().NoError(NewContextPropagator().Inject(WithID(context.Background(), c.Propagated), h))
+				env.SetHeader(&commonpb.Header{Fields: h})
+			}
+
+			env.ExecuteWorkflow(func(ctx workflow.Context, id string) (string, error) {
+				ctx, err := Resolve(ctx, id)
+				if err != nil {
+					return "", err
+				}
+				return FromWorkflow(ctx), nil
+			}, c.Params)
+			suite.Require().True(env.IsWorkflowCompleted())
+
+			if c.Err != nil {
+				suite.Require().ErrorContains(env.GetWorkflowError(), c.Err.Error())
+				return
+			}
+			suite.Require().NoError(env.GetWorkflowError())
+
+			var res string
+			suite.Require().NoError(env.GetWorkflowResult(&res))
+			suite.Require().Equal(c.Expected, res)
+		})
+	}
+}
+
+func (suite *TenantSuite) TestWorkerInterceptor() {
+	env := new(testsuite.WorkflowTestSuite).NewTestWorkflowEnvironment()
+	env.SetWorkerOptions(worker.Options{Interceptors: []interceptor.WorkerInterceptor{NewWorkerInterceptor()}})
+
+	h := header{}
+	suite.Require().NoError(NewContextPropagator().Inject(WithID(context.Background(), "tenant"), h))
+	env.SetHeader(&commonpb.Header{Fields: h})
+
+	// The tenant is kept by the workflow, its activities and its child workflows
+	env.RegisterActivityWithOptions(func(ctx context.Context) (string, error) {
+		return FromContext(ctx), nil
+	}, activity.RegisterOptions{Name: "ActivityTenant"})
+	env.RegisterWorkflowWithOptions(func(ctx workflow.Context) (string, error) {
+		return FromWorkflow(ctx), nil
+	}, workflow.RegisterOptions{Name: "ChildTenant"})
+
+	env.ExecuteWorkflow(func(ctx workflow.Context) ([]string, error) {
+		var fromActivity, fromChild string
+		ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{StartToCloseTimeout: time.Minute})
+		if err := workflow.ExecuteActivity(ctx, "ActivityTenant").Get(ctx, &fromActivity); err != nil {
+			return nil, err
+		}
+		if err := workflow.ExecuteChildWorkflow(ctx, "ChildTenant").Get(ctx, &fromChild); err != nil {
+			return nil, err
+		}
+		return []string{FromWorkflow(ctx), fromActivity, fromChild}, nil
+	})
+	suite.Require().True(env.IsWorkflowCompleted())
+	suite.Require().NoError(env.GetWorkflowError())
+
+	var res []string
+	suite.Require().NoError(env.GetWorkflowResult(&res))
+	suite.Require().Equal([]string{"tenant", "tenant", "tenant"}, res)
+}
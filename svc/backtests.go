@@ -18,22 +18,62 @@ type Backtests interface {
 		ctx workflow.Context,
 		params api.CreateBacktestWorkflowParams,
 	) (api.CreateBacktestWorkflowResults, error)
+	DeleteBacktestWorkflow(
+		ctx workflow.Context,
+		params api.DeleteBacktestWorkflowParams,
+	) (api.DeleteBacktestWorkflowResults, error)
+	ExportBacktestWorkflow(
+		ctx workflow.Context,
+		params api.ExportBacktestWorkflowParams,
+	) (api.ExportBacktestWorkflowResults, error)
+	ForkBacktestWorkflow(
+		ctx workflow.Context,
+		params api.ForkBacktestWorkflowParams,
+	) (api.ForkBacktestWorkflowResults, error)
 	GetBacktestWorkflow(
 		ctx workflow.Context,
 		params api.GetBacktestWorkflowParams,
 	) (api.GetBacktestWorkflowResults, error)
+	GetBacktestReportWorkflow(
+		ctx workflow.Context,
+		params api.GetBacktestReportWorkflowParams,
+	) (api.GetBacktestReportWorkflowResults, error)
 	ListBacktestsWorkflow(
 		ctx workflow.Context,
 		params api.ListBacktestsWorkflowParams,
 	) (api.ListBacktestsWorkflowResults, error)
+	MonteCarloWorkflow(
+		ctx workflow.Context,
+		params api.MonteCarloWorkflowParams,
+	) (api.MonteCarloWorkflowResults, error)
+	PurgeBacktestsWorkflow(
+		ctx workflow.Context,
+		params api.PurgeBacktestsWorkflowParams,
+	) (api.PurgeBacktestsWorkflowResults, error)
 	RunBacktestWorkflow(
 		ctx workflow.Context,
 		params api.RunBacktestWorkflowParams,
 	) (api.RunBacktestWorkflowResults, error)
+	RunParameterSweepWorkflow(
+		ctx workflow.Context,
+		params api.RunParameterSweepWorkflowParams,
+	) (api.RunParameterSweepWorkflowResults, error)
 	SubscribeToPriceWorkflow(
 		ctx workflow.Context,
 		params api.SubscribeToPriceWorkflowParams,
 	) (api.SubscribeToPriceWorkflowResults, error)
+	SetBacktestWakeUpWorkflow(
+		ctx workflow.Context,
+		params api.SetBacktestWakeUpWorkflowParams,
+	) (api.SetBacktestWakeUpWorkflowResults, error)
+	UpdateBacktestMetadataWorkflow(
+		ctx workflow.Context,
+		params api.UpdateBacktestMetadataWorkflowParams,
+	) (api.UpdateBacktestMetadataWorkflowResults, error)
+	WalkForwardWorkflow(
+		ctx workflow.Context,
+		params api.WalkForwardWorkflowParams,
+	) (api.WalkForwardWorkflowResults, error)
 
 	// Backtests Accounts
 
@@ -42,6 +82,13 @@ type Backtests interface {
 		params api.GetBacktestAccountsWorkflowParams,
 	) (api.GetBacktestAccountsWorkflowResults, error)
 
+	// Backtests Strategy Parameters
+
+	GetBacktestStrategyParametersWorkflow(
+		ctx workflow.Context,
+		params api.GetBacktestStrategyParametersWorkflowParams,
+	) (api.GetBacktestStrategyParametersWorkflowResults, error)
+
 	// Backtests Orders
 
 	CreateBacktestOrderWorkflow(
@@ -53,6 +100,17 @@ type Backtests interface {
 		ctx workflow.Context,
 		params api.GetBacktestOrdersWorkflowParams,
 	) (api.GetBacktestOrdersWorkflowResults, error)
+
+	// Quotas
+
+	GetQuotaUsageWorkflow(
+		ctx workflow.Context,
+		params api.GetQuotaUsageWorkflowParams,
+	) (api.GetQuotaUsageWorkflowResults, error)
+	TenantQuotaWorkflow(
+		ctx workflow.Context,
+		params api.TenantQuotaWorkflowParams,
+	) (api.TenantQuotaWorkflowResults, error)
 }
 
 // Check that the workflows implements the Backtests interface.
@@ -71,34 +129,95 @@ func New(db db.DB) Backtests {
 	}
 }
 
-// Register registers the candlesticks workflows to the worker.
+// Register registers the candlesticks workflows to the worker. Their domain
+// errors are sent as application errors with their type.
 func (wf *workflows) Register(w worker.Worker) {
-	w.RegisterWorkflowWithOptions(wf.CreateBacktestOrderWorkflow, workflow.RegisterOptions{
-		Name: api.CreateBacktestOrderWorkflowName,
-	})
-	w.RegisterWorkflowWithOptions(wf.CreateBacktestWorkflow, workflow.RegisterOptions{
-		Name: api.CreateBacktestWorkflowName,
-	})
-	w.RegisterWorkflowWithOptions(wf.GetBacktestAccountsWorkflow, workflow.RegisterOptions{
-		Name: api.GetBacktestAccountsWorkflowName,
-	})
-	w.RegisterWorkflowWithOptions(wf.GetBacktestOrdersWorkflow, workflow.RegisterOptions{
-		Name: api.GetBacktestOrdersWorkflowName,
-	})
-	w.RegisterWorkflowWithOptions(wf.GetBacktestWorkflow, workflow.RegisterOptions{
-		Name: api.GetBacktestWorkflowName,
-	})
-	w.RegisterWorkflowWithOptions(wf.ListBacktestsWorkflow, workflow.RegisterOptions{
-		Name: api.ListBacktestsWorkflowName,
-	})
-	w.RegisterWorkflowWithOptions(wf.RunBacktestWorkflow, workflow.RegisterOptions{
-		Name: api.RunBacktestWorkflowName,
-	})
-	w.RegisterWorkflowWithOptions(wf.SubscribeToPriceWorkflow, workflow.RegisterOptions{
-		Name: api.SubscribeToPriceWorkflowName,
-	})
+	w.RegisterWorkflowWithOptions(
+		withApplicationErrors(withTenant(wf.CreateBacktestOrderWorkflow)),
+		workflow.RegisterOptions{Name: api.CreateBacktestOrderWorkflowName},
+	)
+	w.RegisterWorkflowWithOptions(
+		withApplicationErrors(withTenant(wf.CreateBacktestWorkflow)),
+		workflow.RegisterOptions{Name: api.CreateBacktestWorkflowName},
+	)
+	w.RegisterWorkflowWithOptions(
+		withApplicationErrors(withTenant(wf.DeleteBacktestWorkflow)),
+		workflow.RegisterOptions{Name: api.DeleteBacktestWorkflowName},
+	)
+	w.RegisterWorkflowWithOptions(
+		withApplicationErrors(withTenant(wf.ExportBacktestWorkflow)),
+		workflow.RegisterOptions{Name: api.ExportBacktestWorkflowName},
+	)
+	w.RegisterWorkflowWithOptions(
+		withApplicationErrors(withTenant(wf.ForkBacktestWorkflow)),
+		workflow.RegisterOptions{Name: api.ForkBacktestWorkflowName},
+	)
+	w.RegisterWorkflowWithOptions(
+		withApplicationErrors(withTenant(wf.GetBacktestAccountsWorkflow)),
+		workflow.RegisterOptions{Name: api.GetBacktestAccountsWorkflowName},
+	)
+	w.RegisterWorkflowWithOptions(
+		withApplicationErrors(withTenant(wf.GetBacktestOrdersWorkflow)),
+		workflow.RegisterOptions{Name: api.GetBacktestOrdersWorkflowName},
+	)
+	w.RegisterWorkflowWithOptions(
+		withApplicationErrors(withTenant(wf.GetBacktestStrategyParametersWorkflow)),
+		workflow.RegisterOptions{Name: api.GetBacktestStrategyParametersWorkflowName},
+	)
+	w.RegisterWorkflowWithOptions(
+		withApplicationErrors(withTenant(wf.GetBacktestReportWorkflow)),
+		workflow.RegisterOptions{Name: api.GetBacktestReportWorkflowName},
+	)
+	w.RegisterWorkflowWithOptions(
+		withApplicationErrors(withTenant(wf.GetBacktestWorkflow)),
+		workflow.RegisterOptions{Name: api.GetBacktestWorkflowName},
+	)
+	w.RegisterWorkflowWithOptions(
+		withApplicationErrors(withTenant(wf.GetQuotaUsageWorkflow)),
+		workflow.RegisterOptions{Name: api.GetQuotaUsageWorkflowName},
+	)
+	w.RegisterWorkflowWithOptions(
+		withApplicationErrors(withTenant(wf.ListBacktestsWorkflow)),
+		workflow.RegisterOptions{Name: api.ListBacktestsWorkflowName},
+	)
+	w.RegisterWorkflowWithOptions(
+		withApplicationErrors(withTenant(wf.MonteCarloWorkflow)),
+		workflow.RegisterOptions{Name: api.MonteCarloWorkflowName},
+	)
+	w.RegisterWorkflowWithOptions(
+		withApplicationErrors(withTenant(wf.PurgeBacktestsWorkflow)),
+		workflow.RegisterOptions{Name: api.PurgeBacktestsWorkflowName},
+	)
+	w.RegisterWorkflowWithOptions(
+		withApplicationErrors(withTenant(wf.RunBacktestWorkflow)),
+		workflow.RegisterOptions{Name: api.RunBacktestWorkflowName},
+	)
+	w.RegisterWorkflowWithOptions(
+		withApplicationErrors(withTenant(wf.RunParameterSweepWorkflow)),
+		workflow.RegisterOptions{Name: api.RunParameterSweepWorkflowName},
+	)
+	w.RegisterWorkflowWithOptions(
+		withApplicationErrors(withTenant(wf.SetBacktestWakeUpWorkflow)),
+		workflow.RegisterOptions{Name: api.SetBacktestWakeUpWorkflowName},
+	)
+	w.RegisterWorkflowWithOptions(
+		withApplicationErrors(withTenant(wf.SubscribeToPriceWorkflow)),
+		workflow.RegisterOptions{Name: api.SubscribeToPriceWorkflowName},
+	)
+	w.RegisterWorkflowWithOptions(
+		withApplicationErrors(withTenant(wf.TenantQuotaWorkflow)),
+		workflow.RegisterOptions{Name: api.TenantQuotaWorkflowName},
+	)
+	w.RegisterWorkflowWithOptions(
+		withApplicationErrors(withTenant(wf.UpdateBacktestMetadataWorkflow)),
+		workflow.RegisterOptions{Name: api.UpdateBacktestMetadataWorkflowName},
+	)
+	w.RegisterWorkflowWithOptions(
+		withApplicationErrors(withTenant(wf.WalkForwardWorkflow)),
+		workflow.RegisterOptions{Name: api.WalkForwardWorkflowName},
+	)
 
-	w.RegisterWorkflowWithOptions(ServiceInfoWorkflow, workflow.RegisterOptions{
+	w.RegisterWorkflowWithOptions(withApplicationErrors(ServiceInfoWorkflow), workflow.RegisterOptions{
 		Name: api.ServiceInfoWorkflowName,
 	})
 }
@@ -1,8 +1,14 @@
 package svc
 
 import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+
 	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/backtests/pkg/tenant"
 	"github.com/cryptellation/backtests/svc/db"
+	candlesticksapi "github.com/cryptellation/candlesticks/api"
 	"github.com/google/uuid"
 	"go.temporal.io/sdk/workflow"
 )
@@ -12,7 +18,8 @@ func (wf *workflows) readBacktestFromDB(ctx workflow.Context, id uuid.UUID) (bac
 	err := workflow.ExecuteActivity(
 		workflow.WithActivityOptions(ctx, db.DefaultActivityOptions()),
 		wf.db.ReadBacktestActivity, db.ReadBacktestActivityParams{
-			ID: id,
+			ID:     id,
+			Tenant: tenant.FromWorkflow(ctx),
 		}).Get(ctx, &readRes)
 	if err != nil {
 		return backtest.Backtest{}, err
@@ -20,3 +27,135 @@ func (wf *workflows) readBacktestFromDB(ctx workflow.Context, id uuid.UUID) (bac
 
 	return readRes.Backtest, nil
 }
+
+// getEndPrices gets the close prices at the backtest current time of its open
+// positions against the quote asset, to value them at the end of its equity
+// curve. The positions without candlestick at this time are not included.
+func (wf *workflows) getEndPrices(
+	ctx workflow.Context,
+	bt backtest.Backtest,
+	quoteAsset string,
+) (map[string]float64, error) {
+	prices := make(map[string]float64)
+	for _, p := range bt.OpenPositions(quoteAsset) {
+		if _, ok := prices[p.Asset]; ok {
+			continue
+		}
+
+		res, err := wf.cryptellation.ListCandlesticks(ctx, candlesticksapi.ListCandlesticksWorkflowParams{
+			Exchange: p.Exchange,
+			Pair:     p.Pair,
+			Period:   bt.PricePeriod,
+			Start:    &bt.CurrentCandlestick.Time,
+			End:      &bt.CurrentCandlestick.Time,
+		}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("could not get candlesticks from service: %w", err)
+		} else if len(res.List) > 0 {
+			prices[p.Asset] = res.List[0].Close
+		}
+	}
+
+	return prices, nil
+}
+
+// updateBacktestInDB updates the backtest in database and sets its new version.
+// It fails with db.ErrVersionConflict if the backtest has been updated since it
+// was read.
+func (wf *workflows) updateBacktestInDB(ctx workflow.Context, bt *backtest.Backtest) error {
+	var writeRes db.UpdateBacktestActivityResults
+	err := workflow.ExecuteActivity(
+		workflow.WithActivityOptions(ctx, db.DefaultActivityOptions()),
+		wf.db.UpdateBacktestActivity, db.UpdateBacktestActivityParams{
+			Backtest: *bt,
+		}).Get(ctx, &writeRes)
+	if err != nil {
+		return err
+	}
+
+	bt.Version = writeRes.Version
+	return nil
+}
+
+// maxBacktestUpdateAttempts is the maximum count of attempts to update a
+// backtest that keeps being updated concurrently.
+const maxBacktestUpdateAttempts = 10
+
+const (
+	// backtestUpdateRetryInterval is the wait before the second attempt to update
+	// a backtest updated concurrently. It doubles with each following attempt.
+	backtestUpdateRetryInterval = 100 * time.Millisecond
+	// backtestUpdateMaxRetryInterval is the maximum wait between two attempts.
+	backtestUpdateMaxRetryInterval = 5 * time.Second
+)
+
+// waitBeforeUpdateRetry waits before a new attempt to update a backtest that
+// has been updated concurrently. Half of the wait is random, so that the
+// concurrent updates do not keep conflicting by retrying at the same time.
+func waitBeforeUpdateRetry(ctx workflow.Context, attempt int) error {
+	interval := min(backtestUpdateRetryInterval<<(attempt-1), backtestUpdateMaxRetryInterval)
+
+	// Get the jitter from a side effect to keep the workflow deterministic
+	var jitter time.Duration
+	err := workflow.SideEffect(ctx, func(_ workflow.Context) any {
+		return rand.N(interval / 2)
+	}).Get(&jitter)
+	if err != nil {
+		return err
+	}
+
+	return workflow.Sleep(ctx, interval/2+jitter)
+}
+
+// updateBacktest reads the backtest, modifies it and saves it in database. If
+// the backtest has been updated concurrently in the meantime, it is read and
+// modified again, in order not to lose the other update. Errors returned by
+// the modification are returned as is.
+func (wf *workflows) updateBacktest(
+	ctx workflow.Context,
+	id uuid.UUID,
+	modify func(bt *backtest.Backtest) error,
+) (backtest.Backtest, error) {
+	for attempt := 1; ; attempt++ {
+		bt, err := wf.readBacktestFromDB(ctx, id)
+		if err != nil {
+			return backtest.Backtest{}, fmt.Errorf("read backtest from db: %w", err)
+		}
+
+		if err := modify(&bt); err != nil {
+			return backtest.Backtest{}, err
+		}
+
+		err = wf.updateBacktestInDB(ctx, &bt)
+		if err == nil {
+			return bt, nil
+		} else if !db.IsVersionConflict(err) || attempt >= maxBacktestUpdateAttempts {
+			return backtest.Backtest{}, fmt.Errorf("save backtest to db: %w", err)
+		}
+
+		workflow.GetLogger(ctx).Info("Backtest updated concurrently, retrying",
+			"backtest_id", id.String(),
+			"attempt", attempt)
+		if err := waitBeforeUpdateRetry(ctx, attempt); err != nil {
+			return backtest.Backtest{}, err
+		}
+	}
+}
+
+// setBacktestStatus sets the status of a backtest in database and returns the
+// updated backtest.
+func (wf *workflows) setBacktestStatus(
+	ctx workflow.Context,
+	id uuid.UUID,
+	status backtest.Status,
+) (backtest.Backtest, error) {
+	bt, err := wf.updateBacktest(ctx, id, func(bt *backtest.Backtest) error {
+		bt.Status = status
+		return nil
+	})
+	if err != nil {
+		return backtest.Backtest{}, fmt.Errorf("set backtest status to %q: %w", status, err)
+	}
+
+	return bt, nil
+}
@@ -0,0 +1,60 @@
+//go:build unit
+// +build unit
+
+package svc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/runtime/account"
+	"github.com/cryptellation/runtime/order"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+func TestCommonSuite(t *testing.T) {
+	suite.Run(t, new(CommonSuite))
+}
+
+type CommonSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+}
+
+func (suite *CommonSuite) TestGetEndPrices() {
+	bt := backtest.Backtest{
+		StartTime:          time.Unix(0, 0).UTC(),
+		CurrentCandlestick: backtest.CurrentCandlestick{Time: time.Unix(600, 0).UTC()},
+		Accounts: map[string]account.Account{
+			"exchange": {Balances: map[string]float64{"USDT": 900, "BTC": 1, "ETH": 0}},
+		},
+		Orders: []order.Order{
+			{Exchange: "exchange", Pair: "BTC-USDT", Side: order.SideIsBuy, Quantity: 1, Price: 100},
+			{Exchange: "exchange", Pair: "ETH-USDT", Side: order.SideIsBuy, Quantity: 1, Price: 50},
+			{Exchange: "exchange", Pair: "ETH-USDT", Side: order.SideIsSell, Quantity: 1, Price: 50},
+		},
+	}
+	wf := &workflows{cryptellation: fixedCandlesticks{}}
+
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterWorkflowWithOptions(func(ctx workflow.Context) (backtest.Stats, error) {
+		prices, err := wf.getEndPrices(ctx, bt, "USDT")
+		if err != nil {
+			return backtest.Stats{}, err
+		}
+		return bt.Stats("USDT", prices)
+	}, workflow.RegisterOptions{Name: "GetEndPrices"})
+
+	env.ExecuteWorkflow("GetEndPrices")
+	suite.Require().True(env.IsWorkflowCompleted())
+	suite.Require().NoError(env.GetWorkflowError())
+
+	// The open position is valued at the close of the current candlestick
+	var stats backtest.Stats
+	suite.Require().NoError(env.GetWorkflowResult(&stats))
+	suite.Require().Equal(float64(910), stats.FinalEquity)
+	suite.Require().InDelta(-0.09, stats.Return, 1e-9)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/cryptellation/backtests/api"
 	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/backtests/pkg/tenant"
 	"github.com/cryptellation/backtests/svc/db"
 	"go.temporal.io/sdk/workflow"
 )
@@ -19,11 +20,20 @@ func (wf *workflows) CreateBacktestWorkflow(
 		return api.CreateBacktestWorkflowResults{}, fmt.Errorf("validating callbacks: %w", err)
 	}
 
+	// Validate webhooks
+	for _, w := range params.Webhooks {
+		if err := w.Webhook().Validate(); err != nil {
+			return api.CreateBacktestWorkflowResults{}, fmt.Errorf("validating webhooks: %w", err)
+		}
+	}
+
 	// Create backtest
 	bt, err := backtest.New(params.BacktestParameters, params.Callbacks)
 	if err != nil {
 		return api.CreateBacktestWorkflowResults{}, fmt.Errorf("creating a new backtest from request: %w", err)
 	}
+	bt.Webhooks = backtest.NewWebhooks(params.Webhooks)
+	bt.Tenant = tenant.FromWorkflow(ctx)
 
 	// Save it to DB
 	var dbRes db.CreateBacktestActivityResults
@@ -31,6 +41,7 @@ func (wf *workflows) CreateBacktestWorkflow(
 		workflow.WithActivityOptions(ctx, db.DefaultActivityOptions()),
 		wf.db.CreateBacktestActivity, db.CreateBacktestActivityParams{
 			Backtest: bt,
+			Webhooks: params.Webhooks,
 		}).Get(ctx, &dbRes)
 	if err != nil {
 		return api.CreateBacktestWorkflowResults{}, fmt.Errorf("adding backtest to db: %w", err)
@@ -5,9 +5,9 @@ import (
 
 	"github.com/cryptellation/backtests/api"
 	"github.com/cryptellation/backtests/pkg/backtest"
-	"github.com/cryptellation/backtests/svc/db"
 	candlesticksapi "github.com/cryptellation/candlesticks/api"
 	"github.com/cryptellation/candlesticks/pkg/candlestick"
+	"github.com/cryptellation/runtime/order"
 	"github.com/google/uuid"
 	"go.temporal.io/sdk/workflow"
 )
@@ -27,71 +27,64 @@ func (wf *workflows) CreateBacktestOrderWorkflow(
 		params.Order.ID = uuid.New()
 	}
 
-	// Read backtest and candlesticks
-	bt, cs, err := wf.getBacktestAndCandlestick(ctx, params)
-	if err != nil {
-		return api.CreateBacktestOrderWorkflowResults{}, fmt.Errorf("could not read backtest and candlesticks: %w", err)
-	}
+	// Add order to backtest, again if it has been updated concurrently (i.e. advanced)
+	var cs candlestick.Candlestick
+	bt, err := wf.updateBacktest(ctx, params.BacktestID, func(bt *backtest.Backtest) error {
+		// Check if the backtest is done
+		if bt.Done() {
+			return fmt.Errorf("backtest is done")
+		}
 
-	// Check if the backtest is done
-	if bt.Done() {
-		return api.CreateBacktestOrderWorkflowResults{}, fmt.Errorf("backtest is done")
-	}
+		// Get the candlestick of the backtest current time, if not already read
+		if !cs.Time.Equal(bt.CurrentCandlestick.Time) {
+			var err error
+			if cs, err = wf.getOrderCandlestick(ctx, *bt, params.Order); err != nil {
+				return fmt.Errorf("could not read candlesticks: %w", err)
+			}
+		}
 
-	// Add order to backtest
-	logger.Info("Adding order to backtest",
-		"order", params.Order,
-		"backtest_id", params.BacktestID.String())
-	if err := bt.AddOrder(params.Order, cs); err != nil {
-		return api.CreateBacktestOrderWorkflowResults{}, err
-	}
+		logger.Info("Adding order to backtest",
+			"order", params.Order,
+			"backtest_id", params.BacktestID.String())
+		if err := bt.AddOrder(params.Order, cs); err != nil {
+			notifyBacktestRun(ctx, orderEvent(*bt, params.Order, err))
+			return err
+		}
 
-	// Save backtest
-	if err := workflow.ExecuteActivity(
-		workflow.WithActivityOptions(ctx, db.DefaultActivityOptions()),
-		db.UpdateBacktestActivityName, db.UpdateBacktestActivityParams{
-			Backtest: bt,
-		}).Get(ctx, nil); err != nil {
-		return api.CreateBacktestOrderWorkflowResults{}, fmt.Errorf("could not save backtest to service: %w", err)
+		return nil
+	})
+	if err != nil {
+		return api.CreateBacktestOrderWorkflowResults{}, err
 	}
+	notifyBacktestRun(ctx, orderEvent(bt, bt.Orders[len(bt.Orders)-1], nil))
 
 	return api.CreateBacktestOrderWorkflowResults{}, nil
 }
 
-func (wf *workflows) getBacktestAndCandlestick(
+// getOrderCandlestick gets the candlestick of the order pair at the backtest
+// current time.
+func (wf *workflows) getOrderCandlestick(
 	ctx workflow.Context,
-	params api.CreateBacktestOrderWorkflowParams,
-) (backtest.Backtest, candlestick.Candlestick, error) {
-	// Get backtest
-	var dbBtRes db.ReadBacktestActivityResults
-	if err := workflow.ExecuteActivity(
-		workflow.WithActivityOptions(ctx, db.DefaultActivityOptions()),
-		db.ReadBacktestActivityName, db.ReadBacktestActivityParams{
-			ID: params.BacktestID,
-		}).Get(ctx, &dbBtRes); err != nil {
-		return backtest.Backtest{}, candlestick.Candlestick{}, fmt.Errorf("could not get backtest from service: %w", err)
-	}
-
-	// Get candlestick for the time
+	bt backtest.Backtest,
+	ord order.Order,
+) (candlestick.Candlestick, error) {
 	csRes, err := wf.cryptellation.ListCandlesticks(ctx, candlesticksapi.ListCandlesticksWorkflowParams{
-		Exchange: params.Order.Exchange,
-		Pair:     params.Order.Pair,
-		Period:   dbBtRes.Backtest.PricePeriod,
-		Start:    &dbBtRes.Backtest.CurrentCandlestick.Time,
-		End:      &dbBtRes.Backtest.CurrentCandlestick.Time,
+		Exchange: ord.Exchange,
+		Pair:     ord.Pair,
+		Period:   bt.PricePeriod,
+		Start:    &bt.CurrentCandlestick.Time,
+		End:      &bt.CurrentCandlestick.Time,
 		Limit:    0,
 	}, nil)
 	if err != nil {
-		return backtest.Backtest{}, candlestick.Candlestick{},
-			fmt.Errorf("could not get candlesticks from service: %w", err)
+		return candlestick.Candlestick{}, fmt.Errorf("could not get candlesticks from service: %w", err)
 	}
 
 	// Check if we have a candlestick
 	if len(csRes.List) == 0 {
-		return backtest.Backtest{}, candlestick.Candlestick{},
+		return candlestick.Candlestick{},
 			fmt.Errorf("%w: %d candlesticks retrieved", backtest.ErrNoDataForOrderValidation, len(csRes.List))
 	}
-	cs := csRes.List[0]
 
-	return dbBtRes.Backtest, cs, nil
+	return csRes.List[0], nil
 }
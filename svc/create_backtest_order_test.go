@@ -0,0 +1,163 @@
+//go:build unit
+// +build unit
+
+package svc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/backtests/svc/db"
+	candlesticksapi "github.com/cryptellation/candlesticks/api"
+	"github.com/cryptellation/candlesticks/pkg/candlestick"
+	"github.com/cryptellation/runtime"
+	"github.com/cryptellation/runtime/account"
+	"github.com/cryptellation/runtime/order"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+func TestCreateBacktestOrderSuite(t *testing.T) {
+	suite.Run(t, new(CreateBacktestOrderSuite))
+}
+
+type CreateBacktestOrderSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+}
+
+// versionedBacktests is an in-memory database of a single backtest, rejecting
+// the updates from an outdated version. The first two reads wait for each
+// other, so that the two updates following them conflict.
+type versionedBacktests struct {
+	db.DB
+
+	mu        sync.Mutex
+	bt        backtest.Backtest
+	reads     int
+	bothRead  chan struct{}
+	conflicts int
+}
+
+func newVersionedBacktests(bt backtest.Backtest) *versionedBacktests {
+	bt.Version = 1
+	return &versionedBacktests{
+		bt:       bt,
+		bothRead: make(chan struct{}),
+	}
+}
+
+func (d *versionedBacktests) ReadBacktestActivity(
+	_ context.Context,
+	_ db.ReadBacktestActivityParams,
+) (db.ReadBacktestActivityResults, error) {
+	d.mu.Lock()
+	d.reads++
+	if d.reads == 2 {
+		close(d.bothRead)
+	}
+	reads := d.reads
+	bt := d.bt
+	d.mu.Unlock()
+
+	if reads <= 2 {
+		select {
+		case <-d.bothRead:
+		case <-time.After(5 * time.Second):
+		}
+	}
+
+	return db.ReadBacktestActivityResults{Backtest: bt}, nil
+}
+
+func (d *versionedBacktests) UpdateBacktestActivity(
+	_ context.Context,
+	params db.UpdateBacktestActivityParams,
+) (db.UpdateBacktestActivityResults, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if params.Backtest.Version != d.bt.Version {
+		d.conflicts++
+		return db.UpdateBacktestActivityResults{}, api.NewApplicationError(db.ErrVersionConflict, db.ErrorTypes)
+	}
+
+	d.bt = params.Backtest
+	d.bt.Version++
+	return db.UpdateBacktestActivityResults{Version: d.bt.Version}, nil
+}
+
+// fixedCandlesticks returns a candlestick with the same prices at any time.
+type fixedCandlesticks struct{}
+
+func (fixedCandlesticks) ListCandlesticks(
+	_ workflow.Context,
+	params candlesticksapi.ListCandlesticksWorkflowParams,
+	_ *workflow.ChildWorkflowOptions,
+) (candlesticksapi.ListCandlesticksWorkflowResults, error) {
+	return candlesticksapi.ListCandlesticksWorkflowResults{
+		List: []candlestick.Candlestick{{Time: *params.Start, Open: 10, High: 10, Low: 10, Close: 10}},
+	}, nil
+}
+
+func (suite *CreateBacktestOrderSuite) TestConcurrentAdvance() {
+	bt, err := backtest.New(backtest.Parameters{
+		Accounts: map[string]account.Account{
+			"exchange": {Balances: map[string]float64{"USDT": 1000}},
+		},
+		StartTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, runtime.Callbacks{})
+	suite.Require().NoError(err)
+
+	store := newVersionedBacktests(bt)
+	wf := &workflows{db: store, cryptellation: fixedCandlesticks{}}
+
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterActivityWithOptions(store.ReadBacktestActivity,
+		activity.RegisterOptions{Name: db.ReadBacktestActivityName})
+	env.RegisterActivityWithOptions(store.UpdateBacktestActivity,
+		activity.RegisterOptions{Name: db.UpdateBacktestActivityName})
+	// Create the order while the backtest is advanced
+	env.RegisterWorkflowWithOptions(func(ctx workflow.Context) error {
+		var advanceErr error
+		advanced := workflow.NewChannel(ctx)
+		workflow.Go(ctx, func(ctx workflow.Context) {
+			_, _, advanceErr = wf.advanceBacktest(ctx, bt.ID)
+			advanced.Send(ctx, nil)
+		})
+
+		_, err := wf.CreateBacktestOrderWorkflow(ctx, api.CreateBacktestOrderWorkflowParams{
+			BacktestID: bt.ID,
+			Order: order.Order{
+				Type:     order.TypeIsMarket,
+				Exchange: "exchange",
+				Pair:     "BTC-USDT",
+				Side:     order.SideIsBuy,
+				Quantity: 1,
+			},
+		})
+		advanced.Receive(ctx, nil)
+		return errors.Join(err, advanceErr)
+	}, workflow.RegisterOptions{Name: "CreateOrderWhileAdvancing"})
+	env.OnSignalExternalWorkflow(mock.Anything, mock.Anything, "", api.BacktestEventSignalName, mock.Anything).
+		Return(nil)
+
+	env.ExecuteWorkflow("CreateOrderWhileAdvancing")
+	suite.Require().True(env.IsWorkflowCompleted())
+	suite.Require().NoError(env.GetWorkflowError())
+
+	// Both updates are kept
+	suite.Require().Equal(1, store.conflicts)
+	suite.Require().Equal(int64(3), store.bt.Version)
+	suite.Require().Len(store.bt.Orders, 1)
+	suite.Require().Equal(map[string]float64{"USDT": 990, "BTC": 1}, store.bt.Accounts["exchange"].Balances)
+	suite.Require().NotEqual(bt.CurrentCandlestick, store.bt.CurrentCandlestick)
+}
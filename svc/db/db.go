@@ -7,6 +7,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/cryptellation/backtests/api"
 	"github.com/cryptellation/backtests/pkg/backtest"
 	"github.com/google/uuid"
 	"go.temporal.io/sdk/temporal"
@@ -21,6 +22,9 @@ type (
 	// CreateBacktestActivityParams is the parameters of the CreateBacktestActivity activity.
 	CreateBacktestActivityParams struct {
 		Backtest backtest.Backtest
+		// Webhooks replace the webhooks of the backtest if set, with their
+		// secrets that are not serialized with the backtest.
+		Webhooks []backtest.WebhookDefinition
 	}
 
 	// CreateBacktestActivityResults is the results of the CreateBacktestActivity activity.
@@ -33,7 +37,8 @@ const ReadBacktestActivityName = "ReadBacktestActivity"
 type (
 	// ReadBacktestActivityParams is the parameters of the ReadBacktestActivity activity.
 	ReadBacktestActivityParams struct {
-		ID uuid.UUID
+		ID     uuid.UUID
+		Tenant string
 	}
 
 	// ReadBacktestActivityResults is the results of the ReadBacktestActivity activity.
@@ -47,11 +52,24 @@ const ListBacktestsActivityName = "ListBacktestsActivity"
 
 type (
 	// ListBacktestsActivityParams is the parameters of the ListBacktestsActivity activity.
-	ListBacktestsActivityParams struct{}
+	ListBacktestsActivityParams struct {
+		Filters backtest.Filters
+		Sort    backtest.Sort
+		// Cursor is the cursor returned with the previous page, empty for the first one.
+		Cursor string
+		// Limit is the maximum count of backtests per page. There is no limit if 0 or less.
+		Limit int
+		// SummaryOnly returns the summaries of the backtests instead of the backtests.
+		SummaryOnly bool
+		Tenant      string
+	}
 
 	// ListBacktestsActivityResults is the results of the ListBacktestsActivity activity.
 	ListBacktestsActivityResults struct {
 		Backtests []backtest.Backtest
+		Summaries []backtest.Summary
+		// NextCursor is the cursor of the next page, empty if there is none.
+		NextCursor string
 	}
 )
 
@@ -60,12 +78,32 @@ const UpdateBacktestActivityName = "UpdateBacktestActivity"
 
 type (
 	// UpdateBacktestActivityParams is the parameters of the UpdateBacktestActivity activity.
+	// The update fails with ErrVersionConflict if the version of the backtest
+	// is not the one in database.
 	UpdateBacktestActivityParams struct {
 		Backtest backtest.Backtest
 	}
 
 	// UpdateBacktestActivityResults is the results of the UpdateBacktestActivity activity.
-	UpdateBacktestActivityResults struct{}
+	UpdateBacktestActivityResults struct {
+		// Version is the new version of the backtest.
+		Version int64
+	}
+)
+
+// UpdateBacktestMetadataActivityName is the name of the activity to update the
+// metadata of a backtest.
+const UpdateBacktestMetadataActivityName = "UpdateBacktestMetadataActivity"
+
+type (
+	// UpdateBacktestMetadataActivityParams is the parameters of the UpdateBacktestMetadataActivity activity.
+	// Only the name, description and tags of the backtest are updated.
+	UpdateBacktestMetadataActivityParams struct {
+		Backtest backtest.Backtest
+	}
+
+	// UpdateBacktestMetadataActivityResults is the results of the UpdateBacktestMetadataActivity activity.
+	UpdateBacktestMetadataActivityResults struct{}
 )
 
 // DeleteBacktestActivityName is the name of the activity to delete a backtest.
@@ -73,15 +111,81 @@ const DeleteBacktestActivityName = "DeleteBacktestActivity"
 
 type (
 	// DeleteBacktestActivityParams is the parameters of the DeleteBacktestActivity activity.
+	// It fails with backtest.ErrBacktestRunning if the backtest is running.
 	DeleteBacktestActivityParams struct {
-		ID uuid.UUID
+		ID     uuid.UUID
+		Tenant string
 	}
 
 	// DeleteBacktestActivityResults is the results of the DeleteBacktestActivity activity.
 	DeleteBacktestActivityResults struct{}
 )
 
-// DB is the interface for the backtest activity database.
+// PurgeBacktestsActivityName is the name of the activity to purge backtests.
+const PurgeBacktestsActivityName = "PurgeBacktestsActivity"
+
+type (
+	// PurgeBacktestsActivityParams is the parameters of the PurgeBacktestsActivity activity.
+	// Running backtests are never purged.
+	PurgeBacktestsActivityParams struct {
+		// CreatedBefore purges the backtests created before this time.
+		CreatedBefore *time.Time
+		// Statuses purges the backtests having one of these statuses.
+		Statuses []backtest.Status
+		// Tags purges the backtests having all these tags.
+		Tags []string
+		// Limit is the maximum count of backtests purged at once.
+		// There is no limit if 0 or less.
+		Limit  int
+		Tenant string
+		// AllTenants purges the backtests of every tenant, ignoring Tenant.
+		// It is reserved to the administration commands.
+		AllTenants bool
+	}
+
+	// PurgeBacktestsActivityResults is the results of the PurgeBacktestsActivity activity.
+	PurgeBacktestsActivityResults struct {
+		Deleted int
+	}
+)
+
+// CreateBacktestSnapshotActivityName is the name of the activity to create a backtest snapshot.
+const CreateBacktestSnapshotActivityName = "CreateBacktestSnapshotActivity"
+
+type (
+	// CreateBacktestSnapshotActivityParams is the parameters of the CreateBacktestSnapshotActivity activity.
+	CreateBacktestSnapshotActivityParams struct {
+		Snapshot backtest.Snapshot
+		Tenant   string
+	}
+
+	// CreateBacktestSnapshotActivityResults is the results of the CreateBacktestSnapshotActivity activity.
+	CreateBacktestSnapshotActivityResults struct{}
+)
+
+// ReadBacktestSnapshotActivityName is the name of the activity to read a backtest snapshot.
+const ReadBacktestSnapshotActivityName = "ReadBacktestSnapshotActivity"
+
+type (
+	// ReadBacktestSnapshotActivityParams is the parameters of the ReadBacktestSnapshotActivity activity.
+	ReadBacktestSnapshotActivityParams struct {
+		BacktestID uuid.UUID
+		// Time is the time of the snapshot. If there is no snapshot at this time,
+		// the latest snapshot before it is returned.
+		Time   time.Time
+		Tenant string
+	}
+
+	// ReadBacktestSnapshotActivityResults is the results of the ReadBacktestSnapshotActivity activity.
+	ReadBacktestSnapshotActivityResults struct {
+		Snapshot backtest.Snapshot
+	}
+)
+
+// DB is the interface for the backtest activity database. The activities only
+// work on the backtests of the tenant given in their parameters, or of the
+// backtest itself for the creations and updates: the backtests of the other
+// tenants are not found.
 type DB interface {
 	Register(w worker.Worker)
 
@@ -101,21 +205,34 @@ type DB interface {
 		ctx context.Context,
 		params UpdateBacktestActivityParams,
 	) (UpdateBacktestActivityResults, error)
+	UpdateBacktestMetadataActivity(
+		ctx context.Context,
+		params UpdateBacktestMetadataActivityParams,
+	) (UpdateBacktestMetadataActivityResults, error)
 	DeleteBacktestActivity(
 		ctx context.Context,
 		params DeleteBacktestActivityParams,
 	) (DeleteBacktestActivityResults, error)
+	PurgeBacktestsActivity(
+		ctx context.Context,
+		params PurgeBacktestsActivityParams,
+	) (PurgeBacktestsActivityResults, error)
+
+	CreateBacktestSnapshotActivity(
+		ctx context.Context,
+		params CreateBacktestSnapshotActivityParams,
+	) (CreateBacktestSnapshotActivityResults, error)
+	ReadBacktestSnapshotActivity(
+		ctx context.Context,
+		params ReadBacktestSnapshotActivityParams,
+	) (ReadBacktestSnapshotActivityResults, error)
 }
 
 // DefaultActivityOptions returns the default database activities options.
 func DefaultActivityOptions() workflow.ActivityOptions {
 	return workflow.ActivityOptions{
 		RetryPolicy: &temporal.RetryPolicy{
-			NonRetryableErrorTypes: []string{
-				ErrNilID.Error(),
-				ErrNotFound.Error(),
-				ErrNotImplemented.Error(),
-			},
+			NonRetryableErrorTypes: api.ErrorTypeNames(ErrorTypes),
 		},
 		StartToCloseTimeout:    10 * time.Second,
 		ScheduleToCloseTimeout: 10 * time.Second,
@@ -2,13 +2,37 @@ package db
 
 import (
 	"errors"
+
+	"github.com/cryptellation/backtests/api"
+	"go.temporal.io/sdk/temporal"
 )
 
+// The database errors are defined in the api package, with the other errors
+// returned to the clients.
 var (
 	// ErrNilID is returned when the ID is nil.
-	ErrNilID = errors.New("ID is nil")
+	ErrNilID = api.ErrNilID
 	// ErrNotFound is returned when the record is not found.
-	ErrNotFound = errors.New("not found")
+	ErrNotFound = api.ErrNotFound
 	// ErrNotImplemented is returned when the method is not implemented.
-	ErrNotImplemented = errors.New("not implemented")
+	ErrNotImplemented = api.ErrNotImplemented
+	// ErrInvalidCursor is returned when the pagination cursor is invalid.
+	ErrInvalidCursor = api.ErrInvalidCursor
+	// ErrVersionConflict is returned when a record is updated from an outdated
+	// version, as it has been updated concurrently since it was read.
+	ErrVersionConflict = api.ErrVersionConflict
 )
+
+// ErrorTypes are the database errors that can be returned by the activities.
+// They are all non-retryable.
+var ErrorTypes = api.DBErrorTypes
+
+// IsVersionConflict returns true if the error is a version conflict, either
+// directly or as an application error returned by an activity.
+func IsVersionConflict(err error) bool {
+	var appErr *temporal.ApplicationError
+	if errors.As(err, &appErr) && appErr.Type() == api.VersionConflictErrorType {
+		return true
+	}
+	return errors.Is(err, ErrVersionConflict)
+}
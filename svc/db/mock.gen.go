@@ -50,6 +50,21 @@ func (mr *MockDBMockRecorder) CreateBacktestActivity(ctx, params interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBacktestActivity", reflect.TypeOf((*MockDB)(nil).CreateBacktestActivity), ctx, params)
 }
 
+// CreateBacktestSnapshotActivity mocks base method.
+func (m *MockDB) CreateBacktestSnapshotActivity(ctx context.Context, params CreateBacktestSnapshotActivityParams) (CreateBacktestSnapshotActivityResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBacktestSnapshotActivity", ctx, params)
+	ret0, _ := ret[0].(CreateBacktestSnapshotActivityResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBacktestSnapshotActivity indicates an expected call of CreateBacktestSnapshotActivity.
+func (mr *MockDBMockRecorder) CreateBacktestSnapshotActivity(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBacktestSnapshotActivity", reflect.TypeOf((*MockDB)(nil).CreateBacktestSnapshotActivity), ctx, params)
+}
+
 // DeleteBacktestActivity mocks base method.
 func (m *MockDB) DeleteBacktestActivity(ctx context.Context, params DeleteBacktestActivityParams) (DeleteBacktestActivityResults, error) {
 	m.ctrl.T.Helper()
@@ -80,6 +95,21 @@ func (mr *MockDBMockRecorder) ListBacktestsActivity(ctx, params interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBacktestsActivity", reflect.TypeOf((*MockDB)(nil).ListBacktestsActivity), ctx, params)
 }
 
+// PurgeBacktestsActivity mocks base method.
+func (m *MockDB) PurgeBacktestsActivity(ctx context.Context, params PurgeBacktestsActivityParams) (PurgeBacktestsActivityResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeBacktestsActivity", ctx, params)
+	ret0, _ := ret[0].(PurgeBacktestsActivityResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeBacktestsActivity indicates an expected call of PurgeBacktestsActivity.
+func (mr *MockDBMockRecorder) PurgeBacktestsActivity(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeBacktestsActivity", reflect.TypeOf((*MockDB)(nil).PurgeBacktestsActivity), ctx, params)
+}
+
 // ReadBacktestActivity mocks base method.
 func (m *MockDB) ReadBacktestActivity(ctx context.Context, params ReadBacktestActivityParams) (ReadBacktestActivityResults, error) {
 	m.ctrl.T.Helper()
@@ -95,6 +125,21 @@ func (mr *MockDBMockRecorder) ReadBacktestActivity(ctx, params interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadBacktestActivity", reflect.TypeOf((*MockDB)(nil).ReadBacktestActivity), ctx, params)
 }
 
+// ReadBacktestSnapshotActivity mocks base method.
+func (m *MockDB) ReadBacktestSnapshotActivity(ctx context.Context, params ReadBacktestSnapshotActivityParams) (ReadBacktestSnapshotActivityResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadBacktestSnapshotActivity", ctx, params)
+	ret0, _ := ret[0].(ReadBacktestSnapshotActivityResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadBacktestSnapshotActivity indicates an expected call of ReadBacktestSnapshotActivity.
+func (mr *MockDBMockRecorder) ReadBacktestSnapshotActivity(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadBacktestSnapshotActivity", reflect.TypeOf((*MockDB)(nil).ReadBacktestSnapshotActivity), ctx, params)
+}
+
 // Register mocks base method.
 func (m *MockDB) Register(w worker.Worker) {
 	m.ctrl.T.Helper()
@@ -121,3 +166,18 @@ func (mr *MockDBMockRecorder) UpdateBacktestActivity(ctx, params interface{}) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBacktestActivity", reflect.TypeOf((*MockDB)(nil).UpdateBacktestActivity), ctx, params)
 }
+
+// UpdateBacktestMetadataActivity mocks base method.
+func (m *MockDB) UpdateBacktestMetadataActivity(ctx context.Context, params UpdateBacktestMetadataActivityParams) (UpdateBacktestMetadataActivityResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBacktestMetadataActivity", ctx, params)
+	ret0, _ := ret[0].(UpdateBacktestMetadataActivityResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateBacktestMetadataActivity indicates an expected call of UpdateBacktestMetadataActivity.
+func (mr *MockDBMockRecorder) UpdateBacktestMetadataActivity(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBacktestMetadataActivity", reflect.TypeOf((*MockDB)(nil).UpdateBacktestMetadataActivity), ctx, params)
+}
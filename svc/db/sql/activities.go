@@ -2,8 +2,11 @@ package sql
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 
+	"github.com/cryptellation/backtests/api"
 	"github.com/cryptellation/backtests/pkg/backtest"
 	"github.com/cryptellation/backtests/svc/db"
 	"github.com/cryptellation/backtests/svc/db/sql/entities"
@@ -22,6 +25,13 @@ type Activities struct {
 	db *sqlx.DB
 }
 
+// NewFromDB creates a new activities from an existing database connection.
+func NewFromDB(db *sqlx.DB) *Activities {
+	return &Activities{
+		db: db,
+	}
+}
+
 // New creates a new activities.
 func New(ctx context.Context, dsn string) (*Activities, error) {
 	// Create embedded database access
@@ -41,29 +51,88 @@ func New(ctx context.Context, dsn string) (*Activities, error) {
 // Register registers the activities to the worker.
 func (a *Activities) Register(w worker.Worker) {
 	w.RegisterActivityWithOptions(
-		a.CreateBacktestActivity,
+		withApplicationErrors(a.CreateBacktestActivity),
 		activity.RegisterOptions{Name: db.CreateBacktestActivityName},
 	)
 
 	w.RegisterActivityWithOptions(
-		a.ReadBacktestActivity,
+		withApplicationErrors(a.ReadBacktestActivity),
 		activity.RegisterOptions{Name: db.ReadBacktestActivityName},
 	)
 
 	w.RegisterActivityWithOptions(
-		a.ListBacktestsActivity,
+		withApplicationErrors(a.ListBacktestsActivity),
 		activity.RegisterOptions{Name: db.ListBacktestsActivityName},
 	)
 
 	w.RegisterActivityWithOptions(
-		a.UpdateBacktestActivity,
+		withApplicationErrors(a.UpdateBacktestActivity),
 		activity.RegisterOptions{Name: db.UpdateBacktestActivityName},
 	)
 
 	w.RegisterActivityWithOptions(
-		a.DeleteBacktestActivity,
+		withApplicationErrors(a.UpdateBacktestMetadataActivity),
+		activity.RegisterOptions{Name: db.UpdateBacktestMetadataActivityName},
+	)
+
+	w.RegisterActivityWithOptions(
+		withApplicationErrors(a.DeleteBacktestActivity),
 		activity.RegisterOptions{Name: db.DeleteBacktestActivityName},
 	)
+
+	w.RegisterActivityWithOptions(
+		withApplicationErrors(a.PurgeBacktestsActivity),
+		activity.RegisterOptions{Name: db.PurgeBacktestsActivityName},
+	)
+
+	w.RegisterActivityWithOptions(
+		withApplicationErrors(a.CreateBacktestSnapshotActivity),
+		activity.RegisterOptions{Name: db.CreateBacktestSnapshotActivityName},
+	)
+
+	w.RegisterActivityWithOptions(
+		withApplicationErrors(a.ReadBacktestSnapshotActivity),
+		activity.RegisterOptions{Name: db.ReadBacktestSnapshotActivityName},
+	)
+}
+
+// withApplicationErrors converts the database errors returned by an activity,
+// and the domain ones like a running backtest that cannot be deleted, into
+// application errors, in order to keep their type across Temporal.
+func withApplicationErrors[P, R any](
+	fn func(context.Context, P) (R, error),
+) func(context.Context, P) (R, error) {
+	return func(ctx context.Context, params P) (R, error) {
+		res, err := fn(ctx, params)
+		return res, api.NewApplicationError(err, api.ErrorTypes)
+	}
+}
+
+// readTxOptions are the options of the transactions reading backtests, in
+// order to read their rows from all the tables at the same point in time.
+var readTxOptions = &sql.TxOptions{
+	Isolation: sql.LevelRepeatableRead,
+	ReadOnly:  true,
+}
+
+// inTx executes the function in a transaction, that is committed if the
+// function succeeds and rolled back otherwise.
+func (a *Activities) inTx(ctx context.Context, opts *sql.TxOptions, fn func(tx *sqlx.Tx) error) error {
+	tx, err := a.db.BeginTxx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return nil
 }
 
 // Reset will reset the database.
@@ -86,20 +155,30 @@ func (a *Activities) CreateBacktestActivity(
 		return db.CreateBacktestActivityResults{}, db.ErrNilID
 	}
 
+	// Set the webhooks with their secrets
+	if len(params.Webhooks) > 0 {
+		params.Backtest.Webhooks = backtest.NewWebhooks(params.Webhooks)
+	}
+
 	// Change backtest model to entity
 	entity, err := entities.FromBacktestModel(params.Backtest)
 	if err != nil {
 		return db.CreateBacktestActivityResults{}, err
 	}
 
-	// Insert the backtest
-	_, err = a.db.NamedExecContext(
-		ctx,
-		`INSERT INTO backtests (id, data)
-		VALUES (:id, :data)`,
-		entity)
+	// Insert the backtest and its rows of the other tables
+	err = a.inTx(ctx, nil, func(tx *sqlx.Tx) error {
+		_, err := tx.NamedExecContext(ctx,
+			`INSERT INTO backtests (`+backtestColumns+`) VALUES (`+namedColumns(backtestColumns)+`)`,
+			entity)
+		if err != nil {
+			return fmt.Errorf("inserting backtest: %w", err)
+		}
+
+		return writeBacktestRows(ctx, tx, entity)
+	})
 	if err != nil {
-		return db.CreateBacktestActivityResults{}, fmt.Errorf("inserting backtest: %w", err)
+		return db.CreateBacktestActivityResults{}, err
 	}
 
 	return db.CreateBacktestActivityResults{}, nil
@@ -117,10 +196,27 @@ func (a *Activities) ReadBacktestActivity(
 		return db.ReadBacktestActivityResults{}, db.ErrNilID
 	}
 
-	// Read the backtest
-	err := a.db.GetContext(ctx, &entity, "SELECT * FROM backtests WHERE id = $1", params.ID)
+	// Read the backtest and its rows of the other tables
+	err := a.inTx(ctx, readTxOptions, func(tx *sqlx.Tx) error {
+		err := tx.GetContext(ctx, &entity,
+			"SELECT "+backtestColumns+" FROM backtests WHERE id = $1 AND tenant = $2",
+			params.ID, params.Tenant)
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.ErrNotFound
+		} else if err != nil {
+			return fmt.Errorf("reading backtest: %w", err)
+		}
+
+		bts := []entities.Backtest{entity}
+		if err := readBacktestsRows(ctx, tx, bts, true); err != nil {
+			return err
+		}
+		entity = bts[0]
+
+		return nil
+	})
 	if err != nil {
-		return db.ReadBacktestActivityResults{}, fmt.Errorf("reading backtest: %w", err)
+		return db.ReadBacktestActivityResults{}, err
 	}
 
 	// Convert the entity to the model
@@ -135,30 +231,82 @@ func (a *Activities) ReadBacktestActivity(
 // ListBacktestsActivity lists backtests from the database.
 func (a *Activities) ListBacktestsActivity(
 	ctx context.Context,
-	_ db.ListBacktestsActivityParams,
+	params db.ListBacktestsActivityParams,
 ) (db.ListBacktestsActivityResults, error) {
-	var entities []entities.Backtest
+	// Build the query
+	var q listQuery
+	query, args, err := q.build(params)
+	if err != nil {
+		return db.ListBacktestsActivityResults{}, err
+	}
 
 	// Read the backtests
-	err := a.db.SelectContext(ctx, &entities, "SELECT * FROM backtests")
+	rows, err := a.readListRows(ctx, query, args, !params.SummaryOnly)
 	if err != nil {
-		return db.ListBacktestsActivityResults{}, fmt.Errorf("reading backtests: %w", err)
+		return db.ListBacktestsActivityResults{}, err
+	}
+
+	// Set the cursor of the next page if there is one
+	var res db.ListBacktestsActivityResults
+	if params.Limit > 0 && len(rows) > params.Limit {
+		rows = rows[:params.Limit]
+		last := rows[len(rows)-1]
+		res.NextCursor, err = encodeListCursor(listCursor{Value: last.SortValue, ID: last.ID})
+		if err != nil {
+			return db.ListBacktestsActivityResults{}, fmt.Errorf("encoding cursor: %w", err)
+		}
 	}
 
 	// Convert the entities to models
-	models := make([]backtest.Backtest, 0, len(entities))
-	for _, e := range entities {
-		m, err := e.ToModel()
+	for _, r := range rows {
+		if params.SummaryOnly {
+			m, err := r.BacktestSummary.ToModel()
+			if err != nil {
+				return db.ListBacktestsActivityResults{}, fmt.Errorf("converting entity to model: %w", err)
+			}
+			res.Summaries = append(res.Summaries, m)
+			continue
+		}
+
+		m, err := r.Backtest.ToModel()
 		if err != nil {
 			return db.ListBacktestsActivityResults{}, fmt.Errorf("converting entity to model: %w", err)
 		}
-		models = append(models, m)
+		res.Backtests = append(res.Backtests, m)
 	}
 
-	return db.ListBacktestsActivityResults{Backtests: models}, nil
+	return res, nil
 }
 
-// UpdateBacktestActivity updates the backtest in the database.
+// readListRows reads the backtests returned by the list query and their rows
+// of the other tables. The orders are only read if requested.
+func (a *Activities) readListRows(ctx context.Context, query string, args []any, withOrders bool) ([]listRow, error) {
+	var rows []listRow
+	err := a.inTx(ctx, readTxOptions, func(tx *sqlx.Tx) error {
+		if err := tx.SelectContext(ctx, &rows, query, args...); err != nil {
+			return fmt.Errorf("reading backtests: %w", err)
+		}
+
+		bts := make([]entities.Backtest, len(rows))
+		for i, r := range rows {
+			bts[i] = r.Backtest
+		}
+		if err := readBacktestsRows(ctx, tx, bts, withOrders); err != nil {
+			return err
+		}
+		for i := range rows {
+			rows[i].Backtest = bts[i]
+		}
+
+		return nil
+	})
+	return rows, err
+}
+
+// UpdateBacktestActivity updates the backtest in the database, except its
+// metadata that is updated with UpdateBacktestMetadataActivity. Only the rows
+// of the other tables that changed are written. The update is rejected with
+// db.ErrVersionConflict if the backtest has been updated since it was read.
 func (a *Activities) UpdateBacktestActivity(
 	ctx context.Context,
 	params db.UpdateBacktestActivityParams,
@@ -174,21 +322,93 @@ func (a *Activities) UpdateBacktestActivity(
 		return db.UpdateBacktestActivityResults{}, err
 	}
 
-	// Update the backtest
-	_, err = a.db.NamedExecContext(
+	// Update the backtest and its rows of the other tables
+	err = a.inTx(ctx, nil, func(tx *sqlx.Tx) error {
+		if err := updateBacktestRow(ctx, tx, entity); err != nil {
+			return err
+		}
+		return writeBacktestRows(ctx, tx, entity)
+	})
+	if err != nil {
+		return db.UpdateBacktestActivityResults{}, err
+	}
+
+	return db.UpdateBacktestActivityResults{Version: entity.Version + 1}, nil
+}
+
+// updateBacktestRow updates the row of the backtest in the backtests table if
+// its version is still the one of the entity, and increments it. The webhooks
+// are set on creation only, as their secrets are not read back by the workflows.
+func updateBacktestRow(ctx context.Context, tx *sqlx.Tx, entity entities.Backtest) error {
+	res, err := tx.NamedExecContext(ctx,
+		`UPDATE backtests
+		SET status = :status, start_time = :start_time, end_time = :end_time, mode = :mode,
+			price_period = :price_period, current_candlestick_time = :current_candlestick_time,
+			current_price_type = :current_price_type, gap_policy = :gap_policy, gaps = :gaps,
+			wake_up = :wake_up, snapshot_interval = :snapshot_interval, forked_from = :forked_from,
+			strategy_parameters = :strategy_parameters, fees = :fees,
+			version = version + 1
+		WHERE id = :id AND tenant = :tenant AND version = :version`,
+		entity)
+	if err != nil {
+		return fmt.Errorf("updating backtest: %w", err)
+	}
+
+	// Check the backtest exists for the tenant with the same version
+	err = checkAffected(res)
+	if !errors.Is(err, db.ErrNotFound) {
+		return err
+	}
+
+	var exists bool
+	err = tx.GetContext(ctx, &exists,
+		"SELECT EXISTS (SELECT 1 FROM backtests WHERE id = $1 AND tenant = $2)",
+		entity.ID, entity.Tenant)
+	if err != nil {
+		return fmt.Errorf("checking backtest existence: %w", err)
+	} else if !exists {
+		return db.ErrNotFound
+	}
+
+	return fmt.Errorf("%w: backtest %s updated since version %d", db.ErrVersionConflict, entity.ID, entity.Version)
+}
+
+// UpdateBacktestMetadataActivity updates the name, description and tags of the
+// backtest in the database.
+func (a *Activities) UpdateBacktestMetadataActivity(
+	ctx context.Context,
+	params db.UpdateBacktestMetadataActivityParams,
+) (db.UpdateBacktestMetadataActivityResults, error) {
+	// Check ID is not nil
+	if params.Backtest.ID == uuid.Nil {
+		return db.UpdateBacktestMetadataActivityResults{}, db.ErrNilID
+	}
+
+	// Update the metadata
+	entity := entities.Backtest{
+		ID:       params.Backtest.ID.String(),
+		Metadata: entities.FromMetadataModel(params.Backtest),
+	}
+	res, err := a.db.NamedExecContext(
 		ctx,
 		`UPDATE backtests
-		SET data = :data
-		WHERE id = :id`,
+		SET name = :name, description = :description, tags = :tags
+		WHERE id = :id AND tenant = :tenant`,
 		entity)
 	if err != nil {
-		return db.UpdateBacktestActivityResults{}, fmt.Errorf("updating backtest: %w", err)
+		return db.UpdateBacktestMetadataActivityResults{}, fmt.Errorf("updating backtest metadata: %w", err)
 	}
 
-	return db.UpdateBacktestActivityResults{}, nil
+	// Check the backtest exists for the tenant
+	if err := checkAffected(res); err != nil {
+		return db.UpdateBacktestMetadataActivityResults{}, err
+	}
+
+	return db.UpdateBacktestMetadataActivityResults{}, nil
 }
 
-// DeleteBacktestActivity deletes the backtest from the database.
+// DeleteBacktestActivity deletes the backtest from the database, unless it is
+// running.
 func (a *Activities) DeleteBacktestActivity(
 	ctx context.Context,
 	params db.DeleteBacktestActivityParams,
@@ -198,11 +418,137 @@ func (a *Activities) DeleteBacktestActivity(
 		return db.DeleteBacktestActivityResults{}, db.ErrNilID
 	}
 
-	// Delete the backtest
-	_, err := a.db.ExecContext(ctx, "DELETE FROM backtests WHERE id = $1", params.ID)
+	// Delete the backtest, checking its status in the same statement
+	res, err := a.db.ExecContext(ctx,
+		"DELETE FROM backtests WHERE id = $1 AND tenant = $2 AND status <> $3",
+		params.ID, params.Tenant, backtest.StatusRunning.String())
 	if err != nil {
 		return db.DeleteBacktestActivityResults{}, fmt.Errorf("deleting backtest: %w", err)
+	} else if err := checkAffected(res); !errors.Is(err, db.ErrNotFound) {
+		return db.DeleteBacktestActivityResults{}, err
+	}
+
+	// Tell the running backtests from the missing ones
+	var exists bool
+	err = a.db.GetContext(ctx, &exists,
+		"SELECT EXISTS(SELECT 1 FROM backtests WHERE id = $1 AND tenant = $2)",
+		params.ID, params.Tenant)
+	switch {
+	case err != nil:
+		return db.DeleteBacktestActivityResults{}, fmt.Errorf("checking backtest: %w", err)
+	case exists:
+		return db.DeleteBacktestActivityResults{}, fmt.Errorf("%w: %s", backtest.ErrBacktestRunning, params.ID)
+	default:
+		return db.DeleteBacktestActivityResults{}, fmt.Errorf("%w: backtest %s", db.ErrNotFound, params.ID)
+	}
+}
+
+// PurgeBacktestsActivity deletes the backtests matching the filters from the
+// database, except the running ones.
+func (a *Activities) PurgeBacktestsActivity(
+	ctx context.Context,
+	params db.PurgeBacktestsActivityParams,
+) (db.PurgeBacktestsActivityResults, error) {
+	// Build the query
+	var q listQuery
+	query, args, err := q.buildPurge(params)
+	if err != nil {
+		return db.PurgeBacktestsActivityResults{}, err
+	}
+
+	// Delete the backtests
+	res, err := a.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return db.PurgeBacktestsActivityResults{}, fmt.Errorf("purging backtests: %w", err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return db.PurgeBacktestsActivityResults{}, fmt.Errorf("counting purged backtests: %w", err)
+	}
+
+	return db.PurgeBacktestsActivityResults{Deleted: int(deleted)}, nil
+}
+
+// CreateBacktestSnapshotActivity creates a backtest snapshot in the database.
+// An existing snapshot at the same time is replaced.
+func (a *Activities) CreateBacktestSnapshotActivity(
+	ctx context.Context,
+	params db.CreateBacktestSnapshotActivityParams,
+) (db.CreateBacktestSnapshotActivityResults, error) {
+	// Check ID is not nil
+	if params.Snapshot.BacktestID == uuid.Nil {
+		return db.CreateBacktestSnapshotActivityResults{}, db.ErrNilID
 	}
 
-	return db.DeleteBacktestActivityResults{}, nil
+	// Change snapshot model to entity
+	entity, err := entities.FromSnapshotModel(params.Snapshot)
+	if err != nil {
+		return db.CreateBacktestSnapshotActivityResults{}, err
+	}
+
+	// Insert the snapshot if the backtest exists for the tenant
+	res, err := a.db.ExecContext(
+		ctx,
+		`INSERT INTO backtest_snapshots (backtest_id, time, data)
+		SELECT $1::varchar, $2::timestamp, $3::jsonb
+		WHERE EXISTS (SELECT 1 FROM backtests WHERE id = $1 AND tenant = $4)
+		ON CONFLICT (backtest_id, time) DO UPDATE SET data = EXCLUDED.data`,
+		entity.BacktestID, entity.Time, entity.Data, params.Tenant)
+	if err != nil {
+		return db.CreateBacktestSnapshotActivityResults{}, fmt.Errorf("inserting backtest snapshot: %w", err)
+	}
+
+	if err := checkAffected(res); err != nil {
+		return db.CreateBacktestSnapshotActivityResults{}, err
+	}
+
+	return db.CreateBacktestSnapshotActivityResults{}, nil
+}
+
+// ReadBacktestSnapshotActivity reads the latest backtest snapshot at or before
+// the requested time from the database.
+func (a *Activities) ReadBacktestSnapshotActivity(
+	ctx context.Context,
+	params db.ReadBacktestSnapshotActivityParams,
+) (db.ReadBacktestSnapshotActivityResults, error) {
+	var entity entities.Snapshot
+
+	// Check ID is not nil
+	if params.BacktestID == uuid.Nil {
+		return db.ReadBacktestSnapshotActivityResults{}, db.ErrNilID
+	}
+
+	// Read the snapshot
+	err := a.db.GetContext(ctx, &entity,
+		`SELECT s.backtest_id, s.time, s.data FROM backtest_snapshots s
+		JOIN backtests b ON b.id = s.backtest_id AND b.tenant = $3
+		WHERE s.backtest_id = $1 AND s.time <= $2
+		ORDER BY s.time DESC
+		LIMIT 1`,
+		params.BacktestID, params.Time.UTC(), params.Tenant)
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.ReadBacktestSnapshotActivityResults{}, db.ErrNotFound
+	} else if err != nil {
+		return db.ReadBacktestSnapshotActivityResults{}, fmt.Errorf("reading backtest snapshot: %w", err)
+	}
+
+	// Convert the entity to the model
+	m, err := entity.ToModel()
+	if err != nil {
+		return db.ReadBacktestSnapshotActivityResults{}, fmt.Errorf("converting entity to model: %w", err)
+	}
+
+	return db.ReadBacktestSnapshotActivityResults{Snapshot: m}, nil
+}
+
+// checkAffected returns db.ErrNotFound if the query has not affected any row.
+func checkAffected(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("counting affected rows: %w", err)
+	} else if affected == 0 {
+		return db.ErrNotFound
+	}
+	return nil
 }
@@ -19,101 +19,294 @@ type Parameters struct {
 	PricePeriod string    `json:"price_period"`
 }
 
-// BacktestData is the entity for the backtest data.
-type BacktestData struct {
-	StartTime         time.Time          `json:"start_time"`
-	EndTime           time.Time          `json:"end_time"`
-	Mode              string             `json:"mode"`
-	PricePeriod       string             `json:"price_period"`
-	CurrentTime       time.Time          `json:"current_time"`
-	CurrentPriceType  string             `json:"current_price_type"`
-	Balances          []Balance          `json:"balances"`
-	Orders            []Order            `json:"orders"`
-	TickSubscriptions []TickSubscription `json:"tick_subscriptions"`
-	Callbacks         Callbacks          `json:"callbacks"`
+// Backtest is the entity for a backtest row. Its balances, orders, tick
+// subscriptions and callbacks are stored in their own tables, and its small
+// nested values in JSONB columns.
+type Backtest struct {
+	ID                 string    `db:"id"`
+	Status             string    `db:"status"`
+	StartTime          time.Time `db:"start_time"`
+	EndTime            time.Time `db:"end_time"`
+	Mode               string    `db:"mode"`
+	PricePeriod        string    `db:"price_period"`
+	CurrentTime        time.Time `db:"current_candlestick_time"`
+	CurrentPriceType   string    `db:"current_price_type"`
+	GapPolicy          string    `db:"gap_policy"`
+	Gaps               []byte    `db:"gaps"`
+	WakeUp             []byte    `db:"wake_up"`
+	SnapshotInterval   int64     `db:"snapshot_interval"`
+	ForkedFrom         *string   `db:"forked_from"`
+	StrategyParameters []byte    `db:"strategy_parameters"`
+	Webhooks           []byte    `db:"webhooks"`
+	Fees               []byte    `db:"fees"`
+	Version            int64     `db:"version"`
+	Metadata
+
+	// Rows of the other tables
+	Balances          []Balance          `db:"-"`
+	Orders            []Order            `db:"-"`
+	TickSubscriptions []TickSubscription `db:"-"`
+	Callbacks         []Callback         `db:"-"`
 }
 
-// Backtest is the entity for a backtest.
-type Backtest struct {
-	ID   string `db:"id"`
-	Data []byte `db:"data"`
+// backtestJSON are the values of a backtest stored in JSONB columns.
+type backtestJSON struct {
+	Gaps               []Gap
+	WakeUp             *WakeUp
+	StrategyParameters map[string]any
+	Webhooks           []Webhook
+	Fees               map[string]Fee
+}
+
+func (bt Backtest) unmarshalJSON() (backtestJSON, error) {
+	var v backtestJSON
+	columns := []struct {
+		name  string
+		data  []byte
+		value any
+	}{
+		{name: "gaps", data: bt.Gaps, value: &v.Gaps},
+		{name: "wake_up", data: bt.WakeUp, value: &v.WakeUp},
+		{name: "strategy_parameters", data: bt.StrategyParameters, value: &v.StrategyParameters},
+		{name: "webhooks", data: bt.Webhooks, value: &v.Webhooks},
+		{name: "fees", data: bt.Fees, value: &v.Fees},
+	}
+
+	for _, c := range columns {
+		// NULL columns keep the zero value
+		if len(c.data) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(c.data, c.value); err != nil {
+			return backtestJSON{}, fmt.Errorf("unmarshaling %s: %w", c.name, err)
+		}
+	}
+
+	return v, nil
+}
+
+// setOn sets the values stored as JSONB on a backtest model.
+func (v backtestJSON) setOn(bt *backtest.Backtest) {
+	bt.Gaps = ToGapModels(v.Gaps)
+	bt.WakeUp = ToWakeUpModel(v.WakeUp)
+	bt.StrategyParameters = v.StrategyParameters
+	bt.Webhooks = ToWebhookModels(v.Webhooks)
+	bt.Fees = ToFeeModels(v.Fees)
 }
 
 // ToModel converts the entity to a model.
 func (bt Backtest) ToModel() (backtest.Backtest, error) {
-	// Get the backtest data.
-	var data BacktestData
-	if err := json.Unmarshal(bt.Data, &data); err != nil {
+	id, err := uuid.Parse(bt.ID)
+	if err != nil {
 		return backtest.Backtest{}, err
 	}
 
-	priceType := candlestick.PriceType(data.CurrentPriceType)
-	if err := priceType.Validate(); err != nil {
-		wrappedErr := fmt.Errorf("error when validating current price type, got %q: %w", data.CurrentPriceType, err)
-		return backtest.Backtest{}, wrappedErr
+	enums, err := bt.toEnumsModels()
+	if err != nil {
+		return backtest.Backtest{}, err
 	}
 
-	periodBetweenEvents := period.Symbol(data.PricePeriod)
-	if err := periodBetweenEvents.Validate(); err != nil {
+	orders, err := ToOrderModels(bt.Orders)
+	if err != nil {
 		return backtest.Backtest{}, err
 	}
 
-	mode := backtest.Mode(data.Mode)
-	if err := mode.Validate(); err != nil {
+	callbacks, err := ToCallbacksModels(bt.Callbacks)
+	if err != nil {
 		return backtest.Backtest{}, err
 	}
 
-	orders, err := ToOrderModels(data.Orders)
+	forkedFrom, err := toOptionalUUID(bt.ForkedFrom)
 	if err != nil {
 		return backtest.Backtest{}, err
 	}
 
-	id, err := uuid.Parse(bt.ID)
+	values, err := bt.unmarshalJSON()
 	if err != nil {
 		return backtest.Backtest{}, err
 	}
 
-	return backtest.Backtest{
+	m := backtest.Backtest{
 		ID:          id,
-		StartTime:   data.StartTime,
-		EndTime:     data.EndTime,
-		Mode:        mode,
-		PricePeriod: periodBetweenEvents,
+		Status:      enums.Status,
+		StartTime:   bt.StartTime.UTC(),
+		EndTime:     bt.EndTime.UTC(),
+		Mode:        enums.Mode,
+		PricePeriod: enums.PricePeriod,
 		CurrentCandlestick: backtest.CurrentCandlestick{
-			Time:  data.CurrentTime,
-			Price: priceType,
+			Time:  bt.CurrentTime.UTC(),
+			Price: enums.PriceType,
 		},
-		Accounts:            ToAccountModels(data.Balances),
+		Accounts:            ToAccountModels(bt.Balances),
 		Orders:              orders,
-		PricesSubscriptions: ToTickSubscriptionModels(data.TickSubscriptions),
-		Callbacks:           data.Callbacks.ToCallbacksModel(),
+		PricesSubscriptions: ToTickSubscriptionModels(bt.TickSubscriptions),
+		Callbacks:           callbacks.Callbacks,
+		CallbacksPolicies:   callbacks.Policies,
+		CallbacksFailures:   callbacks.Failures,
+		GapPolicy:           enums.GapPolicy,
+		SnapshotInterval:    time.Duration(bt.SnapshotInterval),
+		ForkedFrom:          forkedFrom,
+		Version:             bt.Version,
+	}
+	values.setOn(&m)
+	bt.setOn(&m)
+
+	return m, nil
+}
+
+// backtestEnums are the enumerated values of a backtest.
+type backtestEnums struct {
+	Status      backtest.Status
+	Mode        backtest.Mode
+	PricePeriod period.Symbol
+	PriceType   candlestick.PriceType
+	GapPolicy   backtest.GapPolicy
+}
+
+func (bt Backtest) toEnumsModels() (backtestEnums, error) {
+	priceType := candlestick.PriceType(bt.CurrentPriceType)
+	if err := priceType.Validate(); err != nil {
+		wrappedErr := fmt.Errorf("error when validating current price type, got %q: %w", bt.CurrentPriceType, err)
+		return backtestEnums{}, wrappedErr
+	}
+
+	periodBetweenEvents := period.Symbol(bt.PricePeriod)
+	if err := periodBetweenEvents.Validate(); err != nil {
+		return backtestEnums{}, err
+	}
+
+	mode := backtest.Mode(bt.Mode)
+	if err := mode.Validate(); err != nil {
+		return backtestEnums{}, err
+	}
+
+	status, err := ToStatusModel(bt.Status, !bt.CurrentTime.Before(bt.EndTime))
+	if err != nil {
+		return backtestEnums{}, err
+	}
+
+	gapPolicy, err := ToGapPolicyModel(bt.GapPolicy)
+	if err != nil {
+		return backtestEnums{}, err
+	}
+
+	return backtestEnums{
+		Status:      status,
+		Mode:        mode,
+		PricePeriod: periodBetweenEvents,
+		PriceType:   priceType,
+		GapPolicy:   gapPolicy,
 	}, nil
 }
 
 // FromBacktestModel converts a model into an entity.
 func FromBacktestModel(bt backtest.Backtest) (Backtest, error) {
-	// Create the backtest data.
-	data := BacktestData{
-		StartTime:         bt.StartTime,
-		EndTime:           bt.EndTime,
+	id := bt.ID.String()
+	entity := Backtest{
+		ID:                id,
+		Status:            bt.Status.String(),
+		StartTime:         bt.StartTime.UTC(),
+		EndTime:           bt.EndTime.UTC(),
 		Mode:              bt.Mode.String(),
 		PricePeriod:       bt.PricePeriod.String(),
-		CurrentTime:       bt.CurrentCandlestick.Time,
+		CurrentTime:       bt.CurrentCandlestick.Time.UTC(),
 		CurrentPriceType:  bt.CurrentCandlestick.Price.String(),
+		GapPolicy:         bt.GapPolicy.String(),
+		SnapshotInterval:  int64(bt.SnapshotInterval),
+		Version:           bt.Version,
+		Metadata:          FromMetadataModel(bt),
 		Balances:          FromAccountModels(bt.Accounts),
 		Orders:            FromOrderModels(bt.Orders),
 		TickSubscriptions: FromTickSubscriptionModels(bt.PricesSubscriptions),
-		Callbacks:         FromCallbacksModel(bt.Callbacks),
+		Callbacks:         FromCallbacksModels(bt.Callbacks, bt.CallbacksPolicies, bt.CallbacksFailures),
+	}
+	if bt.ForkedFrom != nil {
+		forkedFrom := bt.ForkedFrom.String()
+		entity.ForkedFrom = &forkedFrom
 	}
 
-	// Marshal the backtest data.
-	dataByte, err := json.Marshal(data)
-	if err != nil {
+	// Set the backtest ID and position of the rows of the other tables
+	for i := range entity.Balances {
+		entity.Balances[i].BacktestID = id
+	}
+	for i := range entity.Orders {
+		entity.Orders[i].BacktestID, entity.Orders[i].Seq = id, i
+	}
+	for i := range entity.TickSubscriptions {
+		entity.TickSubscriptions[i].BacktestID, entity.TickSubscriptions[i].Seq = id, i
+	}
+	for i := range entity.Callbacks {
+		entity.Callbacks[i].BacktestID = id
+	}
+
+	// Marshal the values stored as JSONB
+	if err := entity.marshalJSON(bt); err != nil {
 		return Backtest{}, err
 	}
 
-	return Backtest{
-		ID:   bt.ID.String(),
-		Data: dataByte,
-	}, nil
+	return entity, nil
+}
+
+func (bt *Backtest) marshalJSON(m backtest.Backtest) error {
+	var err error
+	if bt.Gaps, err = json.Marshal(FromGapModels(m.Gaps)); err != nil {
+		return fmt.Errorf("marshaling gaps: %w", err)
+	}
+
+	if bt.WakeUp, err = marshalOptionalJSON(FromWakeUpModel(m.WakeUp), m.WakeUp == nil); err != nil {
+		return fmt.Errorf("marshaling wake up: %w", err)
+	}
+
+	if bt.StrategyParameters, err = marshalOptionalJSON(m.StrategyParameters, m.StrategyParameters == nil); err != nil {
+		return fmt.Errorf("marshaling strategy parameters: %w", err)
+	}
+
+	if bt.Webhooks, err = marshalOptionalJSON(FromWebhookModels(m.Webhooks), len(m.Webhooks) == 0); err != nil {
+		return fmt.Errorf("marshaling webhooks: %w", err)
+	}
+
+	if bt.Fees, err = marshalOptionalJSON(FromFeeModels(m.Fees), len(m.Fees) == 0); err != nil {
+		return fmt.Errorf("marshaling fees: %w", err)
+	}
+
+	return nil
+}
+
+// marshalOptionalJSON marshals the value of a nullable JSONB column, that is
+// NULL if the value is missing.
+func marshalOptionalJSON(v any, missing bool) ([]byte, error) {
+	if missing {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// ToStatusModel converts the status of a backtest entity to a model.
+// Backtests created before the status was stored get it from their progress.
+func ToStatusModel(status string, done bool) (backtest.Status, error) {
+	if status == "" {
+		if done {
+			return backtest.StatusFinished, nil
+		}
+		return backtest.StatusCreated, nil
+	}
+
+	s := backtest.Status(status)
+	if err := s.Validate(); err != nil {
+		return "", fmt.Errorf("%w: %q", err, status)
+	}
+	return s, nil
+}
+
+func toOptionalUUID(s *string) (*uuid.UUID, error) {
+	if s == nil || *s == "" {
+		return nil, nil
+	}
+
+	id, err := uuid.Parse(*s)
+	if err != nil {
+		return nil, err
+	}
+
+	return &id, nil
 }
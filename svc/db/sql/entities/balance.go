@@ -4,11 +4,13 @@ import (
 	"github.com/cryptellation/runtime/account"
 )
 
-// Balance is the entity for a balance.
+// Balance is the entity for a balance, stored in the backtest snapshots and in
+// its own table.
 type Balance struct {
-	AssetName string  `json:"asset_name"`
-	Exchange  string  `json:"exchange"`
-	Balance   float64 `json:"balance"`
+	BacktestID string  `json:"-" db:"backtest_id"`
+	AssetName  string  `json:"asset_name" db:"asset"`
+	Exchange   string  `json:"exchange" db:"exchange"`
+	Balance    float64 `json:"balance" db:"balance"`
 }
 
 // ToAccountModels transforms account entities to account models.
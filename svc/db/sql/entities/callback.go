@@ -1,57 +1,155 @@
 package entities
 
 import (
+	"fmt"
 	"time"
 
+	"github.com/cryptellation/backtests/pkg/backtest"
 	"github.com/cryptellation/runtime"
 )
 
-// CallbackWorkflow is the entity for a callback workflow.
-type CallbackWorkflow struct {
-	Name             string        `json:"name"`
-	TaskQueueName    string        `json:"task_queue_name"`
-	ExecutionTimeout time.Duration `json:"execution_timeout"`
+const (
+	// CallbackEventOnInit is the event of the callback called at the start of a backtest.
+	CallbackEventOnInit = "on_init"
+	// CallbackEventOnNewPrices is the event of the callback called on each step of a backtest.
+	CallbackEventOnNewPrices = "on_new_prices"
+	// CallbackEventOnExit is the event of the callback called at the end of a backtest.
+	CallbackEventOnExit = "on_exit"
+)
+
+// Callback is the entity for a callback of a backtest, with its policy and
+// failures count. A backtest has one callback per event.
+type Callback struct {
+	BacktestID       string `db:"backtest_id"`
+	Event            string `db:"event"`
+	WorkflowName     string `db:"workflow_name"`
+	TaskQueueName    string `db:"task_queue_name"`
+	ExecutionTimeout int64  `db:"execution_timeout"`
+	OnFailure        string `db:"on_failure"`
+	// The retry columns are all NULL if the callback has no retry policy.
+	RetryInitialInterval    *int64   `db:"retry_initial_interval"`
+	RetryBackoffCoefficient *float64 `db:"retry_backoff_coefficient"`
+	RetryMaximumInterval    *int64   `db:"retry_maximum_interval"`
+	RetryMaximumAttempts    *int32   `db:"retry_maximum_attempts"`
+	Failures                int      `db:"failures"`
 }
 
-// Callbacks is the entity for callbacks.
-type Callbacks struct {
-	OnInitCallback      CallbackWorkflow `json:"on_init_callback"`
-	OnNewPricesCallback CallbackWorkflow `json:"on_new_prices_callback"`
-	OnExitCallback      CallbackWorkflow `json:"on_exit_callback"`
+// CallbacksModels are the models stored in the callbacks of a backtest.
+type CallbacksModels struct {
+	Callbacks runtime.Callbacks
+	Policies  backtest.CallbacksPolicies
+	Failures  backtest.CallbacksFailures
 }
 
-// ToCallbackWorkflowModel converts a CallbackWorkflow entity to a runtime.CallbackWorkflow model.
-func (cw CallbackWorkflow) ToCallbackWorkflowModel() runtime.CallbackWorkflow {
-	return runtime.CallbackWorkflow{
-		Name:             cw.Name,
-		TaskQueueName:    cw.TaskQueueName,
-		ExecutionTimeout: cw.ExecutionTimeout,
+// ToCallbacksModels converts the callbacks entities of a backtest to models.
+func ToCallbacksModels(callbacks []Callback) (CallbacksModels, error) {
+	var m CallbacksModels
+	for _, c := range callbacks {
+		policy, err := c.toCallbackPolicyModel()
+		if err != nil {
+			return CallbacksModels{}, err
+		}
+
+		workflow := runtime.CallbackWorkflow{
+			Name:             c.WorkflowName,
+			TaskQueueName:    c.TaskQueueName,
+			ExecutionTimeout: time.Duration(c.ExecutionTimeout),
+		}
+
+		switch c.Event {
+		case CallbackEventOnInit:
+			m.Callbacks.OnInitCallback, m.Policies.OnInit, m.Failures.OnInit = workflow, policy, c.Failures
+		case CallbackEventOnNewPrices:
+			m.Callbacks.OnNewPricesCallback, m.Policies.OnNewPrices, m.Failures.OnNewPrices = workflow, policy, c.Failures
+		case CallbackEventOnExit:
+			m.Callbacks.OnExitCallback, m.Policies.OnExit, m.Failures.OnExit = workflow, policy, c.Failures
+		default:
+			return CallbacksModels{}, fmt.Errorf("unknown callback event %q", c.Event)
+		}
 	}
+
+	// Backtests without callback rows still get the default policies
+	for _, p := range []*backtest.CallbackPolicy{&m.Policies.OnInit, &m.Policies.OnNewPrices, &m.Policies.OnExit} {
+		if p.OnFailure == "" {
+			p.OnFailure = backtest.CallbackFailureActionFail
+		}
+	}
+
+	return m, nil
 }
 
-// FromCallbackWorkflowModel converts a runtime.CallbackWorkflow model to a CallbackWorkflow entity.
-func FromCallbackWorkflowModel(cw runtime.CallbackWorkflow) CallbackWorkflow {
-	return CallbackWorkflow{
-		Name:             cw.Name,
-		TaskQueueName:    cw.TaskQueueName,
-		ExecutionTimeout: cw.ExecutionTimeout,
+func (c Callback) toCallbackPolicyModel() (backtest.CallbackPolicy, error) {
+	// Backtests saved before callbacks policies were introduced have none
+	action := backtest.CallbackFailureActionFail
+	if c.OnFailure != "" {
+		action = backtest.CallbackFailureAction(c.OnFailure)
+	}
+	if err := action.Validate(); err != nil {
+		return backtest.CallbackPolicy{}, err
+	}
+
+	var retry *backtest.CallbackRetryPolicy
+	if c.RetryInitialInterval != nil {
+		retry = &backtest.CallbackRetryPolicy{
+			InitialInterval:    time.Duration(*c.RetryInitialInterval),
+			BackoffCoefficient: valueOrZero(c.RetryBackoffCoefficient),
+			MaximumInterval:    time.Duration(valueOrZero(c.RetryMaximumInterval)),
+			MaximumAttempts:    valueOrZero(c.RetryMaximumAttempts),
+		}
+	}
+
+	return backtest.CallbackPolicy{
+		Retry:     retry,
+		OnFailure: action,
+	}, nil
+}
+
+// FromCallbacksModels converts the callbacks models of a backtest to one
+// entity per event.
+func FromCallbacksModels(
+	callbacks runtime.Callbacks,
+	policies backtest.CallbacksPolicies,
+	failures backtest.CallbacksFailures,
+) []Callback {
+	return []Callback{
+		fromCallbackModels(CallbackEventOnInit, callbacks.OnInitCallback, policies.OnInit, failures.OnInit),
+		fromCallbackModels(CallbackEventOnNewPrices,
+			callbacks.OnNewPricesCallback, policies.OnNewPrices, failures.OnNewPrices),
+		fromCallbackModels(CallbackEventOnExit, callbacks.OnExitCallback, policies.OnExit, failures.OnExit),
 	}
 }
 
-// ToCallbacksModel converts a Callbacks entity to a runtime.Callbacks model.
-func (c Callbacks) ToCallbacksModel() runtime.Callbacks {
-	return runtime.Callbacks{
-		OnInitCallback:      c.OnInitCallback.ToCallbackWorkflowModel(),
-		OnNewPricesCallback: c.OnNewPricesCallback.ToCallbackWorkflowModel(),
-		OnExitCallback:      c.OnExitCallback.ToCallbackWorkflowModel(),
+func fromCallbackModels(
+	event string,
+	workflow runtime.CallbackWorkflow,
+	policy backtest.CallbackPolicy,
+	failures int,
+) Callback {
+	c := Callback{
+		Event:            event,
+		WorkflowName:     workflow.Name,
+		TaskQueueName:    workflow.TaskQueueName,
+		ExecutionTimeout: int64(workflow.ExecutionTimeout),
+		OnFailure:        policy.OnFailure.String(),
+		Failures:         failures,
+	}
+
+	if r := policy.Retry; r != nil {
+		initialInterval, maximumInterval := int64(r.InitialInterval), int64(r.MaximumInterval)
+		backoffCoefficient, maximumAttempts := r.BackoffCoefficient, r.MaximumAttempts
+		c.RetryInitialInterval = &initialInterval
+		c.RetryBackoffCoefficient = &backoffCoefficient
+		c.RetryMaximumInterval = &maximumInterval
+		c.RetryMaximumAttempts = &maximumAttempts
 	}
+
+	return c
 }
 
-// FromCallbacksModel converts a runtime.Callbacks model to a Callbacks entity.
-func FromCallbacksModel(c runtime.Callbacks) Callbacks {
-	return Callbacks{
-		OnInitCallback:      FromCallbackWorkflowModel(c.OnInitCallback),
-		OnNewPricesCallback: FromCallbackWorkflowModel(c.OnNewPricesCallback),
-		OnExitCallback:      FromCallbackWorkflowModel(c.OnExitCallback),
+func valueOrZero[T any](v *T) T {
+	var zero T
+	if v == nil {
+		return zero
 	}
+	return *v
 }
@@ -0,0 +1,34 @@
+package entities
+
+import "github.com/cryptellation/backtests/pkg/backtest"
+
+// Fee is the entity for the fee of an exchange.
+type Fee struct {
+	Rate float64 `json:"rate"`
+}
+
+// ToFeeModels converts the entities by exchange to models.
+func ToFeeModels(entities map[string]Fee) map[string]backtest.Fee {
+	if len(entities) == 0 {
+		return nil
+	}
+
+	models := make(map[string]backtest.Fee, len(entities))
+	for exchange, e := range entities {
+		models[exchange] = backtest.Fee{Rate: e.Rate}
+	}
+	return models
+}
+
+// FromFeeModels converts the models by exchange to entities.
+func FromFeeModels(models map[string]backtest.Fee) map[string]Fee {
+	if len(models) == 0 {
+		return nil
+	}
+
+	entities := make(map[string]Fee, len(models))
+	for exchange, m := range models {
+		entities[exchange] = Fee{Rate: m.Rate}
+	}
+	return entities
+}
@@ -0,0 +1,68 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/cryptellation/backtests/pkg/backtest"
+)
+
+// ToGapPolicyModel converts a gap policy entity to a model.
+func ToGapPolicyModel(policy string) (backtest.GapPolicy, error) {
+	// Backtests saved before gap policies were introduced have none
+	if policy == "" {
+		return backtest.GapPolicySkip, nil
+	}
+
+	p := backtest.GapPolicy(policy)
+	if err := p.Validate(); err != nil {
+		return "", err
+	}
+
+	return p, nil
+}
+
+// Gap is the entity for a gap in prices.
+type Gap struct {
+	Exchange string    `json:"exchange"`
+	Pair     string    `json:"pair"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+}
+
+// ToModel converts the entity to a model.
+func (g Gap) ToModel() backtest.Gap {
+	return backtest.Gap{
+		Exchange: g.Exchange,
+		Pair:     g.Pair,
+		Start:    g.Start,
+		End:      g.End,
+	}
+}
+
+// ToGapModels converts a slice of entities to a slice of models.
+func ToGapModels(entities []Gap) []backtest.Gap {
+	models := make([]backtest.Gap, len(entities))
+	for i, e := range entities {
+		models[i] = e.ToModel()
+	}
+	return models
+}
+
+// FromGapModels converts a slice of models to a slice of entities.
+func FromGapModels(models []backtest.Gap) []Gap {
+	entities := make([]Gap, len(models))
+	for i, m := range models {
+		entities[i] = FromGapModel(m)
+	}
+	return entities
+}
+
+// FromGapModel converts a model to an entity.
+func FromGapModel(m backtest.Gap) Gap {
+	return Gap{
+		Exchange: m.Exchange,
+		Pair:     m.Pair,
+		Start:    m.Start,
+		End:      m.End,
+	}
+}
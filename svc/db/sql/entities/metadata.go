@@ -0,0 +1,44 @@
+package entities
+
+import (
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/lib/pq"
+)
+
+// Metadata is the entity for the descriptive and ownership columns of a
+// backtest, stored next to its data to be indexed.
+type Metadata struct {
+	Tenant      string         `db:"tenant"`
+	Name        string         `db:"name"`
+	Description string         `db:"description"`
+	Creator     string         `db:"creator"`
+	Tags        pq.StringArray `db:"tags"`
+}
+
+// FromMetadataModel gets the metadata entity from a backtest model.
+func FromMetadataModel(bt backtest.Backtest) Metadata {
+	tags := pq.StringArray(bt.Tags)
+	if tags == nil {
+		tags = pq.StringArray{}
+	}
+
+	return Metadata{
+		Tenant:      bt.Tenant,
+		Name:        bt.Name,
+		Description: bt.Description,
+		Creator:     bt.Creator,
+		Tags:        tags,
+	}
+}
+
+// setOn sets the metadata on a backtest model.
+func (m Metadata) setOn(bt *backtest.Backtest) {
+	bt.Tenant = m.Tenant
+	bt.Name = m.Name
+	bt.Description = m.Description
+	bt.Creator = m.Creator
+	bt.Tags = nil
+	if len(m.Tags) > 0 {
+		bt.Tags = []string(m.Tags)
+	}
+}
@@ -7,16 +7,20 @@ import (
 	"github.com/google/uuid"
 )
 
-// Order is the entity for an order.
+// Order is the entity for an order of a backtest.
 type Order struct {
-	ID            string     `json:"id"`
-	ExecutionTime *time.Time `json:"execution_time"`
-	Type          string     `json:"type"`
-	Exchange      string     `json:"exchange"`
-	Pair          string     `json:"pair"`
-	Side          string     `json:"side"`
-	Quantity      float64    `json:"quantity"`
-	Price         float64    `json:"price"`
+	BacktestID string `db:"backtest_id"`
+	// Seq is the position of the order in the backtest, as orders are only
+	// appended.
+	Seq           int        `db:"seq"`
+	ID            string     `db:"id"`
+	ExecutionTime *time.Time `db:"execution_time"`
+	Type          string     `db:"type"`
+	Exchange      string     `db:"exchange"`
+	Pair          string     `db:"pair"`
+	Side          string     `db:"side"`
+	Quantity      float64    `db:"quantity"`
+	Price         float64    `db:"price"`
 }
 
 // ToModel converts the entity to a model.
@@ -36,9 +40,15 @@ func (o Order) ToModel() (order.Order, error) {
 		return order.Order{}, err
 	}
 
+	var executionTime *time.Time
+	if o.ExecutionTime != nil {
+		t := o.ExecutionTime.UTC()
+		executionTime = &t
+	}
+
 	return order.Order{
 		ID:            id,
-		ExecutionTime: o.ExecutionTime,
+		ExecutionTime: executionTime,
 		Type:          t,
 		Exchange:      o.Exchange,
 		Pair:          o.Pair,
@@ -71,9 +81,15 @@ func FromOrderModels(models []order.Order) []Order {
 
 // FromOrderModel converts a model into an entity.
 func FromOrderModel(m order.Order) Order {
+	var executionTime *time.Time
+	if m.ExecutionTime != nil {
+		t := m.ExecutionTime.UTC()
+		executionTime = &t
+	}
+
 	return Order{
 		ID:            m.ID.String(),
-		ExecutionTime: m.ExecutionTime,
+		ExecutionTime: executionTime,
 		Type:          m.Type.String(),
 		Exchange:      m.Exchange,
 		Pair:          m.Pair,
@@ -0,0 +1,80 @@
+package entities
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/candlesticks/pkg/candlestick"
+	"github.com/google/uuid"
+)
+
+// SnapshotData is the entity for the snapshot data.
+type SnapshotData struct {
+	CurrentPriceType  string             `json:"current_price_type"`
+	Balances          []Balance          `json:"balances"`
+	OrdersCount       int                `json:"orders_count"`
+	TickSubscriptions []TickSubscription `json:"tick_subscriptions"`
+}
+
+// Snapshot is the entity for a backtest snapshot.
+type Snapshot struct {
+	BacktestID string    `db:"backtest_id"`
+	Time       time.Time `db:"time"`
+	Data       []byte    `db:"data"`
+}
+
+// ToModel converts the entity to a model.
+func (s Snapshot) ToModel() (backtest.Snapshot, error) {
+	// Get the snapshot data.
+	var data SnapshotData
+	if err := json.Unmarshal(s.Data, &data); err != nil {
+		return backtest.Snapshot{}, err
+	}
+
+	priceType := candlestick.PriceType(data.CurrentPriceType)
+	if err := priceType.Validate(); err != nil {
+		wrappedErr := fmt.Errorf("error when validating current price type, got %q: %w", data.CurrentPriceType, err)
+		return backtest.Snapshot{}, wrappedErr
+	}
+
+	id, err := uuid.Parse(s.BacktestID)
+	if err != nil {
+		return backtest.Snapshot{}, err
+	}
+
+	return backtest.Snapshot{
+		BacktestID: id,
+		CurrentCandlestick: backtest.CurrentCandlestick{
+			Time:  s.Time.UTC(),
+			Price: priceType,
+		},
+		Accounts:            ToAccountModels(data.Balances),
+		OrdersCount:         data.OrdersCount,
+		PricesSubscriptions: ToTickSubscriptionModels(data.TickSubscriptions),
+	}, nil
+}
+
+// FromSnapshotModel converts a model into an entity.
+func FromSnapshotModel(s backtest.Snapshot) (Snapshot, error) {
+	// Create the snapshot data.
+	data := SnapshotData{
+		CurrentPriceType:  s.CurrentCandlestick.Price.String(),
+		Balances:          FromAccountModels(s.Accounts),
+		OrdersCount:       s.OrdersCount,
+		TickSubscriptions: FromTickSubscriptionModels(s.PricesSubscriptions),
+	}
+
+	// Marshal the snapshot data.
+	dataByte, err := json.Marshal(data)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	return Snapshot{
+		BacktestID: s.BacktestID.String(),
+		Time:       s.CurrentCandlestick.Time.UTC(),
+		Data:       dataByte,
+	}, nil
+}
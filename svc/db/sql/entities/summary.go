@@ -0,0 +1,23 @@
+package entities
+
+import (
+	"github.com/cryptellation/backtests/pkg/backtest"
+)
+
+// BacktestSummary is the entity for a backtest read without its orders.
+type BacktestSummary struct {
+	Backtest
+	OrdersCount int `db:"orders_count"`
+}
+
+// ToModel converts the entity to a model.
+func (s BacktestSummary) ToModel() (backtest.Summary, error) {
+	bt, err := s.Backtest.ToModel()
+	if err != nil {
+		return backtest.Summary{}, err
+	}
+
+	summary := bt.Summary()
+	summary.OrdersCount = s.OrdersCount
+	return summary, nil
+}
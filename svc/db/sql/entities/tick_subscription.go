@@ -2,10 +2,14 @@ package entities
 
 import "github.com/cryptellation/ticks/pkg/tick"
 
-// TickSubscription is the entity for a tick subscription.
+// TickSubscription is the entity for a tick subscription, stored in the
+// backtest snapshots and in its own table.
 type TickSubscription struct {
-	Exchange string `json:"exchange"`
-	Pair     string `json:"pair"`
+	BacktestID string `json:"-" db:"backtest_id"`
+	// Seq is the position of the subscription in the backtest.
+	Seq      int    `json:"-" db:"seq"`
+	Exchange string `json:"exchange" db:"exchange"`
+	Pair     string `json:"pair" db:"pair"`
 }
 
 // ToModel converts the entity to a model.
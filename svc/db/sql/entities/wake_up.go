@@ -0,0 +1,62 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/cryptellation/backtests/pkg/backtest"
+)
+
+// PriceCross is the entity for a price cross wake up condition.
+type PriceCross struct {
+	Exchange string  `json:"exchange"`
+	Pair     string  `json:"pair"`
+	Price    float64 `json:"price"`
+}
+
+// WakeUp is the entity for the wake up conditions of a strategy.
+type WakeUp struct {
+	Time         *time.Time   `json:"time,omitempty"`
+	PriceCrosses []PriceCross `json:"price_crosses,omitempty"`
+}
+
+// ToWakeUpModel converts the entity to a model.
+func ToWakeUpModel(w *WakeUp) *backtest.WakeUp {
+	if w == nil {
+		return nil
+	}
+
+	crosses := make([]backtest.PriceCross, len(w.PriceCrosses))
+	for i, c := range w.PriceCrosses {
+		crosses[i] = backtest.PriceCross{
+			Exchange: c.Exchange,
+			Pair:     c.Pair,
+			Price:    c.Price,
+		}
+	}
+
+	return &backtest.WakeUp{
+		Time:         w.Time,
+		PriceCrosses: crosses,
+	}
+}
+
+// FromWakeUpModel converts a model to an entity.
+func FromWakeUpModel(m *backtest.WakeUp) *WakeUp {
+	if m == nil {
+		return nil
+	}
+
+	crosses := make([]PriceCross, len(m.PriceCrosses))
+	for i, c := range m.PriceCrosses {
+		crosses[i] = PriceCross{
+			Exchange: c.Exchange,
+			Pair:     c.Pair,
+			Price:    c.Price,
+		}
+	}
+
+	return &WakeUp{
+		Time:         m.Time,
+		PriceCrosses: crosses,
+	}
+}
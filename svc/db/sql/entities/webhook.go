@@ -0,0 +1,41 @@
+package entities
+
+import "github.com/cryptellation/backtests/pkg/backtest"
+
+// Webhook is the entity for a webhook notified at the end of the runs.
+type Webhook struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret,omitempty"`
+}
+
+// ToWebhookModels converts a slice of entities to a slice of models.
+func ToWebhookModels(entities []Webhook) []backtest.Webhook {
+	if len(entities) == 0 {
+		return nil
+	}
+
+	models := make([]backtest.Webhook, len(entities))
+	for i, e := range entities {
+		models[i] = backtest.Webhook{
+			URL:    e.URL,
+			Secret: e.Secret,
+		}
+	}
+	return models
+}
+
+// FromWebhookModels converts a slice of models to a slice of entities.
+func FromWebhookModels(models []backtest.Webhook) []Webhook {
+	if len(models) == 0 {
+		return nil
+	}
+
+	entities := make([]Webhook, len(models))
+	for i, m := range models {
+		entities[i] = Webhook{
+			URL:    m.URL,
+			Secret: m.Secret,
+		}
+	}
+	return entities
+}
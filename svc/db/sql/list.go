@@ -0,0 +1,225 @@
+package sql
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/backtests/svc/db"
+	"github.com/cryptellation/backtests/svc/db/sql/entities"
+	"github.com/lib/pq"
+)
+
+// listCursor is the position of the last backtest of a page.
+type listCursor struct {
+	Value time.Time `json:"v"`
+	ID    string    `json:"id"`
+}
+
+func encodeListCursor(c listCursor) (string, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeListCursor(s string) (listCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return listCursor{}, fmt.Errorf("%w: %w", db.ErrInvalidCursor, err)
+	}
+
+	var c listCursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return listCursor{}, fmt.Errorf("%w: %w", db.ErrInvalidCursor, err)
+	}
+	return c, nil
+}
+
+// metadataColumns are the columns of the backtests metadata.
+const metadataColumns = "tenant, name, description, creator, tags"
+
+// listQuery builds the queries listing or purging backtests on their columns
+// and tick subscriptions.
+type listQuery struct {
+	conditions []string
+	args       []any
+}
+
+// where adds the condition, with one '?' per argument.
+func (q *listQuery) where(condition string, args ...any) {
+	for _, arg := range args {
+		q.args = append(q.args, arg)
+		condition = strings.Replace(condition, "?", fmt.Sprintf("$%d", len(q.args)), 1)
+	}
+	q.conditions = append(q.conditions, condition)
+}
+
+func (q *listQuery) whereJSONContains(path string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshaling filter: %w", err)
+	}
+	q.where(path+" @> ?::jsonb", string(b))
+	return nil
+}
+
+// whereSubscribed keeps the backtests subscribed to the exchange and pair, any
+// of them being ignored if empty.
+func (q *listQuery) whereSubscribed(exchange, pair string) {
+	condition := "EXISTS (SELECT 1 FROM backtest_subscriptions s WHERE s.backtest_id = backtests.id"
+	var args []any
+	if exchange != "" {
+		condition += " AND s.exchange = ?"
+		args = append(args, exchange)
+	}
+	if pair != "" {
+		condition += " AND s.pair = ?"
+		args = append(args, pair)
+	}
+	q.where(condition+")", args...)
+}
+
+func (q *listQuery) applyFilters(f backtest.Filters) error {
+	if len(f.Statuses) > 0 {
+		statuses := make([]string, len(f.Statuses))
+		for i, s := range f.Statuses {
+			statuses[i] = s.String()
+		}
+		q.where("status = ANY(?)", pq.Array(statuses))
+	}
+
+	if f.Exchange != "" || f.Pair != "" {
+		q.whereSubscribed(f.Exchange, f.Pair)
+	}
+
+	if f.From != nil {
+		q.where("start_time >= ?", f.From.UTC())
+	}
+
+	if f.To != nil {
+		q.where("end_time <= ?", f.To.UTC())
+	}
+
+	q.applyMetadataFilters(f)
+
+	if len(f.StrategyParameters) > 0 {
+		if err := q.whereJSONContains("strategy_parameters", f.StrategyParameters); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (q *listQuery) applyMetadataFilters(f backtest.Filters) {
+	if len(f.Tags) > 0 {
+		q.where("tags @> ?", pq.Array(f.Tags))
+	}
+
+	if len(f.AnyTags) > 0 {
+		q.where("tags && ?", pq.Array(f.AnyTags))
+	}
+
+	if f.Name != "" {
+		q.where(`name ILIKE ? ESCAPE '\'`, "%"+likeEscaper.Replace(f.Name)+"%")
+	}
+
+	if f.Creator != "" {
+		q.where("creator = ?", f.Creator)
+	}
+}
+
+// likeEscaper escapes the special characters of LIKE patterns.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// sortColumns are the columns of the sort fields.
+var sortColumns = map[backtest.SortField]string{
+	backtest.SortFieldStartTime:   "start_time",
+	backtest.SortFieldEndTime:     "end_time",
+	backtest.SortFieldCurrentTime: "current_candlestick_time",
+}
+
+// build returns the SQL query and its arguments.
+func (q *listQuery) build(params db.ListBacktestsActivityParams) (string, []any, error) {
+	q.where("tenant = ?", params.Tenant)
+	if err := q.applyFilters(params.Filters); err != nil {
+		return "", nil, err
+	}
+
+	// Set the sort expression
+	field := params.Sort.Field
+	if field == "" {
+		field = backtest.SortFieldStartTime
+	} else if err := field.Validate(); err != nil {
+		return "", nil, fmt.Errorf("%w: %q", err, field)
+	}
+	sortExpr := sortColumns[field]
+	direction, comparison := "ASC", ">"
+	if params.Sort.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	// Start after the cursor
+	if params.Cursor != "" {
+		c, err := decodeListCursor(params.Cursor)
+		if err != nil {
+			return "", nil, err
+		}
+		q.args = append(q.args, c.Value, c.ID)
+		q.conditions = append(q.conditions, fmt.Sprintf("(%s, id) %s ($%d, $%d)",
+			sortExpr, comparison, len(q.args)-1, len(q.args)))
+	}
+
+	// Count the orders instead of reading them if only the summary is requested
+	columns := backtestColumns
+	if params.SummaryOnly {
+		columns += ", (SELECT COUNT(*) FROM backtest_orders o WHERE o.backtest_id = backtests.id) AS orders_count"
+	}
+
+	query := fmt.Sprintf("SELECT %s, %s AS sort_value FROM backtests WHERE %s",
+		columns, sortExpr, strings.Join(q.conditions, " AND "))
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s", sortExpr, direction, direction)
+
+	// Get one more backtest to know if there is a next page
+	if params.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", params.Limit+1)
+	}
+
+	return query, q.args, nil
+}
+
+// listRow is a row returned by the list query.
+type listRow struct {
+	entities.BacktestSummary
+	SortValue time.Time `db:"sort_value"`
+}
+
+// buildPurge returns the SQL query deleting backtests and its arguments.
+func (q *listQuery) buildPurge(params db.PurgeBacktestsActivityParams) (string, []any, error) {
+	q.where("tenant = ?", params.Tenant)
+	if err := q.applyFilters(backtest.Filters{
+		Statuses: params.Statuses,
+		Tags:     params.Tags,
+	}); err != nil {
+		return "", nil, err
+	}
+
+	if params.CreatedBefore != nil {
+		q.where("created_at < ?", params.CreatedBefore.UTC())
+	}
+
+	// Never purge running backtests
+	q.where("status <> ?", backtest.StatusRunning.String())
+
+	query := "SELECT id FROM backtests WHERE " + strings.Join(q.conditions, " AND ") + " ORDER BY created_at, id"
+	if params.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", params.Limit)
+	}
+
+	return "DELETE FROM backtests WHERE id IN (" + query + ")", q.args, nil
+}
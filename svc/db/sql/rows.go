@@ -0,0 +1,250 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cryptellation/backtests/svc/db/sql/entities"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// backtestColumns are the columns of the backtests table.
+const backtestColumns = "id, status, start_time, end_time, mode, price_period, " +
+	"current_candlestick_time, current_price_type, gap_policy, gaps, wake_up, snapshot_interval, " +
+	"forked_from, strategy_parameters, webhooks, fees, version, " + metadataColumns
+
+// callbackColumns are the columns of the backtest_callbacks table.
+const callbackColumns = "backtest_id, event, workflow_name, task_queue_name, " +
+	"execution_timeout, on_failure, retry_initial_interval, retry_backoff_coefficient, " +
+	"retry_maximum_interval, retry_maximum_attempts, failures"
+
+// orderColumns are the columns of the backtest_orders table.
+const orderColumns = "backtest_id, seq, id, execution_time, type, exchange, pair, side, quantity, price"
+
+// namedColumns returns the named parameters of the columns.
+func namedColumns(columns string) string {
+	names := strings.Split(columns, ", ")
+	for i, n := range names {
+		names[i] = ":" + n
+	}
+	return strings.Join(names, ", ")
+}
+
+// rowsBatchSize is the maximum count of rows inserted per query, in order to
+// stay below the maximum count of parameters of a query.
+const rowsBatchSize = 1000
+
+// namedExecBatches executes the named query inserting the rows by batches.
+func namedExecBatches[T any](ctx context.Context, tx *sqlx.Tx, query string, rows []T) error {
+	for start := 0; start < len(rows); start += rowsBatchSize {
+		end := min(start+rowsBatchSize, len(rows))
+		if _, err := tx.NamedExecContext(ctx, query, rows[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selectBacktestsRows selects the rows of the backtests with the query, that
+// takes the backtests IDs as argument, and adds them to their backtest.
+func selectBacktestsRows[T any](
+	ctx context.Context,
+	tx *sqlx.Tx,
+	query string,
+	ids []string,
+	add func(T),
+) error {
+	var rows []T
+	if err := tx.SelectContext(ctx, &rows, query, pq.Array(ids)); err != nil {
+		return err
+	}
+
+	for _, r := range rows {
+		add(r)
+	}
+	return nil
+}
+
+// readBacktestsRows reads the rows of the backtests stored in the other
+// tables. The orders are only read if requested.
+func readBacktestsRows(ctx context.Context, tx *sqlx.Tx, bts []entities.Backtest, withOrders bool) error {
+	ids := make([]string, len(bts))
+	index := make(map[string]*entities.Backtest, len(bts))
+	for i := range bts {
+		ids[i] = bts[i].ID
+		index[bts[i].ID] = &bts[i]
+	}
+
+	err := selectBacktestsRows(ctx, tx,
+		`SELECT backtest_id, exchange, asset, balance FROM backtest_balances
+		WHERE backtest_id = ANY($1) ORDER BY backtest_id, exchange, asset`,
+		ids, func(r entities.Balance) {
+			index[r.BacktestID].Balances = append(index[r.BacktestID].Balances, r)
+		})
+	if err != nil {
+		return fmt.Errorf("reading balances: %w", err)
+	}
+
+	err = selectBacktestsRows(ctx, tx,
+		`SELECT backtest_id, seq, exchange, pair FROM backtest_subscriptions
+		WHERE backtest_id = ANY($1) ORDER BY backtest_id, seq`,
+		ids, func(r entities.TickSubscription) {
+			index[r.BacktestID].TickSubscriptions = append(index[r.BacktestID].TickSubscriptions, r)
+		})
+	if err != nil {
+		return fmt.Errorf("reading tick subscriptions: %w", err)
+	}
+
+	err = selectBacktestsRows(ctx, tx,
+		`SELECT `+callbackColumns+` FROM backtest_callbacks
+		WHERE backtest_id = ANY($1) ORDER BY backtest_id, event`,
+		ids, func(r entities.Callback) {
+			index[r.BacktestID].Callbacks = append(index[r.BacktestID].Callbacks, r)
+		})
+	if err != nil {
+		return fmt.Errorf("reading callbacks: %w", err)
+	}
+
+	if !withOrders {
+		return nil
+	}
+
+	err = selectBacktestsRows(ctx, tx,
+		`SELECT `+orderColumns+` FROM backtest_orders
+		WHERE backtest_id = ANY($1) ORDER BY backtest_id, seq`,
+		ids, func(r entities.Order) {
+			index[r.BacktestID].Orders = append(index[r.BacktestID].Orders, r)
+		})
+	if err != nil {
+		return fmt.Errorf("reading orders: %w", err)
+	}
+
+	return nil
+}
+
+// writeBacktestRows writes the rows of the backtest stored in the other
+// tables. Only the rows that changed since the last write are written.
+func writeBacktestRows(ctx context.Context, tx *sqlx.Tx, bt entities.Backtest) error {
+	if err := writeBalances(ctx, tx, bt); err != nil {
+		return fmt.Errorf("writing balances: %w", err)
+	}
+
+	if err := writeTickSubscriptions(ctx, tx, bt); err != nil {
+		return fmt.Errorf("writing tick subscriptions: %w", err)
+	}
+
+	if err := writeCallbacks(ctx, tx, bt); err != nil {
+		return fmt.Errorf("writing callbacks: %w", err)
+	}
+
+	if err := writeOrders(ctx, tx, bt); err != nil {
+		return fmt.Errorf("writing orders: %w", err)
+	}
+
+	return nil
+}
+
+func writeBalances(ctx context.Context, tx *sqlx.Tx, bt entities.Backtest) error {
+	// Upsert the balances that changed
+	err := namedExecBatches(ctx, tx,
+		`INSERT INTO backtest_balances (backtest_id, exchange, asset, balance)
+		VALUES (:backtest_id, :exchange, :asset, :balance)
+		ON CONFLICT (backtest_id, exchange, asset) DO UPDATE SET balance = EXCLUDED.balance
+		WHERE backtest_balances.balance IS DISTINCT FROM EXCLUDED.balance`,
+		bt.Balances)
+	if err != nil {
+		return err
+	}
+
+	// Delete the other ones
+	exchanges, assets := make([]string, len(bt.Balances)), make([]string, len(bt.Balances))
+	for i, b := range bt.Balances {
+		exchanges[i], assets[i] = b.Exchange, b.AssetName
+	}
+	_, err = tx.ExecContext(ctx,
+		`DELETE FROM backtest_balances
+		WHERE backtest_id = $1
+		AND (exchange, asset) NOT IN (SELECT * FROM UNNEST($2::varchar[], $3::varchar[]))`,
+		bt.ID, pq.Array(exchanges), pq.Array(assets))
+	return err
+}
+
+func writeTickSubscriptions(ctx context.Context, tx *sqlx.Tx, bt entities.Backtest) error {
+	// Upsert the subscriptions that changed
+	err := namedExecBatches(ctx, tx,
+		`INSERT INTO backtest_subscriptions (backtest_id, seq, exchange, pair)
+		VALUES (:backtest_id, :seq, :exchange, :pair)
+		ON CONFLICT (backtest_id, exchange, pair) DO UPDATE SET seq = EXCLUDED.seq
+		WHERE backtest_subscriptions.seq IS DISTINCT FROM EXCLUDED.seq`,
+		bt.TickSubscriptions)
+	if err != nil {
+		return err
+	}
+
+	// Delete the other ones
+	exchanges, pairs := make([]string, len(bt.TickSubscriptions)), make([]string, len(bt.TickSubscriptions))
+	for i, s := range bt.TickSubscriptions {
+		exchanges[i], pairs[i] = s.Exchange, s.Pair
+	}
+	_, err = tx.ExecContext(ctx,
+		`DELETE FROM backtest_subscriptions
+		WHERE backtest_id = $1
+		AND (exchange, pair) NOT IN (SELECT * FROM UNNEST($2::varchar[], $3::varchar[]))`,
+		bt.ID, pq.Array(exchanges), pq.Array(pairs))
+	return err
+}
+
+func writeCallbacks(ctx context.Context, tx *sqlx.Tx, bt entities.Backtest) error {
+	// Upsert the callbacks that changed, there is always one per event
+	return namedExecBatches(ctx, tx,
+		`INSERT INTO backtest_callbacks (`+callbackColumns+`)
+		VALUES (`+namedColumns(callbackColumns)+`)
+		ON CONFLICT (backtest_id, event) DO UPDATE SET
+			workflow_name = EXCLUDED.workflow_name,
+			task_queue_name = EXCLUDED.task_queue_name,
+			execution_timeout = EXCLUDED.execution_timeout,
+			on_failure = EXCLUDED.on_failure,
+			retry_initial_interval = EXCLUDED.retry_initial_interval,
+			retry_backoff_coefficient = EXCLUDED.retry_backoff_coefficient,
+			retry_maximum_interval = EXCLUDED.retry_maximum_interval,
+			retry_maximum_attempts = EXCLUDED.retry_maximum_attempts,
+			failures = EXCLUDED.failures
+		WHERE (backtest_callbacks.workflow_name, backtest_callbacks.task_queue_name,
+			backtest_callbacks.execution_timeout, backtest_callbacks.on_failure,
+			backtest_callbacks.retry_initial_interval, backtest_callbacks.retry_backoff_coefficient,
+			backtest_callbacks.retry_maximum_interval, backtest_callbacks.retry_maximum_attempts,
+			backtest_callbacks.failures)
+		IS DISTINCT FROM (EXCLUDED.workflow_name, EXCLUDED.task_queue_name,
+			EXCLUDED.execution_timeout, EXCLUDED.on_failure,
+			EXCLUDED.retry_initial_interval, EXCLUDED.retry_backoff_coefficient,
+			EXCLUDED.retry_maximum_interval, EXCLUDED.retry_maximum_attempts,
+			EXCLUDED.failures)`,
+		bt.Callbacks)
+}
+
+// writeOrders inserts the new orders of the backtest. The orders being only
+// appended to a backtest, the stored ones are not written again.
+func writeOrders(ctx context.Context, tx *sqlx.Tx, bt entities.Backtest) error {
+	var stored int
+	err := tx.GetContext(ctx, &stored, "SELECT COUNT(*) FROM backtest_orders WHERE backtest_id = $1", bt.ID)
+	if err != nil {
+		return err
+	}
+
+	// Remove the orders missing from the backtest, if any
+	if stored > len(bt.Orders) {
+		_, err := tx.ExecContext(ctx,
+			"DELETE FROM backtest_orders WHERE backtest_id = $1 AND seq >= $2",
+			bt.ID, len(bt.Orders))
+		if err != nil {
+			return err
+		}
+		stored = len(bt.Orders)
+	}
+
+	return namedExecBatches(ctx, tx,
+		`INSERT INTO backtest_orders (`+orderColumns+`) VALUES (`+namedColumns(orderColumns)+`)`,
+		bt.Orders[stored:])
+}
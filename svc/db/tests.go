@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/cryptellation/backtests/pkg/backtest"
@@ -9,6 +10,8 @@ import (
 	"github.com/cryptellation/candlesticks/pkg/period"
 	"github.com/cryptellation/runtime"
 	"github.com/cryptellation/runtime/account"
+	"github.com/cryptellation/runtime/order"
+	"github.com/cryptellation/ticks/pkg/tick"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/suite"
 )
@@ -52,6 +55,26 @@ func (suite *BacktestSuite) TestCreateRead() {
 				TaskQueueName: "test-queue",
 			},
 		},
+		CallbacksPolicies: backtest.CallbacksPolicies{
+			OnInit: backtest.CallbackPolicy{OnFailure: backtest.CallbackFailureActionFail},
+			OnNewPrices: backtest.CallbackPolicy{
+				OnFailure: backtest.CallbackFailureActionSkipStep,
+				Retry:     &backtest.CallbackRetryPolicy{MaximumAttempts: 3},
+			},
+			OnExit: backtest.CallbackPolicy{OnFailure: backtest.CallbackFailureActionAbort},
+		},
+		CallbacksFailures: backtest.CallbacksFailures{OnNewPrices: 2},
+		GapPolicy:         backtest.GapPolicyForwardFill,
+		Gaps: []backtest.Gap{
+			{Exchange: "exchange", Pair: "ETH-DAI", Start: time.Unix(0, 0).UTC(), End: time.Unix(60, 0).UTC()},
+		},
+		StrategyParameters: map[string]any{"period": float64(10), "side": "long"},
+		Webhooks:           []backtest.Webhook{{URL: "https://example.com/hook", Secret: "secret"}},
+		Fees:               map[string]backtest.Fee{"exchange": {Rate: 0.001}},
+		Name:               "SMA crossover",
+		Description:        "Buys when the fast SMA crosses the slow one",
+		Creator:            "alice",
+		Tags:               []string{"sma", "eth"},
 	}
 	_, err := suite.DB.CreateBacktestActivity(context.Background(), CreateBacktestActivityParams{
 		Backtest: bt,
@@ -70,6 +93,18 @@ func (suite *BacktestSuite) TestCreateRead() {
 	suite.Require().Equal(bt.Callbacks.OnInitCallback, resp.Backtest.Callbacks.OnInitCallback)
 	suite.Require().Equal(bt.Callbacks.OnNewPricesCallback, resp.Backtest.Callbacks.OnNewPricesCallback)
 	suite.Require().Equal(bt.Callbacks.OnExitCallback, resp.Backtest.Callbacks.OnExitCallback)
+	suite.Require().Equal(bt.CallbacksPolicies, resp.Backtest.CallbacksPolicies)
+	suite.Require().Equal(bt.CallbacksFailures, resp.Backtest.CallbacksFailures)
+	suite.Require().Equal(bt.GapPolicy, resp.Backtest.GapPolicy)
+	suite.Require().Equal(bt.StrategyParameters, resp.Backtest.StrategyParameters)
+	suite.Require().Equal(bt.Webhooks, resp.Backtest.Webhooks)
+	suite.Require().Equal(bt.Fees, resp.Backtest.Fees)
+	suite.Require().Equal(bt.Name, resp.Backtest.Name)
+	suite.Require().Equal(bt.Description, resp.Backtest.Description)
+	suite.Require().Equal(bt.Creator, resp.Backtest.Creator)
+	suite.Require().Equal(bt.Tags, resp.Backtest.Tags)
+	suite.Require().Len(resp.Backtest.Gaps, 1)
+	suite.Require().WithinDuration(bt.Gaps[0].End, resp.Backtest.Gaps[0].End, time.Second)
 }
 
 // createTestBacktest creates a test backtest with the given ID and workflow names.
@@ -126,8 +161,270 @@ func (suite *BacktestSuite) TestList() {
 	suite.Require().NoError(err)
 
 	suite.Require().Len(resp.Backtests, 2)
-	suite.Require().Equal(bt1.ID, resp.Backtests[0].ID)
-	suite.Require().Equal(bt2.ID, resp.Backtests[1].ID)
+	suite.Require().ElementsMatch(
+		[]uuid.UUID{bt1.ID, bt2.ID},
+		[]uuid.UUID{resp.Backtests[0].ID, resp.Backtests[1].ID})
+}
+
+// TestListWithStrategyParameters tests that listing backtests can be filtered
+// on strategy parameters.
+func (suite *BacktestSuite) TestListWithStrategyParameters() {
+	bt1 := suite.createTestBacktest(uuid.New(), "test-init-workflow-1", "test-prices-workflow-1", "test-exit-workflow-1")
+	bt1.StrategyParameters = map[string]any{"period": 10, "side": "long"}
+	bt2 := suite.createTestBacktest(uuid.New(), "test-init-workflow-2", "test-prices-workflow-2", "test-exit-workflow-2")
+	bt2.StrategyParameters = map[string]any{"period": 20, "side": "long"}
+
+	for _, bt := range []backtest.Backtest{bt1, bt2} {
+		_, err := suite.DB.CreateBacktestActivity(context.Background(), CreateBacktestActivityParams{
+			Backtest: bt,
+		})
+		suite.Require().NoError(err)
+	}
+
+	resp, err := suite.DB.ListBacktestsActivity(context.Background(), ListBacktestsActivityParams{
+		Filters: backtest.Filters{StrategyParameters: map[string]any{"period": 20}},
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(resp.Backtests, 1)
+	suite.Require().Equal(bt2.ID, resp.Backtests[0].ID)
+
+	resp, err = suite.DB.ListBacktestsActivity(context.Background(), ListBacktestsActivityParams{
+		Filters: backtest.Filters{StrategyParameters: map[string]any{"side": "long"}},
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(resp.Backtests, 2)
+}
+
+// TestListFiltersAndPagination tests that listing backtests can be filtered,
+// sorted and paginated.
+func (suite *BacktestSuite) TestListFiltersAndPagination() {
+	tag := uuid.New().String()
+	backtests := make([]backtest.Backtest, 3)
+	for i := range backtests {
+		bt := suite.createTestBacktest(uuid.New(), "init", "prices", "exit")
+		bt.StartTime = time.Unix(int64(i)*60, 0).UTC()
+		bt.Status = backtest.StatusFinished
+		bt.PricesSubscriptions = []tick.Subscription{{Exchange: "exchange", Pair: "ETH-DAI"}}
+		bt.Orders = []order.Order{{ID: uuid.New(), Pair: "ETH-DAI", Side: order.SideIsBuy, Type: order.TypeIsMarket}}
+		bt.Tags = []string{tag, "sweep"}
+		backtests[i] = bt
+	}
+	backtests[1].Status = backtest.StatusRunning
+	backtests[2].Tags = []string{tag}
+
+	for _, bt := range backtests {
+		_, err := suite.DB.CreateBacktestActivity(context.Background(), CreateBacktestActivityParams{
+			Backtest: bt,
+		})
+		suite.Require().NoError(err)
+	}
+
+	// Filter on status and tags
+	resp, err := suite.DB.ListBacktestsActivity(context.Background(), ListBacktestsActivityParams{
+		Filters: backtest.Filters{
+			Statuses: []backtest.Status{backtest.StatusFinished},
+			Exchange: "exchange",
+			Pair:     "ETH-DAI",
+			Tags:     []string{tag, "sweep"},
+		},
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(resp.Backtests, 1)
+	suite.Require().Equal(backtests[0].ID, resp.Backtests[0].ID)
+
+	// Paginate on summaries in descending order
+	resp, err = suite.DB.ListBacktestsActivity(context.Background(), ListBacktestsActivityParams{
+		Filters:     backtest.Filters{Tags: []string{tag}},
+		Sort:        backtest.Sort{Field: backtest.SortFieldStartTime, Descending: true},
+		Limit:       2,
+		SummaryOnly: true,
+	})
+	suite.Require().NoError(err)
+	suite.Require().Empty(resp.Backtests)
+	suite.Require().Len(resp.Summaries, 2)
+	suite.Require().Equal(backtests[2].ID, resp.Summaries[0].ID)
+	suite.Require().Equal(backtests[1].ID, resp.Summaries[1].ID)
+	suite.Require().Equal(1, resp.Summaries[0].OrdersCount)
+	suite.Require().NotEmpty(resp.NextCursor)
+
+	resp, err = suite.DB.ListBacktestsActivity(context.Background(), ListBacktestsActivityParams{
+		Filters:     backtest.Filters{Tags: []string{tag}},
+		Sort:        backtest.Sort{Field: backtest.SortFieldStartTime, Descending: true},
+		Limit:       2,
+		SummaryOnly: true,
+		Cursor:      resp.NextCursor,
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(resp.Summaries, 1)
+	suite.Require().Equal(backtests[0].ID, resp.Summaries[0].ID)
+	suite.Require().Empty(resp.NextCursor)
+}
+
+// TestListWithMetadata tests that listing backtests can be filtered on their
+// name, creator and tags.
+func (suite *BacktestSuite) TestListWithMetadata() {
+	bt1 := suite.createTestBacktest(uuid.New(), "init", "prices", "exit")
+	bt1.Name, bt1.Creator, bt1.Tags = "SMA 100%", "alice", []string{"sma", "btc"}
+	bt2 := suite.createTestBacktest(uuid.New(), "init", "prices", "exit")
+	bt2.Name, bt2.Creator, bt2.Tags = "sma_fast", "bob", []string{"sma", "eth"}
+
+	for _, bt := range []backtest.Backtest{bt1, bt2} {
+		_, err := suite.DB.CreateBacktestActivity(context.Background(), CreateBacktestActivityParams{
+			Backtest: bt,
+		})
+		suite.Require().NoError(err)
+	}
+
+	for _, tc := range []struct {
+		Filters  backtest.Filters
+		Expected []uuid.UUID
+	}{
+		{Filters: backtest.Filters{Name: "sma"}, Expected: []uuid.UUID{bt1.ID, bt2.ID}},
+		{Filters: backtest.Filters{Name: "100%"}, Expected: []uuid.UUID{bt1.ID}},
+		{Filters: backtest.Filters{Name: "a_f"}, Expected: []uuid.UUID{bt2.ID}},
+		{Filters: backtest.Filters{Name: "a_1"}, Expected: []uuid.UUID{}},
+		{Filters: backtest.Filters{Creator: "bob"}, Expected: []uuid.UUID{bt2.ID}},
+		{Filters: backtest.Filters{Tags: []string{"sma", "btc"}}, Expected: []uuid.UUID{bt1.ID}},
+		{Filters: backtest.Filters{AnyTags: []string{"btc", "eth"}}, Expected: []uuid.UUID{bt1.ID, bt2.ID}},
+	} {
+		resp, err := suite.DB.ListBacktestsActivity(context.Background(), ListBacktestsActivityParams{
+			Filters:     tc.Filters,
+			SummaryOnly: true,
+		})
+		suite.Require().NoError(err)
+
+		ids := make([]uuid.UUID, 0, len(resp.Summaries))
+		for _, s := range resp.Summaries {
+			ids = append(ids, s.ID)
+		}
+		suite.Require().ElementsMatch(tc.Expected, ids, tc.Filters)
+	}
+}
+
+// TestUpdateMetadata tests that updating the metadata of a backtest only
+// changes its name, description and tags.
+func (suite *BacktestSuite) TestUpdateMetadata() {
+	bt := suite.createTestBacktest(uuid.New(), "init", "prices", "exit")
+	bt.Name, bt.Creator, bt.Tags = "old", "alice", []string{"old"}
+	_, err := suite.DB.CreateBacktestActivity(context.Background(), CreateBacktestActivityParams{
+		Backtest: bt,
+	})
+	suite.Require().NoError(err)
+
+	// Updating the backtest does not change its metadata
+	updated := bt
+	updated.Name, updated.Tags = "ignored", nil
+	updated.Status = backtest.StatusFinished
+	_, err = suite.DB.UpdateBacktestActivity(context.Background(), UpdateBacktestActivityParams{
+		Backtest: updated,
+	})
+	suite.Require().NoError(err)
+
+	// Updating the metadata does not change the rest of the backtest
+	bt.Name, bt.Description, bt.Creator, bt.Tags = "new", "description", "bob", []string{"new"}
+	_, err = suite.DB.UpdateBacktestMetadataActivity(context.Background(), UpdateBacktestMetadataActivityParams{
+		Backtest: bt,
+	})
+	suite.Require().NoError(err)
+
+	resp, err := suite.DB.ReadBacktestActivity(context.Background(), ReadBacktestActivityParams{ID: bt.ID})
+	suite.Require().NoError(err)
+	suite.Require().Equal(backtest.StatusFinished, resp.Backtest.Status)
+	suite.Require().Equal("new", resp.Backtest.Name)
+	suite.Require().Equal("description", resp.Backtest.Description)
+	suite.Require().Equal("alice", resp.Backtest.Creator)
+	suite.Require().Equal([]string{"new"}, resp.Backtest.Tags)
+
+	// The backtest should exist
+	_, err = suite.DB.UpdateBacktestMetadataActivity(context.Background(), UpdateBacktestMetadataActivityParams{
+		Backtest: suite.createTestBacktest(uuid.New(), "init", "prices", "exit"),
+	})
+	suite.Require().ErrorIs(err, ErrNotFound)
+}
+
+// TestPurge tests that purging backtests deletes the ones matching the filters,
+// except the running ones.
+func (suite *BacktestSuite) TestPurge() {
+	statuses := []backtest.Status{
+		backtest.StatusFinished, backtest.StatusFailed,
+		backtest.StatusRunning, backtest.StatusFinished,
+	}
+	backtests := make([]backtest.Backtest, len(statuses))
+	for i, status := range statuses {
+		backtests[i] = suite.createTestBacktest(uuid.New(), "init", "prices", "exit")
+		backtests[i].Status = status
+		backtests[i].Tags = []string{"purge"}
+		_, err := suite.DB.CreateBacktestActivity(context.Background(), CreateBacktestActivityParams{
+			Backtest: backtests[i],
+		})
+		suite.Require().NoError(err)
+	}
+	backtests[3].Tags = nil
+	_, err := suite.DB.UpdateBacktestMetadataActivity(context.Background(), UpdateBacktestMetadataActivityParams{
+		Backtest: backtests[3],
+	})
+	suite.Require().NoError(err)
+
+	// Nothing is created before the past
+	past := time.Now().Add(-time.Hour)
+	resp, err := suite.DB.PurgeBacktestsActivity(context.Background(), PurgeBacktestsActivityParams{
+		CreatedBefore: &past,
+	})
+	suite.Require().NoError(err)
+	suite.Require().Equal(0, resp.Deleted)
+
+	// Purge on tag, in batches of one
+	resp, err = suite.DB.PurgeBacktestsActivity(context.Background(), PurgeBacktestsActivityParams{
+		Tags:  []string{"purge"},
+		Limit: 1,
+	})
+	suite.Require().NoError(err)
+	suite.Require().Equal(1, resp.Deleted)
+
+	resp, err = suite.DB.PurgeBacktestsActivity(context.Background(), PurgeBacktestsActivityParams{
+		Tags: []string{"purge"},
+	})
+	suite.Require().NoError(err)
+	suite.Require().Equal(1, resp.Deleted)
+
+	// Check that only the running and untagged backtests remain
+	list, err := suite.DB.ListBacktestsActivity(context.Background(), ListBacktestsActivityParams{})
+	suite.Require().NoError(err)
+	suite.Require().Len(list.Backtests, 2)
+	suite.Require().ElementsMatch(
+		[]uuid.UUID{backtests[2].ID, backtests[3].ID},
+		[]uuid.UUID{list.Backtests[0].ID, list.Backtests[1].ID})
+}
+
+// TestPurgeAborted tests that purging backtests on the aborted status only
+// deletes the aborted ones.
+func (suite *BacktestSuite) TestPurgeAborted() {
+	ctx := context.Background()
+	tag := uuid.New().String()
+	statuses := []backtest.Status{backtest.StatusAborted, backtest.StatusFailed, backtest.StatusFinished}
+	for _, status := range statuses {
+		bt := suite.createTestBacktest(uuid.New(), "init", "prices", "exit")
+		bt.Status = status
+		bt.Tags = []string{tag}
+		_, err := suite.DB.CreateBacktestActivity(ctx, CreateBacktestActivityParams{Backtest: bt})
+		suite.Require().NoError(err)
+	}
+
+	resp, err := suite.DB.PurgeBacktestsActivity(ctx, PurgeBacktestsActivityParams{
+		Statuses: []backtest.Status{backtest.StatusAborted},
+		Tags:     []string{tag},
+	})
+	suite.Require().NoError(err)
+	suite.Require().Equal(1, resp.Deleted)
+
+	list, err := suite.DB.ListBacktestsActivity(ctx, ListBacktestsActivityParams{
+		Filters: backtest.Filters{Tags: []string{tag}},
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(list.Backtests, 2)
+	for _, bt := range list.Backtests {
+		suite.Require().NotEqual(backtest.StatusAborted, bt.Status)
+	}
 }
 
 // createUpdatedTestBacktest creates an updated test backtest with different values.
@@ -201,6 +498,147 @@ func (suite *BacktestSuite) TestUpdate() {
 	suite.Require().Equal(bt2.Callbacks.OnExitCallback, resp.Backtest.Callbacks.OnExitCallback)
 }
 
+// TestUpdateRows tests that updating a backtest adds, changes and removes its
+// balances, orders, subscriptions and callbacks.
+func (suite *BacktestSuite) TestUpdateRows() {
+	newOrder := func(price float64) order.Order {
+		executionTime := time.Unix(60, 0).UTC()
+		return order.Order{
+			ID:            uuid.New(),
+			ExecutionTime: &executionTime,
+			Type:          order.TypeIsMarket,
+			Exchange:      "exchange",
+			Pair:          "ETH-DAI",
+			Side:          order.SideIsBuy,
+			Quantity:      1,
+			Price:         price,
+		}
+	}
+
+	bt := suite.createTestBacktest(uuid.New(), "init", "prices", "exit")
+	bt.Accounts["exchange"].Balances["ETH"] = 1
+	bt.PricesSubscriptions = []tick.Subscription{
+		{Exchange: "exchange", Pair: "ETH-DAI"},
+		{Exchange: "exchange", Pair: "BTC-DAI"},
+	}
+	bt.Orders = []order.Order{newOrder(1000)}
+	_, err := suite.DB.CreateBacktestActivity(context.Background(), CreateBacktestActivityParams{
+		Backtest: bt,
+	})
+	suite.Require().NoError(err)
+
+	// Change every kind of row
+	bt.Accounts = map[string]account.Account{
+		"exchange": {Balances: map[string]float64{"DAI": 500}},
+	}
+	bt.PricesSubscriptions = []tick.Subscription{
+		{Exchange: "exchange", Pair: "BTC-DAI"},
+		{Exchange: "other", Pair: "ETH-USDT"},
+	}
+	bt.Orders = append(bt.Orders, newOrder(1100), newOrder(1200))
+	bt.CallbacksFailures.OnNewPrices = 1
+	bt.CallbacksPolicies.OnExit = backtest.CallbackPolicy{
+		OnFailure: backtest.CallbackFailureActionAbort,
+		Retry:     &backtest.CallbackRetryPolicy{InitialInterval: time.Second, MaximumAttempts: 2},
+	}
+	_, err = suite.DB.UpdateBacktestActivity(context.Background(), UpdateBacktestActivityParams{
+		Backtest: bt,
+	})
+	suite.Require().NoError(err)
+
+	resp, err := suite.DB.ReadBacktestActivity(context.Background(), ReadBacktestActivityParams{
+		ID: bt.ID,
+	})
+	suite.Require().NoError(err)
+	suite.Require().Equal(bt.Accounts, resp.Backtest.Accounts)
+	suite.Require().Equal(bt.PricesSubscriptions, resp.Backtest.PricesSubscriptions)
+	suite.Require().Equal(bt.Orders, resp.Backtest.Orders)
+	suite.Require().Equal(1, resp.Backtest.CallbacksFailures.OnNewPrices)
+	suite.Require().Equal(bt.CallbacksPolicies.OnExit, resp.Backtest.CallbacksPolicies.OnExit)
+
+	// List the summary, that counts the orders without reading them
+	list, err := suite.DB.ListBacktestsActivity(context.Background(), ListBacktestsActivityParams{
+		SummaryOnly: true,
+	})
+	suite.Require().NoError(err)
+	suite.Require().Len(list.Summaries, 1)
+	suite.Require().Equal(3, list.Summaries[0].OrdersCount)
+	suite.Require().Equal(bt.PricesSubscriptions, list.Summaries[0].PricesSubscriptions)
+}
+
+// TestUpdateVersionConflict tests that an update based on an outdated version
+// of a backtest is rejected, instead of overwriting the concurrent update.
+func (suite *BacktestSuite) TestUpdateVersionConflict() {
+	ctx := context.Background()
+	newOrder := func() order.Order {
+		executionTime := time.Unix(60, 0).UTC()
+		return order.Order{
+			ID:            uuid.New(),
+			ExecutionTime: &executionTime,
+			Type:          order.TypeIsMarket,
+			Exchange:      "exchange",
+			Pair:          "ETH-DAI",
+			Side:          order.SideIsBuy,
+			Quantity:      1,
+			Price:         1000,
+		}
+	}
+
+	bt := suite.createTestBacktest(uuid.New(), "init", "prices", "exit")
+	_, err := suite.DB.CreateBacktestActivity(ctx, CreateBacktestActivityParams{Backtest: bt})
+	suite.Require().NoError(err)
+
+	// An order is added and the backtest advanced from the same version
+	read, err := suite.DB.ReadBacktestActivity(ctx, ReadBacktestActivityParams{ID: bt.ID})
+	suite.Require().NoError(err)
+	withOrder, advanced := read.Backtest, read.Backtest
+	withOrder.Orders = append(withOrder.Orders, newOrder())
+	advanced.SetCurrentTime(advanced.CurrentCandlestick.Time.Add(time.Minute))
+
+	res, err := suite.DB.UpdateBacktestActivity(ctx, UpdateBacktestActivityParams{Backtest: withOrder})
+	suite.Require().NoError(err)
+	suite.Require().Equal(read.Backtest.Version+1, res.Version)
+
+	// The advance would lose the order, so it is rejected
+	_, err = suite.DB.UpdateBacktestActivity(ctx, UpdateBacktestActivityParams{Backtest: advanced})
+	suite.Require().ErrorIs(err, ErrVersionConflict)
+
+	read, err = suite.DB.ReadBacktestActivity(ctx, ReadBacktestActivityParams{ID: bt.ID})
+	suite.Require().NoError(err)
+	suite.Require().Len(read.Backtest.Orders, 1)
+	suite.Require().Equal(res.Version, read.Backtest.Version)
+
+	// Concurrent updates retried on conflict are all kept
+	const writers = 5
+	errs := make(chan error, writers)
+	for range writers {
+		go func() {
+			for {
+				read, err := suite.DB.ReadBacktestActivity(ctx, ReadBacktestActivityParams{ID: bt.ID})
+				if err != nil {
+					errs <- err
+					return
+				}
+
+				read.Backtest.Orders = append(read.Backtest.Orders, newOrder())
+				_, err = suite.DB.UpdateBacktestActivity(ctx, UpdateBacktestActivityParams{Backtest: read.Backtest})
+				if !errors.Is(err, ErrVersionConflict) {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	for range writers {
+		suite.Require().NoError(<-errs)
+	}
+
+	read, err = suite.DB.ReadBacktestActivity(ctx, ReadBacktestActivityParams{ID: bt.ID})
+	suite.Require().NoError(err)
+	suite.Require().Len(read.Backtest.Orders, 1+writers)
+	suite.Require().Equal(res.Version+writers, read.Backtest.Version)
+}
+
 // TestDelete tests that deleting a backtest works.
 func (suite *BacktestSuite) TestDelete() {
 	bt := backtest.Backtest{
@@ -246,10 +684,11 @@ func (suite *BacktestSuite) TestDelete() {
 	_, err = suite.DB.ReadBacktestActivity(context.Background(), ReadBacktestActivityParams{
 		ID: bt.ID,
 	})
-	suite.Error(err)
+	suite.Require().ErrorIs(err, ErrNotFound)
 }
 
-// TestDeleteInexistant tests that deleting an inexistant backtest does not return an error.
+// TestDeleteInexistant tests that deleting an inexistant backtest returns a
+// not found error.
 func (suite *BacktestSuite) TestDeleteInexistant() {
 	bt := backtest.Backtest{
 		ID:          uuid.New(),
@@ -294,5 +733,201 @@ func (suite *BacktestSuite) TestDeleteInexistant() {
 	_, err = suite.DB.DeleteBacktestActivity(context.Background(), DeleteBacktestActivityParams{
 		ID: bt.ID,
 	})
+	suite.Require().ErrorIs(err, ErrNotFound)
+}
+
+// TestDeleteRunning tests that deleting a running backtest fails and keeps it.
+func (suite *BacktestSuite) TestDeleteRunning() {
+	bt := suite.createTestBacktest(uuid.New(), "init", "prices", "exit")
+	bt.Status = backtest.StatusRunning
+	_, err := suite.DB.CreateBacktestActivity(context.Background(), CreateBacktestActivityParams{
+		Backtest: bt,
+	})
+	suite.Require().NoError(err)
+
+	_, err = suite.DB.DeleteBacktestActivity(context.Background(), DeleteBacktestActivityParams{
+		ID: bt.ID,
+	})
+	suite.Require().ErrorIs(err, backtest.ErrBacktestRunning)
+
+	_, err = suite.DB.ReadBacktestActivity(context.Background(), ReadBacktestActivityParams{
+		ID: bt.ID,
+	})
+	suite.Require().NoError(err)
+}
+
+// TestCreateReadSnapshot tests that creating then reading backtest snapshots works.
+func (suite *BacktestSuite) TestCreateReadSnapshot() {
+	bt := suite.createTestBacktest(uuid.New(), "test-init-workflow", "test-prices-workflow", "test-exit-workflow")
+	_, err := suite.DB.CreateBacktestActivity(context.Background(), CreateBacktestActivityParams{
+		Backtest: bt,
+	})
+	suite.Require().NoError(err)
+
+	for _, t := range []int64{60, 120} {
+		bt.CurrentCandlestick.Time = time.Unix(t, 0).UTC()
+		bt.Accounts["exchange"].Balances["DAI"] = float64(t)
+		_, err = suite.DB.CreateBacktestSnapshotActivity(context.Background(), CreateBacktestSnapshotActivityParams{
+			Snapshot: bt.Snapshot(),
+		})
+		suite.Require().NoError(err)
+	}
+
+	// Read at an exact time
+	resp, err := suite.DB.ReadBacktestSnapshotActivity(context.Background(), ReadBacktestSnapshotActivityParams{
+		BacktestID: bt.ID,
+		Time:       time.Unix(60, 0),
+	})
+	suite.Require().NoError(err)
+	suite.Require().Equal(bt.ID, resp.Snapshot.BacktestID)
+	suite.Require().WithinDuration(time.Unix(60, 0), resp.Snapshot.CurrentCandlestick.Time, time.Second)
+	suite.Require().Equal(float64(60), resp.Snapshot.Accounts["exchange"].Balances["DAI"])
+
+	// Read the latest before a time
+	resp, err = suite.DB.ReadBacktestSnapshotActivity(context.Background(), ReadBacktestSnapshotActivityParams{
+		BacktestID: bt.ID,
+		Time:       time.Unix(150, 0),
+	})
+	suite.Require().NoError(err)
+	suite.Require().WithinDuration(time.Unix(120, 0), resp.Snapshot.CurrentCandlestick.Time, time.Second)
+
+	// Read before the first snapshot
+	_, err = suite.DB.ReadBacktestSnapshotActivity(context.Background(), ReadBacktestSnapshotActivityParams{
+		BacktestID: bt.ID,
+		Time:       time.Unix(30, 0),
+	})
+	suite.Require().ErrorIs(err, ErrNotFound)
+}
+
+// TestTenantIsolation tests that the backtests of a tenant are not found by
+// the other tenants.
+func (suite *BacktestSuite) TestTenantIsolation() {
+	ctx := context.Background()
+	bt := suite.createTestBacktest(uuid.New(), "init", "prices", "exit")
+	bt.Tenant = "tenant-a"
+	_, err := suite.DB.CreateBacktestActivity(ctx, CreateBacktestActivityParams{Backtest: bt})
+	suite.Require().NoError(err)
+	_, err = suite.DB.CreateBacktestSnapshotActivity(ctx, CreateBacktestSnapshotActivityParams{
+		Snapshot: bt.Snapshot(),
+		Tenant:   "tenant-a",
+	})
+	suite.Require().NoError(err)
+
+	// The owner can read it
+	resp, err := suite.DB.ReadBacktestActivity(ctx, ReadBacktestActivityParams{ID: bt.ID, Tenant: "tenant-a"})
+	suite.Require().NoError(err)
+	suite.Require().Equal("tenant-a", resp.Backtest.Tenant)
+
+	// The other tenants can't read it
+	_, err = suite.DB.ReadBacktestActivity(ctx, ReadBacktestActivityParams{ID: bt.ID, Tenant: "tenant-b"})
+	suite.Require().ErrorIs(err, ErrNotFound)
+	_, err = suite.DB.ReadBacktestActivity(ctx, ReadBacktestActivityParams{ID: bt.ID})
+	suite.Require().ErrorIs(err, ErrNotFound)
+	_, err = suite.DB.ReadBacktestSnapshotActivity(ctx, ReadBacktestSnapshotActivityParams{
+		BacktestID: bt.ID,
+		Time:       bt.CurrentCandlestick.Time,
+		Tenant:     "tenant-b",
+	})
+	suite.Require().ErrorIs(err, ErrNotFound)
+
+	// Nor list it
+	list, err := suite.DB.ListBacktestsActivity(ctx, ListBacktestsActivityParams{Tenant: "tenant-b"})
+	suite.Require().NoError(err)
+	for _, b := range list.Backtests {
+		suite.Require().NotEqual(bt.ID, b.ID)
+	}
+	list, err = suite.DB.ListBacktestsActivity(ctx, ListBacktestsActivityParams{Tenant: "tenant-a"})
+	suite.Require().NoError(err)
+	suite.Require().Len(list.Backtests, 1)
+
+	// Nor update it
+	other := bt
+	other.Tenant = "tenant-b"
+	other.Status = backtest.StatusFinished
+	_, err = suite.DB.UpdateBacktestActivity(ctx, UpdateBacktestActivityParams{Backtest: other})
+	suite.Require().ErrorIs(err, ErrNotFound)
+	_, err = suite.DB.UpdateBacktestMetadataActivity(ctx, UpdateBacktestMetadataActivityParams{Backtest: other})
+	suite.Require().ErrorIs(err, ErrNotFound)
+	_, err = suite.DB.CreateBacktestSnapshotActivity(ctx, CreateBacktestSnapshotActivityParams{
+		Snapshot: other.Snapshot(),
+		Tenant:   "tenant-b",
+	})
+	suite.Require().ErrorIs(err, ErrNotFound)
+
+	// Nor delete it
+	_, err = suite.DB.DeleteBacktestActivity(ctx, DeleteBacktestActivityParams{ID: bt.ID, Tenant: "tenant-b"})
+	suite.Require().ErrorIs(err, ErrNotFound)
+	purged, err := suite.DB.PurgeBacktestsActivity(ctx, PurgeBacktestsActivityParams{Tenant: "tenant-b"})
+	suite.Require().NoError(err)
+	suite.Require().Zero(purged.Deleted)
+
+	resp, err = suite.DB.ReadBacktestActivity(ctx, ReadBacktestActivityParams{ID: bt.ID, Tenant: "tenant-a"})
+	suite.Require().NoError(err)
+	suite.Require().NotEqual(backtest.StatusFinished, resp.Backtest.Status)
+}
+
+// TestPurgeTenants tests that purging backtests only deletes the ones of the
+// tenant, unless all the tenants are purged.
+func (suite *BacktestSuite) TestPurgeTenants() {
+	ctx := context.Background()
+	for _, t := range []string{"", "tenant-a", "tenant-b"} {
+		bt := suite.createTestBacktest(uuid.New(), "init", "prices", "exit")
+		bt.Status = backtest.StatusFinished
+		bt.Tenant = t
+		_, err := suite.DB.CreateBacktestActivity(ctx, CreateBacktestActivityParams{Backtest: bt})
+		suite.Require().NoError(err)
+	}
+
+	// Purge the backtests of a tenant
+	resp, err := suite.DB.PurgeBacktestsActivity(ctx, PurgeBacktestsActivityParams{Tenant: "tenant-a"})
+	suite.Require().NoError(err)
+	suite.Require().Equal(1, resp.Deleted)
+
+	list, err := suite.DB.ListBacktestsActivity(ctx, ListBacktestsActivityParams{Tenant: "tenant-a"})
+	suite.Require().NoError(err)
+	suite.Require().Empty(list.Backtests)
+
+	// Purge the backtests of all the tenants, ignoring the tenant
+	resp, err = suite.DB.PurgeBacktestsActivity(ctx, PurgeBacktestsActivityParams{
+		Tenant:     "tenant-a",
+		AllTenants: true,
+	})
+	suite.Require().NoError(err)
+	suite.Require().Equal(2, resp.Deleted)
+
+	for _, t := range []string{"", "tenant-b"} {
+		list, err = suite.DB.ListBacktestsActivity(ctx, ListBacktestsActivityParams{Tenant: t})
+		suite.Require().NoError(err)
+		suite.Require().Empty(list.Backtests)
+	}
+}
+
+// TestWebhookSecrets tests that the secrets of the webhooks are set on creation
+// and kept on updates, even from a backtest serialized without them.
+func (suite *BacktestSuite) TestWebhookSecrets() {
+	ctx := context.Background()
+	definitions := []backtest.WebhookDefinition{{URL: "https://example.com/hook", Secret: "secret"}}
+	bt := suite.createTestBacktest(uuid.New(), "init", "prices", "exit")
+	bt.Webhooks = []backtest.Webhook{{URL: "https://example.com/hook"}}
+	_, err := suite.DB.CreateBacktestActivity(ctx, CreateBacktestActivityParams{
+		Backtest: bt,
+		Webhooks: definitions,
+	})
+	suite.Require().NoError(err)
+
+	resp, err := suite.DB.ReadBacktestActivity(ctx, ReadBacktestActivityParams{ID: bt.ID})
+	suite.Require().NoError(err)
+	suite.Require().Equal(backtest.NewWebhooks(definitions), resp.Backtest.Webhooks)
+
+	// Update the backtest as read by a workflow, without the secrets
+	updated := resp.Backtest
+	updated.Webhooks = []backtest.Webhook{{URL: "https://example.com/hook"}}
+	updated.Status = backtest.StatusFinished
+	_, err = suite.DB.UpdateBacktestActivity(ctx, UpdateBacktestActivityParams{Backtest: updated})
+	suite.Require().NoError(err)
+
+	resp, err = suite.DB.ReadBacktestActivity(ctx, ReadBacktestActivityParams{ID: bt.ID})
 	suite.Require().NoError(err)
+	suite.Require().Equal(backtest.StatusFinished, resp.Backtest.Status)
+	suite.Require().Equal(backtest.NewWebhooks(definitions), resp.Backtest.Webhooks)
 }
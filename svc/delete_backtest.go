@@ -0,0 +1,30 @@
+package svc
+
+import (
+	"fmt"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/tenant"
+	"github.com/cryptellation/backtests/svc/db"
+	"go.temporal.io/sdk/workflow"
+)
+
+// DeleteBacktestWorkflow deletes a backtest, unless it is running.
+func (wf *workflows) DeleteBacktestWorkflow(
+	ctx workflow.Context,
+	params api.DeleteBacktestWorkflowParams,
+) (api.DeleteBacktestWorkflowResults, error) {
+	// Delete the backtest, that is checked not to be running at the same time
+	var dbRes db.DeleteBacktestActivityResults
+	err := workflow.ExecuteActivity(
+		workflow.WithActivityOptions(ctx, db.DefaultActivityOptions()),
+		wf.db.DeleteBacktestActivity, db.DeleteBacktestActivityParams{
+			ID:     params.BacktestID,
+			Tenant: tenant.FromWorkflow(ctx),
+		}).Get(ctx, &dbRes)
+	if err != nil {
+		return api.DeleteBacktestWorkflowResults{}, fmt.Errorf("deleting backtest from db: %w", err)
+	}
+
+	return api.DeleteBacktestWorkflowResults{}, nil
+}
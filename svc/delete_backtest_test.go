@@ -0,0 +1,75 @@
+//go:build unit
+// +build unit
+
+package svc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/backtests/svc/db"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+func TestDeleteBacktestSuite(t *testing.T) {
+	suite.Run(t, new(DeleteBacktestSuite))
+}
+
+type DeleteBacktestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+}
+
+// runningBacktest is a database whose only backtest is running.
+type runningBacktest struct {
+	db.DB
+	id uuid.UUID
+}
+
+func (d runningBacktest) DeleteBacktestActivity(
+	_ context.Context,
+	params db.DeleteBacktestActivityParams,
+) (db.DeleteBacktestActivityResults, error) {
+	err := fmt.Errorf("%w: backtest %s", db.ErrNotFound, params.ID)
+	if params.ID == d.id {
+		err = fmt.Errorf("%w: %s", backtest.ErrBacktestRunning, params.ID)
+	}
+	return db.DeleteBacktestActivityResults{}, api.NewApplicationError(err, api.ErrorTypes)
+}
+
+func (suite *DeleteBacktestSuite) TestErrors() {
+	store := runningBacktest{id: uuid.New()}
+	wf := &workflows{db: store}
+
+	cases := []struct {
+		ID   uuid.UUID
+		Type string
+	}{
+		{ID: store.id, Type: "backtest.BacktestRunning"},
+		{ID: uuid.New(), Type: "db.NotFound"},
+	}
+	for _, c := range cases {
+		env := suite.NewTestWorkflowEnvironment()
+		env.RegisterActivityWithOptions(store.DeleteBacktestActivity,
+			activity.RegisterOptions{Name: db.DeleteBacktestActivityName})
+		env.RegisterWorkflowWithOptions(withApplicationErrors(wf.DeleteBacktestWorkflow),
+			workflow.RegisterOptions{Name: api.DeleteBacktestWorkflowName})
+
+		// The error of the activity is returned with its type, without reading the backtest
+		env.ExecuteWorkflow(api.DeleteBacktestWorkflowName, api.DeleteBacktestWorkflowParams{BacktestID: c.ID})
+		suite.Require().True(env.IsWorkflowCompleted())
+
+		var appErr *temporal.ApplicationError
+		suite.Require().True(errors.As(env.GetWorkflowError(), &appErr), c.Type)
+		suite.Require().Equal(c.Type, appErr.Type())
+	}
+}
@@ -0,0 +1,17 @@
+package svc
+
+import (
+	"github.com/cryptellation/backtests/api"
+	"go.temporal.io/sdk/workflow"
+)
+
+// withApplicationErrors converts the domain errors returned by a workflow into
+// application errors, in order to keep their type across Temporal.
+func withApplicationErrors[P, R any](
+	fn func(workflow.Context, P) (R, error),
+) func(workflow.Context, P) (R, error) {
+	return func(ctx workflow.Context, params P) (R, error) {
+		res, err := fn(ctx, params)
+		return res, api.NewApplicationError(err, api.ErrorTypes)
+	}
+}
@@ -0,0 +1,58 @@
+package svc
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/export"
+	"go.temporal.io/sdk/workflow"
+)
+
+// ExportBacktestWorkflow returns the rows of the datasets exported from a
+// backtest, to be written in files by the clients.
+func (wf *workflows) ExportBacktestWorkflow(
+	ctx workflow.Context,
+	params api.ExportBacktestWorkflowParams,
+) (api.ExportBacktestWorkflowResults, error) {
+	datasets := params.Datasets
+	if len(datasets) == 0 {
+		datasets = export.Datasets
+	}
+	for _, d := range datasets {
+		if err := d.Validate(); err != nil {
+			return api.ExportBacktestWorkflowResults{}, err
+		}
+	}
+
+	// Read backtest
+	bt, err := wf.readBacktestFromDB(ctx, params.BacktestID)
+	if err != nil {
+		return api.ExportBacktestWorkflowResults{}, fmt.Errorf("read backtest from db: %w", err)
+	}
+
+	// Get the rows of each dataset
+	var res api.ExportBacktestWorkflowResults
+	if slices.Contains(datasets, export.DatasetOrders) {
+		res.Orders = export.OrderRows(bt)
+	}
+	if slices.Contains(datasets, export.DatasetBalances) {
+		res.Balances, err = export.BalanceRows(bt)
+		if err != nil {
+			return api.ExportBacktestWorkflowResults{}, fmt.Errorf("computing balances history: %w", err)
+		}
+	}
+	if slices.Contains(datasets, export.DatasetEquity) {
+		endPrices, err := wf.getEndPrices(ctx, bt, params.QuoteAsset)
+		if err != nil {
+			return api.ExportBacktestWorkflowResults{}, fmt.Errorf("getting end prices: %w", err)
+		}
+
+		res.Equity, err = export.EquityRows(bt, params.QuoteAsset, endPrices)
+		if err != nil {
+			return api.ExportBacktestWorkflowResults{}, fmt.Errorf("computing equity curve: %w", err)
+		}
+	}
+
+	return res, nil
+}
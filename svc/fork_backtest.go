@@ -0,0 +1,68 @@
+package svc
+
+import (
+	"fmt"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/tenant"
+	"github.com/cryptellation/backtests/svc/db"
+	"go.temporal.io/sdk/workflow"
+)
+
+// ForkBacktestWorkflow creates a new backtest from a snapshot of an existing one.
+func (wf *workflows) ForkBacktestWorkflow(
+	ctx workflow.Context,
+	params api.ForkBacktestWorkflowParams,
+) (api.ForkBacktestWorkflowResults, error) {
+	logger := workflow.GetLogger(ctx)
+
+	// Validate callbacks
+	if err := params.Callbacks.Validate(); err != nil {
+		return api.ForkBacktestWorkflowResults{}, fmt.Errorf("validating callbacks: %w", err)
+	}
+
+	// Read backtest
+	bt, err := wf.readBacktestFromDB(ctx, params.BacktestID)
+	if err != nil {
+		return api.ForkBacktestWorkflowResults{}, fmt.Errorf("read backtest from db: %w", err)
+	}
+
+	// Read snapshot
+	var snapshotRes db.ReadBacktestSnapshotActivityResults
+	err = workflow.ExecuteActivity(
+		workflow.WithActivityOptions(ctx, db.DefaultActivityOptions()),
+		wf.db.ReadBacktestSnapshotActivity, db.ReadBacktestSnapshotActivityParams{
+			BacktestID: params.BacktestID,
+			Time:       params.Time,
+			Tenant:     tenant.FromWorkflow(ctx),
+		}).Get(ctx, &snapshotRes)
+	if err != nil {
+		return api.ForkBacktestWorkflowResults{}, fmt.Errorf("read backtest snapshot from db: %w", err)
+	}
+
+	// Fork the backtest
+	forked, err := bt.Fork(snapshotRes.Snapshot, params.Callbacks)
+	if err != nil {
+		return api.ForkBacktestWorkflowResults{}, fmt.Errorf("forking backtest: %w", err)
+	}
+	logger.Info("Forking backtest",
+		"backtest_id", bt.ID.String(),
+		"fork_id", forked.ID.String(),
+		"snapshot_time", forked.CurrentCandlestick.Time)
+
+	// Save it to DB
+	var dbRes db.CreateBacktestActivityResults
+	err = workflow.ExecuteActivity(
+		workflow.WithActivityOptions(ctx, db.DefaultActivityOptions()),
+		wf.db.CreateBacktestActivity, db.CreateBacktestActivityParams{
+			Backtest: forked,
+		}).Get(ctx, &dbRes)
+	if err != nil {
+		return api.ForkBacktestWorkflowResults{}, fmt.Errorf("adding forked backtest to db: %w", err)
+	}
+
+	return api.ForkBacktestWorkflowResults{
+		ID:           forked.ID,
+		SnapshotTime: forked.CurrentCandlestick.Time,
+	}, nil
+}
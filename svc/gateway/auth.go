@@ -0,0 +1,85 @@
+package gateway
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrUnauthenticated is returned when a request cannot be authenticated.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Authenticator authenticates the requests of the gateway and returns the
+// tenant they act for.
+type Authenticator interface {
+	Authenticate(r *http.Request) (string, error)
+}
+
+// NoAuthenticator accepts every request on the default tenant. The tenant
+// header is rejected, as nothing guarantees that the request belongs to it.
+type NoAuthenticator struct{}
+
+// Authenticate returns the default tenant, unless the request has a tenant header.
+func (NoAuthenticator) Authenticate(r *http.Request) (string, error) {
+	return "", rejectTenantHeader(r)
+}
+
+// TrustedHeaderAuthenticator takes the tenant from the tenant header, set by
+// an authenticating proxy in front of the gateway. It should only be used when
+// the gateway can only be reached through this proxy, that has to replace the
+// header sent by the clients.
+type TrustedHeaderAuthenticator struct{}
+
+// Authenticate returns the tenant of the tenant header.
+func (TrustedHeaderAuthenticator) Authenticate(r *http.Request) (string, error) {
+	return r.Header.Get(TenantHeader), nil
+}
+
+// TokensAuthenticator authenticates the requests with the bearer token of
+// their Authorization header, and maps each token to its tenant.
+type TokensAuthenticator map[string]string
+
+// Authenticate returns the tenant of the request bearer token.
+func (a TokensAuthenticator) Authenticate(r *http.Request) (string, error) {
+	if err := rejectTenantHeader(r); err != nil {
+		return "", err
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return "", fmt.Errorf("%w: missing bearer token", ErrUnauthenticated)
+	}
+
+	for t, id := range a {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("%w: unknown bearer token", ErrUnauthenticated)
+}
+
+// ParseTokens parses the tokens of a TokensAuthenticator, written as
+// comma-separated "token=tenant" pairs. The tenant can be empty for the
+// default one.
+func ParseTokens(s string) (TokensAuthenticator, error) {
+	tokens := make(TokensAuthenticator)
+	for i, pair := range strings.Split(s, ",") {
+		token, id, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || token == "" {
+			return nil, fmt.Errorf("invalid token #%d: should be \"token=tenant\"", i+1)
+		}
+		tokens[token] = id
+	}
+	return tokens, nil
+}
+
+// rejectTenantHeader rejects the requests setting the tenant header, that is
+// only trusted with TrustedHeaderAuthenticator.
+func rejectTenantHeader(r *http.Request) error {
+	if _, ok := r.Header[http.CanonicalHeaderKey(TenantHeader)]; ok {
+		return fmt.Errorf("%w: the %s header is not accepted by this gateway", ErrUnauthenticated, TenantHeader)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cryptellation/backtests/pkg/tenant"
+	"github.com/google/uuid"
+)
+
+// queryParameter is a query parameter of an endpoint.
+type queryParameter struct {
+	Name        string
+	Description string
+	Type        reflect.Type
+	Multiple    bool
+}
+
+// endpoint is an HTTP endpoint calling a workflow.
+type endpoint struct {
+	Method      string
+	Path        string
+	OperationID string
+	Summary     string
+	// Params and Results are the types exchanged with the workflow.
+	Params  reflect.Type
+	Results reflect.Type
+	// Body is true if the parameters are read from the request body.
+	Body    bool
+	Query   []queryParameter
+	Status  int
+	Handler http.HandlerFunc
+}
+
+// binder sets the parameters from the request path and query.
+type binder[P any] func(r *http.Request, params *P) error
+
+// newEndpoint creates an endpoint calling a workflow: the parameters are read
+// from the request body (if any) then from the binders, and the results are
+// written with the given status.
+func newEndpoint[P, R any](
+	method, path, operationID, summary string,
+	status int,
+	call func(context.Context, P) (R, error),
+	binders ...binder[P],
+) endpoint {
+	body := method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+	return endpoint{
+		Method:      method,
+		Path:        path,
+		OperationID: operationID,
+		Summary:     summary,
+		Params:      reflect.TypeFor[P](),
+		Results:     reflect.TypeFor[R](),
+		Body:        body,
+		Status:      status,
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			// Get the parameters
+			var params P
+			if body {
+				if err := json.NewDecoder(r.Body).Decode(&params); err != nil && !errors.Is(err, io.EOF) {
+					writeError(w, requestError{fmt.Errorf("decoding body: %w", err)})
+					return
+				}
+			}
+			for _, bind := range binders {
+				if err := bind(r, &params); err != nil {
+					writeError(w, requestError{err})
+					return
+				}
+			}
+
+			// Set the tenant authenticated with the request
+			ctx, err := withTenant(r, &params)
+			if err != nil {
+				writeError(w, requestError{err})
+				return
+			}
+
+			// Call the workflow
+			res, err := call(ctx, params)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+
+			if status == http.StatusNoContent {
+				w.WriteHeader(status)
+				return
+			}
+			writeJSON(w, status, res)
+		},
+	}
+}
+
+// withQuery documents the query parameters of the endpoint.
+func (e endpoint) withQuery(params ...queryParameter) endpoint {
+	e.Query = append(e.Query, params...)
+	return e
+}
+
+// withBacktestID sets the BacktestID field of the parameters from the request path.
+func withBacktestID[P any](r *http.Request, params *P) error {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		return fmt.Errorf("invalid backtest id %q: %w", r.PathValue("id"), err)
+	}
+
+	reflect.ValueOf(params).Elem().FieldByName("BacktestID").Set(reflect.ValueOf(id))
+	return nil
+}
+
+// withTenant returns the request context with the authenticated tenant, and
+// sets it as the Tenant field of the parameters, if any. The tenant of the
+// body is always replaced, so a request can only reach its own backtests.
+func withTenant[P any](r *http.Request, params *P) (context.Context, error) {
+	id := tenant.FromContext(r.Context())
+	if err := tenant.Validate(id); err != nil {
+		return nil, err
+	}
+
+	v := reflect.ValueOf(params).Elem()
+	if v.Kind() == reflect.Struct {
+		if f := v.FieldByName("Tenant"); f.IsValid() && f.Kind() == reflect.String {
+			f.SetString(id)
+		}
+	}
+
+	return tenant.WithID(r.Context(), id), nil
+}
+
+// queryValues returns the values of a query parameter, splitting them on commas.
+func queryValues(r *http.Request, name string) []string {
+	var values []string
+	for _, v := range r.URL.Query()[name] {
+		for _, s := range strings.Split(v, ",") {
+			if s != "" {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
+
+// queryTime returns the time of a query parameter, in RFC 3339 format.
+func queryTime(r *http.Request, name string) (*time.Time, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %q query parameter: %w", name, err)
+	}
+	return &t, nil
+}
+
+// queryInt returns the integer of a query parameter, or 0 if not set.
+func queryInt(r *http.Request, name string) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return 0, nil
+	}
+
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %q query parameter: %w", name, err)
+	}
+	return i, nil
+}
+
+// queryBool returns the boolean of a query parameter, or false if not set.
+func queryBool(r *http.Request, name string) (bool, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %q query parameter: %w", name, err)
+	}
+	return b, nil
+}
@@ -0,0 +1,180 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/backtests/pkg/clients"
+	"github.com/cryptellation/backtests/pkg/export"
+	"github.com/cryptellation/backtests/pkg/montecarlo"
+	"github.com/cryptellation/backtests/pkg/quota"
+	"github.com/cryptellation/backtests/pkg/sweep"
+	"github.com/cryptellation/backtests/pkg/tenant"
+	"go.temporal.io/api/serviceerror"
+)
+
+const (
+	// CodeInvalidRequest is the code of an error due to invalid parameters.
+	CodeInvalidRequest = "invalid_request"
+	// CodeUnauthenticated is the code of an error due to a request that could not
+	// be authenticated.
+	CodeUnauthenticated = "unauthenticated"
+	// CodeForbidden is the code of an error due to a resource of another tenant.
+	CodeForbidden = "forbidden"
+	// CodeNotFound is the code of an error due to a missing resource.
+	CodeNotFound = "not_found"
+	// CodeConflict is the code of an error due to the state of a resource.
+	CodeConflict = "conflict"
+	// CodeInternal is the code of an unexpected error.
+	CodeInternal = "internal"
+)
+
+// Error is the body of an error response.
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ErrorResponse is the response sent when a request fails.
+type ErrorResponse struct {
+	Error Error `json:"error"`
+}
+
+// requestError is an error due to the request itself, before any workflow call.
+type requestError struct {
+	err error
+}
+
+func (e requestError) Error() string {
+	return e.err.Error()
+}
+
+func (e requestError) Unwrap() error {
+	return e.err
+}
+
+// errorMapping maps errors returned by the workflows to HTTP statuses.
+type errorMapping struct {
+	Errors []error
+	Status int
+	Code   string
+}
+
+// workflowErrors are the errors that can be returned by the workflows, once
+// decoded by the raw client.
+var workflowErrors = []errorMapping{
+	{
+		Errors: []error{
+			backtest.ErrBacktestRunning,
+			clients.ErrBacktestAlreadyRunning,
+			quota.ErrWaitTimeout,
+			api.ErrVersionConflict,
+		},
+		Status: http.StatusConflict,
+		Code:   CodeConflict,
+	},
+	{
+		Errors: []error{
+			tenant.ErrMismatch,
+		},
+		Status: http.StatusForbidden,
+		Code:   CodeForbidden,
+	},
+	{
+		Errors: []error{
+			api.ErrNotFound,
+			clients.ErrBacktestRunNotFound,
+			clients.ErrBacktestNotRunning,
+		},
+		Status: http.StatusNotFound,
+		Code:   CodeNotFound,
+	},
+	{
+		Errors: []error{
+			api.ErrNilID,
+			api.ErrInvalidCursor,
+			backtest.ErrInvalidStatus,
+			backtest.ErrInvalidSortField,
+			backtest.ErrInvalidPurgeFilters,
+			backtest.ErrInvalidMode,
+			backtest.ErrInvalidPricePeriod,
+			backtest.ErrInvalidGapPolicy,
+			backtest.ErrInvalidWakeUp,
+			backtest.ErrInvalidSnapshotInterval,
+			backtest.ErrInvalidCallbackFailureAction,
+			backtest.ErrInvalidCallbackRetryPolicy,
+			backtest.ErrInvalidExchange,
+			backtest.ErrInvalidFee,
+			backtest.ErrInvalidSubscription,
+			backtest.ErrInvalidMetadata,
+			backtest.ErrInvalidMetric,
+			backtest.ErrInvalidQuoteAsset,
+			backtest.ErrStartAfterEnd,
+			backtest.ErrTickSubscriptionAlreadyExists,
+			sweep.ErrInvalidSearchSpace,
+			sweep.ErrTooManyCombinations,
+			sweep.ErrInvalidWindows,
+			montecarlo.ErrInvalidMethod,
+			montecarlo.ErrInvalidParameters,
+			montecarlo.ErrNoTrade,
+			export.ErrInvalidDataset,
+			quota.ErrExceeded,
+			quota.ErrInvalidLimits,
+			tenant.ErrInvalid,
+		},
+		Status: http.StatusBadRequest,
+		Code:   CodeInvalidRequest,
+	},
+}
+
+// errorStatus returns the HTTP status and the code corresponding to an error.
+func errorStatus(err error) (int, string) {
+	var reqErr requestError
+	if errors.As(err, &reqErr) {
+		return http.StatusBadRequest, CodeInvalidRequest
+	} else if errors.Is(err, ErrUnauthenticated) {
+		return http.StatusUnauthorized, CodeUnauthenticated
+	}
+
+	var notFound *serviceerror.NotFound
+	if errors.As(err, &notFound) {
+		return http.StatusNotFound, CodeNotFound
+	}
+
+	for _, m := range workflowErrors {
+		for _, e := range m.Errors {
+			if errors.Is(err, e) {
+				return m.Status, m.Code
+			}
+		}
+	}
+
+	return http.StatusInternalServerError, CodeInternal
+}
+
+// writeError writes the error as a JSON response.
+func writeError(w http.ResponseWriter, err error) {
+	status, code := errorStatus(err)
+	writeJSON(w, status, ErrorResponse{
+		Error: Error{
+			Code:    code,
+			Message: err.Error(),
+		},
+	})
+}
+
+// writeJSON writes the value as a JSON response with the given status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(b)
+}
@@ -0,0 +1,236 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/backtests/pkg/clients"
+	"github.com/cryptellation/backtests/pkg/tenant"
+	"github.com/google/uuid"
+)
+
+// TenantHeader is the HTTP header containing the tenant of the request. It is
+// only accepted from an authenticating proxy, with TrustedHeaderAuthenticator.
+const TenantHeader = "X-Cryptellation-Tenant"
+
+// RunResponse is the response of a started or attached backtest run.
+type RunResponse struct {
+	BacktestID uuid.UUID
+	WorkflowID string
+	RunID      string
+}
+
+// Gateway is an HTTP/JSON gateway calling the backtests temporal workflows.
+type Gateway struct {
+	raw       clients.RawClient
+	auth      Authenticator
+	mux       *http.ServeMux
+	endpoints []endpoint
+}
+
+// New creates a new gateway calling the workflows with the raw client, for the
+// tenant of the requests given by the authenticator. Without authenticator,
+// all the requests work on the backtests of the default tenant.
+func New(raw clients.RawClient, auth Authenticator) *Gateway {
+	if auth == nil {
+		auth = NoAuthenticator{}
+	}
+
+	g := &Gateway{
+		raw:  raw,
+		auth: auth,
+		mux:  http.NewServeMux(),
+	}
+
+	g.endpoints = append(g.endpoints, g.backtestsEndpoints()...)
+	g.endpoints = append(g.endpoints, g.backtestResourcesEndpoints()...)
+	g.endpoints = append(g.endpoints, g.analysisEndpoints()...)
+	for _, e := range g.endpoints {
+		g.mux.HandleFunc(e.Method+" "+e.Path, e.Handler)
+	}
+
+	g.mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, g.OpenAPI())
+	})
+
+	return g
+}
+
+// ServeHTTP serves the HTTP requests, once authenticated.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id, err := g.auth.Authenticate(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	g.mux.ServeHTTP(w, r.WithContext(tenant.WithID(r.Context(), id)))
+}
+
+func (g *Gateway) backtestsEndpoints() []endpoint {
+	return []endpoint{
+		newEndpoint(http.MethodPost, "/backtests", api.CreateBacktestWorkflowName,
+			"Create a backtest", http.StatusCreated, g.raw.CreateBacktest),
+		newEndpoint(http.MethodGet, "/backtests", api.ListBacktestsWorkflowName,
+			"List backtests", http.StatusOK, g.raw.ListBacktests, bindListQuery).
+			withQuery(listQueryParameters...),
+		newEndpoint(http.MethodPost, "/backtests/search", "SearchBacktests",
+			"List backtests with all the filters", http.StatusOK, g.raw.ListBacktests),
+		newEndpoint(http.MethodPost, "/backtests/purge", api.PurgeBacktestsWorkflowName,
+			"Delete the backtests matching the filters, except the running ones", http.StatusOK,
+			g.raw.PurgeBacktests),
+		newEndpoint(http.MethodGet, "/backtests/{id}", api.GetBacktestWorkflowName,
+			"Get a backtest", http.StatusOK, g.raw.GetBacktest, withBacktestID),
+		newEndpoint(http.MethodPatch, "/backtests/{id}", api.UpdateBacktestMetadataWorkflowName,
+			"Update the name, description and tags of a backtest", http.StatusOK, g.raw.UpdateBacktestMetadata,
+			withBacktestID),
+		newEndpoint(http.MethodDelete, "/backtests/{id}", api.DeleteBacktestWorkflowName,
+			"Delete a backtest, unless it is running", http.StatusNoContent, g.raw.DeleteBacktest, withBacktestID),
+		newEndpoint(http.MethodPost, "/backtests/{id}/run", "StartBacktest",
+			"Start a backtest without waiting for its end", http.StatusAccepted, g.startBacktest, withBacktestID),
+		newEndpoint(http.MethodGet, "/backtests/{id}/run", "GetBacktestRun",
+			"Get the last run of a backtest", http.StatusOK, g.attachBacktest, withBacktestID),
+		newEndpoint(http.MethodGet, "/backtests/{id}/events", api.BacktestEventsUpdateName,
+			"Wait for the events of the backtest run following a sequence", http.StatusOK,
+			g.raw.WaitBacktestEvents, withBacktestID, bindEventsQuery).
+			withQuery(eventsQueryParameters...),
+		newEndpoint(http.MethodPost, "/backtests/{id}/fork", api.ForkBacktestWorkflowName,
+			"Fork a backtest from one of its snapshots", http.StatusCreated, g.raw.ForkBacktest, withBacktestID),
+		newEndpoint(http.MethodGet, "/quotas/usage", api.GetQuotaUsageWorkflowName,
+			"Get the current usage of the quota of the tenant", http.StatusOK, g.raw.GetQuotaUsage),
+		newEndpoint(http.MethodGet, "/info", api.ServiceInfoWorkflowName,
+			"Get the service information", http.StatusOK, g.serviceInfo),
+	}
+}
+
+func (g *Gateway) backtestResourcesEndpoints() []endpoint {
+	return []endpoint{
+		newEndpoint(http.MethodGet, "/backtests/{id}/accounts", api.GetBacktestAccountsWorkflowName,
+			"Get the accounts of a backtest", http.StatusOK, g.raw.GetBacktestAccounts, withBacktestID),
+		newEndpoint(http.MethodGet, "/backtests/{id}/orders", api.GetBacktestOrdersWorkflowName,
+			"Get the orders of a backtest", http.StatusOK, g.raw.GetBacktestOrders, withBacktestID),
+		newEndpoint(http.MethodPost, "/backtests/{id}/orders", api.CreateBacktestOrderWorkflowName,
+			"Create an order on a backtest", http.StatusCreated, g.raw.CreateBacktestOrder, withBacktestID),
+		newEndpoint(http.MethodPost, "/backtests/{id}/subscriptions", api.SubscribeToPriceWorkflowName,
+			"Subscribe a backtest to prices", http.StatusCreated, g.raw.SubscribeToPrice, withBacktestID),
+		newEndpoint(http.MethodGet, "/backtests/{id}/strategy-parameters",
+			api.GetBacktestStrategyParametersWorkflowName, "Get the strategy parameters of a backtest",
+			http.StatusOK, g.raw.GetBacktestStrategyParameters, withBacktestID),
+		newEndpoint(http.MethodPut, "/backtests/{id}/wake-up", api.SetBacktestWakeUpWorkflowName,
+			"Set the wake up conditions of a backtest", http.StatusOK, g.raw.SetBacktestWakeUp, withBacktestID),
+	}
+}
+
+func (g *Gateway) analysisEndpoints() []endpoint {
+	return []endpoint{
+		newEndpoint(http.MethodPost, "/backtests/{id}/monte-carlo", api.MonteCarloWorkflowName,
+			"Run a Monte Carlo robustness analysis of a backtest", http.StatusOK, g.raw.MonteCarlo, withBacktestID),
+		newEndpoint(http.MethodPost, "/backtests/{id}/export", api.ExportBacktestWorkflowName,
+			"Get the orders, balances and equity rows of a backtest", http.StatusOK, g.raw.ExportBacktest,
+			withBacktestID),
+		newEndpoint(http.MethodPost, "/parameter-sweeps", api.RunParameterSweepWorkflowName,
+			"Run a backtest for each combination of strategy parameters", http.StatusOK, g.raw.RunParameterSweep),
+		newEndpoint(http.MethodPost, "/walk-forwards", api.WalkForwardWorkflowName,
+			"Run a walk-forward optimization", http.StatusOK, g.raw.WalkForward),
+	}
+}
+
+func (g *Gateway) startBacktest(ctx context.Context, params api.RunBacktestWorkflowParams) (RunResponse, error) {
+	run, err := g.raw.StartBacktest(ctx, params)
+	if err != nil {
+		return RunResponse{}, err
+	}
+
+	return RunResponse{
+		BacktestID: params.BacktestID,
+		WorkflowID: run.GetID(),
+		RunID:      run.GetRunID(),
+	}, nil
+}
+
+func (g *Gateway) attachBacktest(ctx context.Context, params api.RunBacktestWorkflowParams) (RunResponse, error) {
+	run, err := g.raw.AttachBacktest(ctx, params)
+	if err != nil {
+		return RunResponse{}, err
+	}
+
+	return RunResponse{
+		BacktestID: params.BacktestID,
+		WorkflowID: run.GetID(),
+		RunID:      run.GetRunID(),
+	}, nil
+}
+
+func (g *Gateway) serviceInfo(ctx context.Context, _ api.ServiceInfoParams) (api.ServiceInfoResults, error) {
+	return g.raw.ServiceInfo(ctx)
+}
+
+// listQueryParameters are the query parameters of the backtests listing.
+var listQueryParameters = []queryParameter{
+	{Name: "status", Description: "Statuses of the backtests", Type: reflect.TypeFor[backtest.Status](), Multiple: true},
+	{Name: "exchange", Description: "Exchange of the prices subscriptions", Type: reflect.TypeFor[string]()},
+	{Name: "pair", Description: "Pair of the prices subscriptions", Type: reflect.TypeFor[string]()},
+	{Name: "from", Description: "Minimum start time, in RFC 3339 format", Type: reflect.TypeFor[time.Time]()},
+	{Name: "to", Description: "Maximum end time, in RFC 3339 format", Type: reflect.TypeFor[time.Time]()},
+	{Name: "tag", Description: "Tags that the backtests should all have", Type: reflect.TypeFor[string](), Multiple: true},
+	{
+		Name: "any_tag", Description: "Tags that the backtests should have at least one of",
+		Type: reflect.TypeFor[string](), Multiple: true,
+	},
+	{Name: "name", Description: "Text contained in the name, regardless of the case", Type: reflect.TypeFor[string]()},
+	{Name: "creator", Description: "Creator of the backtests", Type: reflect.TypeFor[string]()},
+	{Name: "sort", Description: "Field used to sort the backtests", Type: reflect.TypeFor[backtest.SortField]()},
+	{Name: "descending", Description: "Sort in descending order", Type: reflect.TypeFor[bool]()},
+	{Name: "cursor", Description: "Cursor returned with the previous page", Type: reflect.TypeFor[string]()},
+	{Name: "limit", Description: "Maximum count of backtests per page", Type: reflect.TypeFor[int]()},
+	{Name: "summary_only", Description: "Return the summaries of the backtests", Type: reflect.TypeFor[bool]()},
+}
+
+// bindListQuery sets the listing parameters from the request query.
+func bindListQuery(r *http.Request, params *api.ListBacktestsWorkflowParams) (err error) {
+	query := r.URL.Query()
+
+	for _, s := range queryValues(r, "status") {
+		params.Filters.Statuses = append(params.Filters.Statuses, backtest.Status(s))
+	}
+	params.Filters.Exchange = query.Get("exchange")
+	params.Filters.Pair = query.Get("pair")
+	if params.Filters.From, err = queryTime(r, "from"); err != nil {
+		return err
+	}
+	if params.Filters.To, err = queryTime(r, "to"); err != nil {
+		return err
+	}
+	params.Filters.Tags = queryValues(r, "tag")
+	params.Filters.AnyTags = queryValues(r, "any_tag")
+	params.Filters.Name = query.Get("name")
+	params.Filters.Creator = query.Get("creator")
+
+	params.Sort.Field = backtest.SortField(query.Get("sort"))
+	if params.Sort.Descending, err = queryBool(r, "descending"); err != nil {
+		return err
+	}
+
+	params.Cursor = query.Get("cursor")
+	if params.Limit, err = queryInt(r, "limit"); err != nil {
+		return err
+	}
+	params.SummaryOnly, err = queryBool(r, "summary_only")
+	return err
+}
+
+// eventsQueryParameters are the query parameters of the backtest run events.
+var eventsQueryParameters = []queryParameter{
+	{Name: "after", Description: "Sequence of the last received event", Type: reflect.TypeFor[int64]()},
+}
+
+// bindEventsQuery sets the events parameters from the request query.
+func bindEventsQuery(r *http.Request, params *api.BacktestEventsUpdateParams) error {
+	after, err := queryInt(r, "after")
+	params.AfterSequence = int64(after)
+	return err
+}
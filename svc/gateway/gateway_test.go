@@ -0,0 +1,367 @@
+//go:build unit
+// +build unit
+
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/backtests/pkg/clients"
+	"github.com/cryptellation/backtests/pkg/quota"
+	"github.com/cryptellation/backtests/pkg/tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/temporal"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGatewaySuite(t *testing.T) {
+	suite.Run(t, new(GatewaySuite))
+}
+
+type GatewaySuite struct {
+	suite.Suite
+	ctrl    *gomock.Controller
+	raw     *clients.MockRawClient
+	gateway *Gateway
+}
+
+func (suite *GatewaySuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.raw = clients.NewMockRawClient(suite.ctrl)
+	suite.gateway = New(suite.raw, nil)
+}
+
+func (suite *GatewaySuite) do(method, target, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	suite.gateway.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return rec
+}
+
+func (suite *GatewaySuite) requireError(rec *httptest.ResponseRecorder, status int, code string) {
+	suite.Require().Equal(status, rec.Code)
+
+	var res ErrorResponse
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &res))
+	suite.Require().Equal(code, res.Error.Code)
+	suite.Require().NotEmpty(res.Error.Message)
+}
+
+func (suite *GatewaySuite) TestCreate() {
+	id := uuid.New()
+	suite.raw.EXPECT().
+		CreateBacktest(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ any, params api.CreateBacktestWorkflowParams) (api.CreateBacktestWorkflowResults, error) {
+			suite.Require().Equal([]string{"tag"}, params.BacktestParameters.Tags)
+			return api.CreateBacktestWorkflowResults{ID: id}, nil
+		})
+
+	rec := suite.do(http.MethodPost, "/backtests", `{"BacktestParameters":{"Tags":["tag"]}}`)
+	suite.Require().Equal(http.StatusCreated, rec.Code)
+
+	var res api.CreateBacktestWorkflowResults
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &res))
+	suite.Require().Equal(id, res.ID)
+}
+
+func (suite *GatewaySuite) TestCreateWithInvalidBody() {
+	rec := suite.do(http.MethodPost, "/backtests", `{`)
+	suite.requireError(rec, http.StatusBadRequest, CodeInvalidRequest)
+}
+
+func (suite *GatewaySuite) TestGetWithInvalidID() {
+	rec := suite.do(http.MethodGet, "/backtests/invalid", "")
+	suite.requireError(rec, http.StatusBadRequest, CodeInvalidRequest)
+}
+
+func (suite *GatewaySuite) TestGetNotFound() {
+	id := uuid.New()
+	suite.raw.EXPECT().
+		GetBacktest(gomock.Any(), api.GetBacktestWorkflowParams{BacktestID: id}).
+		Return(api.GetBacktestWorkflowResults{}, clients.DecodeError(
+			temporal.NewNonRetryableApplicationError("read backtest: not found", "db.NotFound", nil)))
+
+	rec := suite.do(http.MethodGet, "/backtests/"+id.String(), "")
+	suite.requireError(rec, http.StatusNotFound, CodeNotFound)
+}
+
+func (suite *GatewaySuite) TestDeleteRunning() {
+	id := uuid.New()
+	suite.raw.EXPECT().
+		DeleteBacktest(gomock.Any(), api.DeleteBacktestWorkflowParams{BacktestID: id}).
+		Return(api.DeleteBacktestWorkflowResults{}, clients.DecodeError(
+			temporal.NewNonRetryableApplicationError("backtest is running", "backtest.BacktestRunning", nil)))
+
+	rec := suite.do(http.MethodDelete, "/backtests/"+id.String(), "")
+	suite.requireError(rec, http.StatusConflict, CodeConflict)
+}
+
+func (suite *GatewaySuite) TestDelete() {
+	id := uuid.New()
+	suite.raw.EXPECT().
+		DeleteBacktest(gomock.Any(), api.DeleteBacktestWorkflowParams{BacktestID: id}).
+		Return(api.DeleteBacktestWorkflowResults{}, nil)
+
+	rec := suite.do(http.MethodDelete, "/backtests/"+id.String(), "")
+	suite.Require().Equal(http.StatusNoContent, rec.Code)
+	suite.Require().Empty(rec.Body.Bytes())
+}
+
+func (suite *GatewaySuite) TestUpdateMetadata() {
+	id := uuid.New()
+	name := "SMA crossover"
+	suite.raw.EXPECT().
+		UpdateBacktestMetadata(gomock.Any(), api.UpdateBacktestMetadataWorkflowParams{
+			BacktestID: id,
+			Metadata:   backtest.MetadataUpdate{Name: &name},
+		}).
+		Return(api.UpdateBacktestMetadataWorkflowResults{Backtest: backtest.Backtest{ID: id, Name: name}}, nil)
+
+	rec := suite.do(http.MethodPatch, "/backtests/"+id.String(), `{"Metadata": {"name": "SMA crossover"}}`)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+
+	var res api.UpdateBacktestMetadataWorkflowResults
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &res))
+	suite.Require().Equal(name, res.Backtest.Name)
+}
+
+func (suite *GatewaySuite) TestUpdateInvalidMetadata() {
+	id := uuid.New()
+	suite.raw.EXPECT().
+		UpdateBacktestMetadata(gomock.Any(), gomock.Any()).
+		Return(api.UpdateBacktestMetadataWorkflowResults{}, clients.DecodeError(
+			temporal.NewNonRetryableApplicationError("empty tag", "backtest.InvalidMetadata", nil)))
+
+	rec := suite.do(http.MethodPatch, "/backtests/"+id.String(), `{"Metadata": {"tags": [""]}}`)
+	suite.requireError(rec, http.StatusBadRequest, CodeInvalidRequest)
+}
+
+func (suite *GatewaySuite) TestRun() {
+	id := uuid.New()
+	run := clients.NewMockWorkflowRun(suite.ctrl)
+	run.EXPECT().GetID().Return(api.RunBacktestWorkflowID(id))
+	run.EXPECT().GetRunID().Return("run-id")
+	suite.raw.EXPECT().
+		StartBacktest(gomock.Any(), api.RunBacktestWorkflowParams{BacktestID: id}).
+		Return(run, nil)
+
+	rec := suite.do(http.MethodPost, "/backtests/"+id.String()+"/run", "")
+	suite.Require().Equal(http.StatusAccepted, rec.Code)
+
+	var res RunResponse
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &res))
+	suite.Require().Equal(RunResponse{
+		BacktestID: id,
+		WorkflowID: api.RunBacktestWorkflowID(id),
+		RunID:      "run-id",
+	}, res)
+}
+
+func (suite *GatewaySuite) TestRunAlreadyRunning() {
+	id := uuid.New()
+	suite.raw.EXPECT().
+		StartBacktest(gomock.Any(), api.RunBacktestWorkflowParams{BacktestID: id}).
+		Return(nil, clients.ErrBacktestAlreadyRunning)
+
+	rec := suite.do(http.MethodPost, "/backtests/"+id.String()+"/run", "")
+	suite.requireError(rec, http.StatusConflict, CodeConflict)
+}
+
+func (suite *GatewaySuite) TestListWithQuery() {
+	suite.raw.EXPECT().
+		ListBacktests(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ any, params api.ListBacktestsWorkflowParams) (api.ListBacktestsWorkflowResults, error) {
+			suite.Require().Equal([]backtest.Status{backtest.StatusFinished, backtest.StatusFailed}, params.Filters.Statuses)
+			suite.Require().Equal([]string{"a", "b"}, params.Filters.Tags)
+			suite.Require().Equal([]string{"c", "d"}, params.Filters.AnyTags)
+			suite.Require().Equal("sma", params.Filters.Name)
+			suite.Require().Equal("alice", params.Filters.Creator)
+			suite.Require().NotNil(params.Filters.From)
+			suite.Require().Equal(backtest.SortFieldEndTime, params.Sort.Field)
+			suite.Require().True(params.Sort.Descending)
+			suite.Require().Equal(10, params.Limit)
+			suite.Require().True(params.SummaryOnly)
+			return api.ListBacktestsWorkflowResults{NextCursor: "next"}, nil
+		})
+
+	rec := suite.do(http.MethodGet, "/backtests?status=finished,failed&tag=a&tag=b&any_tag=c,d&name=sma&creator=alice"+
+		"&from=2024-01-01T00:00:00Z&sort=end_time&descending=true&limit=10&summary_only=true", "")
+	suite.Require().Equal(http.StatusOK, rec.Code)
+
+	var res api.ListBacktestsWorkflowResults
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &res))
+	suite.Require().Equal("next", res.NextCursor)
+}
+
+func (suite *GatewaySuite) TestListWithInvalidQuery() {
+	rec := suite.do(http.MethodGet, "/backtests?limit=ten", "")
+	suite.requireError(rec, http.StatusBadRequest, CodeInvalidRequest)
+}
+
+func (suite *GatewaySuite) TestOpenAPI() {
+	rec := suite.do(http.MethodGet, "/openapi.json", "")
+	suite.Require().Equal(http.StatusOK, rec.Code)
+
+	var spec struct {
+		Paths      map[string]map[string]any
+		Components struct {
+			Schemas map[string]any
+		}
+	}
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &spec))
+	for _, e := range suite.gateway.endpoints {
+		suite.Require().Contains(spec.Paths[e.Path], strings.ToLower(e.Method), e.Path)
+	}
+	suite.Require().Contains(spec.Components.Schemas, "backtest.Backtest")
+	suite.Require().Contains(spec.Components.Schemas, "gateway.ErrorResponse")
+}
+
+func (suite *GatewaySuite) TestEvents() {
+	id := uuid.New()
+	suite.raw.EXPECT().
+		WaitBacktestEvents(gomock.Any(), api.BacktestEventsUpdateParams{BacktestID: id, AfterSequence: 3}).
+		Return(api.BacktestEventsUpdateResults{
+			Events: []backtest.Event{{Sequence: 4, Type: backtest.EventTypeStepAdvanced}},
+		}, nil)
+
+	rec := suite.do(http.MethodGet, "/backtests/"+id.String()+"/events?after=3", "")
+	suite.Require().Equal(http.StatusOK, rec.Code)
+
+	var res api.BacktestEventsUpdateResults
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &res))
+	suite.Require().Len(res.Events, 1)
+	suite.Require().Equal(int64(4), res.Events[0].Sequence)
+}
+
+func (suite *GatewaySuite) TestEventsNotRunning() {
+	id := uuid.New()
+	suite.raw.EXPECT().
+		WaitBacktestEvents(gomock.Any(), api.BacktestEventsUpdateParams{BacktestID: id}).
+		Return(api.BacktestEventsUpdateResults{}, clients.ErrBacktestNotRunning)
+
+	rec := suite.do(http.MethodGet, "/backtests/"+id.String()+"/events", "")
+	suite.requireError(rec, http.StatusNotFound, CodeNotFound)
+}
+
+func (suite *GatewaySuite) TestTenantHeader() {
+	id := uuid.New()
+	suite.raw.EXPECT().
+		GetBacktest(gomock.Any(), api.GetBacktestWorkflowParams{BacktestID: id, Tenant: "tenant"}).
+		DoAndReturn(func(ctx context.Context, _ api.GetBacktestWorkflowParams) (api.GetBacktestWorkflowResults, error) {
+			suite.Require().Equal("tenant", tenant.FromContext(ctx))
+			return api.GetBacktestWorkflowResults{Backtest: backtest.Backtest{ID: id}}, nil
+		})
+
+	req := httptest.NewRequest(http.MethodGet, "/backtests/"+id.String(), nil)
+	req.Header.Set(TenantHeader, "tenant")
+	rec := httptest.NewRecorder()
+	New(suite.raw, TrustedHeaderAuthenticator{}).ServeHTTP(rec, req)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+}
+
+func (suite *GatewaySuite) TestTenantHeaderNotTrusted() {
+	req := httptest.NewRequest(http.MethodGet, "/backtests/"+uuid.New().String(), nil)
+	req.Header.Set(TenantHeader, "tenant")
+	rec := httptest.NewRecorder()
+	suite.gateway.ServeHTTP(rec, req)
+	suite.requireError(rec, http.StatusUnauthorized, CodeUnauthenticated)
+}
+
+func (suite *GatewaySuite) TestTokens() {
+	auth, err := ParseTokens("secret=tenant, other=")
+	suite.Require().NoError(err)
+	g := New(suite.raw, auth)
+
+	id := uuid.New()
+	suite.raw.EXPECT().
+		GetBacktest(gomock.Any(), api.GetBacktestWorkflowParams{BacktestID: id, Tenant: "tenant"}).
+		Return(api.GetBacktestWorkflowResults{Backtest: backtest.Backtest{ID: id}}, nil)
+
+	cases := []struct {
+		Name          string
+		Authorization string
+		Tenant        string
+		Status        int
+	}{
+		{Name: "valid token", Authorization: "Bearer secret", Status: http.StatusOK},
+		{Name: "missing token", Status: http.StatusUnauthorized},
+		{Name: "unknown token", Authorization: "Bearer unknown", Status: http.StatusUnauthorized},
+		{Name: "tenant header", Authorization: "Bearer other", Tenant: "tenant", Status: http.StatusUnauthorized},
+	}
+	for _, c := range cases {
+		suite.Run(c.Name, func() {
+			req := httptest.NewRequest(http.MethodGet, "/backtests/"+id.String(), nil)
+			if c.Authorization != "" {
+				req.Header.Set("Authorization", c.Authorization)
+			}
+			if c.Tenant != "" {
+				req.Header.Set(TenantHeader, c.Tenant)
+			}
+			rec := httptest.NewRecorder()
+			g.ServeHTTP(rec, req)
+			suite.Require().Equal(c.Status, rec.Code)
+		})
+	}
+
+	_, err = ParseTokens("secret")
+	suite.Require().Error(err)
+}
+
+func (suite *GatewaySuite) TestTenantFromBodyIgnored() {
+	suite.raw.EXPECT().
+		CreateBacktest(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ any, params api.CreateBacktestWorkflowParams) (api.CreateBacktestWorkflowResults, error) {
+			suite.Require().Empty(params.Tenant)
+			return api.CreateBacktestWorkflowResults{ID: uuid.New()}, nil
+		})
+
+	rec := suite.do(http.MethodPost, "/backtests", `{"Tenant":"other"}`)
+	suite.Require().Equal(http.StatusCreated, rec.Code)
+}
+
+func (suite *GatewaySuite) TestTenantMismatch() {
+	id := uuid.New()
+	suite.raw.EXPECT().
+		DeleteBacktest(gomock.Any(), gomock.Any()).
+		Return(api.DeleteBacktestWorkflowResults{}, fmt.Errorf("%w: other tenant", tenant.ErrMismatch))
+
+	rec := suite.do(http.MethodDelete, "/backtests/"+id.String(), "")
+	suite.requireError(rec, http.StatusForbidden, CodeForbidden)
+}
+
+func (suite *GatewaySuite) TestQuotaUsage() {
+	suite.raw.EXPECT().
+		GetQuotaUsage(gomock.Any(), api.GetQuotaUsageWorkflowParams{Tenant: "tenant"}).
+		Return(api.GetQuotaUsageWorkflowResults{
+			Usage: quota.Usage{Tenant: "tenant", RunningBacktests: 2, QueuedBacktests: 1},
+		}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/quotas/usage", nil)
+	req.Header.Set(TenantHeader, "tenant")
+	rec := httptest.NewRecorder()
+	New(suite.raw, TrustedHeaderAuthenticator{}).ServeHTTP(rec, req)
+	suite.Require().Equal(http.StatusOK, rec.Code)
+
+	var res api.GetQuotaUsageWorkflowResults
+	suite.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &res))
+	suite.Require().Equal(2, res.Usage.RunningBacktests)
+	suite.Require().Equal(1, res.Usage.QueuedBacktests)
+}
+
+func (suite *GatewaySuite) TestUntypedError() {
+	suite.raw.EXPECT().
+		ListBacktests(gomock.Any(), gomock.Any()).
+		Return(api.ListBacktestsWorkflowResults{}, errors.New("invalid cursor"))
+
+	rec := suite.do(http.MethodGet, "/backtests", "")
+	suite.requireError(rec, http.StatusInternalServerError, CodeInternal)
+}
@@ -0,0 +1,207 @@
+package gateway
+
+import (
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cryptellation/version"
+	"github.com/google/uuid"
+)
+
+// OpenAPIVersion is the version of the OpenAPI specification of the gateway.
+const OpenAPIVersion = "3.0.3"
+
+var (
+	timeType     = reflect.TypeFor[time.Time]()
+	durationType = reflect.TypeFor[time.Duration]()
+	uuidType     = reflect.TypeFor[uuid.UUID]()
+
+	pathParameterRegexp = regexp.MustCompile(`{(\w+)}`)
+)
+
+// OpenAPI returns the OpenAPI specification of the gateway, generated from
+// the types exchanged with the workflows.
+func (g *Gateway) OpenAPI() map[string]any {
+	gen := schemaGenerator{components: map[string]any{}}
+	errorSchema := gen.schema(reflect.TypeFor[ErrorResponse]())
+
+	paths := map[string]map[string]any{}
+	for _, e := range g.endpoints {
+		if paths[e.Path] == nil {
+			paths[e.Path] = map[string]any{}
+		}
+		paths[e.Path][strings.ToLower(e.Method)] = gen.operation(e, errorSchema)
+	}
+
+	return map[string]any{
+		"openapi": OpenAPIVersion,
+		"info": map[string]any{
+			"title":   "Cryptellation Backtests",
+			"version": version.FullVersion(),
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": gen.components,
+		},
+	}
+}
+
+// schemaGenerator generates the OpenAPI schemas of Go types, with the named
+// structs as components.
+type schemaGenerator struct {
+	components map[string]any
+}
+
+func (gen *schemaGenerator) operation(e endpoint, errorSchema map[string]any) map[string]any {
+	op := map[string]any{
+		"operationId": e.OperationID,
+		"summary":     e.Summary,
+	}
+
+	// Set the parameters
+	parameters := make([]map[string]any, 0)
+	for _, m := range pathParameterRegexp.FindAllStringSubmatch(e.Path, -1) {
+		parameters = append(parameters, map[string]any{
+			"name":     m[1],
+			"in":       "path",
+			"required": true,
+			"schema":   gen.schema(uuidType),
+		})
+	}
+	for _, q := range e.Query {
+		schema := gen.schema(q.Type)
+		if q.Multiple {
+			schema = map[string]any{"type": "array", "items": schema}
+		}
+		parameters = append(parameters, map[string]any{
+			"name":        q.Name,
+			"in":          "query",
+			"description": q.Description,
+			"schema":      schema,
+		})
+	}
+	parameters = append(parameters, map[string]any{
+		"name":        TenantHeader,
+		"in":          "header",
+		"description": "Tenant owning the backtests, only accepted from a trusted authenticating proxy",
+		"schema":      gen.schema(reflect.TypeFor[string]()),
+	})
+	op["parameters"] = parameters
+
+	// Set the body
+	if e.Body {
+		op["requestBody"] = map[string]any{
+			"required": true,
+			"content":  jsonContent(gen.schema(e.Params)),
+		}
+	}
+
+	// Set the responses
+	response := map[string]any{"description": http.StatusText(e.Status)}
+	if e.Status != http.StatusNoContent {
+		response["content"] = jsonContent(gen.schema(e.Results))
+	}
+	op["responses"] = map[string]any{
+		strconv.Itoa(e.Status): response,
+		"default": map[string]any{
+			"description": "Error",
+			"content":     jsonContent(errorSchema),
+		},
+	}
+
+	return op
+}
+
+func jsonContent(schema map[string]any) map[string]any {
+	return map[string]any{
+		"application/json": map[string]any{
+			"schema": schema,
+		},
+	}
+}
+
+// schema returns the schema of a type, as it is encoded in JSON.
+func (gen *schemaGenerator) schema(t reflect.Type) map[string]any {
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case durationType:
+		return map[string]any{"type": "integer", "format": "int64", "description": "Duration in nanoseconds"}
+	case uuidType:
+		return map[string]any{"type": "string", "format": "uuid"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return gen.schema(t.Elem())
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": gen.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": gen.schema(t.Elem())}
+	case reflect.Struct:
+		return gen.structSchema(t)
+	default:
+		return map[string]any{}
+	}
+}
+
+// structSchema returns the schema of a struct, as a reference to a component
+// if the struct is named.
+func (gen *schemaGenerator) structSchema(t reflect.Type) map[string]any {
+	if t.Name() == "" {
+		return gen.objectSchema(t)
+	}
+
+	name := path.Base(t.PkgPath()) + "." + t.Name()
+	ref := map[string]any{"$ref": "#/components/schemas/" + name}
+	if _, exists := gen.components[name]; !exists {
+		// Reserve the name first, in case of recursive types
+		gen.components[name] = map[string]any{}
+		gen.components[name] = gen.objectSchema(t)
+	}
+	return ref
+}
+
+func (gen *schemaGenerator) objectSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	gen.addProperties(t, properties)
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+}
+
+// addProperties adds the JSON encoded fields of a struct to the properties.
+func (gen *schemaGenerator) addProperties(t reflect.Type, properties map[string]any) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		switch {
+		case tag == "-" || (!f.IsExported() && !f.Anonymous):
+			continue
+		case f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct:
+			gen.addProperties(f.Type, properties)
+			continue
+		case tag == "":
+			tag = f.Name
+		}
+		properties[tag] = gen.schema(f.Type)
+	}
+}
@@ -18,6 +18,6 @@ func (wf *workflows) GetBacktestWorkflow(
 	}
 
 	return api.GetBacktestWorkflowResults{
-		Backtest: bt,
+		Backtest: bt.WithoutSecrets(),
 	}, nil
 }
@@ -0,0 +1,55 @@
+package svc
+
+import (
+	"fmt"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/report"
+	candlesticksapi "github.com/cryptellation/candlesticks/api"
+	"go.temporal.io/sdk/workflow"
+)
+
+// GetBacktestReportWorkflow gets the content of the report of a backtest, with
+// the candlesticks of its subscriptions from the candlesticks service.
+func (wf *workflows) GetBacktestReportWorkflow(
+	ctx workflow.Context,
+	params api.GetBacktestReportWorkflowParams,
+) (api.GetBacktestReportWorkflowResults, error) {
+	// Read backtest
+	bt, err := wf.readBacktestFromDB(ctx, params.BacktestID)
+	if err != nil {
+		return api.GetBacktestReportWorkflowResults{}, fmt.Errorf("read backtest from db: %w", err)
+	}
+
+	endPrices, err := wf.getEndPrices(ctx, bt, params.QuoteAsset)
+	if err != nil {
+		return api.GetBacktestReportWorkflowResults{}, fmt.Errorf("getting end prices: %w", err)
+	}
+
+	r, err := report.New(bt, params.QuoteAsset, endPrices, workflow.Now(ctx))
+	if err != nil {
+		return api.GetBacktestReportWorkflowResults{}, fmt.Errorf("creating report: %w", err)
+	}
+
+	// Get the candlesticks of each subscription
+	p := report.ChartPeriod(bt)
+	for _, sub := range bt.PricesSubscriptions {
+		res, err := wf.cryptellation.ListCandlesticks(ctx, candlesticksapi.ListCandlesticksWorkflowParams{
+			Exchange: sub.Exchange,
+			Pair:     sub.Pair,
+			Period:   p,
+			Start:    &bt.StartTime,
+			End:      &bt.CurrentCandlestick.Time,
+			Limit:    report.MaxCandlesticks,
+		}, nil)
+		if err != nil {
+			return api.GetBacktestReportWorkflowResults{}, fmt.Errorf("could not get candlesticks from service: %w", err)
+		}
+
+		r.AddChart(sub, p, res.List)
+	}
+
+	return api.GetBacktestReportWorkflowResults{
+		Report: r,
+	}, nil
+}
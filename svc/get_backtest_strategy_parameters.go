@@ -0,0 +1,23 @@
+package svc
+
+import (
+	"fmt"
+
+	"github.com/cryptellation/backtests/api"
+	"go.temporal.io/sdk/workflow"
+)
+
+func (wf *workflows) GetBacktestStrategyParametersWorkflow(
+	ctx workflow.Context,
+	params api.GetBacktestStrategyParametersWorkflowParams,
+) (api.GetBacktestStrategyParametersWorkflowResults, error) {
+	// Read backtest
+	bt, err := wf.readBacktestFromDB(ctx, params.BacktestID)
+	if err != nil {
+		return api.GetBacktestStrategyParametersWorkflowResults{}, fmt.Errorf("read backtest from db: %w", err)
+	}
+
+	return api.GetBacktestStrategyParametersWorkflowResults{
+		StrategyParameters: bt.StrategyParameters,
+	}, nil
+}
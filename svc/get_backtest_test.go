@@ -0,0 +1,47 @@
+//go:build unit
+// +build unit
+
+package svc
+
+import (
+	"testing"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/backtests/svc/db"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+)
+
+func TestGetBacktestSuite(t *testing.T) {
+	suite.Run(t, new(GetBacktestSuite))
+}
+
+type GetBacktestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+}
+
+func (suite *GetBacktestSuite) TestWebhookSecret() {
+	store := storedBacktest{bt: backtest.Backtest{
+		ID:       uuid.New(),
+		Webhooks: []backtest.Webhook{{URL: "https://example.com/hook", Secret: "secret"}},
+	}}
+	wf := &workflows{db: store}
+
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterActivityWithOptions(store.ReadBacktestActivity,
+		activity.RegisterOptions{Name: db.ReadBacktestActivityName})
+	env.RegisterWorkflow(wf.GetBacktestWorkflow)
+
+	env.ExecuteWorkflow(wf.GetBacktestWorkflow, api.GetBacktestWorkflowParams{BacktestID: store.bt.ID})
+	suite.Require().True(env.IsWorkflowCompleted())
+	suite.Require().NoError(env.GetWorkflowError())
+
+	// The webhook is returned without its secret
+	var res api.GetBacktestWorkflowResults
+	suite.Require().NoError(env.GetWorkflowResult(&res))
+	suite.Require().Equal([]backtest.Webhook{{URL: "https://example.com/hook"}}, res.Backtest.Webhooks)
+}
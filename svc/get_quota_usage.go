@@ -0,0 +1,30 @@
+package svc
+
+import (
+	"fmt"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/tenant"
+	"github.com/cryptellation/backtests/svc/quotas"
+	"go.temporal.io/sdk/workflow"
+)
+
+func (wf *workflows) GetQuotaUsageWorkflow(
+	ctx workflow.Context,
+	_ api.GetQuotaUsageWorkflowParams,
+) (api.GetQuotaUsageWorkflowResults, error) {
+	// Query the quota of the tenant
+	var res quotas.GetQuotaUsageActivityResults
+	err := workflow.ExecuteActivity(
+		workflow.WithActivityOptions(ctx, quotas.DefaultActivityOptions()),
+		quotas.GetQuotaUsageActivityName, quotas.GetQuotaUsageActivityParams{
+			Tenant: tenant.FromWorkflow(ctx),
+		}).Get(ctx, &res)
+	if err != nil {
+		return api.GetQuotaUsageWorkflowResults{}, fmt.Errorf("get quota usage: %w", err)
+	}
+
+	return api.GetQuotaUsageWorkflowResults{
+		Usage: res.Usage,
+	}, nil
+}
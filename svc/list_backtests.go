@@ -4,24 +4,44 @@ import (
 	"fmt"
 
 	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/tenant"
 	"github.com/cryptellation/backtests/svc/db"
 	"go.temporal.io/sdk/workflow"
 )
 
 func (wf *workflows) ListBacktestsWorkflow(
 	ctx workflow.Context,
-	_ api.ListBacktestsWorkflowParams,
+	params api.ListBacktestsWorkflowParams,
 ) (api.ListBacktestsWorkflowResults, error) {
+	// Check parameters
+	if err := params.Filters.Validate(); err != nil {
+		return api.ListBacktestsWorkflowResults{}, fmt.Errorf("validating filters: %w", err)
+	}
+
 	// Execute activity for listing backtests
 	var dbRes db.ListBacktestsActivityResults
 	err := workflow.ExecuteActivity(
 		workflow.WithActivityOptions(ctx, db.DefaultActivityOptions()),
-		wf.db.ListBacktestsActivity, db.ListBacktestsActivityParams{}).Get(ctx, &dbRes)
+		wf.db.ListBacktestsActivity, db.ListBacktestsActivityParams{
+			Filters:     params.Filters,
+			Sort:        params.Sort,
+			Cursor:      params.Cursor,
+			Limit:       params.Limit,
+			SummaryOnly: params.SummaryOnly,
+			Tenant:      tenant.FromWorkflow(ctx),
+		}).Get(ctx, &dbRes)
 	if err != nil {
-		return api.ListBacktestsWorkflowResults{}, fmt.Errorf("adding backtest to db: %w", err)
+		return api.ListBacktestsWorkflowResults{}, fmt.Errorf("listing backtests from db: %w", err)
+	}
+
+	// Remove the secrets before sending the backtests
+	for i, bt := range dbRes.Backtests {
+		dbRes.Backtests[i] = bt.WithoutSecrets()
 	}
 
 	return api.ListBacktestsWorkflowResults{
-		Backtests: dbRes.Backtests,
+		Backtests:  dbRes.Backtests,
+		Summaries:  dbRes.Summaries,
+		NextCursor: dbRes.NextCursor,
 	}, nil
 }
@@ -0,0 +1,80 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/montecarlo"
+	"go.temporal.io/sdk/workflow"
+)
+
+// MonteCarloWorkflow resamples the trades of a backtest to analyze the
+// robustness of its results.
+func (wf *workflows) MonteCarloWorkflow(
+	ctx workflow.Context,
+	params api.MonteCarloWorkflowParams,
+) (api.MonteCarloWorkflowResults, error) {
+	if err := params.Parameters.EmptyFieldsToDefault().Validate(); err != nil {
+		return api.MonteCarloWorkflowResults{}, err
+	}
+
+	// Read backtest
+	bt, err := wf.readBacktestFromDB(ctx, params.BacktestID)
+	if err != nil {
+		return api.MonteCarloWorkflowResults{}, fmt.Errorf("read backtest from db: %w", err)
+	}
+
+	// Get the trades from the backtest, with the open positions closed at the end
+	endPrices, err := wf.getEndPrices(ctx, bt, params.QuoteAsset)
+	if err != nil {
+		return api.MonteCarloWorkflowResults{}, fmt.Errorf("getting end prices: %w", err)
+	}
+
+	curve, err := bt.EquityCurve(params.QuoteAsset, endPrices)
+	if err != nil {
+		return api.MonteCarloWorkflowResults{}, fmt.Errorf("computing equity curve: %w", err)
+	}
+
+	// Run the simulation on the worker
+	var res montecarloLocalActivityResults
+	err = workflow.ExecuteLocalActivity(
+		workflow.WithLocalActivityOptions(ctx, workflow.LocalActivityOptions{
+			StartToCloseTimeout: time.Minute,
+		}),
+		montecarloLocalActivity, montecarloLocalActivityParams{
+			InitialEquity: curve[0].Equity,
+			Returns:       montecarlo.TradeReturns(curve),
+			Parameters:    params.Parameters,
+		}).Get(ctx, &res)
+	if err != nil {
+		return api.MonteCarloWorkflowResults{}, fmt.Errorf("simulating equity paths: %w", err)
+	}
+
+	return api.MonteCarloWorkflowResults{
+		Report: res.Report,
+	}, nil
+}
+
+type (
+	montecarloLocalActivityParams struct {
+		InitialEquity float64
+		Returns       []float64
+		Parameters    montecarlo.Parameters
+	}
+
+	montecarloLocalActivityResults struct {
+		Report montecarlo.Report
+	}
+)
+
+func montecarloLocalActivity(
+	_ context.Context,
+	params montecarloLocalActivityParams,
+) (montecarloLocalActivityResults, error) {
+	report, err := montecarlo.Simulate(params.InitialEquity, params.Returns, params.Parameters)
+	return montecarloLocalActivityResults{
+		Report: report,
+	}, err
+}
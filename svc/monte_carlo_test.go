@@ -0,0 +1,74 @@
+//go:build unit
+// +build unit
+
+package svc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/backtests/svc/db"
+	"github.com/cryptellation/runtime/account"
+	"github.com/cryptellation/runtime/order"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+)
+
+func TestMonteCarloSuite(t *testing.T) {
+	suite.Run(t, new(MonteCarloSuite))
+}
+
+type MonteCarloSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+}
+
+// storedBacktest is an in-memory database of a single backtest.
+type storedBacktest struct {
+	db.DB
+	bt backtest.Backtest
+}
+
+func (d storedBacktest) ReadBacktestActivity(
+	_ context.Context,
+	_ db.ReadBacktestActivityParams,
+) (db.ReadBacktestActivityResults, error) {
+	return db.ReadBacktestActivityResults{Backtest: d.bt}, nil
+}
+
+func (suite *MonteCarloSuite) TestOpenPosition() {
+	// Buy and hold: bought at 5, the close at the end is 10
+	store := storedBacktest{bt: backtest.Backtest{
+		StartTime:          time.Unix(0, 0).UTC(),
+		CurrentCandlestick: backtest.CurrentCandlestick{Time: time.Unix(600, 0).UTC()},
+		Accounts: map[string]account.Account{
+			"exchange": {Balances: map[string]float64{"USDT": 500, "BTC": 100}},
+		},
+		Orders: []order.Order{
+			{Exchange: "exchange", Pair: "BTC-USDT", Side: order.SideIsBuy, Quantity: 100, Price: 5},
+		},
+	}}
+	wf := &workflows{db: store, cryptellation: fixedCandlesticks{}}
+
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterActivityWithOptions(store.ReadBacktestActivity,
+		activity.RegisterOptions{Name: db.ReadBacktestActivityName})
+	env.RegisterWorkflow(wf.MonteCarloWorkflow)
+
+	env.ExecuteWorkflow(wf.MonteCarloWorkflow, api.MonteCarloWorkflowParams{
+		BacktestID: store.bt.ID,
+		QuoteAsset: "USDT",
+	})
+	suite.Require().True(env.IsWorkflowCompleted())
+	suite.Require().NoError(env.GetWorkflowError())
+
+	// The position is resampled as a trade closed at the end price
+	var res api.MonteCarloWorkflowResults
+	suite.Require().NoError(env.GetWorkflowResult(&res))
+	suite.Require().Equal(1, res.Report.TradesCount)
+	suite.Require().InDelta(1500, res.Report.FinalEquity.P50, 1e-9)
+}
@@ -0,0 +1,65 @@
+package svc
+
+import (
+	"fmt"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/tenant"
+	"github.com/cryptellation/backtests/svc/db"
+	"go.temporal.io/sdk/workflow"
+)
+
+// purgeBatchesPerRun is the count of batches deleted by a run of the
+// PurgeBacktestsWorkflow workflow before it continues as new, to keep its
+// history short.
+const purgeBatchesPerRun = 50
+
+// PurgeBacktestsWorkflow deletes the backtests matching the filters, in batches.
+// Running backtests are never deleted.
+func (wf *workflows) PurgeBacktestsWorkflow(
+	ctx workflow.Context,
+	params api.PurgeBacktestsWorkflowParams,
+) (api.PurgeBacktestsWorkflowResults, error) {
+	if err := params.Filters.Validate(); err != nil {
+		return api.PurgeBacktestsWorkflowResults{}, err
+	}
+
+	if params.BatchSize <= 0 {
+		params.BatchSize = api.DefaultPurgeBatchSize
+	}
+
+	// Set the creation time limit once, so it doesn't move between batches
+	if params.CreatedBefore == nil && params.Filters.OlderThan > 0 {
+		createdBefore := workflow.Now(ctx).Add(-params.Filters.OlderThan)
+		params.CreatedBefore = &createdBefore
+	}
+	dbParams := db.PurgeBacktestsActivityParams{
+		CreatedBefore: params.CreatedBefore,
+		Statuses:      params.Filters.Statuses,
+		Tags:          params.Filters.Tags,
+		Limit:         params.BatchSize,
+		Tenant:        tenant.FromWorkflow(ctx),
+	}
+
+	// Delete batches until the last one is not full
+	res := api.PurgeBacktestsWorkflowResults{Deleted: params.Deleted}
+	for range purgeBatchesPerRun {
+		var dbRes db.PurgeBacktestsActivityResults
+		err := workflow.ExecuteActivity(
+			workflow.WithActivityOptions(ctx, db.DefaultActivityOptions()),
+			wf.db.PurgeBacktestsActivity, dbParams).Get(ctx, &dbRes)
+		if err != nil {
+			return res, fmt.Errorf("purging backtests from db: %w", err)
+		}
+
+		res.Deleted += dbRes.Deleted
+		if dbRes.Deleted < params.BatchSize {
+			return res, nil
+		}
+	}
+
+	// Continue with the next batches in a new run, of the same tenant
+	params.Deleted = res.Deleted
+	params.Tenant = dbParams.Tenant
+	return res, workflow.NewContinueAsNewError(ctx, api.PurgeBacktestsWorkflowName, params)
+}
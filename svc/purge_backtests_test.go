@@ -0,0 +1,101 @@
+//go:build unit
+// +build unit
+
+package svc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/backtests/svc/db"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+func TestPurgeBacktestsSuite(t *testing.T) {
+	suite.Run(t, new(PurgeBacktestsSuite))
+}
+
+type PurgeBacktestsSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+}
+
+// purgedBacktests is a database with a count of backtests to purge.
+type purgedBacktests struct {
+	db.DB
+	remaining int
+	params    []db.PurgeBacktestsActivityParams
+}
+
+func (d *purgedBacktests) PurgeBacktestsActivity(
+	_ context.Context,
+	params db.PurgeBacktestsActivityParams,
+) (db.PurgeBacktestsActivityResults, error) {
+	d.params = append(d.params, params)
+	deleted := min(params.Limit, d.remaining)
+	d.remaining -= deleted
+	return db.PurgeBacktestsActivityResults{Deleted: deleted}, nil
+}
+
+func (suite *PurgeBacktestsSuite) purge(
+	store *purgedBacktests,
+	params api.PurgeBacktestsWorkflowParams,
+) (api.PurgeBacktestsWorkflowResults, error) {
+	wf := &workflows{db: store}
+
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterActivityWithOptions(store.PurgeBacktestsActivity,
+		activity.RegisterOptions{Name: db.PurgeBacktestsActivityName})
+	env.RegisterWorkflowWithOptions(wf.PurgeBacktestsWorkflow,
+		workflow.RegisterOptions{Name: api.PurgeBacktestsWorkflowName})
+
+	env.ExecuteWorkflow(api.PurgeBacktestsWorkflowName, params)
+	suite.Require().True(env.IsWorkflowCompleted())
+
+	var res api.PurgeBacktestsWorkflowResults
+	if err := env.GetWorkflowError(); err != nil {
+		return res, err
+	}
+	suite.Require().NoError(env.GetWorkflowResult(&res))
+	return res, nil
+}
+
+func (suite *PurgeBacktestsSuite) TestContinueAsNew() {
+	store := &purgedBacktests{remaining: 2*purgeBatchesPerRun + 1}
+	params := api.PurgeBacktestsWorkflowParams{
+		Filters:   backtest.PurgeFilters{OlderThan: time.Hour},
+		BatchSize: 1,
+	}
+
+	// Each run continues as new with the count deleted so far, until the end
+	for run := 1; ; run++ {
+		res, err := suite.purge(store, params)
+		var continueErr *workflow.ContinueAsNewError
+		if !errors.As(err, &continueErr) {
+			suite.Require().NoError(err)
+			suite.Require().Equal(3, run)
+			suite.Require().Equal(2*purgeBatchesPerRun+1, res.Deleted)
+			break
+		}
+
+		var next api.PurgeBacktestsWorkflowParams
+		suite.Require().NoError(converter.GetDefaultDataConverter().FromPayloads(continueErr.Input, &next))
+		suite.Require().Equal(run*purgeBatchesPerRun, next.Deleted)
+		suite.Require().NotNil(next.CreatedBefore)
+		params = next
+	}
+
+	// The creation time limit is the same in all the batches, the last one being empty
+	suite.Require().Len(store.params, 2*purgeBatchesPerRun+2)
+	for _, p := range store.params {
+		suite.Require().True(store.params[0].CreatedBefore.Equal(*p.CreatedBefore))
+	}
+}
@@ -0,0 +1,208 @@
+package quotas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/quota"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+)
+
+// RequestQuotaActivityName is the name of the activity to request a slot of
+// the quota of a tenant for a backtest run.
+const RequestQuotaActivityName = "RequestQuotaActivity"
+
+type (
+	// RequestQuotaActivityParams is the parameters of the RequestQuotaActivity activity.
+	RequestQuotaActivityParams struct {
+		Tenant string
+		Run    quota.Run
+	}
+
+	// RequestQuotaActivityResults is the results of the RequestQuotaActivity activity.
+	RequestQuotaActivityResults struct {
+		// MaxWaitTime is the maximum time the run waits for a slot (0 is no limit).
+		MaxWaitTime time.Duration
+	}
+)
+
+// GetQuotaUsageActivityName is the name of the activity to get the current usage
+// of the quota of a tenant.
+const GetQuotaUsageActivityName = "GetQuotaUsageActivity"
+
+type (
+	// GetQuotaUsageActivityParams is the parameters of the GetQuotaUsageActivity activity.
+	GetQuotaUsageActivityParams struct {
+		Tenant string
+	}
+
+	// GetQuotaUsageActivityResults is the results of the GetQuotaUsageActivity activity.
+	GetQuotaUsageActivityResults struct {
+		Usage quota.Usage
+	}
+)
+
+// CheckQuotaRunsActivityName is the name of the activity to check which runs
+// of a tenant quota have ended.
+const CheckQuotaRunsActivityName = "CheckQuotaRunsActivity"
+
+type (
+	// CheckQuotaRunsActivityParams is the parameters of the CheckQuotaRunsActivity activity.
+	CheckQuotaRunsActivityParams struct {
+		WorkflowIDs []string
+	}
+
+	// CheckQuotaRunsActivityResults is the results of the CheckQuotaRunsActivity activity.
+	CheckQuotaRunsActivityResults struct {
+		// Ended are the IDs of the workflows that are not running anymore.
+		Ended []string
+	}
+)
+
+// DefaultActivityOptions returns the default quota activities options, with
+// retries on an exponential backoff.
+func DefaultActivityOptions() workflow.ActivityOptions {
+	return workflow.ActivityOptions{
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:        time.Second,
+			BackoffCoefficient:     2,
+			MaximumInterval:        time.Minute,
+			NonRetryableErrorTypes: api.ErrorTypeNames(api.ErrorTypes),
+		},
+		StartToCloseTimeout:    10 * time.Second,
+		ScheduleToCloseTimeout: 5 * time.Minute,
+	}
+}
+
+// Activities are the activities requesting the quotas of the tenants.
+type Activities struct {
+	temporal client.Client
+	limits   quota.Limits
+}
+
+// New creates new quota activities, sending the requests with the temporal
+// client and the limits applied to every tenant.
+func New(temporal client.Client, limits quota.Limits) (*Activities, error) {
+	if err := limits.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &Activities{
+		temporal: temporal,
+		limits:   limits,
+	}, nil
+}
+
+// Register registers the activities to the worker.
+func (a *Activities) Register(w worker.Worker) {
+	w.RegisterActivityWithOptions(
+		a.RequestQuotaActivity,
+		activity.RegisterOptions{Name: RequestQuotaActivityName},
+	)
+	w.RegisterActivityWithOptions(
+		a.GetQuotaUsageActivity,
+		activity.RegisterOptions{Name: GetQuotaUsageActivityName},
+	)
+	w.RegisterActivityWithOptions(
+		a.CheckQuotaRunsActivity,
+		activity.RegisterOptions{Name: CheckQuotaRunsActivityName},
+	)
+}
+
+// RequestQuotaActivity sends the request of a slot to the quota workflow of the
+// tenant, starting it if needed. The run is then notified by the quota
+// workflow once it can start.
+func (a *Activities) RequestQuotaActivity(
+	ctx context.Context,
+	params RequestQuotaActivityParams,
+) (RequestQuotaActivityResults, error) {
+	// Refuse the runs that could never start
+	if err := a.limits.Allows(params.Run.Steps); err != nil {
+		return RequestQuotaActivityResults{}, api.NewApplicationError(err, api.ErrorTypes)
+	}
+
+	// Send the request
+	_, err := a.temporal.SignalWithStartWorkflow(ctx,
+		api.TenantQuotaWorkflowID(params.Tenant), api.QuotaRequestSignalName,
+		api.QuotaRequestSignalParams{
+			Run:    params.Run,
+			Limits: a.limits,
+		},
+		client.StartWorkflowOptions{
+			ID:        api.TenantQuotaWorkflowID(params.Tenant),
+			TaskQueue: api.WorkerTaskQueueName,
+		},
+		api.TenantQuotaWorkflowName, api.TenantQuotaWorkflowParams{
+			Tenant: params.Tenant,
+		})
+	if err != nil {
+		return RequestQuotaActivityResults{}, fmt.Errorf("requesting quota of tenant %q: %w", params.Tenant, err)
+	}
+
+	return RequestQuotaActivityResults{
+		MaxWaitTime: a.limits.MaxWaitTime,
+	}, nil
+}
+
+// GetQuotaUsageActivity queries the usage of the quota workflow of the tenant.
+// The quota is unused if this workflow is not running.
+func (a *Activities) GetQuotaUsageActivity(
+	ctx context.Context,
+	params GetQuotaUsageActivityParams,
+) (GetQuotaUsageActivityResults, error) {
+	// Query the quota workflow
+	value, err := a.temporal.QueryWorkflow(ctx,
+		api.TenantQuotaWorkflowID(params.Tenant), "",
+		api.QuotaUsageQueryName, api.QuotaUsageQueryParams{
+			Tenant: params.Tenant,
+		})
+	var notFound *serviceerror.NotFound
+	if errors.As(err, &notFound) {
+		return GetQuotaUsageActivityResults{
+			Usage: quota.State{Limits: a.limits}.Usage(params.Tenant),
+		}, nil
+	} else if err != nil {
+		return GetQuotaUsageActivityResults{}, fmt.Errorf("querying quota of tenant %q: %w", params.Tenant, err)
+	}
+
+	// Get result and return
+	var res api.QuotaUsageQueryResults
+	if err := value.Get(&res); err != nil {
+		return GetQuotaUsageActivityResults{}, err
+	}
+
+	return GetQuotaUsageActivityResults{Usage: res.Usage}, nil
+}
+
+// CheckQuotaRunsActivity returns the workflows of the runs that are not running
+// anymore. The latest run of each workflow is checked, so that a reset
+// workflow keeps its slot.
+func (a *Activities) CheckQuotaRunsActivity(
+	ctx context.Context,
+	params CheckQuotaRunsActivityParams,
+) (CheckQuotaRunsActivityResults, error) {
+	var res CheckQuotaRunsActivityResults
+	for _, id := range params.WorkflowIDs {
+		desc, err := a.temporal.DescribeWorkflowExecution(ctx, id, "")
+		var notFound *serviceerror.NotFound
+		switch {
+		case errors.As(err, &notFound):
+			res.Ended = append(res.Ended, id)
+		case err != nil:
+			return CheckQuotaRunsActivityResults{}, fmt.Errorf("describing workflow %q: %w", id, err)
+		case desc.GetWorkflowExecutionInfo().GetStatus() != enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING:
+			res.Ended = append(res.Ended, id)
+		}
+	}
+
+	return res, nil
+}
@@ -6,11 +6,13 @@ import (
 
 	"github.com/cryptellation/backtests/api"
 	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/backtests/pkg/tenant"
 	"github.com/cryptellation/backtests/svc/db"
 	candlesticksapi "github.com/cryptellation/candlesticks/api"
 	"github.com/cryptellation/runtime"
 	"github.com/cryptellation/ticks/pkg/tick"
 	"github.com/google/uuid"
+	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 )
 
@@ -18,78 +20,267 @@ func (wf *workflows) RunBacktestWorkflow(
 	ctx workflow.Context,
 	params api.RunBacktestWorkflowParams,
 ) (api.RunBacktestWorkflowResults, error) {
+	// Let the clients follow the events of the run
+	events, err := setUpBacktestEvents(ctx)
+	if err != nil {
+		return api.RunBacktestWorkflowResults{}, err
+	}
+
+	// Wait for a slot of the tenant quota
+	release, err := wf.acquireQuota(ctx, params.BacktestID)
+	if err != nil {
+		return api.RunBacktestWorkflowResults{}, err
+	}
+	defer release()
+
+	// Set the backtest as running
+	bt, err := wf.setBacktestStatus(ctx, params.BacktestID, backtest.StatusRunning)
+	if err != nil {
+		return api.RunBacktestWorkflowResults{}, err
+	}
+	events.Append(statusChangedEvent(bt, nil))
+
+	// Run the backtest and end the run, even if the workflow is canceled
+	aborted, runErr := wf.runBacktest(ctx, params, events)
+	disconnectedCtx, _ := workflow.NewDisconnectedContext(ctx)
+	if err := wf.endBacktestRun(disconnectedCtx, params.BacktestID, events, aborted, runErr); err != nil {
+		if runErr != nil {
+			workflow.GetLogger(ctx).Error("Cannot set failed backtest status",
+				"backtest_id", params.BacktestID.String(),
+				"error", err)
+			return api.RunBacktestWorkflowResults{}, runErr
+		}
+		return api.RunBacktestWorkflowResults{}, err
+	}
+
+	return api.RunBacktestWorkflowResults{}, runErr
+}
+
+// endBacktestRun sets the final status of the backtest, notifies its webhooks
+// and lets the clients get the last events of the run.
+func (wf *workflows) endBacktestRun(
+	ctx workflow.Context,
+	backtestID uuid.UUID,
+	events *backtest.EventLog,
+	aborted bool,
+	runErr error,
+) error {
+	logger := workflow.GetLogger(ctx)
+
+	// Set the final status
+	status := backtest.StatusFinished
+	if runErr != nil {
+		status = backtest.StatusFailed
+	} else if aborted {
+		status = backtest.StatusAborted
+	}
+	bt, err := wf.setBacktestStatus(ctx, backtestID, status)
+	if err == nil {
+		events.Append(statusChangedEvent(bt, runErr))
+		notifyWebhooks(ctx, bt, aborted, runErr)
+	}
+
+	// Let the clients get the last events before ending the run
+	if closeErr := closeBacktestEvents(ctx, events); closeErr != nil {
+		logger.Warn("Cannot wait for the clients to get the last events",
+			"backtest_id", backtestID.String(),
+			"error", closeErr)
+	}
+
+	return err
+}
+
+// runBacktest runs the backtest and returns true if it has been aborted by a
+// callback failure.
+func (wf *workflows) runBacktest(
+	ctx workflow.Context,
+	params api.RunBacktestWorkflowParams,
+	events *backtest.EventLog,
+) (bool, error) {
 	// Load backtest from database to get callbacks
 	bt, err := wf.readBacktestFromDB(ctx, params.BacktestID)
 	if err != nil {
-		return api.RunBacktestWorkflowResults{}, fmt.Errorf("loading backtest from database: %w", err)
+		return false, fmt.Errorf("loading backtest from database: %w", err)
 	}
 
 	// Init the backtest from client side
-	bt, err = wf.execOnInitBacktestCallback(ctx, bt.Callbacks.OnInitCallback, params.BacktestID)
+	aborted, policies := false, bt.CallbacksPolicies
+	bt, err = wf.execOnInitBacktestCallback(ctx, bt.Callbacks.OnInitCallback, policies.OnInit, bt.ID)
 	if err != nil {
-		return api.RunBacktestWorkflowResults{}, fmt.Errorf("initializing backtest from client side: %w", err)
+		aborted, err = wf.handleCallbackFailure(ctx, params.BacktestID, policies.OnInit, err,
+			func(f *backtest.CallbacksFailures) { f.OnInit++ })
+		if err != nil {
+			return false, fmt.Errorf("initializing backtest from client side: %w", err)
+		}
+
+		// Reload backtest in case of modifications
+		bt, err = wf.readBacktestFromDB(ctx, params.BacktestID)
+		if err != nil {
+			return false, fmt.Errorf("reload backtest from db: %w", err)
+		}
 	}
 
 	// Loop on backtest events
-	if err := wf.loopThroughBacktestEvents(ctx, bt, bt.Callbacks); err != nil {
-		return api.RunBacktestWorkflowResults{}, fmt.Errorf("looping through backtest events: %w", err)
+	if !aborted {
+		aborted, err = wf.loopThroughBacktestEvents(ctx, bt, bt.Callbacks, events)
+		if err != nil {
+			return false, fmt.Errorf("looping through backtest events: %w", err)
+		}
 	}
 
 	// Exit the backtest from client side
-	if err := wf.execOnExitBacktestCallback(ctx, bt.Callbacks.OnExitCallback, bt.ID); err != nil {
-		return api.RunBacktestWorkflowResults{}, fmt.Errorf("exit backtest from client side: %w", err)
+	err = wf.execOnExitBacktestCallback(ctx, bt.Callbacks.OnExitCallback, policies.OnExit, bt.ID)
+	if err != nil {
+		_, err = wf.handleCallbackFailure(ctx, params.BacktestID, policies.OnExit, err,
+			func(f *backtest.CallbacksFailures) { f.OnExit++ })
+		if err != nil {
+			return aborted, fmt.Errorf("exit backtest from client side: %w", err)
+		}
 	}
 
-	return api.RunBacktestWorkflowResults{}, nil
+	return aborted, nil
 }
 
+// loopThroughBacktestEvents runs the backtest steps until the end and returns
+// true if it has been aborted by a callback failure.
 func (wf *workflows) loopThroughBacktestEvents(
 	ctx workflow.Context,
 	bt backtest.Backtest,
 	callbacks runtime.Callbacks,
-) error {
+	events *backtest.EventLog,
+) (bool, error) {
 	logger := workflow.GetLogger(ctx)
 
+	lastKnown := make(map[tick.Subscription]tick.Tick)
 	for finished := false; !finished; {
 		logger.Debug("Looping over prices",
 			"backtest_id", bt.ID.String(),
 			"current_time", bt.CurrentTime())
 
-		// Get prices
-		prices, err := wf.readActualPrices(ctx, bt)
+		// Get prices for this step
+		step, wake, err := wf.prepareStep(ctx, &bt, lastKnown)
 		if err != nil {
-			return fmt.Errorf("cannot read actual prices: %w", err)
-		}
-		if len(prices) == 0 {
-			logger.Warn("No price detected",
+			return false, err
+		} else if step.Stop || len(step.Ticks) == 0 {
+			logger.Warn("No more price to process",
+				"backtest_id", bt.ID.String(),
 				"time", bt.CurrentCandlestick.Time)
-			bt.SetCurrentTime(bt.EndTime)
 			break
-		} else if !prices[0].Time.Equal(bt.CurrentCandlestick.Time) {
-			logger.Warn("No price between current time and first event retrieved",
-				"current_time", bt.CurrentCandlestick.Time,
-				"first_event_time", prices[0].Time)
-			bt.SetCurrentTime(prices[0].Time)
 		}
 
-		// Execute backtest with these prices
-		if err := execOnPriceBacktest(ctx, callbacks.OnNewPricesCallback, prices, bt.ID); err != nil {
-			return fmt.Errorf("cannot execute backtest: %w", err)
+		// Execute backtest with these prices, unless the strategy is waiting
+		// for a wake up condition
+		if wake {
+			policy := bt.CallbacksPolicies.OnNewPrices
+			if err := execOnPriceBacktest(ctx, callbacks.OnNewPricesCallback, policy, step.Ticks, bt.ID); err != nil {
+				aborted, err := wf.handleCallbackFailure(ctx, bt.ID, policy, err,
+					func(f *backtest.CallbacksFailures) { f.OnNewPrices++ })
+				if err != nil {
+					return false, fmt.Errorf("cannot execute backtest: %w", err)
+				} else if aborted {
+					return true, nil
+				}
+			}
+		}
+		for _, t := range step.Ticks {
+			lastKnown[tick.Subscription{Exchange: t.Exchange, Pair: t.Pair}] = t
 		}
 
 		// Advance backtest
 		finished, bt, err = wf.advanceBacktest(ctx, bt.ID)
 		if err != nil {
-			return fmt.Errorf("cannot advance backtest: %w", err)
+			return false, fmt.Errorf("cannot advance backtest: %w", err)
 		}
+		events.Append(backtest.Event{
+			Type:       backtest.EventTypeStepAdvanced,
+			BacktestID: bt.ID,
+			Time:       bt.CurrentCandlestick.Time,
+		})
 	}
 
-	return nil
+	return false, nil
+}
+
+// prepareStep reads the prices of the backtest current step, applies the gap
+// policy on them and checks the strategy wake up conditions. It returns the step
+// and true if the strategy should be called for it. If the backtest has been
+// updated concurrently, it is read again and the step prepared from it.
+func (wf *workflows) prepareStep(
+	ctx workflow.Context,
+	bt *backtest.Backtest,
+	lastKnown map[tick.Subscription]tick.Tick,
+) (backtest.Step, bool, error) {
+	for attempt := 1; ; attempt++ {
+		step, wake, err := wf.resolveStep(ctx, bt, lastKnown)
+		if !db.IsVersionConflict(err) || attempt >= maxBacktestUpdateAttempts {
+			return step, wake, err
+		}
+
+		if err := waitBeforeUpdateRetry(ctx, attempt); err != nil {
+			return backtest.Step{}, false, err
+		}
+		if *bt, err = wf.readBacktestFromDB(ctx, bt.ID); err != nil {
+			return backtest.Step{}, false, fmt.Errorf("reload backtest from db: %w", err)
+		}
+	}
+}
+
+// resolveStep prepares the step from the backtest, that is saved if modified.
+func (wf *workflows) resolveStep(
+	ctx workflow.Context,
+	bt *backtest.Backtest,
+	lastKnown map[tick.Subscription]tick.Tick,
+) (backtest.Step, bool, error) {
+	logger := workflow.GetLogger(ctx)
+
+	// Get prices
+	prices, err := wf.readActualPrices(ctx, *bt)
+	if err != nil {
+		return backtest.Step{}, false, fmt.Errorf("cannot read actual prices: %w", err)
+	}
+
+	// Apply the gap policy on prices
+	previousTime := bt.CurrentCandlestick.Time
+	step, stepErr := bt.ResolveStep(prices, lastKnown)
+	dirty := len(step.Gaps) > 0 || !bt.CurrentCandlestick.Time.Equal(previousTime)
+	if len(step.Gaps) > 0 {
+		logger.Warn("Gaps detected in prices",
+			"backtest_id", bt.ID.String(),
+			"gap_policy", bt.GapPolicy.String(),
+			"gaps", step.Gaps)
+	}
+
+	// Check the wake up conditions of the strategy
+	wake := true
+	if bt.WakeUp != nil && !step.Stop {
+		wake = bt.WakeUp.IsReached(bt.CurrentCandlestick.Time, step.Ticks, lastKnown)
+		if wake {
+			logger.Debug("Waking up strategy",
+				"backtest_id", bt.ID.String(),
+				"wake_up", *bt.WakeUp)
+			bt.WakeUp = nil
+			dirty = true
+		}
+	}
+
+	// Save the backtest if it has been modified
+	if dirty {
+		if err := wf.updateBacktestInDB(ctx, bt); err != nil {
+			return backtest.Step{}, false, fmt.Errorf("save backtest to db: %w", err)
+		}
+	}
+
+	if stepErr != nil {
+		return backtest.Step{}, false, fmt.Errorf("cannot resolve prices: %w", stepErr)
+	}
+
+	return step, wake, nil
 }
 
 func (wf *workflows) execOnInitBacktestCallback(
 	ctx workflow.Context,
 	onInitCallback runtime.CallbackWorkflow,
+	policy backtest.CallbackPolicy,
 	backtestID uuid.UUID,
 ) (backtest.Backtest, error) {
 	// Load backtest
@@ -99,15 +290,13 @@ func (wf *workflows) execOnInitBacktestCallback(
 	}
 
 	// Options
-	opts := workflow.ChildWorkflowOptions{
-		WorkflowID:               fmt.Sprintf("backtest-%s-on-init", backtestID.String()),
-		TaskQueue:                onInitCallback.TaskQueueName, // Execute in the client queue
-		WorkflowExecutionTimeout: time.Second * 30,             // Timeout if the child workflow does not complete
-	}
-
-	// Check if the timeout is set
-	if onInitCallback.ExecutionTimeout > 0 {
-		opts.WorkflowExecutionTimeout = onInitCallback.ExecutionTimeout
+	opts := callbackChildWorkflowOptions(
+		fmt.Sprintf("backtest-%s-on-init", backtestID.String()),
+		onInitCallback, policy)
+	if len(bt.StrategyParameters) > 0 {
+		opts.Memo = map[string]any{
+			api.StrategyParametersMemoKey: bt.StrategyParameters,
+		}
 	}
 
 	// Run a new child workflow
@@ -116,7 +305,7 @@ func (wf *workflows) execOnInitBacktestCallback(
 		Context: runtime.Context{
 			ID:              backtestID,
 			Mode:            runtime.ModeBacktest,
-			Now:             bt.StartTime,
+			Now:             bt.CurrentCandlestick.Time,
 			ParentTaskQueue: workflow.GetInfo(ctx).TaskQueueName,
 		},
 	}).Get(ctx, nil); err != nil {
@@ -135,30 +324,38 @@ func (wf *workflows) execOnInitBacktestCallback(
 func (wf *workflows) advanceBacktest(ctx workflow.Context, id uuid.UUID) (bool, backtest.Backtest, error) {
 	logger := workflow.GetLogger(ctx)
 
-	// Read backtest
-	bt, err := wf.readBacktestFromDB(ctx, id)
+	// Advance backtest, again if it has been updated concurrently (i.e. an order)
+	var previousTime time.Time
+	var finished bool
+	bt, err := wf.updateBacktest(ctx, id, func(bt *backtest.Backtest) error {
+		previousTime = bt.CurrentCandlestick.Time
+
+		var err error
+		if finished, err = bt.Advance(); err != nil {
+			return fmt.Errorf("cannot advance backtest: %w", err)
+		}
+		logger.Info("Advancing backtest",
+			"id", bt.ID.String(),
+			"current_time", bt.CurrentTime())
+
+		return nil
+	})
 	if err != nil {
-		return false, backtest.Backtest{}, fmt.Errorf("load backtest from db: %w", err)
+		return false, backtest.Backtest{}, err
 	}
 
-	// Advance backtest
-	finished, err := bt.Advance()
-	if err != nil {
-		return false, backtest.Backtest{}, fmt.Errorf("cannot advance backtest: %w", err)
-	}
-	logger.Info("Advancing backtest",
-		"id", bt.ID.String(),
-		"current_time", bt.CurrentTime())
-
-	// Save backtest
-	var writeRes db.UpdateBacktestActivityResults
-	err = workflow.ExecuteActivity(
-		workflow.WithActivityOptions(ctx, db.DefaultActivityOptions()),
-		wf.db.UpdateBacktestActivity, db.UpdateBacktestActivityParams{
-			Backtest: bt,
-		}).Get(ctx, &writeRes)
-	if err != nil {
-		return false, backtest.Backtest{}, fmt.Errorf("save backtest to db: %w", err)
+	// Keep a snapshot of the backtest periodically
+	if !finished && bt.IsSnapshotDue(previousTime) {
+		var snapshotRes db.CreateBacktestSnapshotActivityResults
+		err = workflow.ExecuteActivity(
+			workflow.WithActivityOptions(ctx, db.DefaultActivityOptions()),
+			wf.db.CreateBacktestSnapshotActivity, db.CreateBacktestSnapshotActivityParams{
+				Snapshot: bt.Snapshot(),
+				Tenant:   tenant.FromWorkflow(ctx),
+			}).Get(ctx, &snapshotRes)
+		if err != nil {
+			return false, backtest.Backtest{}, fmt.Errorf("save backtest snapshot to db: %w", err)
+		}
 	}
 
 	return finished, bt, nil
@@ -199,13 +396,11 @@ func (wf *workflows) readActualPrices(ctx workflow.Context, bt backtest.Backtest
 		cs := result.List[0]
 		t := cs.Time
 
-		// Create tick from candlesticks
+		// Create tick from candlesticks (at most one per subscription)
 		p := tick.FromCandlestick(sub.Exchange, sub.Pair, bt.CurrentCandlestick.Price, t, cs)
 		prices = append(prices, p)
 	}
 
-	// Only keep the earliest same time ticks for time consistency
-	_, prices = tick.OnlyKeepEarliestSameTime(prices, bt.EndTime)
 	logger.Info("Gotten ticks on backtest",
 		"quantity", len(prices),
 		"backtest_id", bt.ID.String())
@@ -215,6 +410,7 @@ func (wf *workflows) readActualPrices(ctx workflow.Context, bt backtest.Backtest
 func execOnPriceBacktest(
 	ctx workflow.Context,
 	callback runtime.CallbackWorkflow,
+	policy backtest.CallbackPolicy,
 	prices []tick.Tick,
 	backtestID uuid.UUID,
 ) error {
@@ -224,17 +420,9 @@ func execOnPriceBacktest(
 		"prices", prices)
 
 	// Options
-	opts := workflow.ChildWorkflowOptions{
-		WorkflowID: fmt.Sprintf("backtest-%s-on-new-prices-%s",
-			backtestID.String(), prices[0].Time.Format(time.RFC3339)),
-		TaskQueue:                callback.TaskQueueName, // Execute in the client queue
-		WorkflowExecutionTimeout: time.Second * 30,       // Timeout if the child workflow does not complete
-	}
-
-	// Check if the timeout is set
-	if callback.ExecutionTimeout > 0 {
-		opts.WorkflowExecutionTimeout = callback.ExecutionTimeout
-	}
+	opts := callbackChildWorkflowOptions(
+		fmt.Sprintf("backtest-%s-on-new-prices-%s", backtestID.String(), prices[0].Time.Format(time.RFC3339)),
+		callback, policy)
 
 	// Execute backtest
 	err := workflow.ExecuteChildWorkflow(
@@ -258,6 +446,7 @@ func execOnPriceBacktest(
 func (wf *workflows) execOnExitBacktestCallback(
 	ctx workflow.Context,
 	onExitCallback runtime.CallbackWorkflow,
+	policy backtest.CallbackPolicy,
 	backtestID uuid.UUID,
 ) error {
 	// Load backtest
@@ -267,16 +456,9 @@ func (wf *workflows) execOnExitBacktestCallback(
 	}
 
 	// Options
-	opts := workflow.ChildWorkflowOptions{
-		WorkflowID:               fmt.Sprintf("backtest-%s-on-exit", backtestID.String()),
-		TaskQueue:                onExitCallback.TaskQueueName, // Execute in the client queue
-		WorkflowExecutionTimeout: time.Second * 30,             // Timeout if the child workflow does not complete
-	}
-
-	// Check if the timeout is set
-	if onExitCallback.ExecutionTimeout > 0 {
-		opts.WorkflowExecutionTimeout = onExitCallback.ExecutionTimeout
-	}
+	opts := callbackChildWorkflowOptions(
+		fmt.Sprintf("backtest-%s-on-exit", backtestID.String()),
+		onExitCallback, policy)
 
 	// Run a new child workflow
 	ctx = workflow.WithChildOptions(ctx, opts)
@@ -295,3 +477,70 @@ func (wf *workflows) execOnExitBacktestCallback(
 
 	return nil
 }
+
+func callbackChildWorkflowOptions(
+	workflowID string,
+	callback runtime.CallbackWorkflow,
+	policy backtest.CallbackPolicy,
+) workflow.ChildWorkflowOptions {
+	opts := workflow.ChildWorkflowOptions{
+		WorkflowID:               workflowID,
+		TaskQueue:                callback.TaskQueueName, // Execute in the client queue
+		WorkflowExecutionTimeout: time.Second * 30,       // Timeout if the child workflow does not complete
+	}
+
+	// Check if the timeout is set
+	if callback.ExecutionTimeout > 0 {
+		opts.WorkflowExecutionTimeout = callback.ExecutionTimeout
+	}
+
+	// Set the retry policy with the timeout applied on each attempt
+	if policy.Retry != nil {
+		opts.WorkflowRunTimeout = opts.WorkflowExecutionTimeout
+		opts.WorkflowExecutionTimeout = 0
+		opts.RetryPolicy = &temporal.RetryPolicy{
+			InitialInterval:    policy.Retry.InitialInterval,
+			BackoffCoefficient: policy.Retry.BackoffCoefficient,
+			MaximumInterval:    policy.Retry.MaximumInterval,
+			MaximumAttempts:    policy.Retry.MaximumAttempts,
+		}
+	}
+
+	return opts
+}
+
+// handleCallbackFailure records the failure of a callback on the backtest and
+// applies the failure action of its policy. It returns true if the backtest
+// should be aborted, or an error if the backtest should fail.
+func (wf *workflows) handleCallbackFailure(
+	ctx workflow.Context,
+	backtestID uuid.UUID,
+	policy backtest.CallbackPolicy,
+	callbackErr error,
+	count func(f *backtest.CallbacksFailures),
+) (bool, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Warn("Callback failed",
+		"backtest_id", backtestID.String(),
+		"on_failure", policy.OnFailure.String(),
+		"error", callbackErr)
+
+	// Record the failure on the backtest
+	_, err := wf.updateBacktest(ctx, backtestID, func(bt *backtest.Backtest) error {
+		count(&bt.CallbacksFailures)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	// Apply the failure action
+	switch policy.OnFailure {
+	case backtest.CallbackFailureActionSkipStep:
+		return false, nil
+	case backtest.CallbackFailureActionAbort:
+		return true, nil
+	default:
+		return false, callbackErr
+	}
+}
@@ -0,0 +1,139 @@
+package svc
+
+import (
+	"fmt"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/backtests/pkg/tenant"
+	"github.com/cryptellation/runtime/order"
+	"go.temporal.io/sdk/workflow"
+)
+
+// setUpBacktestEvents creates the event log of a backtest run, with the update
+// letting the clients wait for its events, the query letting them get the
+// events once the run is over and the signal letting the other workflows add
+// events to it.
+func setUpBacktestEvents(ctx workflow.Context) (*backtest.EventLog, error) {
+	events := backtest.NewEventLog(backtest.DefaultEventLogCapacity)
+	owner := tenant.FromWorkflow(ctx)
+
+	// Let the clients wait for new events
+	err := workflow.SetUpdateHandlerWithOptions(ctx, api.BacktestEventsUpdateName,
+		func(ctx workflow.Context, params api.BacktestEventsUpdateParams) (api.BacktestEventsUpdateResults, error) {
+			_, err := workflow.AwaitWithTimeout(ctx, api.BacktestEventsPollTimeout, func() bool {
+				return events.Closed || events.HasAfter(params.AfterSequence)
+			})
+			if err != nil {
+				return api.BacktestEventsUpdateResults{}, err
+			}
+
+			return api.BacktestEventsUpdateResults{
+				Events:   events.After(params.AfterSequence),
+				Finished: events.Closed,
+			}, nil
+		}, workflow.UpdateHandlerOptions{
+			Validator: func(_ workflow.Context, params api.BacktestEventsUpdateParams) error {
+				if params.AfterSequence < 0 {
+					return fmt.Errorf("invalid events sequence: %d", params.AfterSequence)
+				}
+				return checkEventsTenant(owner, params)
+			},
+		})
+	if err != nil {
+		return nil, fmt.Errorf("setting events update handler: %w", err)
+	}
+
+	// Let the clients get the events once the run is over
+	err = workflow.SetQueryHandler(ctx, api.BacktestEventsQueryName,
+		func(params api.BacktestEventsUpdateParams) (api.BacktestEventsUpdateResults, error) {
+			if err := checkEventsTenant(owner, params); err != nil {
+				return api.BacktestEventsUpdateResults{}, err
+			}
+			return api.BacktestEventsUpdateResults{
+				Events:   events.After(params.AfterSequence),
+				Finished: events.Closed,
+			}, nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("setting events query handler: %w", err)
+	}
+
+	// Receive the events from the other workflows
+	signals := workflow.GetSignalChannel(ctx, api.BacktestEventSignalName)
+	workflow.Go(ctx, func(ctx workflow.Context) {
+		for {
+			var evt backtest.Event
+			if more := signals.Receive(ctx, &evt); !more {
+				return
+			}
+			if !events.Closed {
+				events.Append(evt)
+			}
+		}
+	})
+
+	return events, nil
+}
+
+// checkEventsTenant checks that the events of the run are requested by the
+// tenant owning the backtest.
+func checkEventsTenant(owner string, params api.BacktestEventsUpdateParams) error {
+	if params.Tenant != owner {
+		return fmt.Errorf("%w: events of backtest %s", tenant.ErrMismatch, params.BacktestID)
+	}
+	return nil
+}
+
+// closeBacktestEvents closes the event log of a backtest run and waits for the
+// clients waiting for events to get the last ones.
+func closeBacktestEvents(ctx workflow.Context, events *backtest.EventLog) error {
+	events.Closed = true
+	return workflow.Await(ctx, func() bool {
+		return workflow.AllHandlersFinished(ctx)
+	})
+}
+
+// notifyBacktestRun sends an event to the run of the backtest, if it is running.
+func notifyBacktestRun(ctx workflow.Context, evt backtest.Event) {
+	err := workflow.SignalExternalWorkflow(ctx,
+		api.RunBacktestWorkflowID(evt.BacktestID), "",
+		api.BacktestEventSignalName, evt).Get(ctx, nil)
+	if err != nil {
+		workflow.GetLogger(ctx).Debug("Cannot send event to backtest run",
+			"backtest_id", evt.BacktestID.String(),
+			"type", evt.Type.String(),
+			"error", err)
+	}
+}
+
+// statusChangedEvent returns the event of a new status of the backtest, with
+// the error that made the run fail, if any.
+func statusChangedEvent(bt backtest.Backtest, runErr error) backtest.Event {
+	evt := backtest.Event{
+		Type:       backtest.EventTypeStatusChanged,
+		BacktestID: bt.ID,
+		Time:       bt.CurrentCandlestick.Time,
+		Status:     bt.Status,
+	}
+	if runErr != nil {
+		evt.Error = runErr.Error()
+	}
+	return evt
+}
+
+// orderEvent returns the event of an order on the backtest, rejected with the
+// error if any or filled otherwise.
+func orderEvent(bt backtest.Backtest, o order.Order, rejection error) backtest.Event {
+	evt := backtest.Event{
+		Type:       backtest.EventTypeOrderFilled,
+		BacktestID: bt.ID,
+		Time:       bt.CurrentCandlestick.Time,
+		Order:      &o,
+	}
+	if rejection != nil {
+		evt.Type = backtest.EventTypeOrderRejected
+		evt.Error = rejection.Error()
+	}
+	return evt
+}
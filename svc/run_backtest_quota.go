@@ -0,0 +1,95 @@
+package svc
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/quota"
+	"github.com/cryptellation/backtests/pkg/tenant"
+	"github.com/cryptellation/backtests/svc/quotas"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/workflow"
+)
+
+// acquireQuota waits for a slot of the tenant quota for the backtest run, then
+// returns the function releasing it. The excess runs wait in FIFO order.
+func (wf *workflows) acquireQuota(ctx workflow.Context, backtestID uuid.UUID) (func(), error) {
+	logger := workflow.GetLogger(ctx)
+
+	bt, err := wf.readBacktestFromDB(ctx, backtestID)
+	if err != nil {
+		return nil, fmt.Errorf("read backtest from db: %w", err)
+	}
+
+	owner := tenant.FromWorkflow(ctx)
+	run := quota.Run{
+		WorkflowID:  workflow.GetInfo(ctx).WorkflowExecution.ID,
+		BacktestID:  backtestID,
+		Steps:       bt.RemainingSteps(),
+		RequestedAt: workflow.Now(ctx),
+	}
+	release := func() {
+		ctx, _ := workflow.NewDisconnectedContext(ctx)
+		err := workflow.SignalExternalWorkflow(ctx, api.TenantQuotaWorkflowID(owner), "",
+			api.QuotaReleaseSignalName, run.WorkflowID).Get(ctx, nil)
+		if err != nil {
+			logger.Warn("Cannot release the quota of the backtest run",
+				"backtest_id", backtestID.String(),
+				"error", err)
+		}
+	}
+
+	// Request a slot
+	var req quotas.RequestQuotaActivityResults
+	err = workflow.ExecuteActivity(
+		workflow.WithActivityOptions(ctx, quotas.DefaultActivityOptions()),
+		quotas.RequestQuotaActivityName, quotas.RequestQuotaActivityParams{
+			Tenant: owner,
+			Run:    run,
+		}).Get(ctx, &req)
+	if err != nil {
+		return nil, fmt.Errorf("requesting quota: %w", err)
+	}
+
+	// Wait for it
+	logger.Info("Waiting for the tenant quota",
+		"backtest_id", backtestID.String(),
+		"steps", run.Steps)
+	res, timedOut := waitQuota(ctx, req.MaxWaitTime)
+	switch {
+	case ctx.Err() != nil:
+		release()
+		return nil, ctx.Err()
+	case timedOut:
+		release()
+		return nil, fmt.Errorf("%w: no slot of the tenant quota after %s, the maximum wait time of the quota",
+			quota.ErrWaitTimeout, req.MaxWaitTime)
+	case res.Error != "":
+		return nil, fmt.Errorf("%w: refused by the tenant quota: %s", quota.ErrExceeded, res.Error)
+	}
+
+	return release, nil
+}
+
+// waitQuota waits for the response of the tenant quota to the request of a
+// slot. It returns true if there has been none for the maximum wait time (0 is
+// no limit), or if the context has been canceled.
+func waitQuota(ctx workflow.Context, maxWait time.Duration) (res api.QuotaResponseSignalParams, timedOut bool) {
+	timerCtx, cancelTimer := workflow.WithCancel(ctx)
+	defer cancelTimer()
+
+	selector := workflow.NewSelector(ctx)
+	selector.AddReceive(workflow.GetSignalChannel(ctx, api.QuotaResponseSignalName),
+		func(c workflow.ReceiveChannel, _ bool) {
+			c.Receive(ctx, &res)
+		})
+	if maxWait > 0 {
+		selector.AddFuture(workflow.NewTimer(timerCtx, maxWait), func(workflow.Future) {
+			timedOut = true
+		})
+	}
+
+	selector.Select(ctx)
+	return res, timedOut
+}
@@ -0,0 +1,197 @@
+package svc
+
+import (
+	"fmt"
+	"maps"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/backtests/pkg/sweep"
+	"github.com/cryptellation/backtests/pkg/tenant"
+	"github.com/cryptellation/runtime"
+	"go.temporal.io/sdk/workflow"
+)
+
+// RunParameterSweepWorkflow runs a backtest for each combination of strategy
+// parameters from a search space and ranks them on a metric.
+func (wf *workflows) RunParameterSweepWorkflow(
+	ctx workflow.Context,
+	params api.RunParameterSweepWorkflowParams,
+) (api.RunParameterSweepWorkflowResults, error) {
+	logger := workflow.GetLogger(ctx)
+
+	// Check parameters
+	params, err := checkParameterSweepParams(ctx, params)
+	if err != nil {
+		return api.RunParameterSweepWorkflowResults{}, err
+	}
+
+	// Get the combinations of parameters
+	combinations, err := params.SearchSpace.Combinations()
+	if err != nil {
+		return api.RunParameterSweepWorkflowResults{}, fmt.Errorf("getting search space combinations: %w", err)
+	}
+	logger.Info("Running parameter sweep",
+		"combinations", len(combinations),
+		"max_concurrency", params.MaxConcurrency)
+
+	// Run the backtests with a limited concurrency
+	results := make([]sweep.Result, len(combinations))
+	sem := workflow.NewSemaphore(ctx, int64(params.MaxConcurrency))
+	wg := workflow.NewWaitGroup(ctx)
+	for i, combination := range combinations {
+		if err := sem.Acquire(ctx, 1); err != nil {
+			return api.RunParameterSweepWorkflowResults{}, err
+		}
+
+		p := withStrategyParameters(params.BacktestParameters, combination)
+		wg.Add(1)
+		workflow.Go(ctx, func(ctx workflow.Context) {
+			defer wg.Done()
+			defer sem.Release(1)
+			results[i] = wf.runSweepBacktest(ctx, p, params.Callbacks, params.Metric, params.QuoteAsset)
+		})
+	}
+	wg.Wait(ctx)
+
+	// Rank the results
+	sweep.RankResults(results, params.Metric)
+	return api.RunParameterSweepWorkflowResults{
+		Results: results,
+	}, nil
+}
+
+func checkParameterSweepParams(
+	ctx workflow.Context,
+	params api.RunParameterSweepWorkflowParams,
+) (api.RunParameterSweepWorkflowParams, error) {
+	if err := params.Callbacks.Validate(); err != nil {
+		return params, fmt.Errorf("validating callbacks: %w", err)
+	}
+
+	btParams, err := sharedBacktestParameters(ctx, params.BacktestParameters)
+	if err != nil {
+		return params, err
+	}
+	params.BacktestParameters = btParams
+
+	if params.Metric == "" {
+		params.Metric = backtest.MetricReturn
+	} else if err := params.Metric.Validate(); err != nil {
+		return params, fmt.Errorf("%w: %q", err, params.Metric)
+	}
+
+	if params.QuoteAsset == "" {
+		return params, fmt.Errorf("%w: empty", backtest.ErrInvalidQuoteAsset)
+	}
+
+	if params.MaxConcurrency <= 0 {
+		params.MaxConcurrency = sweep.DefaultMaxConcurrency
+	}
+
+	return params, nil
+}
+
+// withStrategyParameters returns a copy of the backtest parameters where the
+// strategy parameters are overridden by the given ones.
+func withStrategyParameters(params backtest.Parameters, strategyParams map[string]any) backtest.Parameters {
+	merged := maps.Clone(params.StrategyParameters)
+	if merged == nil {
+		merged = make(map[string]any, len(strategyParams))
+	}
+	maps.Copy(merged, strategyParams)
+
+	params.StrategyParameters = merged
+	return params
+}
+
+func (wf *workflows) runSweepBacktest(
+	ctx workflow.Context,
+	params backtest.Parameters,
+	callbacks runtime.Callbacks,
+	metric backtest.Metric,
+	quoteAsset string,
+) sweep.Result {
+	res := sweep.Result{
+		Parameters: params.StrategyParameters,
+	}
+
+	bt, err := wf.runChildBacktest(ctx, params, callbacks)
+	res.BacktestID = bt.ID
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	endPrices, err := wf.getEndPrices(ctx, bt, quoteAsset)
+	if err != nil {
+		res.Error = fmt.Sprintf("getting end prices: %s", err)
+		return res
+	}
+
+	res.Stats, err = bt.Stats(quoteAsset, endPrices)
+	if err != nil {
+		res.Error = fmt.Sprintf("computing backtest stats: %s", err)
+		return res
+	}
+	res.Score = metric.Value(res.Stats)
+
+	return res
+}
+
+// sharedBacktestParameters sets the default values of backtest parameters
+// shared by several backtests, so they all run on the same period.
+func sharedBacktestParameters(ctx workflow.Context, params backtest.Parameters) (backtest.Parameters, error) {
+	if params.EndTime == nil {
+		now := workflow.Now(ctx)
+		params.EndTime = &now
+	}
+
+	if err := params.EmptyFieldsToDefault().Validate(); err != nil {
+		return backtest.Parameters{}, fmt.Errorf("validating backtest parameters: %w", err)
+	}
+
+	return params, nil
+}
+
+// runChildBacktest creates and runs a backtest as child workflows, then returns
+// it once it is over. The returned backtest ID is set as soon as it is created.
+func (wf *workflows) runChildBacktest(
+	ctx workflow.Context,
+	params backtest.Parameters,
+	callbacks runtime.Callbacks,
+) (backtest.Backtest, error) {
+	ctx = workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+		TaskQueue: api.WorkerTaskQueueName,
+	})
+
+	// Create the backtest
+	var createRes api.CreateBacktestWorkflowResults
+	err := workflow.ExecuteChildWorkflow(ctx, api.CreateBacktestWorkflowName, api.CreateBacktestWorkflowParams{
+		BacktestParameters: params,
+		Callbacks:          callbacks,
+		Tenant:             tenant.FromWorkflow(ctx),
+	}).Get(ctx, &createRes)
+	if err != nil {
+		return backtest.Backtest{}, fmt.Errorf("creating backtest: %w", err)
+	}
+
+	// Run the backtest
+	var runRes api.RunBacktestWorkflowResults
+	runCtx := workflow.WithWorkflowID(ctx, api.RunBacktestWorkflowID(createRes.ID))
+	err = workflow.ExecuteChildWorkflow(runCtx, api.RunBacktestWorkflowName, api.RunBacktestWorkflowParams{
+		BacktestID: createRes.ID,
+		Tenant:     tenant.FromWorkflow(ctx),
+	}).Get(ctx, &runRes)
+	if err != nil {
+		return backtest.Backtest{ID: createRes.ID}, fmt.Errorf("running backtest: %w", err)
+	}
+
+	// Get the final state of the backtest
+	bt, err := wf.readBacktestFromDB(ctx, createRes.ID)
+	if err != nil {
+		return backtest.Backtest{ID: createRes.ID}, fmt.Errorf("reading backtest from db: %w", err)
+	}
+
+	return bt, nil
+}
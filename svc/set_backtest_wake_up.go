@@ -0,0 +1,32 @@
+package svc
+
+import (
+	"fmt"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"go.temporal.io/sdk/workflow"
+)
+
+func (wf *workflows) SetBacktestWakeUpWorkflow(
+	ctx workflow.Context,
+	params api.SetBacktestWakeUpWorkflowParams,
+) (api.SetBacktestWakeUpWorkflowResults, error) {
+	logger := workflow.GetLogger(ctx)
+
+	// Set wake up conditions
+	_, err := wf.updateBacktest(ctx, params.BacktestID, func(bt *backtest.Backtest) error {
+		if err := bt.SetWakeUp(params.WakeUp); err != nil {
+			return fmt.Errorf("cannot set wake up: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return api.SetBacktestWakeUpWorkflowResults{}, err
+	}
+	logger.Debug("Wake up set",
+		"wake_up", params.WakeUp,
+		"backtest_id", params.BacktestID.String())
+
+	return api.SetBacktestWakeUpWorkflowResults{}, nil
+}
@@ -1,10 +1,11 @@
 package svc
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/cryptellation/backtests/api"
-	"github.com/cryptellation/backtests/svc/db"
+	"github.com/cryptellation/backtests/pkg/backtest"
 	"go.temporal.io/sdk/workflow"
 )
 
@@ -14,31 +15,26 @@ func (wf *workflows) SubscribeToPriceWorkflow(
 ) (api.SubscribeToPriceWorkflowResults, error) {
 	logger := workflow.GetLogger(ctx)
 
-	// Read backtest
-	bt, err := wf.readBacktestFromDB(ctx, params.BacktestID)
-	if err != nil {
-		return api.SubscribeToPriceWorkflowResults{}, fmt.Errorf("read backtest from db: %w", err)
-	}
-
-	// Add subscription
-	if _, err := bt.CreateTickSubscription(params.Exchange, params.Pair); err != nil {
-		return api.SubscribeToPriceWorkflowResults{}, fmt.Errorf("cannot create subscription: %w", err)
+	// Add subscription, already existing ones being kept (i.e. on forked backtests)
+	_, err := wf.updateBacktest(ctx, params.BacktestID, func(bt *backtest.Backtest) error {
+		if _, err := bt.CreateTickSubscription(params.Exchange, params.Pair); err != nil {
+			return fmt.Errorf("cannot create subscription: %w", err)
+		}
+		return nil
+	})
+	if errors.Is(err, backtest.ErrTickSubscriptionAlreadyExists) {
+		logger.Debug("Already subscribed to price",
+			"exchange", params.Exchange,
+			"pair", params.Pair,
+			"backtest_id", params.BacktestID.String())
+		return api.SubscribeToPriceWorkflowResults{}, nil
+	} else if err != nil {
+		return api.SubscribeToPriceWorkflowResults{}, err
 	}
 	logger.Debug("Subscribed to price",
 		"exchange", params.Exchange,
 		"pair", params.Pair,
-		"backtest_id", bt.ID.String())
-
-	// Save backtest
-	var writeRes db.UpdateBacktestActivityResults
-	err = workflow.ExecuteActivity(
-		workflow.WithActivityOptions(ctx, db.DefaultActivityOptions()),
-		wf.db.UpdateBacktestActivity, db.UpdateBacktestActivityParams{
-			Backtest: bt,
-		}).Get(ctx, &writeRes)
-	if err != nil {
-		return api.SubscribeToPriceWorkflowResults{}, fmt.Errorf("save backtest to db: %w", err)
-	}
+		"backtest_id", params.BacktestID.String())
 
 	return api.SubscribeToPriceWorkflowResults{}, nil
 }
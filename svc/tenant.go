@@ -0,0 +1,38 @@
+package svc
+
+import (
+	"reflect"
+
+	"github.com/cryptellation/backtests/pkg/tenant"
+	"go.temporal.io/sdk/workflow"
+)
+
+// withTenant sets the tenant of the workflow parameters on the workflow
+// context, so the workflow only works on the backtests of this tenant. The
+// parameters without Tenant field keep the propagated one.
+func withTenant[P, R any](
+	fn func(workflow.Context, P) (R, error),
+) func(workflow.Context, P) (R, error) {
+	return func(ctx workflow.Context, params P) (R, error) {
+		ctx, err := tenant.Resolve(ctx, paramsTenant(params))
+		if err != nil {
+			var res R
+			return res, err
+		}
+		return fn(ctx, params)
+	}
+}
+
+// paramsTenant returns the Tenant field of the workflow parameters, if any.
+func paramsTenant(params any) string {
+	v := reflect.ValueOf(params)
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+
+	f := v.FieldByName("Tenant")
+	if !f.IsValid() || f.Kind() != reflect.String {
+		return ""
+	}
+	return f.String()
+}
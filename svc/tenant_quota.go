@@ -0,0 +1,189 @@
+package svc
+
+import (
+	"time"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/quota"
+	"github.com/cryptellation/backtests/svc/quotas"
+	"go.temporal.io/sdk/workflow"
+)
+
+// TenantQuotaWorkflow manages the quota of the running backtests of a tenant:
+// it receives the requests and releases of slots, and notifies the queued runs
+// once they can start, in order of request. The runs that ended without
+// releasing their slot are checked every api.QuotaCheckInterval and removed.
+func (wf *workflows) TenantQuotaWorkflow(
+	ctx workflow.Context,
+	params api.TenantQuotaWorkflowParams,
+) (api.TenantQuotaWorkflowResults, error) {
+	q := &tenantQuota{
+		tenant:   params.Tenant,
+		state:    params.State,
+		requests: workflow.GetSignalChannel(ctx, api.QuotaRequestSignalName),
+		releases: workflow.GetSignalChannel(ctx, api.QuotaReleaseSignalName),
+		checkAt:  workflow.Now(ctx).Add(api.QuotaCheckInterval),
+	}
+
+	// Let the clients get the usage of the quota
+	err := workflow.SetQueryHandler(ctx, api.QuotaUsageQueryName,
+		func(_ api.QuotaUsageQueryParams) (api.QuotaUsageQueryResults, error) {
+			return api.QuotaUsageQueryResults{Usage: q.state.Usage(q.tenant)}, nil
+		})
+	if err != nil {
+		return api.TenantQuotaWorkflowResults{}, err
+	}
+
+	for {
+		idle := q.wait(ctx)
+
+		// Handle all the pending signals before ending or continuing as new
+		if !idle && !workflow.GetInfo(ctx).GetContinueAsNewSuggested() {
+			continue
+		}
+		q.receivePending(ctx)
+
+		switch {
+		case idle && q.state.Idle():
+			return api.TenantQuotaWorkflowResults{}, nil
+		case workflow.GetInfo(ctx).GetContinueAsNewSuggested():
+			return api.TenantQuotaWorkflowResults{}, workflow.NewContinueAsNewError(ctx,
+				api.TenantQuotaWorkflowName, api.TenantQuotaWorkflowParams{
+					State:  q.state,
+					Tenant: q.tenant,
+				})
+		}
+	}
+}
+
+// tenantQuota is the state of the TenantQuotaWorkflow workflow.
+type tenantQuota struct {
+	tenant   string
+	state    quota.State
+	requests workflow.ReceiveChannel
+	releases workflow.ReceiveChannel
+	// checkAt is the time of the next check of the ended runs.
+	checkAt time.Time
+}
+
+// wait handles the next signal, and returns true if there has been none for
+// api.QuotaIdleTimeout while the quota was unused.
+func (q *tenantQuota) wait(ctx workflow.Context) (idle bool) {
+	timerCtx, cancelTimer := workflow.WithCancel(ctx)
+	defer cancelTimer()
+
+	selector := workflow.NewSelector(ctx)
+	selector.AddReceive(q.requests, func(c workflow.ReceiveChannel, _ bool) {
+		var req api.QuotaRequestSignalParams
+		c.Receive(ctx, &req)
+		q.request(ctx, req)
+	})
+	selector.AddReceive(q.releases, func(c workflow.ReceiveChannel, _ bool) {
+		var workflowID string
+		c.Receive(ctx, &workflowID)
+		q.release(ctx, workflowID)
+	})
+	if q.state.Idle() {
+		selector.AddFuture(workflow.NewTimer(timerCtx, api.QuotaIdleTimeout), func(workflow.Future) {
+			idle = true
+		})
+	} else {
+		selector.AddFuture(workflow.NewTimer(timerCtx, max(q.checkAt.Sub(workflow.Now(ctx)), 0)), func(workflow.Future) {
+			q.reclaim(ctx)
+		})
+	}
+
+	selector.Select(ctx)
+	return idle
+}
+
+// receivePending handles the signals received but not handled yet.
+func (q *tenantQuota) receivePending(ctx workflow.Context) {
+	for {
+		var req api.QuotaRequestSignalParams
+		var workflowID string
+		switch {
+		case q.requests.ReceiveAsync(&req):
+			q.request(ctx, req)
+		case q.releases.ReceiveAsync(&workflowID):
+			q.release(ctx, workflowID)
+		default:
+			return
+		}
+	}
+}
+
+func (q *tenantQuota) request(ctx workflow.Context, req api.QuotaRequestSignalParams) {
+	if err := req.Limits.Validate(); err == nil {
+		q.state.Limits = req.Limits
+	}
+
+	if err := q.state.Request(req.Run); err != nil {
+		q.respond(ctx, req.Run, err)
+		return
+	}
+	q.grant(ctx)
+}
+
+func (q *tenantQuota) release(ctx workflow.Context, workflowID string) {
+	q.state.Release(workflowID)
+	q.grant(ctx)
+}
+
+// reclaim removes the runs that ended without releasing their slot (i.e.
+// terminated, timed out or reset), and gives their slots to the queued runs.
+func (q *tenantQuota) reclaim(ctx workflow.Context) {
+	logger := workflow.GetLogger(ctx)
+	q.checkAt = workflow.Now(ctx).Add(api.QuotaCheckInterval)
+
+	var res quotas.CheckQuotaRunsActivityResults
+	err := workflow.ExecuteActivity(
+		workflow.WithActivityOptions(ctx, quotas.DefaultActivityOptions()),
+		quotas.CheckQuotaRunsActivityName, quotas.CheckQuotaRunsActivityParams{
+			WorkflowIDs: q.state.WorkflowIDs(),
+		}).Get(ctx, &res)
+	if err != nil {
+		logger.Warn("Cannot check the runs of the tenant quota",
+			"tenant", q.tenant,
+			"error", err)
+		return
+	}
+
+	for _, id := range res.Ended {
+		if q.state.Release(id) {
+			logger.Warn("Reclaiming the slot of an ended backtest run",
+				"tenant", q.tenant,
+				"workflow_id", id)
+		}
+	}
+	q.grant(ctx)
+}
+
+// grant notifies the queued runs that can start. The runs that can't be
+// notified anymore (e.g. terminated) free their slot.
+func (q *tenantQuota) grant(ctx workflow.Context) {
+	for granted := q.state.Grant(); len(granted) > 0; granted = q.state.Grant() {
+		for _, r := range granted {
+			if err := q.respond(ctx, r, nil); err != nil {
+				q.state.Release(r.WorkflowID)
+			}
+		}
+	}
+}
+
+// respond notifies the run that it can start, or why it can't.
+func (q *tenantQuota) respond(ctx workflow.Context, r quota.Run, refusal error) error {
+	var res api.QuotaResponseSignalParams
+	if refusal != nil {
+		res.Error = refusal.Error()
+	}
+
+	err := workflow.SignalExternalWorkflow(ctx, r.WorkflowID, "", api.QuotaResponseSignalName, res).Get(ctx, nil)
+	if err != nil {
+		workflow.GetLogger(ctx).Warn("Cannot notify backtest run of its quota",
+			"tenant", q.tenant,
+			"workflow_id", r.WorkflowID,
+			"error", err)
+	}
+	return err
+}
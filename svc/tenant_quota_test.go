@@ -0,0 +1,122 @@
+//go:build unit
+// +build unit
+
+package svc
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/quota"
+	"github.com/cryptellation/backtests/svc/quotas"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+func TestTenantQuotaSuite(t *testing.T) {
+	suite.Run(t, new(TenantQuotaSuite))
+}
+
+type TenantQuotaSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+}
+
+func (suite *TenantQuotaSuite) TestReclaimEndedRuns() {
+	env := suite.NewTestWorkflowEnvironment()
+	wf := &workflows{}
+	env.RegisterWorkflowWithOptions(wf.TenantQuotaWorkflow,
+		workflow.RegisterOptions{Name: api.TenantQuotaWorkflowName})
+
+	// The run "ended" has been terminated without releasing its slot, then the
+	// run "waiting" ends normally once started
+	var checks int
+	env.RegisterActivityWithOptions(func(
+		_ context.Context,
+		params quotas.CheckQuotaRunsActivityParams,
+	) (quotas.CheckQuotaRunsActivityResults, error) {
+		checks++
+		if checks == 1 {
+			return quotas.CheckQuotaRunsActivityResults{Ended: []string{"ended"}}, nil
+		}
+		return quotas.CheckQuotaRunsActivityResults{Ended: params.WorkflowIDs}, nil
+	}, activity.RegisterOptions{Name: quotas.CheckQuotaRunsActivityName})
+
+	var started []string
+	env.OnSignalExternalWorkflow(mock.Anything, mock.Anything, "", api.QuotaResponseSignalName, mock.Anything).
+		Return(func(_, workflowID, _, _ string, _ any) error {
+			started = append(started, workflowID)
+			return nil
+		})
+
+	// The waiting run gets the reclaimed slot after the first check
+	var usage quota.Usage
+	env.RegisterDelayedCallback(func() {
+		value, err := env.QueryWorkflow(api.QuotaUsageQueryName, api.QuotaUsageQueryParams{})
+		suite.Require().NoError(err)
+
+		var res api.QuotaUsageQueryResults
+		suite.Require().NoError(value.Get(&res))
+		usage = res.Usage
+	}, api.QuotaCheckInterval+time.Second)
+
+	env.ExecuteWorkflow(api.TenantQuotaWorkflowName, api.TenantQuotaWorkflowParams{
+		Tenant: "tenant",
+		State: quota.State{
+			Limits:  quota.Limits{MaxRunningBacktests: 1},
+			Running: []quota.Run{{WorkflowID: "ended", Steps: 10}},
+			Queue:   []quota.Run{{WorkflowID: "waiting", Steps: 10}},
+		},
+	})
+
+	suite.Require().True(env.IsWorkflowCompleted())
+	suite.Require().NoError(env.GetWorkflowError())
+	suite.Require().Equal([]string{"waiting"}, started)
+	suite.Require().Equal(1, usage.RunningBacktests)
+	suite.Require().True(slices.ContainsFunc(usage.Running, func(r quota.Run) bool {
+		return r.WorkflowID == "waiting"
+	}))
+	suite.Require().Equal(2, checks)
+}
+
+func (suite *TenantQuotaSuite) TestWaitQuota() {
+	waitQuotaWorkflow := func(ctx workflow.Context, maxWait time.Duration) (bool, error) {
+		_, timedOut := waitQuota(ctx, maxWait)
+		return timedOut, nil
+	}
+
+	cases := []struct {
+		Name     string
+		MaxWait  time.Duration
+		TimedOut bool
+	}{
+		{Name: "no limit", MaxWait: 0, TimedOut: false},
+		{Name: "limit", MaxWait: time.Hour, TimedOut: true},
+	}
+	for _, c := range cases {
+		suite.Run(c.Name, func() {
+			env := suite.NewTestWorkflowEnvironment()
+			env.RegisterWorkflowWithOptions(waitQuotaWorkflow,
+				workflow.RegisterOptions{Name: "WaitQuotaWorkflow"})
+
+			// The slot is only granted after two days
+			env.RegisterDelayedCallback(func() {
+				env.SignalWorkflow(api.QuotaResponseSignalName, api.QuotaResponseSignalParams{})
+			}, 48*time.Hour)
+
+			env.ExecuteWorkflow("WaitQuotaWorkflow", c.MaxWait)
+			suite.Require().True(env.IsWorkflowCompleted())
+			suite.Require().NoError(env.GetWorkflowError())
+
+			var timedOut bool
+			suite.Require().NoError(env.GetWorkflowResult(&timedOut))
+			suite.Require().Equal(c.TimedOut, timedOut)
+		})
+	}
+}
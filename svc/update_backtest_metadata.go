@@ -0,0 +1,45 @@
+package svc
+
+import (
+	"fmt"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/svc/db"
+	"go.temporal.io/sdk/workflow"
+)
+
+func (wf *workflows) UpdateBacktestMetadataWorkflow(
+	ctx workflow.Context,
+	params api.UpdateBacktestMetadataWorkflowParams,
+) (api.UpdateBacktestMetadataWorkflowResults, error) {
+	// Check parameters
+	if err := params.Metadata.Validate(); err != nil {
+		return api.UpdateBacktestMetadataWorkflowResults{}, fmt.Errorf("validating metadata: %w", err)
+	}
+
+	// Read backtest
+	bt, err := wf.readBacktestFromDB(ctx, params.BacktestID)
+	if err != nil {
+		return api.UpdateBacktestMetadataWorkflowResults{}, fmt.Errorf("read backtest from db: %w", err)
+	}
+
+	// Update the metadata only, as the rest of the backtest can be updated
+	// by a running backtest at the same time
+	if err := bt.UpdateMetadata(params.Metadata); err != nil {
+		return api.UpdateBacktestMetadataWorkflowResults{}, fmt.Errorf("cannot update metadata: %w", err)
+	}
+
+	var res db.UpdateBacktestMetadataActivityResults
+	err = workflow.ExecuteActivity(
+		workflow.WithActivityOptions(ctx, db.DefaultActivityOptions()),
+		wf.db.UpdateBacktestMetadataActivity, db.UpdateBacktestMetadataActivityParams{
+			Backtest: bt,
+		}).Get(ctx, &res)
+	if err != nil {
+		return api.UpdateBacktestMetadataWorkflowResults{}, fmt.Errorf("save backtest metadata to db: %w", err)
+	}
+
+	return api.UpdateBacktestMetadataWorkflowResults{
+		Backtest: bt.WithoutSecrets(),
+	}, nil
+}
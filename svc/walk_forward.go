@@ -0,0 +1,133 @@
+package svc
+
+import (
+	"fmt"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/backtests/pkg/sweep"
+	"github.com/cryptellation/backtests/pkg/tenant"
+	"go.temporal.io/sdk/workflow"
+)
+
+// WalkForwardWorkflow optimizes the strategy parameters on rolling in-sample
+// windows and applies the best ones on the following out-of-sample windows.
+func (wf *workflows) WalkForwardWorkflow(
+	ctx workflow.Context,
+	params api.WalkForwardWorkflowParams,
+) (api.WalkForwardWorkflowResults, error) {
+	logger := workflow.GetLogger(ctx)
+
+	// Check parameters with the ones of the parameter search
+	sweepParams, err := checkParameterSweepParams(ctx, api.RunParameterSweepWorkflowParams{
+		BacktestParameters: params.BacktestParameters,
+		Callbacks:          params.Callbacks,
+		SearchSpace:        params.SearchSpace,
+		MaxConcurrency:     params.MaxConcurrency,
+		Metric:             params.Metric,
+		QuoteAsset:         params.QuoteAsset,
+		Tenant:             tenant.FromWorkflow(ctx),
+	})
+	if err != nil {
+		return api.WalkForwardWorkflowResults{}, err
+	}
+	if err := sweepParams.SearchSpace.Validate(); err != nil {
+		return api.WalkForwardWorkflowResults{}, err
+	}
+
+	// Split the period into windows
+	windows, err := sweep.Windows(
+		sweepParams.BacktestParameters.StartTime, *sweepParams.BacktestParameters.EndTime,
+		params.InSample, params.OutOfSample)
+	if err != nil {
+		return api.WalkForwardWorkflowResults{}, err
+	}
+	logger.Info("Running walk-forward optimization",
+		"windows", len(windows))
+
+	// Run each window
+	results := make([]sweep.WindowResult, len(windows))
+	curves := make([][]backtest.EquityPoint, len(windows))
+	var ordersCount int
+	for i, w := range windows {
+		results[i], err = wf.runWalkForwardWindow(ctx, sweepParams, w)
+		if err != nil {
+			return api.WalkForwardWorkflowResults{}, fmt.Errorf("walk-forward window %d: %w", i, err)
+		}
+		curves[i] = results[i].OutOfSampleCurve
+		ordersCount += results[i].OutOfSampleStats.OrdersCount
+	}
+
+	// Combine the out-of-sample results
+	curve := sweep.StitchEquityCurves(curves...)
+	return api.WalkForwardWorkflowResults{
+		Windows:     results,
+		EquityCurve: curve,
+		Stats:       backtest.NewStats(sweepParams.QuoteAsset, curve, ordersCount),
+	}, nil
+}
+
+func (wf *workflows) runWalkForwardWindow(
+	ctx workflow.Context,
+	params api.RunParameterSweepWorkflowParams,
+	w sweep.Window,
+) (sweep.WindowResult, error) {
+	baseParams := params.BacktestParameters
+
+	// Search the best parameters on the in-sample period
+	params.BacktestParameters.StartTime = w.InSampleStart
+	params.BacktestParameters.EndTime = &w.InSampleEnd
+
+	var sweepRes api.RunParameterSweepWorkflowResults
+	err := workflow.ExecuteChildWorkflow(
+		workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+			TaskQueue: api.WorkerTaskQueueName,
+		}),
+		api.RunParameterSweepWorkflowName, params).Get(ctx, &sweepRes)
+	if err != nil {
+		return sweep.WindowResult{}, fmt.Errorf("running in-sample parameter sweep: %w", err)
+	} else if len(sweepRes.Results) == 0 || sweepRes.Results[0].Error != "" {
+		return sweep.WindowResult{}, fmt.Errorf("in-sample parameter sweep: %w", sweep.ErrNoValidResult)
+	}
+	best := sweepRes.Results[0]
+
+	// Apply them on the out-of-sample period
+	oosParams := withStrategyParameters(baseParams, best.Parameters)
+	oosParams.StartTime = w.OutOfSampleStart
+	oosParams.EndTime = &w.OutOfSampleEnd
+
+	bt, err := wf.runChildBacktest(ctx, oosParams, params.Callbacks)
+	if err != nil {
+		return sweep.WindowResult{}, fmt.Errorf("running out-of-sample backtest: %w", err)
+	}
+
+	return wf.outOfSampleResult(ctx, w, best, bt, params.QuoteAsset)
+}
+
+// outOfSampleResult scores the out-of-sample backtest of a window, with its
+// open positions valued at their price at the end of the window.
+func (wf *workflows) outOfSampleResult(
+	ctx workflow.Context,
+	w sweep.Window,
+	best sweep.Result,
+	bt backtest.Backtest,
+	quoteAsset string,
+) (sweep.WindowResult, error) {
+	endPrices, err := wf.getEndPrices(ctx, bt, quoteAsset)
+	if err != nil {
+		return sweep.WindowResult{}, fmt.Errorf("getting out-of-sample end prices: %w", err)
+	}
+
+	curve, err := bt.EquityCurve(quoteAsset, endPrices)
+	if err != nil {
+		return sweep.WindowResult{}, fmt.Errorf("computing out-of-sample equity curve: %w", err)
+	}
+
+	return sweep.WindowResult{
+		Window:                w,
+		InSample:              best,
+		OutOfSampleBacktestID: bt.ID,
+		OutOfSampleStats:      backtest.NewStats(quoteAsset, curve, len(bt.Orders)),
+		OutOfSampleCurve:      curve,
+	}, nil
+}
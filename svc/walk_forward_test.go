@@ -0,0 +1,68 @@
+//go:build unit
+// +build unit
+
+package svc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/backtests/pkg/sweep"
+	"github.com/cryptellation/runtime/account"
+	"github.com/cryptellation/runtime/order"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+func TestWalkForwardSuite(t *testing.T) {
+	suite.Run(t, new(WalkForwardSuite))
+}
+
+type WalkForwardSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+}
+
+func (suite *WalkForwardSuite) TestOutOfSampleResult() {
+	w := sweep.Window{
+		OutOfSampleStart: time.Unix(0, 0).UTC(),
+		OutOfSampleEnd:   time.Unix(600, 0).UTC(),
+	}
+	// Buy and hold: bought at 5, the close at the end of the window is 10
+	bt := backtest.Backtest{
+		StartTime:          w.OutOfSampleStart,
+		EndTime:            w.OutOfSampleEnd,
+		CurrentCandlestick: backtest.CurrentCandlestick{Time: w.OutOfSampleEnd},
+		Accounts: map[string]account.Account{
+			"exchange": {Balances: map[string]float64{"USDT": 500, "BTC": 100}},
+		},
+		Orders: []order.Order{
+			{Exchange: "exchange", Pair: "BTC-USDT", Side: order.SideIsBuy, Quantity: 100, Price: 5},
+		},
+	}
+	wf := &workflows{cryptellation: fixedCandlesticks{}}
+
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterWorkflowWithOptions(func(ctx workflow.Context) (sweep.WindowResult, error) {
+		return wf.outOfSampleResult(ctx, w, sweep.Result{}, bt, "USDT")
+	}, workflow.RegisterOptions{Name: "OutOfSampleResult"})
+
+	env.ExecuteWorkflow("OutOfSampleResult")
+	suite.Require().True(env.IsWorkflowCompleted())
+	suite.Require().NoError(env.GetWorkflowError())
+
+	var res sweep.WindowResult
+	suite.Require().NoError(env.GetWorkflowResult(&res))
+	suite.Require().Equal(float64(1500), res.OutOfSampleStats.FinalEquity)
+	suite.Require().InDelta(0.5, res.OutOfSampleStats.Return, 1e-9)
+
+	// A following window with the same return is compounded on this one
+	next := make([]backtest.EquityPoint, len(res.OutOfSampleCurve))
+	for i, p := range res.OutOfSampleCurve {
+		next[i] = backtest.EquityPoint{Time: p.Time.Add(600 * time.Second), Equity: p.Equity}
+	}
+	curve := sweep.StitchEquityCurves(res.OutOfSampleCurve, next)
+	suite.Require().InDelta(2250, curve[len(curve)-1].Equity, 1e-9)
+}
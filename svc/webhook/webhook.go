@@ -0,0 +1,176 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cryptellation/backtests/api"
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/backtests/svc/db"
+	"github.com/google/uuid"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+)
+
+// DefaultTimeout is the default timeout of the requests to the webhooks.
+const DefaultTimeout = 10 * time.Second
+
+// ErrRejected is returned when a webhook rejected the payload with a client
+// error, so that sending it again would not help.
+var ErrRejected = errors.New("webhook rejected the payload")
+
+// ErrorTypes are the webhook errors that can be returned by the activities.
+// They are all non-retryable.
+var ErrorTypes = []api.ErrorType{
+	{Name: "webhook.Rejected", Err: ErrRejected},
+}
+
+// NotifyWebhookActivityName is the name of the activity to notify a webhook.
+const NotifyWebhookActivityName = "NotifyWebhookActivity"
+
+type (
+	// NotifyWebhookActivityParams is the parameters of the NotifyWebhookActivity activity.
+	// The webhook is read from the backtest by the activity, so that its secret
+	// is not written in the workflow history.
+	NotifyWebhookActivityParams struct {
+		BacktestID   uuid.UUID
+		Tenant       string
+		WebhookIndex int
+		Payload      backtest.WebhookPayload
+	}
+
+	// NotifyWebhookActivityResults is the results of the NotifyWebhookActivity activity.
+	NotifyWebhookActivityResults struct{}
+)
+
+// DefaultActivityOptions returns the default webhook activities options, with
+// retries on an exponential backoff.
+func DefaultActivityOptions() workflow.ActivityOptions {
+	return workflow.ActivityOptions{
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:        time.Second,
+			BackoffCoefficient:     2,
+			MaximumInterval:        time.Minute,
+			MaximumAttempts:        10,
+			NonRetryableErrorTypes: append(api.ErrorTypeNames(ErrorTypes), api.ErrorTypeNames(db.ErrorTypes)...),
+		},
+		StartToCloseTimeout:    DefaultTimeout + 5*time.Second,
+		ScheduleToCloseTimeout: 5 * time.Minute,
+	}
+}
+
+// Backtests reads the backtests whose webhooks are notified.
+type Backtests interface {
+	ReadBacktestActivity(
+		ctx context.Context,
+		params db.ReadBacktestActivityParams,
+	) (db.ReadBacktestActivityResults, error)
+}
+
+// Activities are the activities notifying webhooks.
+type Activities struct {
+	backtests Backtests
+	client    *http.Client
+}
+
+// New creates new webhook activities reading the webhooks from the backtests
+// and sending the requests with the client, or with a client using
+// DefaultTimeout if nil.
+func New(backtests Backtests, client *http.Client) *Activities {
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	return &Activities{
+		backtests: backtests,
+		client:    client,
+	}
+}
+
+// Register registers the activities to the worker.
+func (a *Activities) Register(w worker.Worker) {
+	w.RegisterActivityWithOptions(
+		a.NotifyWebhookActivity,
+		activity.RegisterOptions{Name: NotifyWebhookActivityName},
+	)
+}
+
+// NotifyWebhookActivity sends the payload to the webhook, signed with its
+// secret if any. Server errors are retried while client errors are not.
+func (a *Activities) NotifyWebhookActivity(
+	ctx context.Context,
+	params NotifyWebhookActivityParams,
+) (NotifyWebhookActivityResults, error) {
+	// Read the webhook
+	w, err := a.readWebhook(ctx, params)
+	if err != nil {
+		return NotifyWebhookActivityResults{}, err
+	}
+
+	// Create the request
+	body, err := json.Marshal(params.Payload)
+	if err != nil {
+		return NotifyWebhookActivityResults{}, fmt.Errorf("encoding payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return NotifyWebhookActivityResults{}, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Sign the payload
+	if w.Secret != "" {
+		now := time.Now()
+		req.Header.Set(backtest.WebhookTimestampHeader, strconv.FormatInt(now.Unix(), 10))
+		req.Header.Set(backtest.WebhookSignatureHeader, backtest.SignWebhookPayload(w.Secret, now, body))
+	}
+
+	// Send it
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return NotifyWebhookActivityResults{}, fmt.Errorf("sending payload to webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	// Check the response
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return NotifyWebhookActivityResults{}, nil
+	case resp.StatusCode == http.StatusRequestTimeout,
+		resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode >= 500:
+		return NotifyWebhookActivityResults{}, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	default:
+		return NotifyWebhookActivityResults{}, api.NewApplicationError(
+			fmt.Errorf("%w: status %d", ErrRejected, resp.StatusCode), ErrorTypes)
+	}
+}
+
+// readWebhook reads the webhook to notify from its backtest.
+func (a *Activities) readWebhook(ctx context.Context, params NotifyWebhookActivityParams) (backtest.Webhook, error) {
+	res, err := a.backtests.ReadBacktestActivity(ctx, db.ReadBacktestActivityParams{
+		ID:     params.BacktestID,
+		Tenant: params.Tenant,
+	})
+	if err != nil {
+		return backtest.Webhook{}, api.NewApplicationError(fmt.Errorf("reading backtest: %w", err), db.ErrorTypes)
+	}
+
+	if params.WebhookIndex < 0 || params.WebhookIndex >= len(res.Backtest.Webhooks) {
+		return backtest.Webhook{}, api.NewApplicationError(
+			fmt.Errorf("%w: webhook %d of backtest %s", db.ErrNotFound, params.WebhookIndex, params.BacktestID),
+			db.ErrorTypes)
+	}
+
+	return res.Backtest.Webhooks[params.WebhookIndex], nil
+}
@@ -0,0 +1,139 @@
+//go:build unit
+// +build unit
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/backtests/svc/db"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/temporal"
+)
+
+func TestWebhookSuite(t *testing.T) {
+	suite.Run(t, new(WebhookSuite))
+}
+
+// backtestsStub returns the backtest with the webhooks.
+type backtestsStub struct {
+	webhooks []backtest.Webhook
+}
+
+func (s *backtestsStub) ReadBacktestActivity(
+	_ context.Context,
+	params db.ReadBacktestActivityParams,
+) (db.ReadBacktestActivityResults, error) {
+	return db.ReadBacktestActivityResults{
+		Backtest: backtest.Backtest{ID: params.ID, Tenant: params.Tenant, Webhooks: s.webhooks},
+	}, nil
+}
+
+type WebhookSuite struct {
+	suite.Suite
+	activities *Activities
+	backtests  *backtestsStub
+	status     int
+	requests   []*http.Request
+	bodies     [][]byte
+	server     *httptest.Server
+}
+
+func (suite *WebhookSuite) SetupTest() {
+	suite.backtests = &backtestsStub{}
+	suite.activities = New(suite.backtests, nil)
+	suite.status = http.StatusNoContent
+	suite.requests, suite.bodies = nil, nil
+	suite.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		suite.Require().NoError(err)
+		suite.requests = append(suite.requests, r)
+		suite.bodies = append(suite.bodies, body)
+		w.WriteHeader(suite.status)
+	}))
+}
+
+func (suite *WebhookSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *WebhookSuite) notify(secret string) error {
+	suite.backtests.webhooks = []backtest.Webhook{{URL: suite.server.URL, Secret: secret}}
+	_, err := suite.activities.NotifyWebhookActivity(context.Background(), NotifyWebhookActivityParams{
+		BacktestID:   uuid.New(),
+		WebhookIndex: 0,
+		Payload: backtest.WebhookPayload{
+			Event:    backtest.WebhookEventFinished,
+			Backtest: backtest.Summary{ID: uuid.New(), Status: backtest.StatusFinished},
+		},
+	})
+	return err
+}
+
+func (suite *WebhookSuite) TestNotifySigned() {
+	suite.Require().NoError(suite.notify("secret"))
+	suite.Require().Len(suite.requests, 1)
+
+	r, body := suite.requests[0], suite.bodies[0]
+	suite.Require().Equal(http.MethodPost, r.Method)
+	suite.Require().Equal("application/json", r.Header.Get("Content-Type"))
+	suite.Require().NoError(backtest.VerifyWebhookSignature("secret",
+		r.Header.Get(backtest.WebhookSignatureHeader),
+		r.Header.Get(backtest.WebhookTimestampHeader), body))
+	suite.Require().ErrorIs(backtest.VerifyWebhookSignature("other",
+		r.Header.Get(backtest.WebhookSignatureHeader),
+		r.Header.Get(backtest.WebhookTimestampHeader), body), backtest.ErrInvalidWebhookSignature)
+
+	var payload backtest.WebhookPayload
+	suite.Require().NoError(json.Unmarshal(body, &payload))
+	suite.Require().Equal(backtest.WebhookEventFinished, payload.Event)
+	suite.Require().Equal(backtest.StatusFinished, payload.Backtest.Status)
+}
+
+func (suite *WebhookSuite) TestNotifyUnsigned() {
+	suite.Require().NoError(suite.notify(""))
+	suite.Require().Len(suite.requests, 1)
+	suite.Require().Empty(suite.requests[0].Header.Get(backtest.WebhookSignatureHeader))
+}
+
+func (suite *WebhookSuite) TestNotifyServerErrorIsRetryable() {
+	suite.status = http.StatusServiceUnavailable
+
+	err := suite.notify("")
+	suite.Require().Error(err)
+
+	var appErr *temporal.ApplicationError
+	suite.Require().NotErrorAs(err, &appErr)
+}
+
+func (suite *WebhookSuite) TestNotifyClientErrorIsNotRetryable() {
+	suite.status = http.StatusBadRequest
+
+	err := suite.notify("")
+
+	var appErr *temporal.ApplicationError
+	suite.Require().ErrorAs(err, &appErr)
+	suite.Require().True(appErr.NonRetryable())
+	suite.Require().Contains(DefaultActivityOptions().RetryPolicy.NonRetryableErrorTypes, appErr.Type())
+}
+
+func (suite *WebhookSuite) TestNotifyUnknownWebhookIsNotRetryable() {
+	_, err := suite.activities.NotifyWebhookActivity(context.Background(), NotifyWebhookActivityParams{
+		BacktestID:   uuid.New(),
+		WebhookIndex: 1,
+	})
+	suite.Require().Empty(suite.requests)
+
+	var appErr *temporal.ApplicationError
+	suite.Require().ErrorAs(err, &appErr)
+	suite.Require().True(appErr.NonRetryable())
+	suite.Require().Equal("db.NotFound", appErr.Type())
+	suite.Require().Contains(DefaultActivityOptions().RetryPolicy.NonRetryableErrorTypes, appErr.Type())
+}
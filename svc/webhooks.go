@@ -0,0 +1,51 @@
+package svc
+
+import (
+	"github.com/cryptellation/backtests/pkg/backtest"
+	"github.com/cryptellation/backtests/svc/webhook"
+	"go.temporal.io/sdk/workflow"
+)
+
+// notifyWebhooks notifies the webhooks of the backtest about the end of its
+// run. Failures are only logged, as they should not fail the run.
+func notifyWebhooks(ctx workflow.Context, bt backtest.Backtest, aborted bool, runErr error) {
+	if len(bt.Webhooks) == 0 {
+		return
+	}
+
+	// Create the payload
+	payload := backtest.WebhookPayload{
+		Event:    backtest.WebhookEventFinished,
+		Time:     workflow.Now(ctx),
+		Backtest: bt.Summary(),
+	}
+	switch {
+	case runErr != nil:
+		payload.Event = backtest.WebhookEventFailed
+		payload.Error = runErr.Error()
+	case aborted:
+		payload.Event = backtest.WebhookEventAborted
+	}
+
+	// Notify all the webhooks at once
+	ctx = workflow.WithActivityOptions(ctx, webhook.DefaultActivityOptions())
+	futures := make([]workflow.Future, len(bt.Webhooks))
+	for i := range bt.Webhooks {
+		futures[i] = workflow.ExecuteActivity(ctx, webhook.NotifyWebhookActivityName,
+			webhook.NotifyWebhookActivityParams{
+				BacktestID:   bt.ID,
+				Tenant:       bt.Tenant,
+				WebhookIndex: i,
+				Payload:      payload,
+			})
+	}
+
+	for i, f := range futures {
+		if err := f.Get(ctx, nil); err != nil {
+			workflow.GetLogger(ctx).Warn("Cannot notify webhook",
+				"backtest_id", bt.ID.String(),
+				"url", bt.Webhooks[i].URL,
+				"error", err)
+		}
+	}
+}
@@ -70,7 +70,7 @@ func (suite *EndToEndSuite) TestBacktestRun() {
 	// GIVEN a running temporal worker
 
 	tq := "BacktestE2eRunner-TaskQueue"
-	w := worker.New(suite.temporalclient, tq, worker.Options{})
+	w := clients.NewWorker(suite.temporalclient, tq, worker.Options{})
 	go func() {
 		if err := w.Run(nil); err != nil {
 			suite.Require().NoError(err)
@@ -102,21 +102,36 @@ func (suite *EndToEndSuite) TestBacktestRun() {
 
 	// WHEN creating a new backtest
 
-	backtest, err := suite.client.NewBacktest(context.Background(), params, callbacks)
+	bt, err := suite.client.NewBacktest(context.Background(), params, callbacks)
 
 	// THEN no error is returned
 
 	suite.Require().NoError(err)
 
-	// WHEN running the backtest with a runner on the worker
+	// WHEN running the backtest with a runner on the worker while following its events
 
-	r.BacktestID = backtest.ID // Add backtest ID to runner for checking backtest run context
-	err = backtest.Run(context.Background())
+	r.BacktestID = bt.ID // Add backtest ID to runner for checking backtest run context
+	run, err := bt.Start(context.Background())
+	suite.Require().NoError(err)
+	events, errs := run.Events(context.Background())
+	err = run.Wait(context.Background())
 
 	// THEN no error is returned
 
 	suite.Require().NoError(err)
 
+	// AND the events of the run are received until its end
+
+	received := make([]backtest.Event, 0)
+	for evt := range events {
+		received = append(received, evt)
+	}
+	suite.Require().NoError(<-errs)
+	suite.Require().GreaterOrEqual(len(received), 3)
+	suite.Require().Equal(backtest.StatusRunning, received[0].Status)
+	suite.Require().Equal(backtest.EventTypeStepAdvanced, received[1].Type)
+	suite.Require().Equal(backtest.StatusFinished, received[len(received)-1].Status)
+
 	// AND the runner callbacks are called
 	suite.Require().Equal(1, r.OnInitCallsCount)
 	suite.Require().Equal(2, r.OnNewPricesCallsCount)